@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+	"github.com/ffuf/ffuf/pkg/filter"
+)
+
+//BENCH_DEFAULT_INPUTS is the wordlist of the benchmark when none is given
+const BENCH_DEFAULT_INPUTS = "range:1-10000"
+
+//runBench runs the bench subcommand, a scan with the given options against an in-process mock server, measuring the
+//requests per second the tool itself can do with the thread and matcher configuration, and returns the exit code
+func runBench(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	latency := fs.Int("latency", 0, "Milliseconds the mock server waits before responding")
+	size := fs.Int("size", 1024, "Size of the mock server response body in bytes")
+	status := fs.Int("status", 200, "Status code of the mock server responses")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s bench [-latency MS] [-size BYTES] [-status CODE] [-- FFUF OPTIONS]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Runs a scan against an in-process mock server, to measure the requests per second ffuf can do with the\nthreads, matchers and filters of the options, without the limits of the network and the target. The target\nURL is the mock server, and the wordlist defaults to %s.\n\n", BENCH_DEFAULT_INPUTS)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *latency < 0 || *size < 0 || *status < 100 || *status > 999 {
+		fs.Usage()
+		return 1
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not start the mock server: %s\n", err)
+		return 1
+	}
+	body := bytes.Repeat([]byte("a"), *size)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if *latency > 0 {
+			time.Sleep(time.Duration(*latency) * time.Millisecond)
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(*status)
+		_, _ = w.Write(body)
+	})}
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	// The ffuf options are parsed like the ones of a regular run
+	os.Args = append([]string{os.Args[0]}, fs.Args()...)
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	opts := ParseFlags(ffuf.NewConfigOptions())
+	opts.HTTP.URL = "http://" + listener.Addr().String() + "/FUZZ"
+	if len(opts.Input.Wordlists) == 0 && len(opts.Input.Inputcommands) == 0 {
+		opts.Input.Wordlists = []string{BENCH_DEFAULT_INPUTS}
+	}
+	opts.General.Noninteractive = true
+	log.SetOutput(ioutil.Discard)
+	if len(opts.Output.DebugLog) != 0 {
+		if f, err := os.OpenFile(opts.Output.DebugLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err == nil {
+			log.SetOutput(f)
+			defer f.Close()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conf, err := ffuf.ConfigFromOptions(opts, ctx, cancel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		return 1
+	}
+	job, err := prepareJob(conf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		return 1
+	}
+	if err := filter.SetupFilters(opts, conf); err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		return 1
+	}
+	if err := filter.CalibrateIfNeeded(job); err != nil {
+		fmt.Fprintf(os.Stderr, "Error in autocalibration, exiting: %s\n", err)
+		return 1
+	}
+	start := time.Now()
+	job.Start()
+	elapsed := time.Since(start)
+
+	fmt.Fprintf(os.Stderr, "\nBenchmark against the mock server (%d ms latency, %d byte responses):\n", *latency, *size)
+	fmt.Fprintf(os.Stderr, " :: Threads          : %d\n", conf.Threads)
+	fmt.Fprintf(os.Stderr, " :: Requests         : %d in %s\n", job.Counter, elapsed.Round(time.Millisecond))
+	fmt.Fprintf(os.Stderr, " :: Errors           : %d\n", job.ErrorCounter)
+	if elapsed > 0 {
+		fmt.Fprintf(os.Stderr, " :: Requests/sec     : %.0f\n", float64(job.Counter)/elapsed.Seconds())
+	}
+	if *latency > 0 {
+		// Every thread waits for the latency of each of its requests
+		fmt.Fprintf(os.Stderr, " :: Latency limit    : %.0f requests/sec\n", float64(conf.Threads)*1000/float64(*latency))
+	}
+	return 0
+}
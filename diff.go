@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+	"github.com/ffuf/ffuf/pkg/output"
+)
+
+//runDiff runs the diff subcommand, comparing the ejson or ndjson output files of two scans, and returns the exit code
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Write the diff as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s diff [-json] BASELINE CURRENT\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Reports the results of the ejson or ndjson output file CURRENT that are new, removed or changed\ncompared to the output file BASELINE of an earlier scan.\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 1
+	}
+	sets := make([][]ffuf.Result, 0, 2)
+	for _, filename := range fs.Args() {
+		results, err := output.ReadResultFile(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not read the results: %s\n", err)
+			return 1
+		}
+		sets = append(sets, results)
+	}
+	diff := output.DiffResults(sets[0], sets[1])
+	if *asJSON {
+		js, err := json.Marshal(diff)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not encode the diff: %s\n", err)
+			return 1
+		}
+		fmt.Printf("%s\n", js)
+		return 0
+	}
+	fmt.Print(diff.Report())
+	return 0
+}
+
+//readBaselineIfNeeded reads the results of the baseline scan to compare the run to (-diff)
+func readBaselineIfNeeded(conf *ffuf.Config) ([]ffuf.Result, error) {
+	if conf.DiffFile == "" {
+		return nil, nil
+	}
+	results, err := output.ReadResultFile(conf.DiffFile)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read the baseline results (-diff): %s", err)
+	}
+	return results, nil
+}
+
+//importResultsIfNeeded reads the results of an earlier scan to replay and recurse into (-import)
+func importResultsIfNeeded(conf *ffuf.Config) error {
+	if conf.ImportFile == "" {
+		return nil
+	}
+	results, err := output.ReadResultFile(conf.ImportFile)
+	if err != nil {
+		return fmt.Errorf("Could not read the imported results (-import): %s", err)
+	}
+	conf.ImportedResults = results
+	return nil
+}
+
+//printDiffIfNeeded prints the results of the run that are new, removed or changed compared to the baseline scan
+func printDiffIfNeeded(job *ffuf.Job, baseline []ffuf.Result) {
+	if job.Config.DiffFile == "" {
+		return
+	}
+	out, ok := job.Output.(*output.Stdoutput)
+	if !ok {
+		return
+	}
+	job.Output.Raw(output.DiffResults(baseline, out.AllResults()).Report())
+}
@@ -1,6 +1,6 @@
 module github.com/ffuf/ffuf
 
-go 1.13
+go 1.24
 
 require (
 	github.com/pelletier/go-toml v1.8.1
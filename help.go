@@ -54,14 +54,14 @@ func Usage() {
 		Description:   "Options controlling the HTTP request and its parts.",
 		Flags:         make([]UsageFlag, 0),
 		Hidden:        false,
-		ExpectedFlags: []string{"H", "X", "b", "d", "r", "u", "recursion", "recursion-depth", "recursion-strategy", "replay-proxy", "timeout", "ignore-body", "x", "sni"},
+		ExpectedFlags: []string{"H", "X", "b", "d", "r", "u", "recursion", "recursion-breadth", "recursion-depth", "recursion-depth-breadth", "recursion-exclude", "recursion-fingerprint", "recursion-include", "recursion-links", "recursion-max-jobs", "recursion-strategy", "recursion-truncate", "recursion-wordlist", "replay-proxy", "replay-dir", "replay-match", "retries", "retry-delay", "retry-on", "timeout", "fast-size", "ignore-body", "ip-rate", "ip-threads", "keep-alive", "conn-reuse", "max-idle", "prewarm", "max-body-size", "body-memory", "auth", "x", "proxy-backup", "proxy-fallback", "proxy-list", "proxy-rotate", "sni", "cc", "ck", "ca-cert", "tls-min", "tls-max", "tls-ciphers", "tls-keylog", "resolve", "resolvers", "unix", "http2", "http2-prior-knowledge", "grpc-reflection", "crawl", "crawl-depth", "crawl-pages"},
 	}
 	u_general := UsageSection{
 		Name:          "GENERAL OPTIONS",
 		Description:   "",
 		Flags:         make([]UsageFlag, 0),
 		Hidden:        false,
-		ExpectedFlags: []string{"ac", "acc", "c", "config", "maxtime", "maxtime-job", "noninteractive", "p", "rate", "s", "sa", "se", "sf", "t", "v", "V"},
+		ExpectedFlags: []string{"ac", "ac-keyword", "acc", "ach", "acs", "c", "columns", "config", "dedup-requests", "host-errors", "import", "job-override", "maxtime", "maxtime-job", "noninteractive", "p", "rate", "rate-adaptive", "rate-mode", "robots-delay", "robots-delay-max", "s", "sa", "safe", "safe-allow", "scraper-dir", "scraperfile", "scrapers", "se", "sf", "status-action", "stop-rule", "t", "update-check", "update-url", "v", "V", "wait-for", "wait-for-interval", "wait-for-regex", "wait-for-timeout"},
 	}
 	u_compat := UsageSection{
 		Name:          "COMPATIBILITY OPTIONS",
@@ -75,28 +75,28 @@ func Usage() {
 		Description:   "Matchers for the response filtering.",
 		Flags:         make([]UsageFlag, 0),
 		Hidden:        false,
-		ExpectedFlags: []string{"mc", "ml", "mr", "ms", "mt", "mw"},
+		ExpectedFlags: []string{"mc", "mjson-keys", "ml", "mmime", "mr", "mr-header", "ms", "mt", "mtags", "mw"},
 	}
 	u_filter := UsageSection{
 		Name:          "FILTER OPTIONS",
 		Description:   "Filters for the response filtering.",
 		Flags:         make([]UsageFlag, 0),
 		Hidden:        false,
-		ExpectedFlags: []string{"fc", "fl", "fr", "fs", "ft", "fw"},
+		ExpectedFlags: []string{"fc", "fl", "fr", "fr-header", "fs", "fsim", "ft", "fw"},
 	}
 	u_input := UsageSection{
 		Name:          "INPUT OPTIONS",
 		Description:   "Options for input data for fuzzing. Wordlists and input generators.",
 		Flags:         make([]UsageFlag, 0),
 		Hidden:        false,
-		ExpectedFlags: []string{"D", "ic", "input-cmd", "input-num", "input-shell", "mode", "request", "request-proto", "e", "w"},
+		ExpectedFlags: []string{"D", "auto-ext", "auto-ext-list", "deny", "deny-file", "hex-wordlist", "ic", "input-cmd", "input-cmd-mode", "input-num", "input-shell", "mode", "openapi", "origin-ips", "pin-inputs", "postman", "request", "request-proto", "shard", "shard-hash", "transform", "e", "w", "wsdl"},
 	}
 	u_output := UsageSection{
 		Name:          "OUTPUT OPTIONS",
 		Description:   "Options for output. Output file formats, file names and debug file locations.",
 		Flags:         make([]UsageFlag, 0),
 		Hidden:        false,
-		ExpectedFlags: []string{"debug-log", "o", "of", "od", "or"},
+		ExpectedFlags: []string{"append-output", "debug-log", "diff", "filter-stats", "fsync", "group-dirs", "json-fields", "o", "of", "od", "or", "pcap", "postprocess", "progress-json", "status-matrix", "summary-json", "time-format", "timezone", "webhook", "webhook-batch", "webhook-events", "webhook-template", "wordlist-stats"},
 	}
 	sections := []UsageSection{u_http, u_general, u_compat, u_matcher, u_filter, u_input, u_output}
 
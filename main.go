@@ -15,6 +15,7 @@ import (
 	"github.com/ffuf/ffuf/pkg/interactive"
 	"github.com/ffuf/ffuf/pkg/output"
 	"github.com/ffuf/ffuf/pkg/runner"
+	"github.com/ffuf/ffuf/pkg/scraper"
 )
 
 type multiStringFlag []string
@@ -34,6 +35,11 @@ func (m *multiStringFlag) Set(value string) error {
 }
 
 func (m *wordlistFlag) Set(value string) error {
+	if strings.HasPrefix(value, ffuf.LIST_PREFIX) || ffuf.InlineWordlist(value) {
+		// The values of an inline list are separated by commas themselves
+		*m = append(*m, value)
+		return nil
+	}
 	delimited := strings.Split(value, ",")
 
 	if len(delimited) > 1 {
@@ -48,92 +54,233 @@ func (m *wordlistFlag) Set(value string) error {
 //ParseFlags parses the command line flags and (re)populates the ConfigOptions struct
 func ParseFlags(opts *ffuf.ConfigOptions) *ffuf.ConfigOptions {
 	var ignored bool
-	var cookies, autocalibrationstrings, headers, inputcommands multiStringFlag
+	var cookies, autocalibrationkeywords, autocalibrationstrings, headers, inputcommands, joboverrides, replaymatchers, resolves, statusactions, stoprules, transforms multiStringFlag
 	var wordlists wordlistFlag
 
 	cookies = opts.HTTP.Cookies
+	autocalibrationkeywords = opts.General.AutoCalibrationKeywords
 	autocalibrationstrings = opts.General.AutoCalibrationStrings
 	headers = opts.HTTP.Headers
+	resolves = opts.HTTP.Resolve
 	inputcommands = opts.Input.Inputcommands
+	joboverrides = opts.General.JobOverrides
+	replaymatchers = opts.HTTP.ReplayMatch
+	statusactions = opts.General.StatusActions
+	stoprules = opts.General.StopRules
+	transforms = opts.Input.Transforms
 
 	flag.BoolVar(&ignored, "compressed", true, "Dummy flag for copy as curl functionality (ignored)")
 	flag.BoolVar(&ignored, "i", true, "Dummy flag for copy as curl functionality (ignored)")
 	flag.BoolVar(&ignored, "k", false, "Dummy flag for backwards compatibility")
+	flag.BoolVar(&opts.Output.AppendOutput, "append-output", opts.Output.AppendOutput, "Merge the results to the existing json or ejson output file instead of overwriting it, replacing the earlier results of the same URL and inputs")
+	flag.BoolVar(&opts.Output.SummaryJSON, "summary-json", opts.Output.SummaryJSON, "Write a summary of the run as a single line of JSON to stderr on exit")
+	flag.BoolVar(&opts.Output.FilterStats, "filter-stats", opts.Output.FilterStats, "Print the number of responses each matcher and filter accepted and rejected, and their evaluation time after the run")
+	flag.BoolVar(&opts.Output.WordlistStats, "wordlist-stats", opts.Output.WordlistStats, "Print the inputs sent, skipped, errored and matched per queue job and keyword after the run")
+	flag.BoolVar(&opts.Output.GroupDirectories, "group-dirs", opts.Output.GroupDirectories, "Group the matched results under their parent directory in the terminal, and print the number of results per directory after the run")
+	flag.BoolVar(&opts.Output.StatusMatrix, "status-matrix", opts.Output.StatusMatrix, "Print the distribution of response status codes per directory depth and file extension after the run")
+	flag.BoolVar(&opts.Output.OutputFsync, "fsync", opts.Output.OutputFsync, "Sync the output file to disk after every result when streaming ndjson output, and after every rewrite of the other formats")
 	flag.BoolVar(&opts.Output.OutputSkipEmptyFile, "or", opts.Output.OutputSkipEmptyFile, "Don't create the output file if we don't have results")
 	flag.BoolVar(&opts.General.AutoCalibration, "ac", opts.General.AutoCalibration, "Automatically calibrate filtering options")
+	flag.BoolVar(&opts.General.AutoCalibrationPerHost, "ach", opts.General.AutoCalibrationPerHost, "Calibrate separately for every host the requests are sent to. Implies -ac")
 	flag.BoolVar(&opts.General.Colors, "c", opts.General.Colors, "Colorize output.")
+	flag.StringVar(&opts.General.Columns, "columns", opts.General.Columns, "Comma separated list of the result columns printed to the terminal, in order: input, url, status, size, words, lines, duration, content-type and redirect. With -s the columns are separated by tabs, eg. '-s -columns url' prints only the URLs for piping")
+	flag.BoolVar(&opts.General.DedupRequests, "dedup-requests", opts.General.DedupRequests, "Send identical requests (method, URL, headers and body) only once per run, eg. with overlapping wordlists or extensions. The duplicates of matched requests are reported as cached results")
 	flag.BoolVar(&opts.General.Noninteractive, "noninteractive", opts.General.Noninteractive, "Disable the interactive console functionality")
 	flag.BoolVar(&opts.General.Quiet, "s", opts.General.Quiet, "Do not print additional information (silent mode)")
+	flag.BoolVar(&opts.General.RateAdaptive, "rate-adaptive", opts.General.RateAdaptive, "Shorthand of -rate-mode adaptive")
+	flag.StringVar(&opts.General.RateMode, "rate-mode", opts.General.RateMode, "Rate control mode: \"fixed\" for -rate only, or \"adaptive\" to back off multiplicatively when the target responds with 429 or 5xx, requests time out or responses slow down, and ramp back up additively when it recovers. -rate is the maximum rate. The decisions are written to the -debug-log")
+	flag.BoolVar(&opts.General.RobotsDelay, "robots-delay", opts.General.RobotsDelay, "Read the Crawl-delay of the target robots.txt, and keep at least that delay between the requests.")
+	flag.BoolVar(&opts.General.Safe, "safe", opts.General.Safe, "Safe mode for live targets: refuse to send POST, PUT, DELETE and PATCH requests, and payloads matching known destructive patterns")
+	flag.BoolVar(&opts.General.UpdateCheck, "update-check", opts.General.UpdateCheck, "Check for a newer version of ffuf and of the used wordlists in the background when starting. Requires -update-url")
 	flag.BoolVar(&opts.General.ShowVersion, "V", opts.General.ShowVersion, "Show version information.")
 	flag.BoolVar(&opts.General.StopOn403, "sf", opts.General.StopOn403, "Stop when > 95% of responses return 403 Forbidden")
 	flag.BoolVar(&opts.General.StopOnAll, "sa", opts.General.StopOnAll, "Stop on all error cases. Implies -sf and -se.")
 	flag.BoolVar(&opts.General.StopOnErrors, "se", opts.General.StopOnErrors, "Stop on spurious errors")
 	flag.BoolVar(&opts.General.Verbose, "v", opts.General.Verbose, "Verbose output, printing full URL and redirect location (if any) with the results.")
 	flag.BoolVar(&opts.HTTP.FollowRedirects, "r", opts.HTTP.FollowRedirects, "Follow redirects")
+	flag.BoolVar(&opts.HTTP.Crawl, "crawl", opts.HTTP.Crawl, "Crawl the target alongside fuzzing. Discovered directories are added to the job queue, and discovered parameters fuzzed after them.")
+	flag.BoolVar(&opts.HTTP.Http2, "http2", opts.HTTP.Http2, "Use HTTP2 protocol, negotiated through ALPN")
+	flag.BoolVar(&opts.HTTP.Http2PriorKnowledge, "http2-prior-knowledge", opts.HTTP.Http2PriorKnowledge, "Use HTTP2 without HTTP/1.1 upgrade, also for plaintext targets (h2c). Implies -http2")
+	flag.BoolVar(&opts.HTTP.FastSize, "fast-size", opts.HTTP.FastSize, "Trust the Content-Length header for the response size, and download only the part of the body the matchers and filters need: none for status, size, time and header ones")
+	flag.BoolVar(&opts.HTTP.KeepAlive, "keep-alive", opts.HTTP.KeepAlive, "Keep the connections open and reuse them for the next requests, instead of a new connection for every request")
+	flag.BoolVar(&opts.HTTP.ConnReuse, "conn-reuse", opts.HTTP.ConnReuse, "Read the unread rest of the response bodies, up to 256 kB, so that their connections can be reused. Implies -keep-alive")
 	flag.BoolVar(&opts.HTTP.IgnoreBody, "ignore-body", opts.HTTP.IgnoreBody, "Do not fetch the response content.")
 	flag.BoolVar(&opts.HTTP.Recursion, "recursion", opts.HTTP.Recursion, "Scan recursively. Only FUZZ keyword is supported, and URL (-u) has to end in it.")
+	flag.StringVar(&opts.HTTP.RecursionExclude, "recursion-exclude", opts.HTTP.RecursionExclude, "Regexp of the directory URLs not to recurse into")
+	flag.StringVar(&opts.HTTP.RecursionInclude, "recursion-include", opts.HTTP.RecursionInclude, "Regexp the directory URLs need to match to be recursed into")
+	flag.IntVar(&opts.HTTP.RecursionMaxJobs, "recursion-max-jobs", opts.HTTP.RecursionMaxJobs, "Maximum number of recursion jobs queued in total. 0 for unlimited.")
+	flag.StringVar(&opts.HTTP.RecursionTruncate, "recursion-truncate", opts.HTTP.RecursionTruncate, "Comma separated maximum number of inputs of the recursion jobs at each depth, the last one applying to the deeper ones too. eg. '1000,200'. 0 for unlimited.")
+	flag.IntVar(&opts.HTTP.RecursionFingerprint, "recursion-fingerprint", opts.HTTP.RecursionFingerprint, "Probe this many random paths under the directory of every recursion job, and filter the responses matching their not-found fingerprint (status, length and body hash) in that job. 0 to disable.")
+	flag.BoolVar(&opts.HTTP.RecursionLinks, "recursion-links", opts.HTTP.RecursionLinks, "Also recurse into the directories linked from the HTML bodies of matched responses. Requires -recursion")
+	flag.BoolVar(&opts.Input.AutoExtensions, "auto-ext", opts.Input.AutoExtensions, "Probe a sample of the wordlist with candidate extensions, and add the ones yielding non-error responses to the run")
 	flag.BoolVar(&opts.Input.DirSearchCompat, "D", opts.Input.DirSearchCompat, "DirSearch wordlist compatibility mode. Used in conjunction with -e flag.")
 	flag.BoolVar(&opts.Input.IgnoreWordlistComments, "ic", opts.Input.IgnoreWordlistComments, "Ignore wordlist comments")
+	flag.StringVar(&opts.General.Import, "import", opts.General.Import, "Results of an earlier scan to replay through -replay-proxy and to recurse into with -recursion before the scan. ejson or ndjson output file, or json or ejson output file of upstream ffuf")
+	flag.IntVar(&opts.General.HostErrors, "host-errors", opts.General.HostErrors, "Quarantine a host after this many requests to it failed in a row (DNS, connection, TLS or timeout errors), skipping its remaining inputs. For targets with a keyword in the host. 0 to disable")
 	flag.IntVar(&opts.General.MaxTime, "maxtime", opts.General.MaxTime, "Maximum running time in seconds for entire process.")
 	flag.IntVar(&opts.General.MaxTimeJob, "maxtime-job", opts.General.MaxTimeJob, "Maximum running time in seconds per job.")
 	flag.IntVar(&opts.General.Rate, "rate", opts.General.Rate, "Rate of requests per second")
+	flag.Float64Var(&opts.HTTP.RetryDelay, "retry-delay", opts.HTTP.RetryDelay, "Seconds of delay before the first retry, doubled for every further retry with random jitter. A Retry-After header of the response is honored instead")
+	flag.Float64Var(&opts.General.RobotsDelayMax, "robots-delay-max", opts.General.RobotsDelayMax, "Maximum Crawl-delay in seconds to apply from robots.txt, overriding a longer one. 0 for no limit.")
 	flag.IntVar(&opts.General.Threads, "t", opts.General.Threads, "Number of concurrent threads.")
+	flag.IntVar(&opts.HTTP.BodyMemory, "body-memory", opts.HTTP.BodyMemory, "Memory budget in megabytes for the response bodies of the requests in flight, the bodies beyond it are spilled to temporary files. 0 for no limit")
+	flag.Int64Var(&opts.HTTP.MaxBodySize, "max-body-size", opts.HTTP.MaxBodySize, "Maximum size in bytes of the response body to read, larger bodies are truncated")
+	flag.IntVar(&opts.HTTP.MaxIdleConns, "max-idle", opts.HTTP.MaxIdleConns, "Maximum number of idle connections kept open to each host with -keep-alive")
+	flag.IntVar(&opts.HTTP.Prewarm, "prewarm", opts.HTTP.Prewarm, "Open this many connections to the target before the scan starts. Implies -keep-alive")
+	flag.IntVar(&opts.HTTP.IPRate, "ip-rate", opts.HTTP.IPRate, "Rate of requests per second to each destination IP, for fuzzing the hostname or the Host header. 0 for no limit")
+	flag.IntVar(&opts.HTTP.IPThreads, "ip-threads", opts.HTTP.IPThreads, "Maximum number of concurrent requests to each destination IP, for fuzzing the hostname or the Host header. 0 for no limit")
+	flag.IntVar(&opts.HTTP.CrawlDepth, "crawl-depth", opts.HTTP.CrawlDepth, "Maximum number of links to follow from the start page when crawling.")
+	flag.IntVar(&opts.HTTP.CrawlPages, "crawl-pages", opts.HTTP.CrawlPages, "Maximum number of pages to crawl.")
+	flag.IntVar(&opts.HTTP.Retries, "retries", opts.HTTP.Retries, "Number of times to retry a request failing with a network error, or responding with a -retry-on status code")
+	flag.IntVar(&opts.HTTP.RecursionBreadth, "recursion-breadth", opts.HTTP.RecursionBreadth, "Maximum number of recursion jobs a single directory may add to the queue. 0 for unlimited.")
 	flag.IntVar(&opts.HTTP.RecursionDepth, "recursion-depth", opts.HTTP.RecursionDepth, "Maximum recursion depth.")
+	flag.IntVar(&opts.HTTP.RecursionDepthBreadth, "recursion-depth-breadth", opts.HTTP.RecursionDepthBreadth, "Maximum number of recursion jobs in total for each recursion depth. 0 for unlimited.")
 	flag.IntVar(&opts.HTTP.Timeout, "timeout", opts.HTTP.Timeout, "HTTP request timeout in seconds.")
-	flag.IntVar(&opts.Input.InputNum, "input-num", opts.Input.InputNum, "Number of inputs to test. Used in conjunction with --input-cmd.")
+	flag.IntVar(&opts.Output.WebhookBatch, "webhook-batch", opts.Output.WebhookBatch, "Number of results to send in a single webhook request")
+	flag.IntVar(&opts.Input.InputNum, "input-num", opts.Input.InputNum, "Number of inputs to test, or the maximum number of batches in batch mode. Used in conjunction with --input-cmd.")
 	flag.StringVar(&opts.General.ConfigFile, "config", "", "Load configuration from a file")
 	flag.StringVar(&opts.Filter.Lines, "fl", opts.Filter.Lines, "Filter by amount of lines in response. Comma separated list of line counts and ranges")
 	flag.StringVar(&opts.Filter.Regexp, "fr", opts.Filter.Regexp, "Filter regexp")
+	flag.StringVar(&opts.Filter.HeaderRegexp, "fr-header", opts.Filter.HeaderRegexp, "Filter regexp matching the response headers only. EG: \"Server: nginx\"")
+	flag.StringVar(&opts.Filter.Similarity, "fsim", opts.Filter.Similarity, "Filter responses with bodies at least the given percentage similar to calibration responses for nonexistent resources, ignoring the reflected input and numbers. EG: 90")
 	flag.StringVar(&opts.Filter.Size, "fs", opts.Filter.Size, "Filter HTTP response size. Comma separated list of sizes and ranges")
 	flag.StringVar(&opts.Filter.Status, "fc", opts.Filter.Status, "Filter HTTP status codes from response. Comma separated list of codes and ranges")
-	flag.StringVar(&opts.Filter.Time, "ft", opts.Filter.Time, "Filter by number of milliseconds to the first response byte, either greater or less than. EG: >100 or <100")
+	flag.StringVar(&opts.Filter.Time, "ft", opts.Filter.Time, "Filter by number of milliseconds to the first response byte. Comma separated list of values greater or less than, exact values and ranges. EG: >100, <100 or 100-500")
 	flag.StringVar(&opts.Filter.Words, "fw", opts.Filter.Words, "Filter by amount of words in response. Comma separated list of word counts and ranges")
+	flag.StringVar(&opts.General.AutoCalibrationStrategy, "acs", opts.General.AutoCalibrationStrategy, "Auto-calibration strategy: \"basic\", \"advanced\" for more probes and range or similarity filters of varying responses, or \"keyword\" to probe each input keyword separately. Implies -ac")
+	flag.StringVar(&opts.General.SafeAllow, "safe-allow", opts.General.SafeAllow, "Comma separated list of methods to permit in safe mode, and \"payloads\" to permit the destructive payload patterns")
+	flag.StringVar(&opts.General.ScraperDir, "scraper-dir", opts.General.ScraperDir, "Directory of scraper rule files (*.json). Defaults to ~/.ffuf/scraper")
+	flag.StringVar(&opts.General.ScraperFile, "scraperfile", opts.General.ScraperFile, "Custom scraper rules file, always active")
+	flag.StringVar(&opts.General.Scrapers, "scrapers", opts.General.Scrapers, "Comma separated list of scraper groups to run on the matched responses, \"builtin\" for the bundled rules or \"all\" for every group. Groups marked active are used by default")
+	flag.StringVar(&opts.General.UpdateURL, "update-url", opts.General.UpdateURL, "Version endpoint of the update check, such as the latest release in the GitHub API of the repository the ffuf build is from: https://api.github.com/repos/OWNER/REPO/releases/latest")
 	flag.StringVar(&opts.General.Delay, "p", opts.General.Delay, "Seconds of `delay` between requests, or a range of random delay. For example \"0.1\" or \"0.1-2.0\"")
 	flag.StringVar(&opts.HTTP.Data, "d", opts.HTTP.Data, "POST data")
 	flag.StringVar(&opts.HTTP.Data, "data", opts.HTTP.Data, "POST data (alias of -d)")
 	flag.StringVar(&opts.HTTP.Data, "data-ascii", opts.HTTP.Data, "POST data (alias of -d)")
 	flag.StringVar(&opts.HTTP.Data, "data-binary", opts.HTTP.Data, "POST data (alias of -d)")
 	flag.StringVar(&opts.HTTP.Method, "X", opts.HTTP.Method, "HTTP method to use")
+	flag.StringVar(&opts.HTTP.Auth, "auth", opts.HTTP.Auth, "HTTP authentication SCHEME:USER:PASSWORD[:DOMAIN]. Schemes: ntlm, negotiate (NTLM through the Negotiate scheme) and digest. For example: ntlm:user:pass:CORP")
+	flag.StringVar(&opts.HTTP.CACert, "ca-cert", opts.HTTP.CACert, "CA bundle (PEM) to verify the target certificates with, in addition to the system CAs. The certificates are not verified without it")
+	flag.StringVar(&opts.HTTP.ClientCert, "cc", opts.HTTP.ClientCert, "Client certificate (PEM) for mutual TLS authentication. May contain the key as well")
+	flag.StringVar(&opts.HTTP.ClientKey, "ck", opts.HTTP.ClientKey, "Client certificate key (PEM), if not in the client certificate file (-cc)")
+	flag.StringVar(&opts.HTTP.TLSCiphers, "tls-ciphers", opts.HTTP.TLSCiphers, "Comma separated list of TLS 1.0-1.2 cipher suites to offer, including the insecure ones. For example: TLS_RSA_WITH_AES_128_CBC_SHA,TLS_RSA_WITH_3DES_EDE_CBC_SHA")
+	flag.StringVar(&opts.HTTP.TLSKeyLog, "tls-keylog", opts.HTTP.TLSKeyLog, "Append the TLS session keys to a file in the NSS key log format, for decrypting captured traffic in Wireshark. Defaults to the SSLKEYLOGFILE environment variable")
+	flag.StringVar(&opts.HTTP.TLSMaxVersion, "tls-max", opts.HTTP.TLSMaxVersion, "Maximum TLS version: 1.0, 1.1, 1.2 or 1.3")
+	flag.StringVar(&opts.HTTP.TLSMinVersion, "tls-min", opts.HTTP.TLSMinVersion, "Minimum TLS version: 1.0, 1.1, 1.2 or 1.3. Use 1.0 for legacy targets")
+	flag.StringVar(&opts.HTTP.ProxyBackup, "proxy-backup", opts.HTTP.ProxyBackup, "Comma separated list of backup proxy URLs, switched to in order when the proxy (-x) stops accepting connections")
+	flag.StringVar(&opts.HTTP.ProxyFallback, "proxy-fallback", opts.HTTP.ProxyFallback, "What to do when the proxy (-x) and its backups, or the proxies of the proxy list, are down: \"fail\" to stop the run, or \"direct\" to continue without a proxy")
+	flag.StringVar(&opts.HTTP.ProxyList, "proxy-list", opts.HTTP.ProxyList, "File of proxy URLs (HTTP or SOCKS5), one per line, rotated per request. Proxies that stop accepting connections are removed from the rotation")
+	flag.StringVar(&opts.HTTP.ProxyRotate, "proxy-rotate", opts.HTTP.ProxyRotate, "Proxy rotation of the proxy list (-proxy-list): \"roundrobin\" or \"random\"")
 	flag.StringVar(&opts.HTTP.ProxyURL, "x", opts.HTTP.ProxyURL, "Proxy URL (SOCKS5 or HTTP). For example: http://127.0.0.1:8080 or socks5://127.0.0.1:8080")
 	flag.StringVar(&opts.HTTP.ReplayProxyURL, "replay-proxy", opts.HTTP.ReplayProxyURL, "Replay matched requests using this proxy.")
+	flag.StringVar(&opts.HTTP.ReplayDir, "replay-dir", opts.HTTP.ReplayDir, "Directory to record the replayed requests and their responses to")
 	flag.StringVar(&opts.HTTP.RecursionStrategy, "recursion-strategy", opts.HTTP.RecursionStrategy, "Recursion strategy: \"default\" for a redirect based, and \"greedy\" to recurse on all matches")
-	flag.StringVar(&opts.HTTP.URL, "u", opts.HTTP.URL, "Target URL")
+	flag.StringVar(&opts.HTTP.RecursionWordlist, "recursion-wordlist", opts.HTTP.RecursionWordlist, "Wordlist for FUZZ keyword in recursion jobs, instead of the one of the root job")
+	flag.BoolVar(&opts.HTTP.GRPCReflection, "grpc-reflection", opts.HTTP.GRPCReflection, "List the services of a grpc:// or grpcs:// target through the gRPC server reflection, and fuzz them as GRPCSERVICE keyword, eg. grpc://host:50051/GRPCSERVICE/FUZZ")
+	flag.StringVar(&opts.HTTP.URL, "u", opts.HTTP.URL, "Target URL. Use dns://FUZZ.example.org for DNS lookups, or ws:// and wss:// for WebSocket endpoints (-d is sent as the first message), or grpc:// and grpcs:// for gRPC methods, eg. grpc://host:50051/package.Service/FUZZ (-d is the request message as FIELD=VALUE string fields separated by &, eg. 1=FUZZ&2=admin), or tcp://, ftp://, ssh:// and smb:// to grab the service banners, eg. tcp://host:FUZZ (-d is sent as the probe)")
+	flag.StringVar(&opts.HTTP.RetryOn, "retry-on", opts.HTTP.RetryOn, "Comma separated list of response status codes to retry the request on, up to -retries times. For example: 429,502,503")
+	flag.StringVar(&opts.HTTP.Resolvers, "resolvers", opts.HTTP.Resolvers, "Comma separated list of DNS resolvers to use with dns:// target URLs. For example: 1.1.1.1,8.8.8.8:53")
 	flag.StringVar(&opts.HTTP.SNI, "sni", opts.HTTP.SNI, "Target TLS SNI, does not support FUZZ keyword")
+	flag.StringVar(&opts.Input.AutoExtensionsList, "auto-ext-list", opts.Input.AutoExtensionsList, "Comma separated list of candidate extensions for -auto-ext")
+	flag.StringVar(&opts.Input.Denylist, "deny", opts.Input.Denylist, "Comma separated list of payload patterns never to send. Plain patterns match values containing them, * and ? are globs matching the whole value, and \"regex:\" prefix denotes a regular expression")
+	flag.StringVar(&opts.Input.DenylistFile, "deny-file", opts.Input.DenylistFile, "File of payload patterns never to send, one per line. See -deny for the pattern format")
 	flag.StringVar(&opts.Input.Extensions, "e", opts.Input.Extensions, "Comma separated list of extensions. Extends FUZZ keyword.")
-	flag.StringVar(&opts.Input.InputMode, "mode", opts.Input.InputMode, "Multi-wordlist operation mode. Available modes: clusterbomb, pitchfork")
+	flag.StringVar(&opts.Input.InputMode, "mode", opts.Input.InputMode, "Multi-wordlist operation mode. Available modes: clusterbomb, pitchfork, sniper (a single wordlist injected to each of the positions marked with §default§ in turn)")
+	flag.StringVar(&opts.Input.HexWordlists, "hex-wordlist", opts.Input.HexWordlists, "Comma separated list of keywords whose wordlists (-w) have hex encoded entries, decoded to raw bytes before use. Example: FUZZ,W2")
+	flag.StringVar(&opts.Input.InputCommandMode, "input-cmd-mode", opts.Input.InputCommandMode, "How the --input-cmd is run: input (once per input), stream (once, every output line is an input), batch (repeatedly, every output line is an input, FFUF_NUM is the batch number)")
 	flag.StringVar(&opts.Input.InputShell, "input-shell", opts.Input.InputShell, "Shell to be used for running command")
+	flag.StringVar(&opts.Input.OpenAPI, "openapi", opts.Input.OpenAPI, "OpenAPI or Swagger definition file (JSON) to fuzz every operation of. Parameters are replaced with FUZZ keyword, -u overrides the base URL.")
+	flag.StringVar(&opts.Input.OriginIPs, "origin-ips", opts.Input.OriginIPs, "File of candidate origin IPs to connect to while keeping the Host header and SNI. Filters out the ones not serving the same response as the target. All status codes are matched unless matchers are given. Available as ORIGINIP keyword.")
+	flag.StringVar(&opts.Input.PinnedInputs, "pin-inputs", opts.Input.PinnedInputs, "File of inputs sent in every queue job before the wordlist, one per line. EG: .git/HEAD to check every recursed directory for it. Needs FUZZ as the only input keyword")
+	flag.StringVar(&opts.Input.Postman, "postman", opts.Input.Postman, "Postman collection (v2.1) to fuzz every request of. Variables are replaced with the keyword of the same name if defined, with the collection value or with FUZZ keyword otherwise. -u overrides the base URL.")
+	flag.StringVar(&opts.Input.WSDL, "wsdl", opts.Input.WSDL, "WSDL document to fuzz every SOAP operation of. Values of the request envelopes are replaced with FUZZ keyword, -u overrides the endpoint URL.")
 	flag.StringVar(&opts.Input.Request, "request", opts.Input.Request, "File containing the raw http request")
 	flag.StringVar(&opts.Input.RequestProto, "request-proto", opts.Input.RequestProto, "Protocol to use along with raw request")
+	flag.StringVar(&opts.Input.Shard, "shard", opts.Input.Shard, "Run only the shard N of M of the inputs, to split them between ffuf instances. EG: 1/3 for every third input starting from the first")
+	flag.BoolVar(&opts.Input.ShardHash, "shard-hash", opts.Input.ShardHash, "Assign the inputs to the -shard by a hash of their values instead of their position, so that the instances do not need identical wordlists")
+	flag.StringVar(&opts.Matcher.JSONKeys, "mjson-keys", opts.Matcher.JSONKeys, "Match amount of object keys in JSON responses, at any depth. Comma separated list of key counts and ranges")
+	flag.StringVar(&opts.Matcher.Tags, "mtags", opts.Matcher.Tags, "Match amount of HTML tags (elements) in HTML responses. Comma separated list of tag counts and ranges")
 	flag.StringVar(&opts.Matcher.Lines, "ml", opts.Matcher.Lines, "Match amount of lines in response")
 	flag.StringVar(&opts.Matcher.Regexp, "mr", opts.Matcher.Regexp, "Match regexp")
+	flag.StringVar(&opts.Matcher.HeaderRegexp, "mr-header", opts.Matcher.HeaderRegexp, "Match regexp against the response headers only. EG: \"Server: nginx\"")
+	flag.StringVar(&opts.Matcher.Mime, "mmime", opts.Matcher.Mime, "Match responses whose content type sniffed from the body differs from the Content-Type header. \"all\" for any mismatch, or a comma separated list of sniffed types. EG: text/plain,application/zip")
 	flag.StringVar(&opts.Matcher.Size, "ms", opts.Matcher.Size, "Match HTTP response size")
 	flag.StringVar(&opts.Matcher.Status, "mc", opts.Matcher.Status, "Match HTTP status codes, or \"all\" for everything.")
-	flag.StringVar(&opts.Matcher.Time, "mt", opts.Matcher.Time, "Match how many milliseconds to the first response byte, either greater or less than. EG: >100 or <100")
+	flag.StringVar(&opts.Matcher.Time, "mt", opts.Matcher.Time, "Match how many milliseconds to the first response byte. Comma separated list of values greater or less than, exact values and ranges. EG: >100, <100 or 100-500")
 	flag.StringVar(&opts.Matcher.Words, "mw", opts.Matcher.Words, "Match amount of words in response")
 	flag.StringVar(&opts.Output.DebugLog, "debug-log", opts.Output.DebugLog, "Write all of the internal logging to the specified file.")
-	flag.StringVar(&opts.Output.OutputDirectory, "od", opts.Output.OutputDirectory, "Directory path to store matched results to.")
+	flag.StringVar(&opts.Output.Diff, "diff", opts.Output.Diff, "Compare the results to the ejson or ndjson output file of an earlier scan, and print the new, removed and changed ones after the run")
+	flag.StringVar(&opts.Output.ProgressJSON, "progress-json", opts.Output.ProgressJSON, "Write the progress as NDJSON lines, with the queue position, rate and ETA, to \"stderr\" or to a file descriptor number opened by the caller, eg. 3 with 3>progress.ndjson. On stderr the progress bar is not shown")
+	flag.StringVar(&opts.Output.JSONFields, "json-fields", opts.Output.JSONFields, "Comma separated list of the result fields of the json output file (-of json): input, position, status, length, words, lines, content-type, redirectlocation, redirectscheme, duration, resultfile, url, host, scraper, tags and timestamp. Defaults to all of them")
+	flag.StringVar(&opts.Output.OutputDirectory, "od", opts.Output.OutputDirectory, "Directory path to store the request and response of every match to, in numbered files referenced as the resultfile of the output")
 	flag.StringVar(&opts.Output.OutputFile, "o", opts.Output.OutputFile, "Write output to file")
-	flag.StringVar(&opts.Output.OutputFormat, "of", opts.Output.OutputFormat, "Output file format. Available formats: json, ejson, html, md, csv, ecsv (or, 'all' for all formats)")
+	flag.StringVar(&opts.Output.Pcap, "pcap", opts.Output.Pcap, "Capture the fuzzing traffic to a pcapng file. The TLS session keys are written to the same path with a .keylog suffix")
+	flag.StringVar(&opts.Output.TimeFormat, "time-format", opts.Output.TimeFormat, "Format of the times in the output files and results: rfc3339, rfc3339nano, rfc1123, unix, unixms or a Go time layout. Defaults to rfc3339 for the output file and rfc3339nano for the results")
+	flag.StringVar(&opts.Output.TimeZone, "timezone", opts.Output.TimeZone, "Timezone of the times in the output files and results, such as UTC or Europe/Helsinki. Defaults to the local time")
+	flag.StringVar(&opts.Output.Webhook, "webhook", opts.Output.Webhook, "Webhook URL to POST the matched results to")
+	flag.StringVar(&opts.Output.WebhookEvents, "webhook-events", opts.Output.WebhookEvents, "Comma separated list of what to send to the webhook: results, job (queue job completed), stop (stop condition or interrupt) and progress milestones as percentages of the job. eg. 'job,stop,25,50,75,100'")
+	flag.StringVar(&opts.Output.WebhookTemplate, "webhook-template", opts.Output.WebhookTemplate, "Format of the webhook payload: json, slack, discord or a Go template receiving .Results, or .Event for the milestones")
+	flag.StringVar(&opts.Output.PostProcess, "postprocess", opts.Output.PostProcess, "Comma separated list of result post-processors, run in order: dedup-url and dedup-body to drop the results seen already in any job, collapse-redirects to keep one of the results redirecting to the same location, and tag to tag backup files, directories, redirects and denied responses")
+	flag.StringVar(&opts.Output.OutputFormat, "of", opts.Output.OutputFormat, "Output file format. Available formats: json, ejson, html, md, csv, ecsv, sqlite, ndjson (or, 'all' for all formats)")
+	flag.Var(&autocalibrationkeywords, "ac-keyword", "Calibration probes of a keyword as KEYWORD:STYLE, where style is path (default), param or vhost, or a comma separated list of probe templates with {rand} for a random string. eg. 'HOST:vhost'. Multiple -ac-keyword flags are accepted. Implies -ac")
 	flag.Var(&autocalibrationstrings, "acc", "Custom auto-calibration string. Can be used multiple times. Implies -ac")
 	flag.Var(&cookies, "b", "Cookie data `\"NAME1=VALUE1; NAME2=VALUE2\"` for copy as curl functionality.")
 	flag.Var(&cookies, "cookie", "Cookie data (alias of -b)")
+	flag.Var(&resolves, "resolve", "Connect to IP for the requests to HOST, as HOST:IP, keeping the Host header and SNI of the host. Multiple -resolve flags are accepted.")
+	flag.StringVar(&opts.HTTP.UnixSocket, "unix", opts.HTTP.UnixSocket, "Send the requests to a Unix domain socket instead of the host of the URL")
 	flag.Var(&headers, "H", "Header `\"Name: Value\"`, separated by colon. Multiple -H flags are accepted.")
 	flag.Var(&inputcommands, "input-cmd", "Command producing the input. --input-num is required when using this input method. Overrides -w.")
-	flag.Var(&wordlists, "w", "Wordlist file path and (optional) keyword separated by colon. eg. '/path/to/wordlist:KEYWORD'")
+	flag.Var(&replaymatchers, "replay-match", "Replay only the matches also matching this matcher through the replay proxy, as MATCHER:VALUE of the matcher options. eg. 'mc:200' or 'mr:admin'. Multiple -replay-match flags are accepted, any of them matching.")
+	flag.StringVar(&opts.General.WaitFor, "wait-for", opts.General.WaitFor, "Readiness check URL polled until it responds with a 2xx status before the scan starts. Exits with code 3 if it is not ready within -wait-for-timeout")
+	flag.IntVar(&opts.General.WaitForInterval, "wait-for-interval", opts.General.WaitForInterval, "Seconds between the readiness checks of -wait-for")
+	flag.StringVar(&opts.General.WaitForRegex, "wait-for-regex", opts.General.WaitForRegex, "Regexp the response body of the -wait-for readiness check needs to match")
+	flag.IntVar(&opts.General.WaitForTimeout, "wait-for-timeout", opts.General.WaitForTimeout, "Seconds to wait for the -wait-for readiness check to pass")
+	flag.Var(&statusactions, "status-action", "Action on the responses with the status codes, as CODES:ACTION with a comma separated list of codes and ranges, eg. '429,503:retry' or '500-599:error'. Actions: retry, ignore, error (counted as a request error of the status class) and match. Multiple -status-action flags are accepted.")
+	flag.Var(&joboverrides, "job-override", "Settings of the queued recursion and crawl jobs with URLs matching a regular expression, as SETTINGS for REGEX with the settings threads=N, delay=SECONDS and the filters fc, fl, fr, fs, ft and fw added to the ones of the run, eg. 'threads=2 delay=0.5 fs=42 for /admin/'. The first matching rule applies. Multiple -job-override flags are accepted.")
+	flag.Var(&stoprules, "stop-rule", "Rule to stop, pause, skip the current job or alert on the responses, as ACTION [DURATION] if CONDITIONS with the conditions status=CODES (or error, or error:CLASS for dns, tls, timeout, connection-refused, connection-reset, redirects, proxy and other errors), ratio>SHARE, count>NUMBER, window=RESPONSES and min=RESPONSES. eg. 'stop if status=403 ratio>0.8 window=100' or 'pause 60s if status=429 count>20'. Actions: stop, pause, skip and alert. Multiple -stop-rule flags are accepted.")
+	flag.Var(&transforms, "transform", "Input transformation pipeline of a keyword, KEYWORD:STAGE[;STAGE...]. Each stage is a comma separated list of variants: original, upper, lower, capitalize, urlencode, doubleurlencode, base64 or an .extension. eg. 'FUZZ:original,.php,.bak;urlencode'. Multiple -transform flags are accepted.")
+	flag.Var(&wordlists, "w", "Wordlist file path and (optional) keyword separated by colon. eg. '/path/to/wordlist:KEYWORD'. A generated sequence with range:START-END[:STEP][:FORMAT][:KEYWORD], eg. 'range:0-9999:%04d' or 'range:2023-01-01..2023-12-31:7d:20060102'. Inline values with list:VALUE,VALUE...[:KEYWORD], or without the prefix when none of the values is a file, eg. 'admin,root:USER'. The inputs of several -w of the same keyword are merged without duplicates")
 	flag.Usage = Usage
 	flag.Parse()
 
+	opts.General.AutoCalibrationKeywords = autocalibrationkeywords
 	opts.General.AutoCalibrationStrings = autocalibrationstrings
+	opts.General.StatusActions = statusactions
+	opts.General.JobOverrides = joboverrides
+	opts.General.StopRules = stoprules
 	opts.HTTP.Cookies = cookies
 	opts.HTTP.Headers = headers
+	opts.HTTP.Resolve = resolves
+	opts.HTTP.ReplayMatch = replaymatchers
 	opts.Input.Inputcommands = inputcommands
+	opts.Input.Transforms = transforms
 	opts.Input.Wordlists = wordlists
 	return opts
 }
 
+//EXIT_NOT_READY is the exit code when the readiness check of -wait-for times out, to tell an environment that never
+//came up apart from a failed scan
+const EXIT_NOT_READY = 3
+
 func main() {
 	var err, optserr error
 
+	if len(os.Args) > 1 && os.Args[1] == "merge" {
+		os.Exit(runMerge(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		os.Exit(runDiff(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "schema" {
+		os.Exit(runSchema(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "bench" {
+		os.Exit(runBench(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "test-filters" {
+		os.Exit(runTestFilters(os.Args[2:]))
+	}
+
 	// prepare the default config options from default config file
 	var opts *ffuf.ConfigOptions
 	opts, optserr = ffuf.ReadDefaultConfig()
@@ -166,6 +313,7 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Encoutered error(s): %s\n", err)
 			Usage()
 			fmt.Fprintf(os.Stderr, "Encoutered error(s): %s\n", err)
+			writeErrorSummary(opts, nil, err)
 			os.Exit(1)
 		}
 		// Reset the flag package state
@@ -182,6 +330,7 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
 		Usage()
 		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		writeErrorSummary(opts, conf, err)
 		os.Exit(1)
 	}
 	job, err := prepareJob(conf)
@@ -189,19 +338,57 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
 		Usage()
 		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		writeErrorSummary(opts, conf, err)
+		os.Exit(1)
+	}
+	baseline, err := readBaselineIfNeeded(conf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		writeErrorSummary(opts, conf, err)
+		os.Exit(1)
+	}
+	if err := importResultsIfNeeded(conf); err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		writeErrorSummary(opts, conf, err)
+		os.Exit(1)
+	}
+	if err := readAppendedOutputIfNeeded(conf); err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		writeErrorSummary(opts, conf, err)
 		os.Exit(1)
 	}
 	if err := filter.SetupFilters(opts, conf); err != nil {
 		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
 		Usage()
 		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		writeErrorSummary(opts, conf, err)
+		os.Exit(1)
+	}
+
+	if err := waitForReadyIfNeeded(job); err != nil {
+		fmt.Fprintf(os.Stderr, "Target not ready, exiting: %s\n", err)
+		writeErrorSummary(opts, conf, err)
+		os.Exit(EXIT_NOT_READY)
+	}
+
+	if err := discoverExtensionsIfNeeded(job); err != nil {
+		fmt.Fprintf(os.Stderr, "Error in extension discovery, exiting: %s\n", err)
+		writeErrorSummary(opts, conf, err)
+		os.Exit(1)
+	}
+
+	if err := filter.OriginBaselineIfNeeded(job); err != nil {
+		fmt.Fprintf(os.Stderr, "Error in origin baseline, exiting: %s\n", err)
+		writeErrorSummary(opts, conf, err)
 		os.Exit(1)
 	}
 
 	if err := filter.CalibrateIfNeeded(job); err != nil {
 		fmt.Fprintf(os.Stderr, "Error in autocalibration, exiting: %s\n", err)
+		writeErrorSummary(opts, conf, err)
 		os.Exit(1)
 	}
+	prewarmIfNeeded(job)
 	if !conf.Noninteractive {
 		go func() {
 			err := interactive.Handle(job)
@@ -213,19 +400,140 @@ func main() {
 
 	// Job handles waiting for goroutines to complete itself
 	job.Start()
+	printDiffIfNeeded(job, baseline)
+	printGroupsIfNeeded(job)
+}
+
+//writeErrorSummary writes the summary of a run that failed before the job was started, if requested
+func writeErrorSummary(opts *ffuf.ConfigOptions, conf *ffuf.Config, err error) {
+	if opts != nil && opts.Output.SummaryJSON {
+		_ = ffuf.WriteSummary(os.Stderr, ffuf.NewErrorSummary(conf, err))
+	}
+}
+
+//discoverExtensionsIfNeeded runs the extension discovery and recreates the input provider to include the selected
+//extensions
+func discoverExtensionsIfNeeded(job *ffuf.Job) error {
+	if !job.Config.AutoExtensions {
+		return nil
+	}
+	found, err := job.DiscoverExtensions()
+	if err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		job.Output.Info("Extension discovery did not find any extensions to add")
+		return nil
+	}
+	job.Output.Info(fmt.Sprintf("Extension discovery selected extensions: %s", strings.Join(found, ", ")))
+	job.Config.Extensions = append(job.Config.Extensions, found...)
+	var errs ffuf.Multierror
+	job.Input, errs = input.NewInputProvider(job.Config)
+	return errs.ErrorOrNil()
+}
+
+//waitForReadyIfNeeded polls the readiness endpoint of -wait-for before any requests are sent to the target
+func waitForReadyIfNeeded(job *ffuf.Job) error {
+	if len(job.Config.WaitFor) == 0 {
+		return nil
+	}
+	return runner.WaitForReady(job.Runner, job.Config, job.Output)
+}
+
+//grpcServicesIfNeeded lists the services of the gRPC target through the server reflection (-grpc-reflection), and
+//adds them as the inputs of the GRPCSERVICE keyword
+func grpcServicesIfNeeded(conf *ffuf.Config) error {
+	if !conf.GRPCReflection {
+		return nil
+	}
+	services, err := runner.GRPCServices(conf)
+	if err != nil {
+		return fmt.Errorf("Could not list the gRPC services (-grpc-reflection): %s", err)
+	}
+	if len(services) == 0 {
+		return fmt.Errorf("The gRPC server reflection (-grpc-reflection) listed no services")
+	}
+	conf.InputProviders = append(conf.InputProviders, ffuf.InputProviderConfig{
+		Name:    "list",
+		Value:   ffuf.LIST_PREFIX + strings.Join(services, ",") + ":" + ffuf.GRPC_SERVICE_KEYWORD,
+		Keyword: ffuf.GRPC_SERVICE_KEYWORD,
+	})
+	return nil
+}
+
+//printGroupsIfNeeded prints the number of results per directory after the run (-group-dirs)
+func printGroupsIfNeeded(job *ffuf.Job) {
+	out, ok := job.Output.(*output.Stdoutput)
+	if !job.Config.GroupDirectories || !ok {
+		return
+	}
+	job.Output.Raw(output.DirectoryReport(out.AllResults()))
+}
+
+//prewarmIfNeeded opens the connections to the target before the scan starts (-prewarm)
+func prewarmIfNeeded(job *ffuf.Job) {
+	if job.Config.Prewarm == 0 {
+		return
+	}
+	opened, err := runner.Prewarm(job.Runner, job.Config, job.Config.Prewarm)
+	if err != nil {
+		job.Output.Warning(fmt.Sprintf("Could not pre-warm the connections: %s", err))
+		return
+	}
+	job.Output.Info(fmt.Sprintf("Pre-warmed %d connections to the target", opened))
 }
 
 func prepareJob(conf *ffuf.Config) (*ffuf.Job, error) {
 	job := ffuf.NewJob(conf)
+	job.HandleSignals = true
 	var errs ffuf.Multierror
+	if len(conf.PcapFile) > 0 {
+		// The capture is created after the job, for the section header to carry the scan ID
+		pcap, err := ffuf.NewPcapWriter(conf.PcapFile, conf)
+		if err != nil {
+			return job, err
+		}
+		conf.Pcap = pcap
+	}
+	if len(conf.ProgressJSON) > 0 {
+		w, err := ffuf.NewProgressJSONWriter(conf.ProgressJSON)
+		if err != nil {
+			return job, err
+		}
+		conf.ProgressJSONWriter = w
+	}
+	if len(conf.TLSKeyLogFile) > 0 {
+		keylog, err := ffuf.NewTLSKeyLog(conf.TLSKeyLogFile)
+		if err != nil {
+			return job, err
+		}
+		conf.TLSKeyLog = keylog
+	}
+	if err := grpcServicesIfNeeded(conf); err != nil {
+		return job, err
+	}
 	job.Input, errs = input.NewInputProvider(conf)
 	// TODO: implement error handling for runnerprovider and outputprovider
-	// We only have http runner right now
-	job.Runner = runner.NewRunnerByName("http", conf, false)
+	job.Runner = runner.NewRunnerByName(runner.RunnerNameFromURL(conf.Url), conf, false)
 	if len(conf.ReplayProxyURL) > 0 {
 		job.ReplayRunner = runner.NewRunnerByName("http", conf, true)
 	}
 	// We only have stdout outputprovider right now
 	job.Output = output.NewOutputProviderByName("stdout", conf)
+	job.Calibrator = filter.NewCalibrator()
+	if len(conf.WebhookURL) > 0 {
+		notifier, err := ffuf.NewNotifier(conf)
+		if err != nil {
+			return job, err
+		}
+		job.Notifier = notifier
+	}
+	scr, err := scraper.NewScraper(conf)
+	if err != nil {
+		return job, err
+	}
+	if scr != nil {
+		job.Scraper = scr
+	}
 	return job, errs.ErrorOrNil()
 }
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+	"github.com/ffuf/ffuf/pkg/output"
+)
+
+//runMerge runs the merge subcommand, combining the ejson or ndjson output files of the shards (-shard) of a scan to
+//a single output file, and returns the exit code
+func runMerge(args []string) int {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	outfile := fs.String("o", "", "Write the merged results to file")
+	outformat := fs.String("of", "ejson", "Output file format. Available formats: json, ejson, html, md, csv, ecsv, sqlite, ndjson (or, 'all' for all formats)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s merge -o FILE [-of FORMAT] SHARDFILE...\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Merges the ejson or ndjson output files of the shards (-shard) of a scan.\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *outfile == "" || fs.NArg() == 0 {
+		fs.Usage()
+		return 1
+	}
+	valid := false
+	for _, f := range []string{"all", "json", "ejson", "html", "md", "csv", "ecsv", "sqlite", "ndjson"} {
+		if f == *outformat {
+			valid = true
+		}
+	}
+	if !valid {
+		fmt.Fprintf(os.Stderr, "Unknown output file format (-of): %s\n", *outformat)
+		return 1
+	}
+
+	sets := make([][]ffuf.Result, 0, fs.NArg())
+	for _, filename := range fs.Args() {
+		results, err := output.ReadResultFile(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not read the results: %s\n", err)
+			return 1
+		}
+		sets = append(sets, results)
+	}
+	merged := output.MergeResults(sets...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conf := ffuf.NewConfig(ctx, cancel)
+	conf.CommandLine = strings.Join(os.Args, " ")
+	conf.ScanID = ffuf.NewScanID()
+	conf.InputProviders = output.MergeKeywords(merged)
+	conf.OutputFile = *outfile
+	conf.OutputFormat = *outformat
+	out := output.NewStdoutput(&conf)
+	out.Results = merged
+	if err := out.SaveFile(*outfile, *outformat); err != nil {
+		fmt.Fprintf(os.Stderr, "Could not write the output file %s: %s\n", *outfile, err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Merged %d results from %d files to %s\n", len(merged), fs.NArg(), *outfile)
+	return 0
+}
+
+//readAppendedOutputIfNeeded reads the results of the existing output file to merge the new ones to (-append-output)
+func readAppendedOutputIfNeeded(conf *ffuf.Config) error {
+	if !conf.AppendOutput {
+		return nil
+	}
+	if _, err := os.Stat(conf.OutputFile); os.IsNotExist(err) {
+		// The first run creates the file
+		return nil
+	}
+	results, err := output.ReadResultFile(conf.OutputFile)
+	if err != nil {
+		return fmt.Errorf("Could not read the output file to append to (-append-output): %s", err)
+	}
+	conf.AppendedResults = results
+	return nil
+}
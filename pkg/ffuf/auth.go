@@ -0,0 +1,50 @@
+package ffuf
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	//AUTH_NTLM authenticates the connections with the NTLM handshake
+	AUTH_NTLM = "ntlm"
+	//AUTH_NEGOTIATE authenticates the connections with the NTLM handshake through the Negotiate scheme
+	AUTH_NEGOTIATE = "negotiate"
+	//AUTH_DIGEST authenticates the requests with the Digest scheme
+	AUTH_DIGEST = "digest"
+)
+
+//AuthSchemes are the accepted authentication schemes of -auth
+var AuthSchemes = []string{AUTH_NTLM, AUTH_NEGOTIATE, AUTH_DIGEST}
+
+//parseAuth parses the -auth value SCHEME:USER:PASSWORD[:DOMAIN] to the configuration. The domain may also be given
+//with the user name as DOMAIN\USER.
+func parseAuth(value string, conf *Config) error {
+	parts := strings.SplitN(value, ":", 4)
+	if len(parts) < 3 {
+		return fmt.Errorf("Authentication (-auth) needs to be in format SCHEME:USER:PASSWORD[:DOMAIN]")
+	}
+	scheme := strings.ToLower(parts[0])
+	valid := false
+	for _, s := range AuthSchemes {
+		if s == scheme {
+			valid = true
+		}
+	}
+	if !valid {
+		return fmt.Errorf("Unknown authentication scheme (-auth): %s. Available schemes: %s", parts[0], strings.Join(AuthSchemes, ", "))
+	}
+	conf.AuthScheme = scheme
+	conf.AuthUser = parts[1]
+	conf.AuthPassword = parts[2]
+	if len(parts) == 4 {
+		conf.AuthDomain = parts[3]
+	} else if i := strings.Index(conf.AuthUser, `\`); i >= 0 {
+		conf.AuthDomain = conf.AuthUser[:i]
+		conf.AuthUser = conf.AuthUser[i+1:]
+	}
+	if conf.AuthScheme == AUTH_DIGEST && conf.AuthDomain != "" {
+		return fmt.Errorf("Digest authentication (-auth) does not take a domain")
+	}
+	return nil
+}
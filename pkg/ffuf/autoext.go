@@ -0,0 +1,105 @@
+package ffuf
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const AUTO_EXTENSION_SAMPLE = 25
+
+//DiscoverExtensions probes a sample of the wordlist entries with every candidate extension, and returns the
+//extensions yielding non-error responses that differ from the ones of a random filename with the same extension
+func (j *Job) DiscoverExtensions() ([]string, error) {
+	if !keywordProvided("FUZZ", j.Config) {
+		return nil, fmt.Errorf("extension discovery requires an input for the FUZZ keyword")
+	}
+	samples := make([]map[string][]byte, 0)
+	seen := make(map[string]bool)
+	j.Input.Reset()
+	for len(samples) < AUTO_EXTENSION_SAMPLE && j.Input.Next() {
+		input := j.Input.Value()
+		word := string(input["FUZZ"])
+		if seen[word] || strings.Contains(word, ".") {
+			// Entries with an extension already are not useful for probing
+			continue
+		}
+		seen[word] = true
+		samples = append(samples, input)
+	}
+	j.Input.Reset()
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no wordlist entries without an extension to probe with")
+	}
+
+	candidates := make([]string, 0)
+	for _, ext := range j.Config.AutoExtensionCandidates {
+		configured := false
+		for _, e := range j.Config.Extensions {
+			configured = configured || e == ext
+		}
+		if !configured {
+			candidates = append(candidates, ext)
+		}
+	}
+	selected := make([]bool, len(candidates))
+	var wg sync.WaitGroup
+	limiter := make(chan bool, j.Config.Threads)
+	for i, ext := range candidates {
+		wg.Add(1)
+		go func(i int, ext string) {
+			defer wg.Done()
+			selected[i] = j.probeExtension(samples, ext, limiter)
+		}(i, ext)
+	}
+	wg.Wait()
+
+	found := make([]string, 0)
+	for i, ext := range candidates {
+		if selected[i] {
+			found = append(found, ext)
+		}
+	}
+	return found, nil
+}
+
+//probeExtension checks if any of the sampled entries with the extension returns a non-error response differing
+//from the response for a random filename
+func (j *Job) probeExtension(samples []map[string][]byte, ext string, limiter chan bool) bool {
+	limiter <- true
+	baseline, err := j.probeResponse(samples[0], RandomString(16)+ext)
+	<-limiter
+	if err != nil {
+		return false
+	}
+	results := make(chan bool, len(samples))
+	for _, s := range samples {
+		go func(s map[string][]byte) {
+			limiter <- true
+			defer func() { <-limiter }()
+			resp, err := j.probeResponse(s, string(s["FUZZ"])+ext)
+			results <- err == nil && resp.StatusCode < 400 &&
+				(resp.StatusCode != baseline.StatusCode || resp.ContentLength != baseline.ContentLength)
+		}(s)
+	}
+	found := false
+	for range samples {
+		found = <-results || found
+	}
+	return found
+}
+
+func (j *Job) probeResponse(sample map[string][]byte, word string) (Response, error) {
+	inputs := make(map[string][]byte, len(sample))
+	for k, v := range sample {
+		inputs[k] = v
+	}
+	inputs["FUZZ"] = []byte(word)
+	req, err := j.Runner.Prepare(inputs)
+	if err != nil {
+		return Response{}, err
+	}
+	resp, err := j.Runner.Execute(&req)
+	resp.MakeFreeMemory()
+	return resp, err
+}
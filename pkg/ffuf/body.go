@@ -0,0 +1,191 @@
+package ffuf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	//DEFAULT_MAX_BODY_SIZE is the size in bytes the response bodies are truncated to by default
+	DEFAULT_MAX_BODY_SIZE = 5242880
+	//DEFAULT_BODY_MEMORY is the default memory budget of the response bodies in megabytes
+	DEFAULT_BODY_MEMORY = 256
+)
+
+//BODY_READ_CHUNK is the size of the chunks the response bodies are read in, and reserved from the memory budget
+const BODY_READ_CHUNK = 32 * 1024
+
+//BodyStore holds the response bodies within a memory budget (-body-memory) shared by all of the requests in flight.
+//Bodies not fitting in the budget are spilled to temporary files, and read back from them when needed. Bodies
+//larger than the maximum body size (-max-body-size) are truncated.
+type BodyStore struct {
+	// Accessed atomically, first for the 64-bit alignment
+	used        int64
+	MaxBodySize int64
+	budget      int64
+	mutex       sync.Mutex
+	dir         string
+}
+
+//storedBody is the memory reservation or the spill file of a response body. Copies of a response share it, so that
+//it is released only once.
+type storedBody struct {
+	store *BodyStore
+	size  int64
+	file  string
+	once  sync.Once
+}
+
+//NewBodyStore creates a body store with a memory budget in bytes, 0 for no limit
+func NewBodyStore(maxBodySize int64, budget int64) *BodyStore {
+	return &BodyStore{MaxBodySize: maxBodySize, budget: budget}
+}
+
+//ReadBody reads a response body to the response, up to the maximum body size, and counts its words and lines. The
+//body is kept in memory while the budget allows, and spilled to a temporary file otherwise.
+func (s *BodyStore) ReadBody(resp *Response, body io.Reader) error {
+	return s.readBody(resp, body, s.MaxBodySize)
+}
+
+//ReadBodyPrefix reads at most limit bytes from the start of a response body, leaving the rest unread
+func (s *BodyStore) ReadBodyPrefix(resp *Response, body io.Reader, limit int64) error {
+	if limit > s.MaxBodySize {
+		limit = s.MaxBodySize
+	}
+	return s.readBody(resp, body, limit)
+}
+
+func (s *BodyStore) readBody(resp *Response, body io.Reader, maxSize int64) error {
+	stored := &storedBody{store: s}
+	resp.stored = stored
+	var buf bytes.Buffer
+	var spill *os.File
+	var read, words, lines int64 = 0, 1, 1
+	chunk := make([]byte, BODY_READ_CHUNK)
+	var err error
+	for read < maxSize {
+		want := int64(len(chunk))
+		if maxSize-read < want {
+			want = maxSize - read
+		}
+		n, rerr := body.Read(chunk[:want])
+		if n > 0 {
+			data := chunk[:n]
+			words += int64(bytes.Count(data, []byte(" ")))
+			lines += int64(bytes.Count(data, []byte("\n")))
+			if spill == nil && !s.reserve(int64(n)) {
+				spill, err = s.spillFile()
+				if err != nil {
+					break
+				}
+				stored.file = spill.Name()
+				_, err = spill.Write(buf.Bytes())
+				s.release(stored.size)
+				stored.size = 0
+				buf = bytes.Buffer{}
+			}
+			if spill != nil {
+				if err == nil {
+					_, err = spill.Write(data)
+				}
+			} else {
+				stored.size += int64(n)
+				buf.Write(data)
+			}
+			read += int64(n)
+		}
+		if rerr != nil {
+			if rerr != io.EOF {
+				err = rerr
+			}
+			break
+		}
+	}
+	if read >= maxSize {
+		// Anything beyond the maximum size is left unread
+		if n, _ := body.Read(chunk[:1]); n > 0 {
+			resp.Truncated = true
+		}
+	}
+	if spill != nil {
+		if cerr := spill.Close(); err == nil {
+			err = cerr
+		}
+	} else {
+		resp.Data = buf.Bytes()
+	}
+	if !resp.Truncated || resp.ContentLength < read {
+		resp.ContentLength = read
+	}
+	resp.ContentWords = words
+	resp.ContentLines = lines
+	return err
+}
+
+//Close removes the spilled bodies
+func (s *BodyStore) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.dir != "" {
+		os.RemoveAll(s.dir)
+		s.dir = ""
+	}
+}
+
+func (s *BodyStore) reserve(n int64) bool {
+	if s.budget == 0 {
+		return true
+	}
+	if atomic.AddInt64(&s.used, n) > s.budget {
+		atomic.AddInt64(&s.used, -n)
+		return false
+	}
+	return true
+}
+
+func (s *BodyStore) release(n int64) {
+	if s.budget > 0 {
+		atomic.AddInt64(&s.used, -n)
+	}
+}
+
+//spillFile creates a temporary file for a body, in a directory of the store removed on Close
+func (s *BodyStore) spillFile() (*os.File, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.dir == "" {
+		dir, err := ioutil.TempDir("", "ffuf-bodies")
+		if err != nil {
+			return nil, fmt.Errorf("could not create a directory for the response bodies: %s", err)
+		}
+		s.dir = dir
+	}
+	return ioutil.TempFile(s.dir, "body")
+}
+
+//release frees the memory reservation, or removes the spill file, of the body
+func (b *storedBody) release() {
+	b.once.Do(func() {
+		b.store.release(b.size)
+		if b.file != "" {
+			os.Remove(b.file)
+		}
+	})
+}
+
+//spilled returns the body read back from its spill file
+func (b *storedBody) spilled() []byte {
+	if b.file == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(b.file)
+	if err != nil {
+		return nil
+	}
+	return data
+}
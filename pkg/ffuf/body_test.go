@@ -0,0 +1,110 @@
+package ffuf
+
+import (
+	"bytes"
+	"os"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBodyStoreMemory(t *testing.T) {
+	s := NewBodyStore(DEFAULT_MAX_BODY_SIZE, 1024*1024)
+	body := bytes.Repeat([]byte("word word\n"), 10000)
+	var resp Response
+	if err := s.ReadBody(&resp, bytes.NewReader(body)); err != nil {
+		t.Fatalf("Could not read the body: %s", err)
+	}
+	if !bytes.Equal(resp.Data, body) || resp.stored.file != "" {
+		t.Errorf("Was expecting the body to be kept in memory")
+	}
+	if resp.ContentLength != 100000 || resp.ContentWords != 10001 || resp.ContentLines != 10001 {
+		t.Errorf("Unexpected length %d, words %d, lines %d", resp.ContentLength, resp.ContentWords, resp.ContentLines)
+	}
+	if used := atomic.LoadInt64(&s.used); used != 100000 {
+		t.Errorf("Was expecting 100000 bytes reserved from the budget, got %d", used)
+	}
+	// Copies of the response share the reservation, and release it only once
+	copied := resp
+	resp.MakeFreeMemory()
+	copied.MakeFreeMemory()
+	if used := atomic.LoadInt64(&s.used); used != 0 {
+		t.Errorf("Was expecting the budget to be released, %d bytes still reserved", used)
+	}
+}
+
+func TestBodyStoreSpill(t *testing.T) {
+	s := NewBodyStore(DEFAULT_MAX_BODY_SIZE, 64*1024)
+	defer s.Close()
+	body := bytes.Repeat([]byte("x"), 200*1024)
+	var resp Response
+	if err := s.ReadBody(&resp, bytes.NewReader(body)); err != nil {
+		t.Fatalf("Could not read the body: %s", err)
+	}
+	if resp.Data != nil || resp.stored.file == "" {
+		t.Fatalf("Was expecting the body to be spilled to a file")
+	}
+	if !bytes.Equal(resp.Body(), body) {
+		t.Errorf("The body read back from the spill file does not match")
+	}
+	if used := atomic.LoadInt64(&s.used); used != 0 {
+		t.Errorf("Was expecting the reservation of the spilled body to be released, %d bytes still reserved", used)
+	}
+	spill := resp.stored.file
+	resp.MakeFreeMemory()
+	if _, err := os.Stat(spill); !os.IsNotExist(err) {
+		t.Errorf("Was expecting the spill file to be removed when the response is freed")
+	}
+}
+
+func TestBodyStoreBudgetReleased(t *testing.T) {
+	s := NewBodyStore(DEFAULT_MAX_BODY_SIZE, 64*1024)
+	defer s.Close()
+	body := bytes.Repeat([]byte("x"), 48*1024)
+	var first, second, third Response
+	if err := s.ReadBody(&first, bytes.NewReader(body)); err != nil || first.Data == nil {
+		t.Fatalf("Was expecting the first body to fit in the budget: %v", err)
+	}
+	if err := s.ReadBody(&second, bytes.NewReader(body)); err != nil || second.Data != nil {
+		t.Fatalf("Was expecting the second body to be spilled while the first one is held: %v", err)
+	}
+	first.MakeFreeMemory()
+	second.MakeFreeMemory()
+	if err := s.ReadBody(&third, bytes.NewReader(body)); err != nil || third.Data == nil {
+		t.Errorf("Was expecting the third body to fit in the budget released by the first one: %v", err)
+	}
+	third.MakeFreeMemory()
+}
+
+func TestBodyStoreTruncate(t *testing.T) {
+	s := NewBodyStore(10, 0)
+	var resp Response
+	if err := s.ReadBody(&resp, bytes.NewReader([]byte("0123456789abcdef"))); err != nil {
+		t.Fatalf("Could not read the body: %s", err)
+	}
+	if string(resp.Data) != "0123456789" || !resp.Truncated {
+		t.Errorf("Was expecting the body to be truncated to 10 bytes, got %q (truncated: %t)", resp.Data, resp.Truncated)
+	}
+	var prefix Response
+	if err := s.ReadBodyPrefix(&prefix, bytes.NewReader([]byte("0123456789")), 4); err != nil {
+		t.Fatalf("Could not read the body prefix: %s", err)
+	}
+	if string(prefix.Data) != "0123" || !prefix.Truncated {
+		t.Errorf("Was expecting the first 4 bytes of the body, got %q (truncated: %t)", prefix.Data, prefix.Truncated)
+	}
+}
+
+func TestBodyStoreClose(t *testing.T) {
+	s := NewBodyStore(DEFAULT_MAX_BODY_SIZE, 1)
+	var resp Response
+	if err := s.ReadBody(&resp, bytes.NewReader([]byte("spilled"))); err != nil {
+		t.Fatalf("Could not read the body: %s", err)
+	}
+	dir := s.dir
+	if dir == "" {
+		t.Fatalf("Was expecting a spill directory")
+	}
+	s.Close()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Was expecting the spill directory to be removed on Close")
+	}
+}
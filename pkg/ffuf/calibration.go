@@ -0,0 +1,343 @@
+package ffuf
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	//CALIBRATION_BASIC filters the sizes, word and line counts of the default calibration probes
+	CALIBRATION_BASIC = "basic"
+	//CALIBRATION_ADVANCED sends more probes, and falls back to size ranges and body similarity for varying responses
+	CALIBRATION_ADVANCED = "advanced"
+	//CALIBRATION_KEYWORD probes every input keyword separately, keeping the other keywords at a constant value
+	CALIBRATION_KEYWORD = "keyword"
+)
+
+const (
+	//CALIBRATION_STYLE_PATH probes for a keyword in the URL path, the default
+	CALIBRATION_STYLE_PATH = "path"
+	//CALIBRATION_STYLE_PARAM probes for a keyword in a parameter name or value
+	CALIBRATION_STYLE_PARAM = "param"
+	//CALIBRATION_STYLE_VHOST probes for a keyword in the hostname or the Host header
+	CALIBRATION_STYLE_VHOST = "vhost"
+	//CALIBRATION_RAND is replaced with a random string in the calibration probe templates
+	CALIBRATION_RAND = "{rand}"
+)
+
+//CalibrationStrategies are the accepted values of -acs
+var CalibrationStrategies = []string{CALIBRATION_BASIC, CALIBRATION_ADVANCED, CALIBRATION_KEYWORD}
+
+//calibrationTemplates are the probe templates of each calibration style (-ac-keyword)
+var calibrationTemplates = map[string][]string{
+	CALIBRATION_STYLE_PATH:  {"admin{rand}/", ".htaccess{rand}", "{rand}/", "{rand}"},
+	CALIBRATION_STYLE_PARAM: {"{rand}", "admin{rand}", "1{rand}", "{rand}[]"},
+	CALIBRATION_STYLE_VHOST: {"{rand}", "admin{rand}", "dev-{rand}", "{rand}.{rand}"},
+}
+
+//calibrationAdvancedTemplates are the additional probe templates of each calibration style for -acs advanced
+var calibrationAdvancedTemplates = map[string][]string{
+	CALIBRATION_STYLE_PATH:  {"{rand}.php", "{rand}.html", ".{rand}", "{rand}/{rand}"},
+	CALIBRATION_STYLE_PARAM: {"{rand}.{rand}", "-{rand}", "{rand}%20{rand}", "{rand}&{rand}"},
+	CALIBRATION_STYLE_VHOST: {"www.{rand}", "{rand}-{rand}", "api.{rand}", "{rand}1"},
+}
+
+//parseCalibrationKeyword parses an -ac-keyword value KEYWORD:STYLE to the keyword and its probe templates. The style
+//is path, param or vhost, or a comma separated list of probe templates with {rand} for a random string.
+func parseCalibrationKeyword(value string, strategy string) (string, []string, error) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", nil, fmt.Errorf("Calibration keyword (-ac-keyword) needs to be in format KEYWORD:STYLE, got %s", value)
+	}
+	if templates, ok := calibrationTemplates[strings.ToLower(parts[1])]; ok {
+		if strategy == CALIBRATION_ADVANCED {
+			templates = append(append([]string{}, templates...), calibrationAdvancedTemplates[strings.ToLower(parts[1])]...)
+		}
+		return parts[0], templates, nil
+	}
+	return parts[0], strings.Split(parts[1], ","), nil
+}
+
+//calibrationValues returns the values sent as calibration probes for the keyword, or the default ones for an empty
+//keyword
+func (j *Job) calibrationValues(keyword string) []string {
+	templates, ok := j.Config.AutoCalibrationKeywords[keyword]
+	if !ok {
+		if len(j.Config.AutoCalibrationStrings) > 0 {
+			return j.Config.AutoCalibrationStrings
+		}
+		templates = calibrationTemplates[CALIBRATION_STYLE_PATH]
+		if j.Config.AutoCalibrationStrategy == CALIBRATION_ADVANCED {
+			templates = append(append([]string{}, templates...), calibrationAdvancedTemplates[CALIBRATION_STYLE_PATH]...)
+		}
+	}
+	values := make([]string, 0, len(templates))
+	for _, t := range templates {
+		v := t
+		for strings.Contains(v, CALIBRATION_RAND) {
+			v = strings.Replace(v, CALIBRATION_RAND, RandomString(16), 1)
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+//keywordCalibrationValues returns the calibration probe values of every input keyword. The keywords without an
+//-ac-keyword style share the same default values.
+func (j *Job) keywordCalibrationValues() (map[string][]string, int) {
+	defaults := j.calibrationValues("")
+	values := make(map[string][]string, len(j.Config.InputProviders))
+	probes := len(defaults)
+	for _, p := range j.Config.InputProviders {
+		if _, ok := j.Config.AutoCalibrationKeywords[p.Keyword]; !ok {
+			values[p.Keyword] = defaults
+			continue
+		}
+		values[p.Keyword] = j.calibrationValues(p.Keyword)
+		if len(values[p.Keyword]) > probes {
+			probes = len(values[p.Keyword])
+		}
+	}
+	return values, probes
+}
+
+//calibrationInputs returns the keyword values of each calibration request
+func (j *Job) calibrationInputs() []map[string][]byte {
+	inputs := make([]map[string][]byte, 0)
+	values, probes := j.keywordCalibrationValues()
+	if j.Config.InputMode == "sniper" && len(j.Config.InputProviders) > 0 {
+		// Every injection position is probed in turn, like when fuzzing
+		keyword := j.Config.InputProviders[0].Keyword
+		for index := range j.Config.SniperDefaults {
+			for _, v := range values[keyword] {
+				inputs = append(inputs, j.Config.SniperInput(keyword, index, []byte(v)))
+			}
+		}
+		return inputs
+	}
+	if j.Config.AutoCalibrationStrategy == CALIBRATION_KEYWORD && len(j.Config.InputKeywords()) > 1 {
+		// Every keyword is probed in turn while the others are kept at the same value, so that the filters cover
+		// the responses of each of the fuzzed positions
+		constant := RandomString(16)
+		keywords := j.Config.InputKeywords()
+		for _, keyword := range keywords {
+			for _, v := range values[keyword] {
+				input := make(map[string][]byte, len(keywords))
+				for _, o := range keywords {
+					input[o] = []byte(constant)
+				}
+				input[keyword] = []byte(v)
+				inputs = append(inputs, input)
+			}
+		}
+		return inputs
+	}
+	for i := 0; i < probes; i++ {
+		input := make(map[string][]byte, len(j.Config.InputProviders))
+		for _, p := range j.Config.InputProviders {
+			// Keywords with fewer probe values start over from the first one
+			if kv := values[p.Keyword]; len(kv) > 0 {
+				input[p.Keyword] = []byte(kv[i%len(kv)])
+			}
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs
+}
+
+//CalibrateResponses returns slice of Responses for randomly generated filter autocalibration requests. The requests
+//are sent to the given host (scheme://host) when it's not empty.
+func (j *Job) CalibrateResponses(host string) ([]Response, error) {
+	results, _, err := j.calibrationResponses(host)
+	return results, err
+}
+
+//calibrationResponses sends the calibration requests, and returns the responses to calibrate on along with every
+//probe sent for the calibration report
+func (j *Job) calibrationResponses(host string) ([]Response, []CalibrationProbe, error) {
+	rand.Seed(time.Now().UnixNano())
+	results := make([]Response, 0)
+	probes := make([]CalibrationProbe, 0)
+	for _, inputs := range j.calibrationInputs() {
+		req, err := j.Runner.Prepare(inputs)
+		if err != nil {
+			j.Output.Error(fmt.Sprintf("Encountered an error while preparing request: %s\n", err))
+			j.incError(ERROR_PREPARE)
+			log.Printf("%s", err)
+			return results, probes, err
+		}
+		if host != "" {
+			req.Url = calibrationUrl(req.Url, host)
+		}
+		resp, err := j.Runner.Execute(&req)
+		if err != nil {
+			return results, probes, err
+		}
+
+		// Only calibrate on responses that would be matched otherwise. The body is kept for the similarity filter.
+		probe := CalibrationProbe{
+			Input:         make(map[string]string, len(inputs)),
+			Url:           req.Url,
+			StatusCode:    resp.StatusCode,
+			ContentLength: resp.ContentLength,
+			ContentWords:  resp.ContentWords,
+			ContentLines:  resp.ContentLines,
+			Used:          j.matchResponse(resp, false),
+		}
+		for k, v := range inputs {
+			probe.Input[k] = string(v)
+		}
+		probes = append(probes, probe)
+		if probe.Used {
+			results = append(results, resp)
+		}
+	}
+	return results, probes, nil
+}
+
+//calibrationUrl returns the calibration request URL with the scheme and host replaced by those of the given host
+func calibrationUrl(reqUrl, host string) string {
+	u, err := url.Parse(reqUrl)
+	h, herr := url.Parse(host)
+	if err != nil || herr != nil {
+		return reqUrl
+	}
+	if u.Host == "" {
+		// The whole URL comes from the input, use the probe as a path
+		u = &url.URL{Path: "/" + strings.TrimPrefix(reqUrl, "/")}
+	}
+	u.Scheme = h.Scheme
+	u.Host = h.Host
+	return u.String()
+}
+
+//Calibrate sends the calibration requests to the target of the current job, and replaces the calibration filters of
+//its host with ones created from the responses
+func (j *Job) Calibrate() error {
+	j.calibrateMutex.Lock()
+	defer j.calibrateMutex.Unlock()
+	return j.calibrate(j.calibrationKey(j.Config.Url))
+}
+
+//calibrate runs the calibration for the key. The caller holds calibrateMutex.
+func (j *Job) calibrate(key string) error {
+	if j.Calibrator == nil {
+		return fmt.Errorf("No calibrator configured")
+	}
+	responses, probes, err := j.calibrationResponses(key)
+	if err != nil {
+		return err
+	}
+	filters, err := j.Calibrator.Filters(j.Config, responses)
+	var report CalibrationReport
+	if err == nil {
+		// The bodies are still needed for checking which responses the similarity filter covers
+		report = newCalibrationReport(key, j.Config.Url, probes, responses, filters)
+	}
+	for i := range responses {
+		responses[i].MakeFreeMemory()
+	}
+	if err != nil {
+		return err
+	}
+	j.Config.CalibrationLog.Add(report)
+	j.calibrationMutex.Lock()
+	j.calibrationFilters[key] = filters
+	j.calibrationReports[key] = report
+	j.calibrationMutex.Unlock()
+	return nil
+}
+
+//calibrateHostIfNeeded runs the calibration for the host of the request URL when calibrating per host, unless done
+//already
+func (j *Job) calibrateHostIfNeeded(reqUrl string) {
+	key := j.calibrationKey(reqUrl)
+	if key == "" || j.hasCalibration(key) {
+		return
+	}
+	j.calibrateMutex.Lock()
+	defer j.calibrateMutex.Unlock()
+	if j.hasCalibration(key) {
+		// Another thread calibrated the host while waiting
+		return
+	}
+	if err := j.calibrate(key); err != nil {
+		j.Output.Error(fmt.Sprintf("Calibration for host %s failed: %s", key, err))
+		// Do not retry the failed host on every request
+		j.calibrationMutex.Lock()
+		j.calibrationFilters[key] = map[string]FilterProvider{}
+		j.calibrationMutex.Unlock()
+		return
+	}
+	j.Output.Info(fmt.Sprintf("Calibrated for host %s: %s", key, j.calibrationRepr(key)))
+}
+
+func (j *Job) hasCalibration(key string) bool {
+	j.calibrationMutex.RLock()
+	defer j.calibrationMutex.RUnlock()
+	_, ok := j.calibrationFilters[key]
+	return ok
+}
+
+//calibrationKey returns the key the calibration filters for the URL are stored with: the scheme and host when
+//calibrating per host, and an empty string otherwise
+func (j *Job) calibrationKey(reqUrl string) string {
+	if !j.Config.AutoCalibrationPerHost {
+		return ""
+	}
+	u, err := url.Parse(reqUrl)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+//CalibrationFilters returns the calibration filters applied to the responses of the URL
+func (j *Job) CalibrationFilters(reqUrl string) map[string]FilterProvider {
+	j.calibrationMutex.RLock()
+	defer j.calibrationMutex.RUnlock()
+	return j.calibrationFilters[j.calibrationKey(reqUrl)]
+}
+
+//calibrationRepr returns the description of the calibration filters stored with the key, and the probes they were
+//created from
+func (j *Job) calibrationRepr(key string) string {
+	j.calibrationMutex.RLock()
+	defer j.calibrationMutex.RUnlock()
+	if report, ok := j.calibrationReports[key]; ok {
+		return report.Repr()
+	}
+	descs := make([]string, 0)
+	for _, f := range j.calibrationFilters[key] {
+		descs = append(descs, f.ReprVerbose())
+	}
+	if len(descs) == 0 {
+		return "no filters"
+	}
+	sort.Strings(descs)
+	return strings.Join(descs, ", ")
+}
+
+//calibrationFiltered checks if the response is filtered by the calibration filters of its host
+func (j *Job) calibrationFiltered(resp *Response) bool {
+	reqUrl := j.Config.Url
+	if resp.Request != nil {
+		reqUrl = resp.Request.Url
+	}
+	for name, f := range j.CalibrationFilters(reqUrl) {
+		start := time.Now()
+		fv, err := f.Filter(resp)
+		if err == nil && j.filterStats != nil {
+			j.filterStats.Add("calibration", name, f, !fv, time.Since(start))
+		}
+		if err == nil && fv {
+			return true
+		}
+	}
+	return false
+}
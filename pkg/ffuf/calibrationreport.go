@@ -0,0 +1,144 @@
+package ffuf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//calibrationFilterFlags are the command line flags of the filters created by the calibration
+var calibrationFilterFlags = map[string]string{
+	"size":       "-fs",
+	"word":       "-fw",
+	"line":       "-fl",
+	"similarity": "-fsim",
+}
+
+//CalibrationProbe is a calibration request and the response it got
+type CalibrationProbe struct {
+	Input         map[string]string `json:"input"`
+	Url           string            `json:"url"`
+	StatusCode    int64             `json:"status"`
+	ContentLength int64             `json:"length"`
+	ContentWords  int64             `json:"words"`
+	ContentLines  int64             `json:"lines"`
+	Used          bool              `json:"used"`
+}
+
+//CalibrationFilter is a filter created by the calibration, and the probes whose responses it filters
+type CalibrationFilter struct {
+	Name   string `json:"name"`
+	Filter string `json:"filter"`
+	Flag   string `json:"flag"`
+	Probes []int  `json:"probes"`
+}
+
+//CalibrationReport records a calibration run: the probes sent and the filters created from their responses. The probes
+//are numbered from 1 in the filters.
+type CalibrationReport struct {
+	Host    string              `json:"host,omitempty"`
+	Url     string              `json:"url"`
+	Time    time.Time           `json:"time"`
+	Probes  []CalibrationProbe  `json:"probes"`
+	Filters []CalibrationFilter `json:"filters"`
+}
+
+//CalibrationLog keeps the reports of the calibration runs of a job, for the output files
+type CalibrationLog struct {
+	mutex   sync.Mutex
+	reports []CalibrationReport
+}
+
+func NewCalibrationLog() *CalibrationLog {
+	return &CalibrationLog{reports: make([]CalibrationReport, 0)}
+}
+
+//Add records the report of a calibration run
+func (l *CalibrationLog) Add(report CalibrationReport) {
+	if l == nil {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.reports = append(l.reports, report)
+}
+
+//Reports returns the reports of the calibration runs so far, in the order they were run
+func (l *CalibrationLog) Reports() []CalibrationReport {
+	if l == nil {
+		return []CalibrationReport{}
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return append([]CalibrationReport{}, l.reports...)
+}
+
+//newCalibrationReport creates the report of a calibration run from the probes and the filters created from the
+//responses of the used ones
+func newCalibrationReport(host, reqUrl string, probes []CalibrationProbe, responses []Response, filters map[string]FilterProvider) CalibrationReport {
+	report := CalibrationReport{Host: host, Url: reqUrl, Time: time.Now(), Probes: probes, Filters: make([]CalibrationFilter, 0, len(filters))}
+	// The responses are the ones of the used probes, in the same order
+	used := make([]int, 0, len(responses))
+	for i, p := range probes {
+		if p.Used {
+			used = append(used, i+1)
+		}
+	}
+	names := make([]string, 0, len(filters))
+	for name := range filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := filters[name]
+		cf := CalibrationFilter{Name: name, Filter: f.ReprVerbose(), Probes: make([]int, 0)}
+		if flag, ok := calibrationFilterFlags[name]; ok {
+			cf.Flag = flag + " " + f.Repr()
+		}
+		for i := range responses {
+			if i >= len(used) {
+				break
+			}
+			if filtered, err := f.Filter(&responses[i]); err == nil && filtered {
+				cf.Probes = append(cf.Probes, used[i])
+			}
+		}
+		report.Filters = append(report.Filters, cf)
+	}
+	return report
+}
+
+//Repr returns the description of the report printed when starting the job: every filter with the probes it filters,
+//and the probes
+func (r CalibrationReport) Repr() string {
+	var b strings.Builder
+	if len(r.Filters) == 0 {
+		b.WriteString("no filters")
+	}
+	for i, f := range r.Filters {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(f.Filter)
+	}
+	for _, f := range r.Filters {
+		probes := make([]string, 0, len(f.Probes))
+		for _, p := range f.Probes {
+			probes = append(probes, fmt.Sprintf("#%d", p))
+		}
+		flag := f.Flag
+		if flag == "" {
+			flag = f.Name
+		}
+		fmt.Fprintf(&b, "\n  %s: created from the probes %s", flag, strings.Join(probes, ", "))
+	}
+	for i, p := range r.Probes {
+		fmt.Fprintf(&b, "\n  #%d %s [Status: %d, Size: %d, Words: %d, Lines: %d]", i+1, p.Url, p.StatusCode, p.ContentLength, p.ContentWords, p.ContentLines)
+		if !p.Used {
+			b.WriteString(" not matched by the matchers, ignored")
+		}
+	}
+	return b.String()
+}
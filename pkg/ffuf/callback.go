@@ -0,0 +1,210 @@
+package ffuf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+//JobCallbacks are the functions an application embedding ffuf receives the results, progress and messages of a job
+//with through CallbackOutput. The callbacks are called from the goroutines of the job, and the ones left nil are
+//skipped. The response body is only valid during the Result callback.
+type JobCallbacks struct {
+	Result   func(resp Response)
+	Progress func(progress Progress)
+	Info     func(msg string)
+	Warning  func(msg string)
+	Error    func(msg string)
+	Summary  func(summary Summary)
+}
+
+//CallbackOutput is the OutputProvider for applications embedding ffuf: instead of writing to the terminal it passes
+//the results, progress and messages of the job to the callbacks. The results are also kept for GetCurrentResults.
+type CallbackOutput struct {
+	callbacks      JobCallbacks
+	mutex          sync.Mutex
+	Results        []Result
+	CurrentResults []Result
+}
+
+func NewCallbackOutput(callbacks JobCallbacks) *CallbackOutput {
+	return &CallbackOutput{callbacks: callbacks, Results: make([]Result, 0), CurrentResults: make([]Result, 0)}
+}
+
+//NewResult returns the result of a matched response, as kept by the output providers
+func NewResult(resp Response) Result {
+	inputs := make(map[string][]byte, len(resp.Request.Input))
+	for k, v := range resp.Request.Input {
+		inputs[k] = v
+	}
+	return Result{
+		Input:            inputs,
+		Position:         resp.Request.Position,
+		StatusCode:       resp.StatusCode,
+		ContentLength:    resp.ContentLength,
+		ContentWords:     resp.ContentWords,
+		ContentLines:     resp.ContentLines,
+		ContentType:      resp.ContentType,
+		RedirectLocation: resp.GetRedirectLocation(false),
+		RedirectScheme:   resp.RedirectScheme(),
+		Url:              resp.Request.Url,
+		Duration:         resp.Time,
+		ResultFile:       resp.ResultFile,
+		Host:             resp.Request.Host,
+		Timestamp:        time.Now(),
+		ScraperData:      resp.ScraperData,
+		Tags:             resp.Tags,
+	}
+}
+
+func (c *CallbackOutput) Banner() {}
+
+func (c *CallbackOutput) Finalize() error {
+	return nil
+}
+
+func (c *CallbackOutput) Progress(status Progress) {
+	if c.callbacks.Progress != nil {
+		c.callbacks.Progress(status)
+	}
+}
+
+func (c *CallbackOutput) Info(infostring string) {
+	if c.callbacks.Info != nil {
+		c.callbacks.Info(infostring)
+	}
+}
+
+func (c *CallbackOutput) Error(errstring string) {
+	if c.callbacks.Error != nil {
+		c.callbacks.Error(strings.TrimSpace(errstring))
+	}
+}
+
+//Raw passes the reports printed at the end of the run, such as the status matrix, to the Info callback
+func (c *CallbackOutput) Raw(output string) {
+	c.Info(output)
+}
+
+func (c *CallbackOutput) Warning(warnstring string) {
+	if c.callbacks.Warning != nil {
+		c.callbacks.Warning(strings.TrimSpace(warnstring))
+	}
+}
+
+func (c *CallbackOutput) Result(resp Response) {
+	res := NewResult(resp)
+	c.mutex.Lock()
+	c.CurrentResults = append(c.CurrentResults, res)
+	c.mutex.Unlock()
+	if c.callbacks.Result != nil {
+		c.callbacks.Result(resp)
+	}
+}
+
+func (c *CallbackOutput) PrintResult(res Result) {}
+
+func (c *CallbackOutput) SaveFile(filename, format string) error {
+	return fmt.Errorf("Writing the results to a file is not supported by the callback output")
+}
+
+func (c *CallbackOutput) SetOutputFile(filename string) error {
+	return fmt.Errorf("Writing the results to a file is not supported by the callback output")
+}
+
+func (c *CallbackOutput) GetCurrentResults() []Result {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.CurrentResults
+}
+
+func (c *CallbackOutput) SetCurrentResults(results []Result) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.CurrentResults = results
+}
+
+//SetSummary passes the summary of the finished run to the Summary callback
+func (c *CallbackOutput) SetSummary(summary Summary) {
+	if c.callbacks.Summary != nil {
+		c.callbacks.Summary(summary)
+	}
+}
+
+func (c *CallbackOutput) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.CurrentResults = make([]Result, 0)
+}
+
+//Cycle moves the results of the finished queue job to Results
+func (c *CallbackOutput) Cycle() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.Results = append(c.Results, c.CurrentResults...)
+	c.CurrentResults = make([]Result, 0)
+}
+
+//checkShutdown stops the job once Shutdown has been called. It is run by the goroutine of the job between the
+//requests and the queue jobs, so that the state of the job is not changed under it.
+func (j *Job) checkShutdown() {
+	select {
+	case <-j.shutdown:
+		if j.Running {
+			j.Error = "Shutting down, waiting for the requests in flight"
+			j.stopReason = STOP_SHUTDOWN
+			j.Running = false
+		}
+	default:
+	}
+}
+
+//Run runs the job like Start, and stops it when the context is cancelled. It never installs signal handlers, which
+//are left to the application embedding ffuf, and returns the reason if the job did not run to completion.
+func (j *Job) Run(ctx context.Context) error {
+	j.HandleSignals = false
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			j.interrupt("Cancelled by the context")
+		case <-done:
+		}
+	}()
+	j.Start()
+	if j.stopReason != "" && j.stopReason != STOP_COMPLETED && j.stopReason != STOP_SHUTDOWN {
+		return fmt.Errorf("%s", strings.TrimSpace(j.Error))
+	}
+	return nil
+}
+
+//Shutdown stops the running job gracefully: no new requests or queue jobs are started, while the requests in flight
+//are let to finish and their results processed until the context is done, after which they are cancelled like with
+//Stop. It waits for the job to write its output files, and returns the final summary of the run, along with the error
+//of the context if the requests in flight had to be cancelled. The job must have been started with Start or Run.
+func (j *Job) Shutdown(ctx context.Context) (Summary, error) {
+	if j.startTime.IsZero() {
+		return j.Summary(), fmt.Errorf("The job has not been started")
+	}
+	select {
+	case <-j.finished:
+		return j.Summary(), nil
+	default:
+	}
+	// The job stops itself from its own goroutine, see checkShutdown
+	j.shutdownOnce.Do(func() { close(j.shutdown) })
+	j.stopOnce.Do(func() { close(j.stopped) })
+	j.resume()
+	var err error
+	select {
+	case <-j.finished:
+	case <-ctx.Done():
+		err = ctx.Err()
+		j.Stop()
+		<-j.finished
+	}
+	return j.Summary(), err
+}
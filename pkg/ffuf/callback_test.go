@@ -0,0 +1,122 @@
+package ffuf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+//slowTestRunner answers every request with a 200 response after a delay
+type slowTestRunner struct {
+	delay    time.Duration
+	requests int64
+}
+
+func (r *slowTestRunner) Prepare(input map[string][]byte) (Request, error) {
+	return Request{Method: "GET", Url: "http://localhost/" + string(input["FUZZ"]), Headers: make(map[string]string), Input: input}, nil
+}
+
+func (r *slowTestRunner) Execute(req *Request) (Response, error) {
+	atomic.AddInt64(&r.requests, 1)
+	time.Sleep(r.delay)
+	return Response{StatusCode: 200, Request: req}, nil
+}
+
+func newShutdownTestJob(runner *slowTestRunner, inputs int) *Job {
+	ctx, cancel := context.WithCancel(context.Background())
+	conf := NewConfig(ctx, cancel)
+	conf.Url = "http://localhost/FUZZ"
+	conf.Threads = 4
+	conf.Quiet = true
+	conf.InputProviders = []InputProviderConfig{{Name: "wordlist", Keyword: "FUZZ"}}
+	j := NewJob(&conf)
+	j.Output = NewCallbackOutput(JobCallbacks{})
+	j.Runner = runner
+	values := make([]map[string][]byte, inputs)
+	for i := range values {
+		values[i] = map[string][]byte{"FUZZ": []byte(fmt.Sprintf("word%d", i))}
+	}
+	j.Input = &listTestInput{inputs: values, total: inputs}
+	return j
+}
+
+//waitForRequests waits until the job has sent at least n requests
+func waitForRequests(t *testing.T, runner *slowTestRunner, n int64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt64(&runner.requests) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("The job did not send %d requests in time", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	runner := &slowTestRunner{delay: 5 * time.Millisecond}
+	j := newShutdownTestJob(runner, 10000)
+	go j.Start()
+	waitForRequests(t, runner, 10)
+	summary, err := j.Shutdown(context.Background())
+	if err != nil {
+		t.Fatalf("Was expecting the requests in flight to finish, got %s", err)
+	}
+	if summary.StopReason != STOP_SHUTDOWN {
+		t.Errorf("Was expecting the stop reason %s, got %s", STOP_SHUTDOWN, summary.StopReason)
+	}
+	sent := atomic.LoadInt64(&runner.requests)
+	if sent >= 10000 {
+		t.Errorf("Was expecting the job to stop before going through the input, sent %d requests", sent)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if after := atomic.LoadInt64(&runner.requests); after != sent {
+		t.Errorf("Was expecting no requests after the shutdown, got %d more", after-sent)
+	}
+}
+
+func TestShutdownCancelled(t *testing.T) {
+	runner := &slowTestRunner{delay: 100 * time.Millisecond}
+	j := newShutdownTestJob(runner, 10000)
+	go j.Start()
+	waitForRequests(t, runner, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := j.Shutdown(ctx); err != context.Canceled {
+		t.Errorf("Was expecting the error of the cancelled context, got %v", err)
+	}
+}
+
+func TestShutdownPausedWhileResumed(t *testing.T) {
+	for i := 0; i < 5; i++ {
+		runner := &slowTestRunner{delay: time.Millisecond}
+		j := newShutdownTestJob(runner, 10000)
+		go j.Start()
+		waitForRequests(t, runner, 1)
+		j.Pause()
+		// The user resuming at the same time must not release the pause twice
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			j.Resume()
+		}()
+		if _, err := j.Shutdown(context.Background()); err != nil {
+			t.Fatalf("Could not shut down the paused job: %s", err)
+		}
+		wg.Wait()
+		if j.Paused {
+			t.Fatalf("The job was left paused")
+		}
+	}
+}
+
+func TestShutdownNotStarted(t *testing.T) {
+	j := newShutdownTestJob(&slowTestRunner{}, 1)
+	if _, err := j.Shutdown(context.Background()); err == nil || !strings.Contains(err.Error(), "not been started") {
+		t.Errorf("Was expecting an error for a job not started, got %v", err)
+	}
+}
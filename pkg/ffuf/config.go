@@ -2,55 +2,202 @@ package ffuf
 
 import (
 	"context"
+	"io"
+	"regexp"
+	"time"
 )
 
+//SCAN_ID_HEADER is the header carrying the scan ID in the requests sent through the replay proxy
+const SCAN_ID_HEADER = "X-Ffuf-Scan-Id"
+
+//ORIGIN_KEYWORD is the keyword holding the candidate origin IP address when using -origin-ips
+const ORIGIN_KEYWORD = "ORIGINIP"
+
+//GRPC_SERVICE_KEYWORD is the keyword holding the services listed by the gRPC server reflection when using
+//-grpc-reflection
+const GRPC_SERVICE_KEYWORD = "GRPCSERVICE"
+
+//DEFAULT_MAX_IDLE_CONNS is the default number of idle connections kept open to each host with -keep-alive
+const DEFAULT_MAX_IDLE_CONNS = 500
+
+const (
+	//INPUT_COMMAND_INPUT runs the input command once for every input, using its whole output as the input value
+	INPUT_COMMAND_INPUT = "input"
+	//INPUT_COMMAND_STREAM runs the input command once, every line of its output being an input
+	INPUT_COMMAND_STREAM = "stream"
+	//INPUT_COMMAND_BATCH runs the input command repeatedly, every run producing a batch of inputs line by line
+	INPUT_COMMAND_BATCH = "batch"
+)
+
+//InputCommandModes are the accepted values of -input-cmd-mode
+var InputCommandModes = []string{INPUT_COMMAND_INPUT, INPUT_COMMAND_STREAM, INPUT_COMMAND_BATCH}
+
 type Config struct {
-	AutoCalibration        bool                      `json:"autocalibration"`
-	AutoCalibrationStrings []string                  `json:"autocalibration_strings"`
-	Cancel                 context.CancelFunc        `json:"-"`
-	Colors                 bool                      `json:"colors"`
-	CommandKeywords        []string                  `json:"-"`
-	CommandLine            string                    `json:"cmdline"`
-	ConfigFile             string                    `json:"configfile"`
-	Context                context.Context           `json:"-"`
-	Data                   string                    `json:"postdata"`
-	Delay                  optRange                  `json:"delay"`
-	DirSearchCompat        bool                      `json:"dirsearch_compatibility"`
-	Extensions             []string                  `json:"extensions"`
-	Filters                map[string]FilterProvider `json:"filters"`
-	FollowRedirects        bool                      `json:"follow_redirects"`
-	Headers                map[string]string         `json:"headers"`
-	IgnoreBody             bool                      `json:"ignorebody"`
-	IgnoreWordlistComments bool                      `json:"ignore_wordlist_comments"`
-	InputMode              string                    `json:"inputmode"`
-	InputNum               int                       `json:"cmd_inputnum"`
-	InputProviders         []InputProviderConfig     `json:"inputproviders"`
-	InputShell             string                    `json:"inputshell"`
-	Matchers               map[string]FilterProvider `json:"matchers"`
-	MaxTime                int                       `json:"maxtime"`
-	MaxTimeJob             int                       `json:"maxtime_job"`
-	Method                 string                    `json:"method"`
-	Noninteractive         bool                      `json:"noninteractive"`
-	OutputDirectory        string                    `json:"outputdirectory"`
-	OutputFile             string                    `json:"outputfile"`
-	OutputFormat           string                    `json:"outputformat"`
-	OutputSkipEmptyFile    bool                      `json:"OutputSkipEmptyFile"`
-	ProgressFrequency      int                       `json:"-"`
-	ProxyURL               string                    `json:"proxyurl"`
-	Quiet                  bool                      `json:"quiet"`
-	Rate                   int64                     `json:"rate"`
-	Recursion              bool                      `json:"recursion"`
-	RecursionDepth         int                       `json:"recursion_depth"`
-	RecursionStrategy      string                    `json:"recursion_strategy"`
-	ReplayProxyURL         string                    `json:"replayproxyurl"`
-	SNI                    string                    `json:"sni"`
-	StopOn403              bool                      `json:"stop_403"`
-	StopOnAll              bool                      `json:"stop_all"`
-	StopOnErrors           bool                      `json:"stop_errors"`
-	Threads                int                       `json:"threads"`
-	Timeout                int                       `json:"timeout"`
-	Url                    string                    `json:"url"`
-	Verbose                bool                      `json:"verbose"`
+	ApiOperations           []ApiOperation            `json:"api_operations"`
+	AppendOutput            bool                      `json:"append_output"`
+	AppendedResults         []Result                  `json:"-"`
+	AuthDomain              string                    `json:"auth_domain"`
+	AuthPassword            string                    `json:"-"`
+	AuthScheme              string                    `json:"auth_scheme"`
+	AuthUser                string                    `json:"auth_user"`
+	AutoCalibration         bool                      `json:"autocalibration"`
+	AutoCalibrationKeywords map[string][]string       `json:"autocalibration_keywords"`
+	AutoCalibrationPerHost  bool                      `json:"autocalibration_perhost"`
+	AutoCalibrationStrategy string                    `json:"autocalibration_strategy"`
+	AutoCalibrationStrings  []string                  `json:"autocalibration_strings"`
+	AutoExtensions          bool                      `json:"auto_extensions"`
+	AutoExtensionCandidates []string                  `json:"auto_extension_candidates"`
+	BodyLimit               int64                     `json:"-"`
+	BodyMemory              int                       `json:"body_memory"`
+	BodyStore               *BodyStore                `json:"-"`
+	CACert                  string                    `json:"ca_cert"`
+	CalibrationLog          *CalibrationLog           `json:"-"`
+	Cancel                  context.CancelFunc        `json:"-"`
+	ClientCert              string                    `json:"client_cert"`
+	ClientKey               string                    `json:"client_key"`
+	Colors                  bool                      `json:"colors"`
+	Columns                 []string                  `json:"columns"`
+	ConnReuse               bool                      `json:"conn_reuse"`
+	CommandKeywords         []string                  `json:"-"`
+	CommandLine             string                    `json:"cmdline"`
+	ConfigFile              string                    `json:"configfile"`
+	Crawl                   bool                      `json:"crawl"`
+	CrawlDepth              int                       `json:"crawl_depth"`
+	CrawlPages              int                       `json:"crawl_pages"`
+	Context                 context.Context           `json:"-"`
+	Data                    string                    `json:"postdata"`
+	DedupRequests           bool                      `json:"dedup_requests"`
+	Delay                   optRange                  `json:"delay"`
+	Denylist                *Denylist                 `json:"-"`
+	DiffFile                string                    `json:"diff_file"`
+	DirSearchCompat         bool                      `json:"dirsearch_compatibility"`
+	Extensions              []string                  `json:"extensions"`
+	HexWordlists            []string                  `json:"hex_wordlists"`
+	FastSize                bool                      `json:"fast_size"`
+	FilterStats             bool                      `json:"filter_stats"`
+	Filters                 map[string]FilterProvider `json:"filters"`
+	FollowRedirects         bool                      `json:"follow_redirects"`
+	GRPCReflection          bool                      `json:"grpc_reflection"`
+	GlobalLimiter           *GlobalLimiter            `json:"-"`
+	GroupDirectories        bool                      `json:"group_directories"`
+	Headers                 map[string]string         `json:"headers"`
+	HostErrors              int                       `json:"host_errors"`
+	Http2                   bool                      `json:"http2"`
+	Http2PriorKnowledge     bool                      `json:"http2_prior_knowledge"`
+	IgnoreBody              bool                      `json:"ignorebody"`
+	IPRate                  int64                     `json:"ip_rate"`
+	IPThreads               int                       `json:"ip_threads"`
+	IgnoreWordlistComments  bool                      `json:"ignore_wordlist_comments"`
+	ImportFile              string                    `json:"import_file"`
+	ImportedResults         []Result                  `json:"-"`
+	InputCommandMode        string                    `json:"cmd_inputmode"`
+	InputMode               string                    `json:"inputmode"`
+	InputNum                int                       `json:"cmd_inputnum"`
+	InputProviders          []InputProviderConfig     `json:"inputproviders"`
+	InputShell              string                    `json:"inputshell"`
+	InputTransforms         map[string][][]string     `json:"input_transforms"`
+	JSONFields              []string                  `json:"json_fields"`
+	KeepAlive               bool                      `json:"keep_alive"`
+	Matchers                map[string]FilterProvider `json:"matchers"`
+	MaxBodySize             int64                     `json:"max_body_size"`
+	MaxIdleConns            int                       `json:"max_idle_conns"`
+	MaxTime                 int                       `json:"maxtime"`
+	MaxTimeJob              int                       `json:"maxtime_job"`
+	Method                  string                    `json:"method"`
+	Noninteractive          bool                      `json:"noninteractive"`
+	OriginIPs               string                    `json:"origin_ips"`
+	OutputDirectory         string                    `json:"outputdirectory"`
+	OutputFile              string                    `json:"outputfile"`
+	OutputFormat            string                    `json:"outputformat"`
+	OutputFsync             bool                      `json:"output_fsync"`
+	OutputSkipEmptyFile     bool                      `json:"OutputSkipEmptyFile"`
+	Pcap                    *PcapWriter               `json:"-"`
+	Prewarm                 int                       `json:"prewarm"`
+	PinnedInputs            []string                  `json:"pinned_inputs"`
+	PcapFile                string                    `json:"pcap_file"`
+	PostProcess             []string                  `json:"postprocess"`
+	ProgressFrequency       int                       `json:"-"`
+	ProgressJSON            string                    `json:"progress_json"`
+	ProgressJSONWriter      io.Writer                 `json:"-"`
+	ProxyBackups            []string                  `json:"proxy_backups"`
+	ProxyFallback           string                    `json:"proxy_fallback"`
+	ProxyList               []string                  `json:"proxy_list"`
+	ProxyPool               *ProxyPool                `json:"-"`
+	ProxyRotate             string                    `json:"proxy_rotate"`
+	ProxyURL                string                    `json:"proxyurl"`
+	QueueOverrideRules      []QueueOverrideRule       `json:"-"`
+	Quiet                   bool                      `json:"quiet"`
+	Rate                    int64                     `json:"rate"`
+	RateAdaptive            bool                      `json:"rate_adaptive"`
+	RateMode                string                    `json:"rate_mode"`
+	Recursion               bool                      `json:"recursion"`
+	RecursionBreadth        int                       `json:"recursion_breadth"`
+	RecursionDepth          int                       `json:"recursion_depth"`
+	RecursionDepthBreadth   int                       `json:"recursion_depth_breadth"`
+	RecursionExclude        *regexp.Regexp            `json:"-"`
+	RecursionFingerprint    int                       `json:"recursion_fingerprint"`
+	RecursionInclude        *regexp.Regexp            `json:"-"`
+	RecursionInputLimits    []int                     `json:"recursion_input_limits"`
+	RecursionLinks          bool                      `json:"recursion_links"`
+	RecursionMaxJobs        int                       `json:"recursion_max_jobs"`
+	RecursionWordlist       string                    `json:"recursion_wordlist"`
+	RecursionStrategy       string                    `json:"recursion_strategy"`
+	RequestMiddleware       []RequestMiddleware       `json:"-"`
+	ReplayDir               string                    `json:"replay_dir"`
+	ReplayMatchers          map[string]FilterProvider `json:"replay_matchers"`
+	ReplayProxyURL          string                    `json:"replayproxyurl"`
+	Resolve                 map[string]string         `json:"resolve"`
+	Resolvers               []string                  `json:"resolvers"`
+	Retries                 int                       `json:"retries"`
+	RetryDelay              float64                   `json:"retry_delay"`
+	RetryStatus             []int64                   `json:"retry_status"`
+	ResponseMiddleware      []ResponseMiddleware      `json:"-"`
+	ResultProcessors        []ResultProcessor         `json:"-"`
+	RobotsDelay             bool                      `json:"robots_delay"`
+	RobotsDelayMax          float64                   `json:"robots_delay_max"`
+	SafeAllow               []string                  `json:"safe_allow"`
+	SafeMode                bool                      `json:"safe_mode"`
+	ScanID                  string                    `json:"scan_id"`
+	ScraperDir              string                    `json:"scraper_dir"`
+	Shard                   int                       `json:"shard"`
+	ShardCount              int                       `json:"shard_count"`
+	ShardHash               bool                      `json:"shard_hash"`
+	ScraperFile             string                    `json:"scraperfile"`
+	Scrapers                string                    `json:"scrapers"`
+	SniperDefaults          []string                  `json:"sniper_defaults"`
+	SNI                     string                    `json:"sni"`
+	StatusActions           map[int64]string          `json:"status_actions"`
+	StatusMatrix            bool                      `json:"status_matrix"`
+	StopOn403               bool                      `json:"stop_403"`
+	StopOnAll               bool                      `json:"stop_all"`
+	StopOnErrors            bool                      `json:"stop_errors"`
+	StopRules               []StopRule                `json:"stop_rules"`
+	SummaryJSON             bool                      `json:"summary_json"`
+	Threads                 int                       `json:"threads"`
+	TLSCiphers              []string                  `json:"tls_ciphers"`
+	TLSKeyLog               io.WriteCloser            `json:"-"`
+	TLSKeyLogFile           string                    `json:"tls_keylog_file"`
+	TLSMaxVersion           string                    `json:"tls_max_version"`
+	TLSMinVersion           string                    `json:"tls_min_version"`
+	Timeout                 int                       `json:"timeout"`
+	TimeFormat              string                    `json:"time_format"`
+	TimeLocation            *time.Location            `json:"-"`
+	TimeZone                string                    `json:"timezone"`
+	UpdateCheck             bool                      `json:"update_check"`
+	UpdateURL               string                    `json:"update_url"`
+	UnixSocket              string                    `json:"unix_socket"`
+	Url                     string                    `json:"url"`
+	Verbose                 bool                      `json:"verbose"`
+	WaitFor                 string                    `json:"wait_for"`
+	WaitForInterval         int                       `json:"wait_for_interval"`
+	WaitForRegex            string                    `json:"wait_for_regex"`
+	WaitForTimeout          int                       `json:"wait_for_timeout"`
+	WebhookBatch            int                       `json:"webhook_batch"`
+	WebhookEvents           []string                  `json:"webhook_events"`
+	WebhookMilestones       []int                     `json:"webhook_milestones"`
+	WebhookTemplate         string                    `json:"webhook_template"`
+	WebhookURL              string                    `json:"webhook_url"`
+	WordlistStats           bool                      `json:"wordlist_stats"`
 }
 
 type InputProviderConfig struct {
@@ -61,41 +208,158 @@ type InputProviderConfig struct {
 
 func NewConfig(ctx context.Context, cancel context.CancelFunc) Config {
 	var conf Config
+	conf.ApiOperations = make([]ApiOperation, 0)
+	conf.AppendOutput = false
+	conf.AppendedResults = make([]Result, 0)
+	conf.AuthDomain = ""
+	conf.AuthPassword = ""
+	conf.AuthScheme = ""
+	conf.AuthUser = ""
+	conf.AutoCalibrationKeywords = make(map[string][]string)
+	conf.AutoCalibrationPerHost = false
+	conf.AutoCalibrationStrategy = CALIBRATION_BASIC
 	conf.AutoCalibrationStrings = make([]string, 0)
+	conf.AutoExtensions = false
+	conf.AutoExtensionCandidates = make([]string, 0)
+	conf.BodyMemory = DEFAULT_BODY_MEMORY
+	conf.BodyStore = NewBodyStore(DEFAULT_MAX_BODY_SIZE, int64(DEFAULT_BODY_MEMORY)*1024*1024)
+	conf.CACert = ""
+	conf.CalibrationLog = NewCalibrationLog()
+	conf.ClientCert = ""
+	conf.ClientKey = ""
+	conf.Columns = make([]string, 0)
 	conf.CommandKeywords = make([]string, 0)
 	conf.Context = ctx
 	conf.Cancel = cancel
+	conf.Crawl = false
+	conf.CrawlDepth = 2
+	conf.CrawlPages = 100
 	conf.Data = ""
+	conf.DedupRequests = false
 	conf.Delay = optRange{0, 0, false, false}
+	conf.Denylist = nil
+	conf.DiffFile = ""
 	conf.DirSearchCompat = false
 	conf.Extensions = make([]string, 0)
+	conf.HexWordlists = make([]string, 0)
+	conf.FastSize = false
+	conf.FilterStats = false
+	conf.WordlistStats = false
 	conf.Filters = make(map[string]FilterProvider)
 	conf.FollowRedirects = false
+	conf.GRPCReflection = false
+	conf.GlobalLimiter = nil
+	conf.GroupDirectories = false
 	conf.Headers = make(map[string]string)
+	conf.HostErrors = 0
+	conf.Http2 = false
+	conf.Http2PriorKnowledge = false
+	conf.IPRate = 0
+	conf.IPThreads = 0
 	conf.IgnoreWordlistComments = false
+	conf.ImportFile = ""
+	conf.ImportedResults = make([]Result, 0)
+	conf.InputCommandMode = INPUT_COMMAND_INPUT
 	conf.InputMode = "clusterbomb"
 	conf.InputNum = 0
 	conf.InputShell = ""
 	conf.InputProviders = make([]InputProviderConfig, 0)
+	conf.InputTransforms = make(map[string][][]string)
+	conf.JSONFields = JSONFields
+	conf.KeepAlive = false
 	conf.Matchers = make(map[string]FilterProvider)
 	conf.MaxTime = 0
 	conf.MaxTimeJob = 0
+	conf.MaxBodySize = DEFAULT_MAX_BODY_SIZE
+	conf.MaxIdleConns = DEFAULT_MAX_IDLE_CONNS
 	conf.Method = "GET"
 	conf.Noninteractive = false
+	conf.OriginIPs = ""
+	conf.OutputFsync = false
+	conf.Pcap = nil
+	conf.Prewarm = 0
+	conf.PinnedInputs = make([]string, 0)
+	conf.PcapFile = ""
+	conf.PostProcess = make([]string, 0)
 	conf.ProgressFrequency = 125
+	conf.ProgressJSON = ""
+	conf.ProgressJSONWriter = nil
+	conf.ProxyBackups = make([]string, 0)
+	conf.ProxyFallback = PROXY_FALLBACK_FAIL
+	conf.ProxyList = make([]string, 0)
+	conf.ProxyPool = nil
+	conf.ProxyRotate = PROXY_ROTATE_ROUNDROBIN
 	conf.ProxyURL = ""
+	conf.QueueOverrideRules = make([]QueueOverrideRule, 0)
 	conf.Quiet = false
 	conf.Rate = 0
+	conf.RateAdaptive = false
+	conf.RateMode = RATE_MODE_FIXED
 	conf.Recursion = false
+	conf.RecursionBreadth = 0
 	conf.RecursionDepth = 0
+	conf.RecursionDepthBreadth = 0
+	conf.RecursionExclude = nil
+	conf.RecursionFingerprint = 0
+	conf.RecursionInclude = nil
+	conf.RecursionInputLimits = make([]int, 0)
+	conf.RecursionLinks = false
+	conf.RecursionMaxJobs = 0
 	conf.RecursionStrategy = "default"
+	conf.RequestMiddleware = make([]RequestMiddleware, 0)
+	conf.RecursionWordlist = ""
+	conf.ReplayDir = ""
+	conf.ReplayMatchers = make(map[string]FilterProvider)
+	conf.Resolve = make(map[string]string)
+	conf.Resolvers = make([]string, 0)
+	conf.ResponseMiddleware = make([]ResponseMiddleware, 0)
+	conf.ResultProcessors = make([]ResultProcessor, 0)
+	conf.Retries = 1
+	conf.RetryDelay = 0.5
+	conf.RetryStatus = make([]int64, 0)
+	conf.RobotsDelay = false
+	conf.RobotsDelayMax = 0
+	conf.SafeAllow = make([]string, 0)
+	conf.SafeMode = false
+	conf.ScanID = ""
+	conf.ScraperDir = ""
+	conf.Shard = 0
+	conf.ShardCount = 0
+	conf.ShardHash = false
+	conf.ScraperFile = ""
+	conf.Scrapers = ""
+	conf.SniperDefaults = make([]string, 0)
 	conf.SNI = ""
+	conf.StatusMatrix = false
 	conf.StopOn403 = false
 	conf.StopOnAll = false
 	conf.StopOnErrors = false
+	conf.StatusActions = make(map[int64]string)
+	conf.StopRules = make([]StopRule, 0)
+	conf.SummaryJSON = false
 	conf.Timeout = 10
+	conf.TimeFormat = ""
+	conf.TimeLocation = nil
+	conf.TimeZone = ""
+	conf.TLSCiphers = make([]string, 0)
+	conf.TLSKeyLog = nil
+	conf.TLSKeyLogFile = ""
+	conf.TLSMaxVersion = ""
+	conf.TLSMinVersion = ""
+	conf.UpdateCheck = false
+	conf.UpdateURL = ""
+	conf.UnixSocket = ""
 	conf.Url = ""
 	conf.Verbose = false
+	conf.WaitFor = ""
+	conf.WaitForInterval = 5
+	conf.WaitForRegex = ""
+	conf.WaitForTimeout = 300
+	conf.WebhookBatch = 10
+	conf.WebhookEvents = []string{NOTIFY_EVENT_RESULTS}
+	conf.WebhookMilestones = make([]int, 0)
+	conf.WebhookTemplate = "json"
+	conf.WebhookURL = ""
 	return conf
 }
 
@@ -103,3 +367,17 @@ func (c *Config) SetContext(ctx context.Context, cancel context.CancelFunc) {
 	c.Context = ctx
 	c.Cancel = cancel
 }
+
+//InputKeywords returns the distinct input keywords in the order they were defined. A keyword may have several input
+//providers, whose inputs are merged.
+func (c *Config) InputKeywords() []string {
+	keywords := make([]string, 0, len(c.InputProviders))
+	seen := make(map[string]bool, len(c.InputProviders))
+	for _, p := range c.InputProviders {
+		if !seen[p.Keyword] {
+			seen[p.Keyword] = true
+			keywords = append(keywords, p.Keyword)
+		}
+	}
+	return keywords
+}
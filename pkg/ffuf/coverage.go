@@ -0,0 +1,198 @@
+package ffuf
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+)
+
+//KeywordCoverage counts the distinct entries of an input keyword that were sent, errored and matched
+type KeywordCoverage struct {
+	Keyword string `json:"keyword"`
+	Sent    int    `json:"sent"`
+	Errored int    `json:"errored"`
+	Matched int    `json:"matched"`
+}
+
+//JobCoverage counts the inputs of a queue job by what happened to them. Denied are the inputs skipped by the denylist,
+//Quarantined the ones skipped for a quarantined host and Deduplicated the matches dropped by the result post-processors.
+type JobCoverage struct {
+	Url          string            `json:"url"`
+	Total        int               `json:"total"`
+	Sent         int               `json:"sent"`
+	Denied       int               `json:"denied"`
+	Quarantined  int               `json:"quarantined"`
+	Deduplicated int               `json:"deduplicated"`
+	Errored      int               `json:"errored"`
+	Matched      int               `json:"matched"`
+	Keywords     []KeywordCoverage `json:"keywords"`
+}
+
+//keywordEntries keeps the hashes of the distinct entries of a keyword, so that an entry sent with many values of the
+//other keywords is counted once
+type keywordEntries struct {
+	sent    map[uint64]bool
+	errored map[uint64]bool
+	matched map[uint64]bool
+}
+
+//Coverage collects the wordlist coverage of the queue jobs, printed with -wordlist-stats and included in -summary-json
+type Coverage struct {
+	mutex    sync.Mutex
+	jobs     []JobCoverage
+	current  *JobCoverage
+	keywords []string
+	entries  map[string]*keywordEntries
+}
+
+func NewCoverage() *Coverage {
+	return &Coverage{jobs: make([]JobCoverage, 0)}
+}
+
+//StartJob starts counting the inputs of a queue job
+func (c *Coverage) StartJob(jobUrl string, total int, providers []InputProviderConfig) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.finish()
+	c.current = &JobCoverage{Url: jobUrl, Total: total}
+	c.keywords = make([]string, 0, len(providers))
+	c.entries = make(map[string]*keywordEntries)
+	for _, p := range providers {
+		if _, ok := c.entries[p.Keyword]; ok {
+			continue
+		}
+		c.keywords = append(c.keywords, p.Keyword)
+		c.entries[p.Keyword] = &keywordEntries{sent: make(map[uint64]bool), errored: make(map[uint64]bool), matched: make(map[uint64]bool)}
+	}
+}
+
+//FinishJob records the inputs the denylist skipped in the current queue job, and finishes counting it
+func (c *Coverage) FinishJob(denied int) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.current != nil {
+		c.current.Denied = denied
+	}
+	c.finish()
+}
+
+func (c *Coverage) finish() {
+	if c.current == nil {
+		return
+	}
+	c.current.Keywords = c.keywordStats()
+	c.jobs = append(c.jobs, *c.current)
+	c.current = nil
+}
+
+//Quarantined counts an input skipped for a quarantined host
+func (c *Coverage) Quarantined() {
+	c.count(func(jc *JobCoverage) { jc.Quarantined++ })
+}
+
+//Deduplicated counts a match dropped by a result post-processor
+func (c *Coverage) Deduplicated() {
+	c.count(func(jc *JobCoverage) { jc.Deduplicated++ })
+}
+
+func (c *Coverage) count(f func(jc *JobCoverage)) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.current != nil {
+		f(c.current)
+	}
+}
+
+//Sent counts an input that got a response, and whether it matched
+func (c *Coverage) Sent(input map[string][]byte, matched bool) {
+	c.add(input, false, matched)
+}
+
+//Errored counts an input whose request failed after the retries
+func (c *Coverage) Errored(input map[string][]byte) {
+	c.add(input, true, false)
+}
+
+func (c *Coverage) add(input map[string][]byte, errored, matched bool) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.current == nil {
+		return
+	}
+	c.current.Sent++
+	if errored {
+		c.current.Errored++
+	}
+	if matched {
+		c.current.Matched++
+	}
+	for kw, e := range c.entries {
+		v, ok := input[kw]
+		if !ok {
+			continue
+		}
+		h := fnv.New64a()
+		h.Write(v)
+		sum := h.Sum64()
+		e.sent[sum] = true
+		if errored {
+			e.errored[sum] = true
+		}
+		if matched {
+			e.matched[sum] = true
+		}
+	}
+}
+
+func (c *Coverage) keywordStats() []KeywordCoverage {
+	stats := make([]KeywordCoverage, 0, len(c.keywords))
+	for _, kw := range c.keywords {
+		e := c.entries[kw]
+		stats = append(stats, KeywordCoverage{Keyword: kw, Sent: len(e.sent), Errored: len(e.errored), Matched: len(e.matched)})
+	}
+	return stats
+}
+
+//Stats returns the coverage of the queue jobs run so far, including the current one
+func (c *Coverage) Stats() []JobCoverage {
+	if c == nil {
+		return []JobCoverage{}
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	stats := append([]JobCoverage{}, c.jobs...)
+	if c.current != nil {
+		current := *c.current
+		current.Keywords = c.keywordStats()
+		stats = append(stats, current)
+	}
+	return stats
+}
+
+//Report returns the wordlist coverage of the queue jobs as a printable table
+func (c *Coverage) Report() string {
+	var b strings.Builder
+	b.WriteString("Wordlist coverage:\n")
+	for _, jc := range c.Stats() {
+		fmt.Fprintf(&b, " :: %s\n", jc.Url)
+		fmt.Fprintf(&b, " :: %-12s: %6d inputs, %6d sent, %6d denied, %6d quarantined, %6d deduplicated, %6d errored, %6d matched\n",
+			"Job", jc.Total, jc.Sent, jc.Denied, jc.Quarantined, jc.Deduplicated, jc.Errored, jc.Matched)
+		for _, kc := range jc.Keywords {
+			fmt.Fprintf(&b, " :: %-12s: %6d entries sent, %6d errored, %6d matched\n", kc.Keyword, kc.Sent, kc.Errored, kc.Matched)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,292 @@
+package ffuf
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	crawlLinkRegexp  = regexp.MustCompile(`(?i)(?:href|src|action)\s*=\s*["']([^"'#]+)`)
+	crawlFormRegexp  = regexp.MustCompile(`(?is)<form([^>]*)>(.*?)</form>`)
+	crawlInputRegexp = regexp.MustCompile(`(?i)<(?:input|select|textarea)[^>]+name\s*=\s*["']([^"']+)["']`)
+	crawlActionRegex = regexp.MustCompile(`(?i)action\s*=\s*["']([^"']*)["']`)
+)
+
+type crawlPage struct {
+	url   *url.URL
+	depth int
+}
+
+//crawlConfig is the snapshot of the settings used by the background crawl, taken before it starts, as the queue
+//jobs change them in the configuration of the job
+type crawlConfig struct {
+	target     string
+	headers    map[string]string
+	delay      optRange
+	fuzzParams bool
+}
+
+//crawlSnapshot returns the settings of the run for the crawl, with the headers not containing any keywords
+func (j *Job) crawlSnapshot() crawlConfig {
+	c := crawlConfig{
+		target:     j.Config.Url,
+		headers:    make(map[string]string),
+		delay:      j.Config.Delay,
+		fuzzParams: keywordProvided("FUZZ", j.Config),
+	}
+	for k, v := range j.Config.Headers {
+		if !containsKeyword(k, j.Config) && !containsKeyword(v, j.Config) {
+			c.headers[k] = v
+		}
+	}
+	return c
+}
+
+//crawl runs a bounded crawl starting from the base of the target URL. Directories found are added to the job queue
+//as they are discovered, and parameters found are added as parameter fuzzing jobs once the crawl has finished.
+func (j *Job) crawl(c crawlConfig) {
+	defer j.crawlWg.Done()
+	target := c.target
+	start, err := url.Parse(strings.TrimSuffix(target, "FUZZ"))
+	if err != nil {
+		j.Output.Error(fmt.Sprintf("Could not parse the crawl start URL: %s", err))
+		return
+	}
+	// Directory jobs need the FUZZ keyword in the end of the URL
+	dirJobs := strings.HasSuffix(target, "FUZZ")
+	if !dirJobs {
+		// Crawl from the root of the host if FUZZ is positioned elsewhere in the URL
+		start.Path = "/"
+		start.RawQuery = ""
+	}
+	queued := map[string]bool{target: true}
+	seen := map[string]bool{start.String(): true}
+	params := make(map[string]map[string]bool)
+	pages := []crawlPage{{url: start, depth: 0}}
+	crawled := 0
+	dirs := 0
+
+	for len(pages) > 0 && crawled < j.Config.CrawlPages && !j.isStopped() {
+		page := pages[0]
+		pages = pages[1:]
+		body, ok := j.crawlFetch(c, page.url.String())
+		if !ok {
+			continue
+		}
+		crawled++
+		for _, link := range crawlLinks(page.url, body) {
+			if link.Host != start.Host || link.Scheme != start.Scheme {
+				// Stay on the same origin
+				continue
+			}
+			for _, name := range crawlQueryParams(link) {
+				addCrawlParam(params, link, name)
+			}
+			if dirJobs {
+				for _, dir := range crawlDirectories(start.Path, link.Path) {
+					if j.addCrawlDirectory(start, dir, queued) {
+						dirs++
+					}
+				}
+			}
+			link.RawQuery = ""
+			if page.depth < j.Config.CrawlDepth && !seen[link.String()] {
+				seen[link.String()] = true
+				pages = append(pages, crawlPage{url: link, depth: page.depth + 1})
+			}
+		}
+		for action, names := range crawlForms(page.url, body) {
+			if action.Host != start.Host || action.Scheme != start.Scheme {
+				continue
+			}
+			for _, name := range names {
+				addCrawlParam(params, action, name)
+			}
+		}
+	}
+
+	// Parameter fuzzing stage, run after the discovered directories
+	paramJobs := 0
+	if c.fuzzParams {
+		pageUrls := make([]string, 0, len(params))
+		for p := range params {
+			pageUrls = append(pageUrls, p)
+		}
+		sort.Strings(pageUrls)
+		for _, p := range pageUrls {
+			names := make([]string, 0, len(params[p]))
+			for name := range params[p] {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				paramUrl := p + "?" + url.QueryEscape(name) + "=FUZZ"
+				if queued[paramUrl] {
+					continue
+				}
+				queued[paramUrl] = true
+				j.addQueueJob(QueueJob{Url: paramUrl, depth: 0, noRecursion: true})
+				paramJobs++
+			}
+		}
+	}
+	j.Output.Info(fmt.Sprintf("Crawl finished: %d pages crawled, %d directories and %d parameters queued", crawled, dirs, paramJobs))
+}
+
+//crawlFetch requests a page for crawling, returning its body if it is an HTML document. The request takes a thread
+//of the running queue job, and is rate limited and delayed like its requests.
+func (j *Job) crawlFetch(c crawlConfig, target string) ([]byte, bool) {
+	if j.Config.Denylist != nil {
+		if u, err := url.Parse(target); err == nil && j.Config.Denylist.Denied([]byte(u.Path)) {
+			return nil, false
+		}
+	}
+	req := Request{Method: "GET", Url: target, Headers: make(map[string]string)}
+	for k, v := range c.headers {
+		req.Headers[k] = v
+	}
+	j.limiterMutex.Lock()
+	limiter := j.limiter
+	j.limiterMutex.Unlock()
+	limiter <- true
+	defer func() { <-limiter }()
+	if j.Config.GlobalLimiter != nil {
+		if err := j.Config.GlobalLimiter.Wait(j.Config.Context); err != nil {
+			return nil, false
+		}
+	}
+	if j.robotsLimiter != nil {
+		if err := j.robotsLimiter.Wait(j.Config.Context); err != nil {
+			return nil, false
+		}
+	}
+	threadStart := time.Now()
+	resp, err := j.Runner.Execute(&req)
+	defer resp.MakeFreeMemory()
+	defer func() {
+		j.sleepDelay(c.delay)
+		j.Rate.Throttle()
+		j.Rate.Tick(threadStart, time.Now())
+	}()
+	if err != nil {
+		return nil, false
+	}
+	if resp.StatusCode >= 400 || (len(resp.ContentType) > 0 && !strings.Contains(resp.ContentType, "html")) {
+		return nil, false
+	}
+	// Copied, as freeing the response releases its body
+	return append([]byte(nil), resp.Body()...), true
+}
+
+//addCrawlDirectory adds a queue job for the discovered directory if it has not been queued yet and is within
+//the maximum recursion depth
+func (j *Job) addCrawlDirectory(start *url.URL, dir string, queued map[string]bool) bool {
+	depth := strings.Count(strings.TrimPrefix(dir, start.Path), "/")
+	if j.Config.RecursionDepth > 0 && depth > j.Config.RecursionDepth {
+		return false
+	}
+	u := *start
+	u.Path = dir
+	u.RawQuery = ""
+	dirUrl := u.String() + "FUZZ"
+	if queued[dirUrl] {
+		return false
+	}
+	queued[dirUrl] = true
+	j.addQueueJob(QueueJob{Url: dirUrl, depth: depth})
+	j.Output.Info(fmt.Sprintf("Adding a new crawled directory job to the queue: %s", dirUrl))
+	return true
+}
+
+//crawlLinks returns the absolute URLs of links found in an HTML body
+func crawlLinks(base *url.URL, body []byte) []*url.URL {
+	links := make([]*url.URL, 0)
+	for _, m := range crawlLinkRegexp.FindAllSubmatch(body, -1) {
+		ref, err := url.Parse(strings.TrimSpace(string(m[1])))
+		if err != nil {
+			continue
+		}
+		link := base.ResolveReference(ref)
+		link.Fragment = ""
+		links = append(links, link)
+	}
+	return links
+}
+
+//crawlForms returns the input names of the HTML forms found in the body, keyed by the form target
+func crawlForms(base *url.URL, body []byte) map[*url.URL][]string {
+	forms := make(map[*url.URL][]string)
+	for _, m := range crawlFormRegexp.FindAllSubmatch(body, -1) {
+		action := base
+		if am := crawlActionRegex.FindSubmatch(m[1]); am != nil {
+			ref, err := url.Parse(strings.TrimSpace(string(am[1])))
+			if err != nil {
+				continue
+			}
+			action = base.ResolveReference(ref)
+		}
+		names := make([]string, 0)
+		for _, im := range crawlInputRegexp.FindAllSubmatch(m[2], -1) {
+			names = append(names, string(im[1]))
+		}
+		forms[action] = append(forms[action], names...)
+	}
+	return forms
+}
+
+//crawlQueryParams returns the names of query parameters of a URL
+func crawlQueryParams(u *url.URL) []string {
+	names := make([]string, 0)
+	for name := range u.Query() {
+		names = append(names, name)
+	}
+	return names
+}
+
+//crawlDirectories returns the directories of a path that reside under the base path, excluding the base itself
+func crawlDirectories(basePath, path string) []string {
+	dirs := make([]string, 0)
+	if !strings.HasPrefix(path, basePath) {
+		return dirs
+	}
+	for i := len(basePath); i < len(path); i++ {
+		if path[i] == '/' {
+			dirs = append(dirs, path[:i+1])
+		}
+	}
+	return dirs
+}
+
+func addCrawlParam(params map[string]map[string]bool, page *url.URL, name string) {
+	u := *page
+	u.RawQuery = ""
+	u.Fragment = ""
+	if _, ok := params[u.String()]; !ok {
+		params[u.String()] = make(map[string]bool)
+	}
+	params[u.String()][name] = true
+}
+
+//keywordProvided checks if an input provider for the keyword has been configured
+func keywordProvided(keyword string, conf *Config) bool {
+	for _, p := range conf.InputProviders {
+		if p.Keyword == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+//containsKeyword checks if the string contains any of the configured input keywords
+func containsKeyword(s string, conf *Config) bool {
+	for _, p := range conf.InputProviders {
+		if strings.Contains(s, p.Keyword) {
+			return true
+		}
+	}
+	return false
+}
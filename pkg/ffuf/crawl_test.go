@@ -0,0 +1,172 @@
+package ffuf
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// crawlTestRunner serves the pages of a site from memory, and records the requests
+type crawlTestRunner struct {
+	pages    map[string]string
+	mutex    sync.Mutex
+	requests []Request
+}
+
+func (r *crawlTestRunner) Prepare(input map[string][]byte) (Request, error) {
+	return Request{}, fmt.Errorf("not supported")
+}
+
+func (r *crawlTestRunner) Execute(req *Request) (Response, error) {
+	r.mutex.Lock()
+	r.requests = append(r.requests, *req)
+	r.mutex.Unlock()
+	body, ok := r.pages[req.Url]
+	if !ok {
+		return Response{StatusCode: 404, Request: req}, nil
+	}
+	return Response{StatusCode: 200, ContentType: "text/html", Data: []byte(body), Request: req}, nil
+}
+
+func (r *crawlTestRunner) count() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.requests)
+}
+
+func newCrawlTestJob(runner *crawlTestRunner) *Job {
+	ctx, cancel := context.WithCancel(context.Background())
+	conf := NewConfig(ctx, cancel)
+	conf.Url = "http://localhost/FUZZ"
+	conf.Threads = 2
+	conf.CrawlPages = 50
+	conf.CrawlDepth = 3
+	conf.InputProviders = []InputProviderConfig{{Name: "wordlist", Keyword: "FUZZ"}}
+	conf.Headers = map[string]string{"X-Test": "1", "X-Fuzz": "FUZZ"}
+	j := NewJob(&conf)
+	j.Output = NewCallbackOutput(JobCallbacks{})
+	j.Runner = runner
+	j.threadLimiter()
+	return j
+}
+
+func crawlTestSite() map[string]string {
+	return map[string]string{
+		"http://localhost/":                 `<a href="/admin/users/list?page=2">users</a> <a href="http://other.localhost/x/">other</a>`,
+		"http://localhost/admin/users/list": `<form action="/login"><input name="user"><input type="password" name="pass"></form>`,
+	}
+}
+
+func TestCrawl(t *testing.T) {
+	runner := &crawlTestRunner{pages: crawlTestSite()}
+	j := newCrawlTestJob(runner)
+	j.crawlWg.Add(1)
+	j.crawl(j.crawlSnapshot())
+	urls := make([]string, 0)
+	for _, q := range j.queuejobs {
+		urls = append(urls, q.Url)
+	}
+	want := []string{
+		"http://localhost/admin/FUZZ",
+		"http://localhost/admin/users/FUZZ",
+		"http://localhost/admin/users/list?page=FUZZ",
+		"http://localhost/login?pass=FUZZ",
+		"http://localhost/login?user=FUZZ",
+	}
+	if !reflect.DeepEqual(urls, want) {
+		t.Errorf("Was expecting the queue jobs %v, got %v", want, urls)
+	}
+	for _, req := range runner.requests {
+		if req.Method != "GET" || req.Headers["X-Test"] != "1" {
+			t.Errorf("Unexpected crawl request %s %s %v", req.Method, req.Url, req.Headers)
+		}
+		if _, ok := req.Headers["X-Fuzz"]; ok {
+			t.Errorf("The header containing a keyword was sent with the crawl request %s", req.Url)
+		}
+	}
+}
+
+func TestCrawlSnapshot(t *testing.T) {
+	j := newCrawlTestJob(&crawlTestRunner{})
+	c := j.crawlSnapshot()
+	// The queue jobs change the configuration while the crawl runs
+	j.Config.Url = "http://localhost/admin/FUZZ"
+	j.Config.Headers["X-Test"] = "2"
+	j.Config.InputProviders = nil
+	if c.target != "http://localhost/FUZZ" || c.headers["X-Test"] != "1" || !c.fuzzParams {
+		t.Errorf("The crawl settings changed with the configuration: %+v", c)
+	}
+}
+
+func TestCrawlThreadLimiter(t *testing.T) {
+	runner := &crawlTestRunner{pages: crawlTestSite()}
+	j := newCrawlTestJob(runner)
+	limiter := j.threadLimiter()
+	// The queue job has every thread busy
+	for i := 0; i < cap(limiter); i++ {
+		limiter <- true
+	}
+	j.crawlWg.Add(1)
+	go j.crawl(j.crawlSnapshot())
+	time.Sleep(50 * time.Millisecond)
+	if n := runner.count(); n != 0 {
+		t.Errorf("Was expecting the crawl to wait for a free thread, it sent %d requests", n)
+	}
+	<-limiter
+	j.crawlWg.Wait()
+	if n := runner.count(); n != 3 {
+		t.Errorf("Was expecting 3 crawl requests, got %d", n)
+	}
+}
+
+func TestCrawlStopped(t *testing.T) {
+	runner := &crawlTestRunner{pages: crawlTestSite()}
+	j := newCrawlTestJob(runner)
+	j.Stop()
+	j.crawlWg.Add(1)
+	j.crawl(j.crawlSnapshot())
+	if n := runner.count(); n != 0 {
+		t.Errorf("Was expecting no requests from a stopped crawl, got %d", n)
+	}
+}
+
+func TestCrawlForms(t *testing.T) {
+	base, _ := url.Parse("http://localhost/dir/page")
+	body := []byte(`<form action="search"><input name="q"><select name="sort"></select></form>` +
+		`<form><textarea name="comment"></textarea></form>`)
+	forms := crawlForms(base, body)
+	found := make(map[string][]string)
+	for action, names := range forms {
+		sort.Strings(names)
+		found[action.String()] = names
+	}
+	want := map[string][]string{
+		"http://localhost/dir/search": {"q", "sort"},
+		"http://localhost/dir/page":   {"comment"},
+	}
+	if !reflect.DeepEqual(found, want) {
+		t.Errorf("Was expecting the forms %v, got %v", want, found)
+	}
+}
+
+func TestCrawlDirectories(t *testing.T) {
+	for _, test := range []struct {
+		base string
+		path string
+		want []string
+	}{
+		{"/", "/a/b/c.html", []string{"/a/", "/a/b/"}},
+		{"/a/", "/a/b/c/", []string{"/a/b/", "/a/b/c/"}},
+		{"/a/", "/other/b/", []string{}},
+		{"/", "/index.html", []string{}},
+	} {
+		if got := crawlDirectories(test.base, test.path); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Directories of %s under %s: was expecting %v, got %v", test.path, test.base, test.want, got)
+		}
+	}
+}
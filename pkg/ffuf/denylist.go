@@ -0,0 +1,83 @@
+package ffuf
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+//Denylist holds the patterns of payloads that must never be sent. Patterns prefixed with "regex:" are regular
+//expressions, patterns containing * or ? are globs matching the whole value, and others match any value containing
+//them. Globs and plain patterns are case insensitive.
+type Denylist struct {
+	patterns []*regexp.Regexp
+}
+
+//NewDenylist compiles the denylist patterns
+func NewDenylist(patterns []string) (*Denylist, error) {
+	d := &Denylist{patterns: make([]*regexp.Regexp, 0, len(patterns))}
+	for _, p := range patterns {
+		var expr string
+		switch {
+		case strings.HasPrefix(p, "regex:"):
+			expr = strings.TrimPrefix(p, "regex:")
+		case strings.ContainsAny(p, "*?"):
+			expr = regexp.QuoteMeta(p)
+			expr = strings.ReplaceAll(expr, `\*`, ".*")
+			expr = strings.ReplaceAll(expr, `\?`, ".")
+			expr = "(?i)^" + expr + "$"
+		default:
+			expr = "(?i)" + regexp.QuoteMeta(p)
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("Denylist: invalid pattern %s: %s", p, err)
+		}
+		d.patterns = append(d.patterns, re)
+	}
+	return d, nil
+}
+
+//Denied checks if the value matches any of the denylist patterns
+func (d *Denylist) Denied(value []byte) bool {
+	for _, re := range d.patterns {
+		if re.Match(value) {
+			return true
+		}
+	}
+	return false
+}
+
+//DeniedInput checks if any of the keyword values matches the denylist
+func (d *Denylist) DeniedInput(input map[string][]byte) bool {
+	for _, v := range input {
+		if d.Denied(v) {
+			return true
+		}
+	}
+	return false
+}
+
+//Len returns the number of patterns in the denylist
+func (d *Denylist) Len() int {
+	return len(d.patterns)
+}
+
+//readDenylistFile reads the denylist patterns from a file, one per line
+func readDenylistFile(filename string) ([]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Denylist: could not read %s: %s", filename, err)
+	}
+	defer f.Close()
+	patterns := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			patterns = append(patterns, line)
+		}
+	}
+	return patterns, scanner.Err()
+}
@@ -0,0 +1,92 @@
+package ffuf
+
+import (
+	"fmt"
+	"sort"
+)
+
+//BODY_ALL is the body need of the matchers and filters needing the whole response body
+const BODY_ALL = -1
+
+//filterBodyNeeds returns the number of bytes of the response body the filter needs. The filters not implementing
+//BodyNeeds need the whole body.
+func filterBodyNeeds(f FilterProvider) int64 {
+	if bn, ok := f.(BodyNeeds); ok {
+		return bn.BodyNeeds()
+	}
+	return BODY_ALL
+}
+
+//bodyNeeds negotiates the part of the response bodies to download (-fast-size): it returns the number of bytes from
+//the start of the bodies the matchers, the filters and the other options need, and what needs them. It is 0 if the
+//status, the headers and the Content-Length of the responses are enough, and BODY_ALL for the whole bodies.
+func (j *Job) bodyNeeds() (int64, string) {
+	needs, reason := int64(0), ""
+	for _, set := range []struct {
+		kind    string
+		filters map[string]FilterProvider
+	}{{"matcher", j.Config.Matchers}, {"filter", j.Config.Filters}, {"replay matcher", j.Config.ReplayMatchers}} {
+		names := make([]string, 0, len(set.filters))
+		for name := range set.filters {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			n := filterBodyNeeds(set.filters[name])
+			if n == BODY_ALL {
+				return BODY_ALL, fmt.Sprintf("the %s %s", name, set.kind)
+			}
+			if n > needs {
+				needs, reason = n, fmt.Sprintf("the %s %s", name, set.kind)
+			}
+		}
+	}
+	if other := j.bodyNeededBy(); other != "" {
+		return BODY_ALL, other
+	}
+	return needs, reason
+}
+
+//bodyNeededBy returns the option other than the matchers and filters that needs the whole response bodies, if any
+func (j *Job) bodyNeededBy() string {
+	switch {
+	case j.Config.AutoCalibration:
+		return "the calibration (-ac)"
+	case j.Config.OutputDirectory != "":
+		return "the output directory (-od)"
+	case j.Scraper != nil:
+		return "the scrapers"
+	case j.Config.Crawl:
+		return "crawling (-crawl)"
+	case j.Config.RecursionLinks:
+		return "the link recursion (-recursion-links)"
+	case len(j.Config.ResponseMiddleware) > 0:
+		return "the response middleware"
+	}
+	for _, p := range j.Config.PostProcess {
+		if p == POSTPROCESS_DEDUP_BODY {
+			return "the body deduplication (-postprocess dedup-body)"
+		}
+	}
+	return ""
+}
+
+//setupFastSize skips downloading the response bodies that have a Content-Length if nothing needs them, or reads only
+//the start of them the matchers and filters need
+func (j *Job) setupFastSize() {
+	if !j.Config.FastSize || j.Config.IgnoreBody {
+		return
+	}
+	needs, reason := j.bodyNeeds()
+	switch needs {
+	case 0:
+		j.Config.IgnoreBody = true
+	case BODY_ALL:
+		j.Output.Warning(fmt.Sprintf("The response bodies are downloaded as %s needs them, -fast-size has no effect", reason))
+	default:
+		j.Config.BodyLimit = needs
+		if !j.Config.Quiet {
+			j.Output.Info(fmt.Sprintf("Reading only the first %d bytes of the response bodies, as needed by %s", needs, reason))
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package ffuf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//FilterStat is the number of responses a single matcher or filter was evaluated for, and its cumulative time
+type FilterStat struct {
+	Kind      string        `json:"kind"` // matcher, filter or calibration
+	Name      string        `json:"name"`
+	Value     string        `json:"value"`
+	Evaluated int           `json:"evaluated"`
+	Accepted  int           `json:"accepted"`
+	Rejected  int           `json:"rejected"`
+	Duration  time.Duration `json:"duration"` // nanoseconds
+}
+
+//FilterStats keeps count of the responses accepted and rejected by each matcher and filter. Matchers accept the
+//responses they match, filters accept the responses they let through.
+type FilterStats struct {
+	mutex sync.Mutex
+	stats map[string]*FilterStat
+}
+
+func NewFilterStats() *FilterStats {
+	return &FilterStats{stats: make(map[string]*FilterStat)}
+}
+
+//Add counts a single evaluation of a matcher or filter
+func (f *FilterStats) Add(kind, name string, filter FilterProvider, accepted bool, duration time.Duration) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	key := kind + ":" + name
+	s, ok := f.stats[key]
+	if !ok {
+		s = &FilterStat{Kind: kind, Name: name}
+		f.stats[key] = s
+	}
+	// The value may change from the interactive console during the run, the latest one is shown
+	s.Value = filter.Repr()
+	s.Evaluated++
+	if accepted {
+		s.Accepted++
+	} else {
+		s.Rejected++
+	}
+	s.Duration += duration
+}
+
+//Stats returns the statistics of the matchers and filters, the most time consuming first
+func (f *FilterStats) Stats() []FilterStat {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	stats := make([]FilterStat, 0, len(f.stats))
+	for _, s := range f.stats {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Duration == stats[j].Duration {
+			return stats[i].Kind+stats[i].Name < stats[j].Kind+stats[j].Name
+		}
+		return stats[i].Duration > stats[j].Duration
+	})
+	return stats
+}
+
+//Report returns the statistics of the matchers and filters as a printable table
+func (f *FilterStats) Report() string {
+	var b strings.Builder
+	b.WriteString("Matcher and filter statistics:\n")
+	for _, s := range f.Stats() {
+		avg := time.Duration(0)
+		if s.Evaluated > 0 {
+			avg = s.Duration / time.Duration(s.Evaluated)
+		}
+		fmt.Fprintf(&b, " :: %-11s %-10s: %6d evaluated, %6d accepted, %6d rejected, %s total (%s avg) :: %s\n",
+			s.Kind, s.Name, s.Evaluated, s.Accepted, s.Rejected, s.Duration.Round(time.Microsecond), avg, s.Value)
+	}
+	return b.String()
+}
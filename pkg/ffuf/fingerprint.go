@@ -0,0 +1,131 @@
+package ffuf
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+//RECURSION_FINGERPRINT_BUCKET is the size of the length buckets the not-found fingerprints compare the response
+//lengths with, so that the soft-404 pages reflecting the requested path still match
+const RECURSION_FINGERPRINT_BUCKET = 64
+
+//notFoundFingerprint identifies the response of a directory for nonexistent resources
+type notFoundFingerprint struct {
+	StatusCode int64
+	Length     int64
+	BodyHash   uint64
+}
+
+//DirectoryFingerprint filters the responses matching the not-found fingerprints of the directory of a recursion job
+//(-recursion-fingerprint): the same status, and either the same body or a length in the same bucket
+type DirectoryFingerprint struct {
+	Url          string
+	fingerprints []notFoundFingerprint
+}
+
+func (d *DirectoryFingerprint) Filter(response *Response) (bool, error) {
+	hash := bodyHash(response)
+	for _, fp := range d.fingerprints {
+		if response.StatusCode != fp.StatusCode {
+			continue
+		}
+		if hash != 0 && hash == fp.BodyHash {
+			return true, nil
+		}
+		if response.ContentLength/RECURSION_FINGERPRINT_BUCKET == fp.Length/RECURSION_FINGERPRINT_BUCKET {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (d *DirectoryFingerprint) Repr() string {
+	descs := make([]string, 0, len(d.fingerprints))
+	for _, fp := range d.fingerprints {
+		bucket := fp.Length / RECURSION_FINGERPRINT_BUCKET * RECURSION_FINGERPRINT_BUCKET
+		descs = append(descs, fmt.Sprintf("status %d, size %d-%d", fp.StatusCode, bucket, bucket+RECURSION_FINGERPRINT_BUCKET-1))
+	}
+	sort.Strings(descs)
+	return strings.Join(descs, "; ")
+}
+
+func (d *DirectoryFingerprint) ReprVerbose() string {
+	return fmt.Sprintf("Not-found fingerprint of %s: %s", d.Url, d.Repr())
+}
+
+//bodyHash returns the hash of the response body, or 0 when the body was not read
+func bodyHash(response *Response) uint64 {
+	body := response.Body()
+	if len(body) == 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write(body)
+	return h.Sum64()
+}
+
+//fingerprintDirectory probes random paths under the directory of the current recursion job, and sets up the filter
+//for the responses matching their not-found fingerprints. Only the probe responses that would be matched otherwise
+//are fingerprinted.
+func (j *Job) fingerprintDirectory() {
+	fingerprint := &DirectoryFingerprint{Url: j.Config.Url, fingerprints: make([]notFoundFingerprint, 0)}
+	keywords := j.Config.InputKeywords()
+	for i := 0; i < j.Config.RecursionFingerprint; i++ {
+		inputs := make(map[string][]byte, len(keywords))
+		for _, k := range keywords {
+			inputs[k] = []byte(RandomString(16))
+		}
+		req, err := j.Runner.Prepare(inputs)
+		if err != nil {
+			j.incError(ERROR_PREPARE)
+			continue
+		}
+		resp, err := j.Runner.Execute(&req)
+		if err != nil {
+			j.Output.Warning(fmt.Sprintf("Not-found fingerprint probe for %s failed: %s", j.Config.Url, err))
+			continue
+		}
+		if j.matchResponse(resp, false) {
+			fp := notFoundFingerprint{StatusCode: resp.StatusCode, Length: resp.ContentLength, BodyHash: bodyHash(&resp)}
+			if !fingerprintKnown(fingerprint.fingerprints, fp) {
+				fingerprint.fingerprints = append(fingerprint.fingerprints, fp)
+			}
+		}
+		log.Printf("Not-found fingerprint probe %s: status %d, size %d", req.Url, resp.StatusCode, resp.ContentLength)
+		resp.MakeFreeMemory()
+	}
+	if len(fingerprint.fingerprints) == 0 {
+		return
+	}
+	j.dirFingerprint = fingerprint
+	if !j.Config.Quiet {
+		j.Output.Info(fingerprint.ReprVerbose())
+	}
+}
+
+//fingerprintKnown checks if a fingerprint with the same status and length bucket was taken already
+func fingerprintKnown(fingerprints []notFoundFingerprint, fp notFoundFingerprint) bool {
+	for _, f := range fingerprints {
+		if f.StatusCode == fp.StatusCode && f.Length/RECURSION_FINGERPRINT_BUCKET == fp.Length/RECURSION_FINGERPRINT_BUCKET {
+			return true
+		}
+	}
+	return false
+}
+
+//fingerprintFiltered checks if the response is filtered by the not-found fingerprints of the current recursion job
+func (j *Job) fingerprintFiltered(resp *Response) bool {
+	if j.dirFingerprint == nil {
+		return false
+	}
+	start := time.Now()
+	fv, _ := j.dirFingerprint.Filter(resp)
+	if j.filterStats != nil {
+		j.filterStats.Add("fingerprint", "recursion", j.dirFingerprint, !fv, time.Since(start))
+	}
+	return fv
+}
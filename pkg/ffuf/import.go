@@ -0,0 +1,82 @@
+package ffuf
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+//processImportedResults replays the results of an earlier scan (-import) through the replay proxy, and adds recursion
+//jobs for their directories under the target URL, as if they had been matched by the scan
+func (j *Job) processImportedResults() {
+	replayed, skipped := 0, 0
+	keywords := j.Config.InputKeywords()
+	for _, r := range j.Config.ImportedResults {
+		if j.Config.Recursion {
+			j.seedRecursion(r)
+		}
+		if j.ReplayRunner == nil {
+			continue
+		}
+		if !hasInputs(r.Input, keywords) {
+			// Imported from a scan with other keywords
+			skipped++
+			continue
+		}
+		req, err := j.ReplayRunner.Prepare(r.Input)
+		if err != nil {
+			j.incError(ERROR_PREPARE)
+			log.Printf("%s", err)
+			continue
+		}
+		req.Position = r.Position
+		req.Headers[SCAN_ID_HEADER] = j.Config.ScanID
+		resp, err := j.ReplayRunner.Execute(&req)
+		if err != nil {
+			resp.MakeFreeMemory()
+			log.Printf("Replaying the imported result %s failed: %s", r.Url, err)
+			continue
+		}
+		replayed++
+		if len(j.Config.ReplayDir) > 0 {
+			j.recordReplay(resp)
+		}
+		resp.MakeFreeMemory()
+	}
+	if j.ReplayRunner != nil {
+		j.Output.Info(fmt.Sprintf("Replayed %d imported results", replayed))
+	}
+	if skipped > 0 {
+		j.Output.Warning(fmt.Sprintf("%d imported results were not replayed for missing inputs of the keywords %s", skipped, strings.Join(keywords, ", ")))
+	}
+}
+
+//seedRecursion adds a recursion job for the directory of an imported result under the target URL. With the default
+//strategy only the results redirecting to the directory are recursed into, like the matched ones.
+func (j *Job) seedRecursion(r Result) {
+	base := strings.TrimSuffix(j.Config.Url, "FUZZ")
+	if !strings.HasSuffix(base, "/") || !strings.HasPrefix(r.Url, base) || r.Url == base {
+		return
+	}
+	if j.Config.RecursionStrategy != "greedy" {
+		resp := Response{StatusCode: r.StatusCode, Headers: map[string][]string{"Location": {r.RedirectLocation}}, Request: &Request{Url: r.Url}}
+		if resp.GetRedirectLocation(true) != r.Url+"/" {
+			return
+		}
+	}
+	depth := strings.Count(strings.TrimSuffix(strings.TrimPrefix(r.Url, base), "/"), "/") + 1
+	if j.Config.RecursionDepth > 0 && depth > j.Config.RecursionDepth {
+		return
+	}
+	j.addRecursionJob(strings.TrimSuffix(r.Url, "/")+"/FUZZ", depth)
+}
+
+//hasInputs checks if the inputs have a value for every keyword
+func hasInputs(inputs map[string][]byte, keywords []string) bool {
+	for _, k := range keywords {
+		if _, ok := inputs[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
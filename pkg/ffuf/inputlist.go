@@ -0,0 +1,41 @@
+package ffuf
+
+import (
+	"fmt"
+	"strings"
+)
+
+//LIST_PREFIX marks a wordlist (-w) value listing the inputs inline instead of reading a file
+const LIST_PREFIX = "list:"
+
+//ParseInputList parses an inline list wordlist value list:VALUE,VALUE...[:KEYWORD], e.g. "list:admin,root:USER". The
+//keyword defaults to FUZZ.
+func ParseInputList(value string) ([]string, string, error) {
+	keyword := "FUZZ"
+	list := strings.TrimPrefix(value, LIST_PREFIX)
+	if i := strings.LastIndex(list, ":"); i >= 0 && rangeKeywordRegexp.MatchString(list[i+1:]) {
+		keyword = list[i+1:]
+		list = list[:i]
+	}
+	if len(list) == 0 {
+		return []string{}, keyword, fmt.Errorf("Inline list %s has no values, expected list:VALUE,VALUE...[:KEYWORD]", value)
+	}
+	return strings.Split(list, ","), keyword, nil
+}
+
+//InlineWordlist checks if a comma separated wordlist (-w) value is an inline list of inputs without the list: prefix,
+//eg. "admin,administrator,root:USER". It is when none of its parts is a wordlist file, stdin or a generated input.
+func InlineWordlist(value string) bool {
+	if !strings.Contains(value, ",") || strings.HasPrefix(value, LIST_PREFIX) {
+		return false
+	}
+	for _, part := range strings.Split(value, ",") {
+		if part == "-" || strings.HasPrefix(part, RANGE_PREFIX) || FileExists(part) {
+			return false
+		}
+		if i := strings.LastIndex(part, ":"); i >= 0 && FileExists(part[:i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,112 @@
+package ffuf
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//RANGE_PREFIX marks a wordlist (-w) value generating a numeric or date sequence instead of reading a file
+const RANGE_PREFIX = "range:"
+
+//RANGE_DATE_LAYOUT is the layout of the dates in a date range, and the default output format of the dates
+const RANGE_DATE_LAYOUT = "2006-01-02"
+
+var (
+	rangeNumberRegexp  = regexp.MustCompile(`^(-?\d+)(?:-|\.\.)(-?\d+)$`)
+	rangeDateRegexp    = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})\.\.(\d{4}-\d{2}-\d{2})$`)
+	rangeStepRegexp    = regexp.MustCompile(`^\d+d?$`)
+	rangeKeywordRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+//InputRange is a numeric or date sequence generated as the input of a keyword. The sequence is iterated in reverse
+//when the start is greater than the end. Dates are stored as days since the Unix epoch.
+type InputRange struct {
+	Start  int64
+	End    int64
+	Step   int64
+	Date   bool
+	Format string
+}
+
+//ParseInputRange parses a range wordlist value range:START-END[:STEP][:FORMAT][:KEYWORD], e.g. "range:0-9999:%04d" or
+//"range:2023-01-01..2023-12-31:7d:20060102:DATE". The numeric output format is a printf verb, the date output format
+//a Go time layout. The step of a date range has a d suffix. The keyword defaults to FUZZ.
+func ParseInputRange(value string) (InputRange, string, error) {
+	r := InputRange{Step: 1}
+	keyword := "FUZZ"
+	parts := strings.Split(strings.TrimPrefix(value, RANGE_PREFIX), ":")
+	if m := rangeDateRegexp.FindStringSubmatch(parts[0]); m != nil {
+		start, err := time.Parse(RANGE_DATE_LAYOUT, m[1])
+		if err != nil {
+			return r, keyword, fmt.Errorf("Invalid range start date: %s", m[1])
+		}
+		end, err := time.Parse(RANGE_DATE_LAYOUT, m[2])
+		if err != nil {
+			return r, keyword, fmt.Errorf("Invalid range end date: %s", m[2])
+		}
+		r.Date = true
+		r.Start = start.Unix() / 86400
+		r.End = end.Unix() / 86400
+		r.Format = RANGE_DATE_LAYOUT
+	} else if m := rangeNumberRegexp.FindStringSubmatch(parts[0]); m != nil {
+		r.Start, _ = strconv.ParseInt(m[1], 10, 64)
+		r.End, _ = strconv.ParseInt(m[2], 10, 64)
+		r.Format = "%d"
+	} else {
+		return r, keyword, fmt.Errorf("Invalid range %s, expected START-END or YYYY-MM-DD..YYYY-MM-DD", parts[0])
+	}
+	parts = parts[1:]
+	if len(parts) > 0 && rangeStepRegexp.MatchString(parts[0]) && r.Date == strings.HasSuffix(parts[0], "d") {
+		r.Step, _ = strconv.ParseInt(strings.TrimSuffix(parts[0], "d"), 10, 64)
+		if r.Step < 1 {
+			return r, keyword, fmt.Errorf("Range step has to be at least 1")
+		}
+		parts = parts[1:]
+	}
+	if len(parts) > 0 && rangeKeywordRegexp.MatchString(parts[len(parts)-1]) && (len(parts) == 2 || !r.isFormat(parts[0])) {
+		keyword = parts[len(parts)-1]
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) > 1 {
+		return r, keyword, fmt.Errorf("Invalid range %s, expected range:START-END[:STEP][:FORMAT][:KEYWORD]", value)
+	}
+	if len(parts) == 1 {
+		if !r.isFormat(parts[0]) {
+			return r, keyword, fmt.Errorf("Invalid range output format: %s", parts[0])
+		}
+		r.Format = parts[0]
+	}
+	return r, keyword, nil
+}
+
+//isFormat checks if the string is a valid output format for the range values
+func (r InputRange) isFormat(format string) bool {
+	if r.Date {
+		return format != "" && !rangeKeywordRegexp.MatchString(format)
+	}
+	return strings.Count(format, "%")-2*strings.Count(format, "%%") == 1 && !strings.Contains(fmt.Sprintf(format, 0), "%!")
+}
+
+//Total returns the number of values in the range
+func (r InputRange) Total() int {
+	diff := r.End - r.Start
+	if diff < 0 {
+		diff = -diff
+	}
+	return int(diff/r.Step) + 1
+}
+
+//Value returns the formatted value at the given position of the range
+func (r InputRange) Value(position int) []byte {
+	offset := int64(position) * r.Step
+	if r.Start > r.End {
+		offset = -offset
+	}
+	if r.Date {
+		return []byte(time.Unix((r.Start+offset)*86400, 0).UTC().Format(r.Format))
+	}
+	return []byte(fmt.Sprintf(r.Format, r.Start+offset))
+}
@@ -9,12 +9,31 @@ type FilterProvider interface {
 	ReprVerbose() string
 }
 
+//BodyNeeds is implemented by the matchers and filters that need only the start of the response body, or none of it,
+//so that the rest of the body is not downloaded with -fast-size. The ones not implementing it get the whole body.
+type BodyNeeds interface {
+	//BodyNeeds returns the number of bytes from the start of the body needed, 0 for none or BODY_ALL for the whole body
+	BodyNeeds() int64
+}
+
 //RunnerProvider is an interface for request executors
 type RunnerProvider interface {
 	Prepare(input map[string][]byte) (Request, error)
 	Execute(req *Request) (Response, error)
 }
 
+//RequestMiddleware is run by the runner on every request right before it is sent, for signing or changing it. An
+//error cancels the request, and is reported as a request error.
+type RequestMiddleware func(req *Request) error
+
+//ResponseMiddleware is run by the runner on every response once received, before the matchers and filters see it
+type ResponseMiddleware func(resp *Response)
+
+//ResultProcessor is run on every matched response before it is output, after the built-in processors of -postprocess
+//and the ones registered before it. It may change the response, such as tagging it, and returns false to drop the
+//result.
+type ResultProcessor func(resp *Response) bool
+
 //InputProvider interface handles the input data for RunnerProvider
 type InputProvider interface {
 	AddProvider(InputProviderConfig) error
@@ -23,6 +42,8 @@ type InputProvider interface {
 	Reset()
 	Value() map[string][]byte
 	Total() int
+	Skipped() int
+	ReplaceProviders([]InputProviderConfig) error
 }
 
 //InternalInputProvider interface handles providing input data to InputProvider
@@ -36,6 +57,16 @@ type InternalInputProvider interface {
 	Total() int
 }
 
+//ScraperProvider extracts data from the matched responses
+type ScraperProvider interface {
+	Execute(resp *Response) map[string][]string
+}
+
+//CalibratorProvider creates the filters for the responses of the autocalibration requests
+type CalibratorProvider interface {
+	Filters(conf *Config, responses []Response) (map[string]FilterProvider, error)
+}
+
 //OutputProvider is responsible of providing output from the RunnerProvider
 type OutputProvider interface {
 	Banner()
@@ -48,24 +79,37 @@ type OutputProvider interface {
 	Result(resp Response)
 	PrintResult(res Result)
 	SaveFile(filename, format string) error
+	SetOutputFile(filename string) error
 	GetCurrentResults() []Result
 	SetCurrentResults(results []Result)
+	SetSummary(summary Summary)
 	Reset()
 	Cycle()
 }
 
 type Result struct {
-	Input            map[string][]byte `json:"input"`
-	Position         int               `json:"position"`
-	StatusCode       int64             `json:"status"`
-	ContentLength    int64             `json:"length"`
-	ContentWords     int64             `json:"words"`
-	ContentLines     int64             `json:"lines"`
-	ContentType      string            `json:"content-type"`
-	RedirectLocation string            `json:"redirectlocation"`
-	Url              string            `json:"url"`
-	Duration         time.Duration     `json:"duration"`
-	ResultFile       string            `json:"resultfile"`
-	Host             string            `json:"host"`
-	HTMLColor        string            `json:"-"`
+	Input            map[string][]byte   `json:"input"`
+	Position         int                 `json:"position"`
+	StatusCode       int64               `json:"status"`
+	ContentLength    int64               `json:"length"`
+	ContentWords     int64               `json:"words"`
+	ContentLines     int64               `json:"lines"`
+	ContentType      string              `json:"content-type"`
+	RedirectLocation string              `json:"redirectlocation"`
+	RedirectScheme   string              `json:"redirectscheme"`
+	Url              string              `json:"url"`
+	Duration         time.Duration       `json:"duration"`
+	ResultFile       string              `json:"resultfile"`
+	Host             string              `json:"host"`
+	Timestamp        time.Time           `json:"timestamp"`
+	ScraperData      map[string][]string `json:"scraper"`
+	Tags             []string            `json:"tags,omitempty"`
+	HTMLColor        string              `json:"-"`
 }
+
+//TerminalColumns are the result columns printed to the terminal, selectable with -columns
+var TerminalColumns = []string{"input", "url", "status", "size", "words", "lines", "duration", "content-type", "redirect"}
+
+//JSONFields are the result fields of the json output file, in the order they are written in, selectable with
+//-json-fields
+var JSONFields = []string{"input", "position", "status", "length", "words", "lines", "content-type", "redirectlocation", "redirectscheme", "duration", "resultfile", "url", "host", "scraper", "tags", "timestamp"}
@@ -1,11 +1,14 @@
 package ffuf
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
+	"net/url"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -19,6 +22,9 @@ type Job struct {
 	Runner               RunnerProvider
 	ReplayRunner         RunnerProvider
 	Output               OutputProvider
+	Notifier             *Notifier
+	Scraper              ScraperProvider
+	Calibrator           CalibratorProvider
 	Counter              int
 	ErrorCounter         int
 	SpuriousErrorCounter int
@@ -28,25 +34,83 @@ type Job struct {
 	Paused               bool
 	Count403             int
 	Count429             int
+	Count5xx             int
+	CountTimeout         int
 	Error                string
 	Rate                 *RateThrottle
+	// HandleSignals installs the handlers of SIGINT, SIGTERM and the snapshot signal. Set by the ffuf command, while
+	// applications embedding ffuf handle the signals themselves and stop the job through Run or Stop.
+	HandleSignals        bool
 	startTime            time.Time
 	startTimeJob         time.Time
 	queuejobs            []QueueJob
 	queuepos             int
+	queueMutex           sync.Mutex
 	skipQueue            bool
+	skipRecursion        bool
 	currentDepth         int
+	baseHeaders          map[string]string
+	baseInputProviders   []InputProviderConfig
+	statusMatrix         *StatusMatrix
+	responseStats        *ResponseStats
+	filterStats          *FilterStats
+	robotsLimiter        *GlobalLimiter
+	stopRules            *StopRules
+	quarantine           *HostQuarantine
+	coverage             *Coverage
+	pinned               map[string]bool
+	requestCache         *RequestCache
+	resultProcessors     []ResultProcessor
+	recursionChildren    map[string]int
+	recursionDepths      map[int]int
+	recursionOverflow    int
+	inputLimit           int
+	recursionQueued      map[string]bool
+	deniedInputs         int
+	requestCount         int
+	matchCount           int
+	replayCount          int
+	outputFailing        bool
+	jobsProcessed        int
+	errorClasses         map[string]int
+	stopReason           string
+	inputDone            bool
+	calibrationFilters   map[string]map[string]FilterProvider
+	calibrationReports   map[string]CalibrationReport
+	calibrationMutex     sync.RWMutex
+	calibrateMutex       sync.Mutex
+	dirFingerprint       *DirectoryFingerprint
+	crawlWg              sync.WaitGroup
 	pauseWg              sync.WaitGroup
+	pauseMutex           sync.Mutex
+	finished             chan struct{}
+	// Closed when the job stops, for the background crawl not reading Running
+	stopped              chan struct{}
+	stopOnce             sync.Once
+	shutdown             chan struct{}
+	shutdownOnce         sync.Once
+	limiter              chan bool
+	limiterMutex         sync.Mutex
+	overridden           bool
+	baseThreads          int
+	baseDelay            optRange
 }
 
 type QueueJob struct {
-	Url   string
-	depth int
+	Url            string
+	Overrides      *QueueJobOverrides
+	depth          int
+	noRecursion    bool
+	template       *ApiOperation
+	inputProviders []InputProviderConfig
 }
 
 func NewJob(conf *Config) *Job {
 	var j Job
 	j.Config = conf
+	if conf.ScanID == "" {
+		conf.ScanID = NewScanID()
+	}
 	j.Counter = 0
 	j.ErrorCounter = 0
 	j.SpuriousErrorCounter = 0
@@ -58,15 +122,66 @@ func NewJob(conf *Config) *Job {
 	j.currentDepth = 0
 	j.Rate = NewRateThrottle(conf)
 	j.skipQueue = false
+	j.recursionChildren = make(map[string]int)
+	j.recursionDepths = make(map[int]int)
+	j.recursionQueued = make(map[string]bool)
+	j.errorClasses = make(map[string]int)
+	j.calibrationFilters = make(map[string]map[string]FilterProvider)
+	j.calibrationReports = make(map[string]CalibrationReport)
+	j.stopRules = NewStopRules(append(builtinStopRules(conf), conf.StopRules...))
+	j.responseStats = NewResponseStats()
+	j.finished = make(chan struct{})
+	j.stopped = make(chan struct{})
+	j.shutdown = make(chan struct{})
+	// The processor names are validated with the options
+	j.resultProcessors, _ = newResultProcessors(conf.PostProcess)
+	if conf.HostErrors > 0 {
+		j.quarantine = NewHostQuarantine(conf.HostErrors)
+	}
+	if conf.StatusMatrix {
+		j.statusMatrix = NewStatusMatrix()
+	}
+	if conf.FilterStats || conf.SummaryJSON {
+		j.filterStats = NewFilterStats()
+	}
+	j.pinned = make(map[string]bool, len(conf.PinnedInputs))
+	for _, p := range conf.PinnedInputs {
+		j.pinned[p] = true
+	}
+	if conf.DedupRequests {
+		j.requestCache = NewRequestCache()
+	}
+	if conf.WordlistStats || conf.SummaryJSON {
+		j.coverage = NewCoverage()
+	}
 	return &j
 }
 
-//incError increments the error counter
-func (j *Job) incError() {
+//incError increments the error counter and the counter of the error class
+func (j *Job) incError(class string) {
 	j.ErrorMutex.Lock()
 	defer j.ErrorMutex.Unlock()
 	j.ErrorCounter++
 	j.SpuriousErrorCounter++
+	j.errorClasses[class]++
+}
+
+//errorClassCounts returns a copy of the error counts by class
+func (j *Job) errorClassCounts() map[string]int {
+	j.ErrorMutex.Lock()
+	defer j.ErrorMutex.Unlock()
+	counts := make(map[string]int, len(j.errorClasses))
+	for k, v := range j.errorClasses {
+		counts[k] = v
+	}
+	return counts
+}
+
+//incMatch increments the matched response counter
+func (j *Job) incMatch() {
+	j.ErrorMutex.Lock()
+	defer j.ErrorMutex.Unlock()
+	j.matchCount++
 }
 
 //inc403 increments the 403 response counter
@@ -83,6 +198,27 @@ func (j *Job) inc429() {
 	j.Count429++
 }
 
+//inc5xx increments the 5xx response counter
+func (j *Job) inc5xx() {
+	j.ErrorMutex.Lock()
+	defer j.ErrorMutex.Unlock()
+	j.Count5xx++
+}
+
+//incTimeout increments the timed out request counter
+func (j *Job) incTimeout() {
+	j.ErrorMutex.Lock()
+	defer j.ErrorMutex.Unlock()
+	j.CountTimeout++
+}
+
+//congestion returns the number of responses and timeouts telling that the target is overloaded, for the adaptive rate
+func (j *Job) congestion() int {
+	j.ErrorMutex.Lock()
+	defer j.ErrorMutex.Unlock()
+	return j.Count429 + j.Count5xx + j.CountTimeout
+}
+
 //resetSpuriousErrors resets the spurious error counter
 func (j *Job) resetSpuriousErrors() {
 	j.ErrorMutex.Lock()
@@ -92,25 +228,52 @@ func (j *Job) resetSpuriousErrors() {
 
 //DeleteQueueItem deletes a recursion job from the queue by its index in the slice
 func (j *Job) DeleteQueueItem(index int) {
+	j.queueMutex.Lock()
+	defer j.queueMutex.Unlock()
 	index = j.queuepos + index - 1
 	j.queuejobs = append(j.queuejobs[:index], j.queuejobs[index+1:]...)
 }
 
 //QueuedJobs returns the slice of queued recursive jobs
 func (j *Job) QueuedJobs() []QueueJob {
+	j.queueMutex.Lock()
+	defer j.queueMutex.Unlock()
 	return j.queuejobs[j.queuepos-1:]
 }
 
+//addQueueJob appends a new job to the end of the job queue
+func (j *Job) addQueueJob(job QueueJob) {
+	job.Overrides = j.queueOverridesFor(job.Url)
+	j.queueMutex.Lock()
+	defer j.queueMutex.Unlock()
+	j.queuejobs = append(j.queuejobs, job)
+}
+
 //Start the execution of the Job
 func (j *Job) Start() {
 	if j.startTime.IsZero() {
 		j.startTime = time.Now()
+		log.Printf("Starting scan %s: %s", j.Config.ScanID, j.Config.CommandLine)
 	}
 
-	// Add the default job to job queue
-	j.queuejobs = append(j.queuejobs, QueueJob{Url: j.Config.Url, depth: 0})
+	if len(j.Config.ApiOperations) > 0 {
+		// Add a job for every imported API operation
+		j.baseHeaders = make(map[string]string)
+		for k, v := range j.Config.Headers {
+			j.baseHeaders[k] = v
+		}
+		for i := range j.Config.ApiOperations {
+			op := &j.Config.ApiOperations[i]
+			j.queuejobs = append(j.queuejobs, QueueJob{Url: op.Url, depth: 0, noRecursion: true, template: op})
+		}
+	} else {
+		// Add the default job to job queue
+		j.queuejobs = append(j.queuejobs, QueueJob{Url: j.Config.Url, depth: 0})
+	}
+	j.baseInputProviders = j.Config.InputProviders
 	rand.Seed(time.Now().UnixNano())
 	j.Total = j.Input.Total()
+	defer close(j.finished)
 	defer j.Stop()
 
 	j.Running = true
@@ -119,19 +282,116 @@ func (j *Job) Start() {
 	if !j.Config.Quiet {
 		j.Output.Banner()
 	}
-	// Monitor for SIGTERM and do cleanup properly (writing the output files etc)
-	j.interruptMonitor()
-	for j.jobsInQueue() {
-		j.prepareQueueJob()
+	j.setupFastSize()
+	if j.Config.AutoCalibration && j.hasCalibration("") && !j.Config.Quiet {
+		j.Output.Info(fmt.Sprintf("Calibration filters: %s", j.calibrationRepr("")))
+	}
+	if j.Config.RobotsDelay {
+		if delay := j.robotsDelay(); delay > 0 {
+			j.robotsLimiter = NewIntervalLimiter(delay)
+			if !j.Config.Quiet {
+				j.Output.Info(fmt.Sprintf("Respecting the Crawl-delay of robots.txt: at most one request every %s", delay))
+			}
+		}
+	}
+	if j.Config.UpdateCheck {
+		// Runs in the background, the scan is never waiting for it
+		go j.checkUpdates()
+	}
+	if j.HandleSignals {
+		// Monitor for SIGTERM and do cleanup properly (writing the output files etc)
+		j.interruptMonitor()
+		j.snapshotMonitor()
+	}
+	if j.Config.ProxyPool != nil && j.Config.ProxyPool.OnChange == nil {
+		j.Config.ProxyPool.OnChange = func(msg string) { j.Output.Warning(msg) }
+	}
+	if len(j.Config.ImportedResults) > 0 {
+		j.processImportedResults()
+	}
+	if j.Config.Crawl {
+		// The limiter of the first queue job is shared with the crawl from the start
+		j.threadLimiter()
+		j.crawlWg.Add(1)
+		go j.crawl(j.crawlSnapshot())
+	}
+	for j.jobsInQueue() || j.waitForCrawl() {
+		j.checkShutdown()
+		if !j.Running {
+			// Stopped, or shutting down after the previous job
+			break
+		}
+		if err := j.prepareQueueJob(); err != nil {
+			j.Output.Error(err.Error())
+			continue
+		}
+		if j.Config.AutoCalibration && j.queuepos > 1 {
+			// The queued job may target a directory with different responses for nonexistent resources
+			if err := j.Calibrate(); err != nil {
+				j.Output.Error(fmt.Sprintf("Calibration for the queued job failed: %s", err))
+			} else {
+				j.Output.Info(fmt.Sprintf("Calibrated for %s: %s", j.Config.Url, j.calibrationRepr(j.calibrationKey(j.Config.Url))))
+			}
+		}
+		j.dirFingerprint = nil
+		if j.Config.RecursionFingerprint > 0 && j.currentDepth > 0 {
+			j.fingerprintDirectory()
+		}
 		j.Reset(true)
 		j.RunningJob = true
+		j.coverage.StartJob(j.Config.Url, j.Input.Total(), j.Config.InputProviders)
 		j.startExecution()
+		j.coverage.FinishJob(j.Input.Skipped())
+		j.resetQueueOverrides()
+		j.deniedInputs += j.Input.Skipped()
+		j.requestCount += j.Counter
+		j.jobsProcessed++
+		if j.Running {
+			j.notifyEvent(NotifyEvent{Event: NOTIFY_EVENT_JOB, Message: fmt.Sprintf("Finished job %d of %d on %s", j.queuepos, j.queueLen(), j.Config.Url), Requests: j.requestCount})
+		}
+	}
+	if j.stopReason != "" {
+		j.notifyEvent(NotifyEvent{Event: NOTIFY_EVENT_STOP, Message: fmt.Sprintf("Stopped (%s): %s", j.stopReason, strings.TrimSpace(j.Error)), Requests: j.requestCount})
 	}
 
+	if j.Config.Denylist != nil {
+		j.Output.Info(fmt.Sprintf("Denylist prevented %d requests from being sent", j.deniedInputs))
+	}
+	if j.requestCache != nil {
+		j.Output.Info(fmt.Sprintf("Skipped %d duplicate requests", j.requestCache.Skipped()))
+	}
+	if j.recursionOverflow > 0 {
+		j.Output.Warning(fmt.Sprintf("%d recursion jobs were not added to the queue due to the recursion breadth and job limits", j.recursionOverflow))
+	}
+	if j.statusMatrix != nil {
+		j.Output.Raw(j.statusMatrix.Report())
+	}
+	if j.filterStats != nil && j.Config.FilterStats {
+		j.Output.Raw(j.filterStats.Report())
+	}
+	if j.coverage != nil && j.Config.WordlistStats {
+		j.Output.Raw(j.coverage.Report())
+	}
+	if j.Notifier != nil {
+		j.Notifier.Flush()
+	}
+	if j.Config.Pcap != nil {
+		if err := j.Config.Pcap.Close(); err != nil {
+			j.Output.Error(fmt.Sprintf("Could not write the capture file: %s", err))
+		}
+	}
+	if j.Config.TLSKeyLog != nil {
+		j.Config.TLSKeyLog.Close()
+	}
+	j.Config.BodyStore.Close()
+	j.Output.SetSummary(j.Summary())
 	err := j.Output.Finalize()
 	if err != nil {
 		j.Output.Error(err.Error())
 	}
+	if j.Config.SummaryJSON {
+		_ = WriteSummary(os.Stderr, j.Summary())
+	}
 }
 
 // Reset resets the counters and wordlist position for a job
@@ -139,6 +399,7 @@ func (j *Job) Reset(cycle bool) {
 	j.Input.Reset()
 	j.Counter = 0
 	j.skipQueue = false
+	j.stopRules.Reset()
 	j.startTimeJob = time.Now()
 	if cycle {
 		j.Output.Cycle()
@@ -148,13 +409,86 @@ func (j *Job) Reset(cycle bool) {
 }
 
 func (j *Job) jobsInQueue() bool {
+	j.queueMutex.Lock()
+	defer j.queueMutex.Unlock()
 	return j.queuepos < len(j.queuejobs)
 }
 
-func (j *Job) prepareQueueJob() {
+//waitForCrawl blocks until the background crawl has finished, and returns true if there are new jobs in the queue
+func (j *Job) waitForCrawl() bool {
+	j.crawlWg.Wait()
+	return j.Running && j.jobsInQueue()
+}
+
+func (j *Job) prepareQueueJob() error {
+	j.queueMutex.Lock()
+	defer j.queueMutex.Unlock()
+	defer func() { j.queuepos += 1 }()
 	j.Config.Url = j.queuejobs[j.queuepos].Url
 	j.currentDepth = j.queuejobs[j.queuepos].depth
-	j.queuepos += 1
+	j.skipRecursion = j.queuejobs[j.queuepos].noRecursion
+	if t := j.queuejobs[j.queuepos].template; t != nil {
+		j.Config.Method = t.Method
+		j.Config.Data = t.Data
+		// Headers of the previous template must not leak to this one
+		j.Config.Headers = make(map[string]string)
+		for k, v := range t.Headers {
+			j.Config.Headers[k] = v
+		}
+		if t.ContentType != "" {
+			j.Config.Headers["Content-Type"] = t.ContentType
+		}
+		for k, v := range j.baseHeaders {
+			j.Config.Headers[k] = v
+		}
+	}
+	providers := j.queuejobs[j.queuepos].inputProviders
+	if providers == nil {
+		providers = j.baseInputProviders
+	}
+	if !sameInputProviders(providers, j.Config.InputProviders) {
+		// The job uses different wordlists than the previous one
+		if err := j.Input.ReplaceProviders(providers); err != nil {
+			return fmt.Errorf("Could not set up the input for queued job %s: %s", j.Config.Url, err)
+		}
+		j.Config.InputProviders = providers
+	}
+	j.inputLimit = j.recursionInputLimit(j.currentDepth)
+	j.applyQueueOverrides(j.queuejobs[j.queuepos].Overrides)
+	j.Total = j.Input.Total()
+	if j.inputLimit > 0 && j.Total > j.inputLimit {
+		j.Total = j.inputLimit
+	}
+	return nil
+}
+
+//sameInputProviders checks if the two input provider configurations are equal
+func sameInputProviders(a, b []InputProviderConfig) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+//recursionInputProviders returns the input providers for recursion jobs, using the recursion wordlist for the FUZZ
+//keyword if one is set
+func (j *Job) recursionInputProviders() []InputProviderConfig {
+	if j.Config.RecursionWordlist == "" {
+		return nil
+	}
+	providers := make([]InputProviderConfig, 0, len(j.baseInputProviders))
+	for _, p := range j.baseInputProviders {
+		if p.Keyword == "FUZZ" {
+			p = InputProviderConfig{Name: "wordlist", Keyword: "FUZZ", Value: j.Config.RecursionWordlist}
+		}
+		providers = append(providers, p)
+	}
+	return providers
 }
 
 //SkipQueue allows to skip the current job and advance to the next queued recursion job
@@ -163,13 +497,18 @@ func (j *Job) SkipQueue() {
 }
 
 func (j *Job) sleepIfNeeded() {
+	j.sleepDelay(j.Config.Delay)
+}
+
+//sleepDelay sleeps for the delay between the requests of a thread (-p)
+func (j *Job) sleepDelay(delay optRange) {
 	var sleepDuration time.Duration
-	if j.Config.Delay.HasDelay {
-		if j.Config.Delay.IsRange {
-			sTime := j.Config.Delay.Min + rand.Float64()*(j.Config.Delay.Max-j.Config.Delay.Min)
+	if delay.HasDelay {
+		if delay.IsRange {
+			sTime := delay.Min + rand.Float64()*(delay.Max-delay.Min)
 			sleepDuration = time.Duration(sTime * 1000)
 		} else {
-			sleepDuration = time.Duration(j.Config.Delay.Min * 1000)
+			sleepDuration = time.Duration(delay.Min * 1000)
 		}
 		sleepDuration = sleepDuration * time.Millisecond
 	}
@@ -180,8 +519,45 @@ func (j *Job) sleepIfNeeded() {
 	}
 }
 
+//finalizeOutput writes the new results to the output file. When the writing starts failing, the job is paused until
+//the output file is switched to another one or the job is resumed, so that hours of results are not lost unnoticed.
+func (j *Job) finalizeOutput() {
+	err := j.Output.Finalize()
+	j.ErrorMutex.Lock()
+	failing := j.outputFailing
+	j.outputFailing = err != nil
+	j.ErrorMutex.Unlock()
+	if err == nil || failing {
+		return
+	}
+	if j.Config.Noninteractive {
+		j.Output.Error(fmt.Sprintf("%s. The results are kept, and writing them retried", err))
+		return
+	}
+	j.Output.Error(fmt.Sprintf("%s. Pausing the job: enter \"output [filename]\" to write the results to another file, or \"resume\" to retry", err))
+	j.Pause()
+}
+
+//SetOutputFile switches the output file (-o) to another path, writing all of the results so far to it. A job paused
+//for a failure to write the output file is resumed.
+func (j *Job) SetOutputFile(filename string) error {
+	if err := j.Output.SetOutputFile(filename); err != nil {
+		return err
+	}
+	j.ErrorMutex.Lock()
+	failing := j.outputFailing
+	j.outputFailing = false
+	j.ErrorMutex.Unlock()
+	if failing {
+		j.Resume()
+	}
+	return nil
+}
+
 // Pause pauses the job process
 func (j *Job) Pause() {
+	j.pauseMutex.Lock()
+	defer j.pauseMutex.Unlock()
 	if !j.Paused {
 		j.Paused = true
 		j.pauseWg.Add(1)
@@ -191,13 +567,24 @@ func (j *Job) Pause() {
 
 // Resume resumes the job process
 func (j *Job) Resume() {
-	if j.Paused {
-		j.Paused = false
+	if j.resume() {
 		j.Output.Info("------ RESUMING -----")
-		j.pauseWg.Done()
 	}
 }
 
+//resume resumes the job if paused, and returns true if it was. The job may be resumed by the user, a pause stop rule
+//and a stop of the job at the same time, only one of them releases the pause.
+func (j *Job) resume() bool {
+	j.pauseMutex.Lock()
+	defer j.pauseMutex.Unlock()
+	if !j.Paused {
+		return false
+	}
+	j.Paused = false
+	j.pauseWg.Done()
+	return true
+}
+
 func (j *Job) startExecution() {
 	var wg sync.WaitGroup
 	wg.Add(1)
@@ -205,13 +592,24 @@ func (j *Job) startExecution() {
 
 	// Print the base URL when starting a new recursion queue job
 	if j.queuepos > 1 {
-		j.Output.Info(fmt.Sprintf("Starting queued job on target: %s", j.Config.Url))
+		if len(j.Config.ApiOperations) > 0 {
+			j.Output.Info(fmt.Sprintf("Starting queued job on target: %s %s", j.Config.Method, j.Config.Url))
+		} else {
+			j.Output.Info(fmt.Sprintf("Starting queued job on target: %s", j.Config.Url))
+		}
 	}
 
 	//Limiter blocks after reaching the buffer, ensuring limited concurrency
-	limiter := make(chan bool, j.Config.Threads)
+	limiter := j.threadLimiter()
+	j.inputDone = false
+	milestones := make(map[int]bool)
+	j.runPinnedInputs(limiter, &wg)
 
 	for j.Input.Next() && !j.skipQueue {
+		if j.inputLimit > 0 && j.Counter >= j.inputLimit {
+			// The inputs of the recursion job are truncated for its depth
+			break
+		}
 		// Check if we should stop the process
 		j.CheckStop()
 
@@ -220,20 +618,25 @@ func (j *Job) startExecution() {
 			break
 		}
 		j.pauseWg.Wait()
+		if j.pinnedInput(j.Input.Value()) {
+			// Already sent with the pinned inputs
+			j.Counter++
+			continue
+		}
 		limiter <- true
 		nextInput := j.Input.Value()
 		nextPosition := j.Input.Position()
 		wg.Add(1)
-		//test with finalize
-		j.Output.Finalize()
+		j.finalizeOutput()
 
 		j.Counter++
+		j.notifyProgress(milestones, false)
 
 		go func() {
 			defer func() { <-limiter }()
 			defer wg.Done()
 			threadStart := time.Now()
-			j.runTask(nextInput, nextPosition, false)
+			j.runTask(nextInput, nextPosition, 0)
 			j.sleepIfNeeded()
 			j.Rate.Throttle()
 			threadEnd := time.Now()
@@ -245,8 +648,13 @@ func (j *Job) startExecution() {
 			return
 		}
 	}
+	// Lets the background tasks finish also when the total of a streamed input is not known
+	j.inputDone = true
 	wg.Wait()
 	j.updateProgress()
+	if j.Running && !j.skipQueue {
+		j.notifyProgress(milestones, true)
+	}
 }
 
 func (j *Job) interruptMonitor() {
@@ -254,57 +662,92 @@ func (j *Job) interruptMonitor() {
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		for range sigChan {
-			j.Error = "Caught keyboard interrupt (Ctrl-C)\n"
-			// resume if paused
-			if j.Paused {
-				j.pauseWg.Done()
-			}
-			// Stop the job
-			j.Stop()
+			j.interrupt("Caught keyboard interrupt (Ctrl-C)\n")
 		}
 	}()
 }
 
+//interrupt stops the job from the outside, resuming it first if paused
+func (j *Job) interrupt(reason string) {
+	j.Error = reason
+	j.stopReason = STOP_INTERRUPTED
+	j.resume()
+	// Stop the job
+	j.Stop()
+}
+
 func (j *Job) runBackgroundTasks(wg *sync.WaitGroup) {
 	defer wg.Done()
-	totalProgress := j.Input.Total()
-	for j.Counter <= totalProgress && !j.skipQueue {
+	for !j.skipQueue {
+		// The total changes from unknown (-1) to the final count once a streamed input has been read through
+		totalProgress := j.Input.Total()
+		if totalProgress >= 0 && j.Counter+j.Input.Skipped() > totalProgress {
+			break
+		}
 		j.pauseWg.Wait()
 		if !j.Running {
 			break
 		}
 		j.updateProgress()
-		if j.Counter == totalProgress {
+		if j.Counter+j.Input.Skipped() == totalProgress || j.inputDone {
 			return
 		}
 		if !j.RunningJob {
 			return
 		}
-		j.Rate.Adjust()
+		j.Rate.Adjust(j.congestion())
 		time.Sleep(time.Millisecond * time.Duration(j.Config.ProgressFrequency))
 	}
 }
 
 func (j *Job) updateProgress() {
+	total := j.Input.Total()
+	if j.inputLimit > 0 && total > j.inputLimit {
+		total = j.inputLimit
+	}
 	prog := Progress{
-		StartedAt:  j.startTimeJob,
-		ReqCount:   j.Counter,
-		ReqTotal:   j.Input.Total(),
-		ReqSec:     j.Rate.CurrentRate(),
-		QueuePos:   j.queuepos,
-		QueueTotal: len(j.queuejobs),
-		ErrorCount: j.ErrorCounter,
+		StartedAt:    j.startTimeJob,
+		ReqCount:     j.Counter,
+		ReqTotal:     total,
+		ReqSec:       j.Rate.CurrentRate(),
+		QueuePos:     j.queuepos,
+		QueueTotal:   j.queueLen(),
+		ErrorCount:   j.ErrorCounter,
+		ErrorClasses: j.errorClassCounts(),
 	}
 	j.Output.Progress(prog)
 }
 
+func (j *Job) queueLen() int {
+	j.queueMutex.Lock()
+	defer j.queueMutex.Unlock()
+	return len(j.queuejobs)
+}
+
 func (j *Job) isMatch(resp Response) bool {
+	if j.statusAction(resp) == STATUS_ACTION_MATCH {
+		return true
+	}
+	return j.matchResponse(resp, true)
+}
+
+//matchResponse runs the matchers and filters against the response, including the calibration filters if requested
+func (j *Job) matchResponse(resp Response, calibration bool) bool {
 	matched := false
-	for _, m := range j.Config.Matchers {
+	// Only the responses of the actual fuzzing are counted in the statistics
+	stats := j.filterStats
+	if !calibration {
+		stats = nil
+	}
+	for name, m := range j.Config.Matchers {
+		start := time.Now()
 		match, err := m.Filter(&resp)
 		if err != nil {
 			continue
 		}
+		if stats != nil {
+			stats.Add("matcher", name, m, match, time.Since(start))
+		}
 		if match {
 			matched = true
 		}
@@ -314,80 +757,208 @@ func (j *Job) isMatch(resp Response) bool {
 		resp.MakeFreeMemory()
 		return false
 	}
-	for _, f := range j.Config.Filters {
+	for name, f := range j.Config.Filters {
+		start := time.Now()
 		fv, err := f.Filter(&resp)
 		if err != nil {
 			continue
 		}
+		if stats != nil {
+			stats.Add("filter", name, f, !fv, time.Since(start))
+		}
 		if fv {
 			resp.MakeFreeMemory()
 			return false
 		}
 	}
-	resp.MakeFreeMemory()
+	if calibration && (j.calibrationFiltered(&resp) || j.fingerprintFiltered(&resp)) {
+		resp.MakeFreeMemory()
+		return false
+	}
+	// The body of a match is freed by the caller once done with it
 	return true
 }
 
-func (j *Job) runTask(input map[string][]byte, position int, retried bool) {
+func (j *Job) runTask(input map[string][]byte, position int, attempt int) {
 	req, err := j.Runner.Prepare(input)
 	req.Position = position
 	if err != nil {
 		j.Output.Error(fmt.Sprintf("Encountered an error while preparing request: %s\n", err))
-		j.incError()
+		j.incError(ERROR_PREPARE)
 		log.Printf("%s", err)
 		return
 	}
+	host := ""
+	if j.quarantine != nil {
+		host = quarantineHost(req.Url)
+		if j.quarantine.Skip(host) {
+			j.coverage.Quarantined()
+			return
+		}
+	}
+	if j.Config.AutoCalibrationPerHost {
+		j.calibrateHostIfNeeded(req.Url)
+	}
+	if !j.Config.SafeMethodAllowed(req.Method) {
+		// The method is set by an input keyword, and can only be checked here
+		j.Output.Error(fmt.Sprintf("Safe mode refused to send a %s request to %s\n", req.Method, req.Url))
+		j.incError(ERROR_SAFE)
+		return
+	}
+	cacheKey := ""
+	if j.requestCache != nil {
+		cacheKey = requestKey(&req)
+		if attempt == 0 {
+			cached, seen := j.requestCache.Seen(j.Config.Context, cacheKey)
+			if seen {
+				if cached != nil {
+					j.cachedResult(cached, req)
+				}
+				return
+			}
+			// The retries run within this attempt
+			defer j.requestCache.Finish(cacheKey)
+		}
+	}
+	if j.Config.GlobalLimiter != nil {
+		if err := j.Config.GlobalLimiter.Wait(j.Config.Context); err != nil {
+			// The job is stopping
+			return
+		}
+	}
+	if j.robotsLimiter != nil {
+		if err := j.robotsLimiter.Wait(j.Config.Context); err != nil {
+			return
+		}
+	}
 	resp, err := j.Runner.Execute(&req)
 	if err != nil {
-		if retried {
-			j.incError()
+		if j.Config.Context.Err() != nil {
+			// The request was aborted as the job is stopping
+			return
+		}
+		if errors.Is(err, ErrProxyUnavailable) {
+			// Every request would fail the same way, stop with the reason instead of piling up errors
+			j.Error = j.Config.ProxyPool.Error()
+			j.stopReason = STOP_PROXY
+			j.Stop()
+			return
+		}
+		if j.Config.RateAdaptive && errorClass(err) == ERROR_TIMEOUT {
+			j.incTimeout()
+		}
+		if attempt >= j.Config.Retries {
+			j.stopRules.Observe(0, errorClass(err))
+			j.coverage.Errored(input)
+			if j.requestCache != nil {
+				j.requestCache.Fail(cacheKey)
+			}
+			j.incError(errorClass(err))
 			log.Printf("%s", err)
-		} else {
-			j.runTask(input, position, true)
+			if j.quarantine != nil && j.quarantine.Fail(host, errorClass(err)) {
+				j.Output.Warning(fmt.Sprintf("Host %s failed %d requests in a row, skipping its remaining inputs", host, j.Config.HostErrors))
+			}
+		} else if j.waitRetry(j.retryDelay(attempt, nil)) {
+			j.runTask(input, position, attempt+1)
 		}
 		return
 	}
 	if j.SpuriousErrorCounter > 0 {
 		j.resetSpuriousErrors()
 	}
+	if j.quarantine != nil {
+		j.quarantine.Succeed(host)
+	}
 	if j.Config.StopOn403 || j.Config.StopOnAll {
 		// Increment Forbidden counter if we encountered one
 		if resp.StatusCode == 403 {
 			j.inc403()
 		}
 	}
-	if j.Config.StopOnAll {
+	if j.Config.StopOnAll || j.Config.RateAdaptive {
 		// increment 429 counter if the response code is 429
 		if resp.StatusCode == 429 {
 			j.inc429()
 		}
 	}
+	if j.Config.RateAdaptive && resp.StatusCode >= 500 && resp.StatusCode <= 599 {
+		j.inc5xx()
+	}
+	if attempt < j.Config.Retries && j.retryStatus(resp) {
+		// The target is overloaded or throttling, and the response would not tell anything about the input
+		delay := j.retryDelay(attempt, &resp)
+		resp.MakeFreeMemory()
+		if j.waitRetry(delay) {
+			j.runTask(input, position, attempt+1)
+		}
+		return
+	}
+	switch j.statusAction(resp) {
+	case STATUS_ACTION_IGNORE:
+		resp.MakeFreeMemory()
+		return
+	case STATUS_ACTION_ERROR:
+		j.stopRules.Observe(0, ERROR_STATUS)
+		j.coverage.Errored(input)
+		j.incError(ERROR_STATUS)
+		resp.MakeFreeMemory()
+		return
+	}
+	j.stopRules.Observe(resp.StatusCode, "")
+	j.responseStats.Observe(&resp)
+	if j.statusMatrix != nil {
+		j.statusMatrix.Add(req.Url, resp.StatusCode)
+	}
 	j.pauseWg.Wait()
-	if j.isMatch(resp) {
+	matched := j.isMatch(resp)
+	if matched && !j.processResult(&resp) {
+		matched = false
+		j.coverage.Deduplicated()
+	}
+	j.coverage.Sent(input, matched)
+	if matched {
+		j.incMatch()
 
 		// Re-send request through replay-proxy if needed
-		if j.ReplayRunner != nil {
+		if j.ReplayRunner != nil && j.replayMatch(resp) {
 			replayreq, err := j.ReplayRunner.Prepare(input)
 			replayreq.Position = position
 			if err != nil {
 				j.Output.Error(fmt.Sprintf("Encountered an error while preparing replayproxy request: %s\n", err))
-				j.incError()
+				j.incError(ERROR_PREPARE)
 				log.Printf("%s", err)
 			} else {
-				_, _ = j.ReplayRunner.Execute(&replayreq)
+				replayreq.Headers[SCAN_ID_HEADER] = j.Config.ScanID
+				replayresp, err := j.ReplayRunner.Execute(&replayreq)
+				if err == nil && len(j.Config.ReplayDir) > 0 {
+					j.recordReplay(replayresp)
+				}
+				replayresp.MakeFreeMemory()
 			}
 		}
+		if j.Scraper != nil {
+			resp.ScraperData = j.Scraper.Execute(&resp)
+		}
 		j.Output.Result(resp)
+		if j.requestCache != nil {
+			j.requestCache.Store(cacheKey, &resp)
+		}
+		if j.Notifier != nil && j.Notifier.Enabled(NOTIFY_EVENT_RESULTS) {
+			j.Notifier.Notify(resp)
+		}
 
 		// Refresh the progress indicator as we printed something out
 		j.updateProgress()
-		if j.Config.Recursion && j.Config.RecursionStrategy == "greedy" {
+		if j.Config.Recursion && !j.skipRecursion && j.Config.RecursionStrategy == "greedy" {
 			j.handleGreedyRecursionJob(resp)
 		}
+		if j.Config.Recursion && !j.skipRecursion && j.Config.RecursionLinks {
+			j.handleLinkRecursionJob(resp)
+		}
 	}
 	resp.MakeFreeMemory()
 
-	if j.Config.Recursion && j.Config.RecursionStrategy == "default" && len(resp.GetRedirectLocation(false)) > 0 {
+	if j.Config.Recursion && !j.skipRecursion && j.Config.RecursionStrategy == "default" && len(resp.GetRedirectLocation(false)) > 0 {
 		j.handleDefaultRecursionJob(resp)
 	}
 }
@@ -396,10 +967,7 @@ func (j *Job) runTask(input map[string][]byte, position int, retried bool) {
 func (j *Job) handleGreedyRecursionJob(resp Response) {
 	// Handle greedy recursion strategy. Match has been determined before calling handleRecursionJob
 	if j.Config.RecursionDepth == 0 || j.currentDepth < j.Config.RecursionDepth {
-		recUrl := resp.Request.Url + "/" + "FUZZ"
-		newJob := QueueJob{Url: recUrl, depth: j.currentDepth + 1}
-		j.queuejobs = append(j.queuejobs, newJob)
-		j.Output.Info(fmt.Sprintf("Adding a new job to the queue: %s", recUrl))
+		j.addRecursionJob(resp.Request.Url+"/"+"FUZZ", j.currentDepth+1)
 	} else {
 		j.Output.Warning(fmt.Sprintf("Maximum recursion depth reached. Ignoring: %s", resp.Request.Url))
 	}
@@ -415,81 +983,161 @@ func (j *Job) handleDefaultRecursionJob(resp Response) {
 	}
 	if j.Config.RecursionDepth == 0 || j.currentDepth < j.Config.RecursionDepth {
 		// We have yet to reach the maximum recursion depth
-		newJob := QueueJob{Url: recUrl, depth: j.currentDepth + 1}
-		j.queuejobs = append(j.queuejobs, newJob)
-		j.Output.Info(fmt.Sprintf("Adding a new job to the queue: %s", recUrl))
+		j.addRecursionJob(recUrl, j.currentDepth+1)
 	} else {
 		j.Output.Warning(fmt.Sprintf("Directory found, but recursion depth exceeded. Ignoring: %s", resp.GetRedirectLocation(true)))
 	}
 }
 
-//CalibrateResponses returns slice of Responses for randomly generated filter autocalibration requests
-func (j *Job) CalibrateResponses() ([]Response, error) {
-	cInputs := make([]string, 0)
-	rand.Seed(time.Now().UnixNano())
-	if len(j.Config.AutoCalibrationStrings) < 1 {
-		cInputs = append(cInputs, "admin"+RandomString(16)+"/")
-		cInputs = append(cInputs, ".htaccess"+RandomString(16))
-		cInputs = append(cInputs, RandomString(16)+"/")
-		cInputs = append(cInputs, RandomString(16))
-	} else {
-		cInputs = append(cInputs, j.Config.AutoCalibrationStrings...)
+//handleLinkRecursionJob adds recursion jobs for the directories under the current job linked from the HTML body of
+//a matched response
+func (j *Job) handleLinkRecursionJob(resp Response) {
+	if len(resp.ContentType) > 0 && !strings.Contains(resp.ContentType, "html") {
+		return
 	}
+	base, err := url.Parse(strings.TrimSuffix(j.Config.Url, "FUZZ"))
+	if err != nil {
+		return
+	}
+	page, err := url.Parse(resp.Request.Url)
+	if err != nil {
+		return
+	}
+	for _, link := range crawlLinks(page, resp.Body()) {
+		if link.Host != base.Host || link.Scheme != base.Scheme {
+			// Stay on the same origin
+			continue
+		}
+		for _, dir := range crawlDirectories(base.Path, link.Path) {
+			depth := j.currentDepth + strings.Count(strings.TrimPrefix(dir, base.Path), "/")
+			if j.Config.RecursionDepth > 0 && depth > j.Config.RecursionDepth {
+				break
+			}
+			u := *base
+			u.Path = dir
+			j.addRecursionJob(u.String()+"FUZZ", depth)
+		}
+	}
+}
 
-	results := make([]Response, 0)
-	for _, input := range cInputs {
-		inputs := make(map[string][]byte, len(j.Config.InputProviders))
-		for _, v := range j.Config.InputProviders {
-			inputs[v.Keyword] = []byte(input)
+//addRecursionJob adds a recursion job for a directory of the current job to the queue, unless it has been queued
+//already, is out of the recursion scope, or the current directory, the recursion depth or the whole run has already
+//spawned the maximum number of jobs
+func (j *Job) addRecursionJob(recUrl string, depth int) {
+	parent := j.Config.Url
+	if !j.inRecursionScope(strings.TrimSuffix(recUrl, "FUZZ")) {
+		log.Printf("Directory out of the recursion scope, ignoring: %s", recUrl)
+		return
+	}
+	j.queueMutex.Lock()
+	if j.recursionQueued[recUrl] {
+		j.queueMutex.Unlock()
+		return
+	}
+	overParent := j.Config.RecursionBreadth > 0 && j.recursionChildren[parent] >= j.Config.RecursionBreadth
+	overDepth := j.Config.RecursionDepthBreadth > 0 && j.recursionDepths[depth] >= j.Config.RecursionDepthBreadth
+	overTotal := j.Config.RecursionMaxJobs > 0 && len(j.recursionQueued) >= j.Config.RecursionMaxJobs
+	if overParent || overDepth || overTotal {
+		j.recursionOverflow++
+		j.queueMutex.Unlock()
+		if overParent {
+			j.Output.Warning(fmt.Sprintf("Recursion breadth limit reached for %s. Ignoring: %s", parent, recUrl))
+		} else if overDepth {
+			j.Output.Warning(fmt.Sprintf("Recursion breadth limit reached for depth %d. Ignoring: %s", depth, recUrl))
+		} else {
+			j.Output.Warning(fmt.Sprintf("Maximum number of recursion jobs reached. Ignoring: %s", recUrl))
 		}
+		return
+	}
+	j.recursionQueued[recUrl] = true
+	j.recursionChildren[parent]++
+	j.recursionDepths[depth]++
+	j.queuejobs = append(j.queuejobs, QueueJob{Url: recUrl, Overrides: j.queueOverridesFor(recUrl), depth: depth, inputProviders: j.recursionInputProviders()})
+	j.queueMutex.Unlock()
+	j.Output.Info(fmt.Sprintf("Adding a new job to the queue: %s", recUrl))
+}
 
-		req, err := j.Runner.Prepare(inputs)
-		if err != nil {
-			j.Output.Error(fmt.Sprintf("Encountered an error while preparing request: %s\n", err))
-			j.incError()
-			log.Printf("%s", err)
-			return results, err
+//inRecursionScope checks if the directory matches the -recursion-include regexp and does not match the
+//-recursion-exclude one, if set
+func (j *Job) inRecursionScope(dirUrl string) bool {
+	if j.Config.RecursionInclude != nil && !j.Config.RecursionInclude.MatchString(dirUrl) {
+		return false
+	}
+	return j.Config.RecursionExclude == nil || !j.Config.RecursionExclude.MatchString(dirUrl)
+}
+
+//recursionInputLimit returns the maximum number of inputs of the jobs at the recursion depth (-recursion-truncate),
+//0 for unlimited. The last limit applies to the deeper recursion depths too.
+func (j *Job) recursionInputLimit(depth int) int {
+	limits := j.Config.RecursionInputLimits
+	if depth == 0 || len(limits) == 0 {
+		return 0
+	}
+	if depth > len(limits) {
+		return limits[len(limits)-1]
+	}
+	return limits[depth-1]
+}
+
+//BaselineResponse returns the response for the target requested through its regular address, used as a reference
+//when hunting for origin servers with -origin-ips
+func (j *Job) BaselineResponse() (Response, error) {
+	req, err := j.Runner.Prepare(j.baselineInputs())
+	if err != nil {
+		return Response{}, err
+	}
+	resp, err := j.Runner.Execute(&req)
+	if err != nil {
+		return resp, fmt.Errorf("could not get the baseline response: %s", err)
+	}
+	resp.MakeFreeMemory()
+	return resp, nil
+}
+
+//baselineInputs returns the keyword values of the baseline request: the first input of the run, or the default values
+//of the injection positions in sniper mode. The keywords of streamed or command input, which cannot be read ahead of
+//the run, are left empty.
+func (j *Job) baselineInputs() map[string][]byte {
+	inputs := make(map[string][]byte, len(j.Config.InputProviders))
+	peek := j.Input != nil && j.Input.Total() >= 0
+	for _, p := range j.Config.InputProviders {
+		inputs[p.Keyword] = []byte("")
+		if p.Name == "command" {
+			peek = false
 		}
-		resp, err := j.Runner.Execute(&req)
-		if err != nil {
-			return results, err
+	}
+	if j.Config.InputMode == "sniper" && len(j.Config.SniperDefaults) > 0 && len(j.Config.InputProviders) > 0 {
+		for k, v := range j.Config.SniperInput(j.Config.InputProviders[0].Keyword, 0, []byte(j.Config.SniperDefaults[0])) {
+			inputs[k] = v
 		}
-
-		// Only calibrate on responses that would be matched otherwise
-		if j.isMatch(resp) {
-			resp.MakeFreeMemory()
-			results = append(results, resp)
+	} else if peek {
+		j.Input.Reset()
+		if j.Input.Next() {
+			for k, v := range j.Input.Value() {
+				inputs[k] = v
+			}
 		}
-		resp.MakeFreeMemory()
+		j.Input.Reset()
 	}
-	return results, nil
+	// Requested through the regular address of the target
+	delete(inputs, ORIGIN_KEYWORD)
+	return inputs
 }
 
 // CheckStop stops the job if stopping conditions are met
 func (j *Job) CheckStop() {
-	if j.Counter > 50 {
-		// We have enough samples
-		if j.Config.StopOn403 || j.Config.StopOnAll {
-			if float64(j.Count403)/float64(j.Counter) > 0.95 {
-				// Over 95% of requests are 403
-				j.Error = "Getting an unusual amount of 403 responses, exiting."
-				j.Stop()
-			}
-		}
-		if j.Config.StopOnErrors || j.Config.StopOnAll {
-			if j.SpuriousErrorCounter > j.Config.Threads*2 {
-				// Most of the requests are erroring
-				j.Error = "Receiving spurious errors, exiting."
-				j.Stop()
-			}
-
-		}
-		if j.Config.StopOnAll && (float64(j.Count429)/float64(j.Counter) > 0.2) {
-			// Over 20% of responses are 429
-			j.Error = "Getting an unusual amount of 429 responses, exiting."
+	j.checkShutdown()
+	if j.Counter > 50 && (j.Config.StopOnErrors || j.Config.StopOnAll) {
+		if j.SpuriousErrorCounter > j.Config.Threads*2 {
+			// Most of the requests are erroring
+			j.Error = "Receiving spurious errors, exiting."
+			j.stopReason = STOP_ERRORS
 			j.Stop()
 		}
 	}
+	if rule, ok := j.stopRules.Check(); ok {
+		j.applyStopRule(rule)
+	}
 
 	// Check for runtime of entire process
 	if j.Config.MaxTime > 0 {
@@ -497,6 +1145,7 @@ func (j *Job) CheckStop() {
 		runningSecs := int(dur / time.Second)
 		if runningSecs >= j.Config.MaxTime {
 			j.Error = "Maximum running time for entire process reached, exiting."
+			j.stopReason = STOP_MAXTIME
 			j.Stop()
 		}
 	}
@@ -513,12 +1162,63 @@ func (j *Job) CheckStop() {
 	}
 }
 
+//applyStopRule takes the action of a stop rule that was met
+func (j *Job) applyStopRule(rule StopRule) {
+	switch rule.Action {
+	case STOP_RULE_STOP:
+		j.Error = rule.Message()
+		j.stopReason = rule.Reason()
+		j.Stop()
+	case STOP_RULE_PAUSE:
+		if j.Paused {
+			// Paused interactively, leave the resuming to the user
+			return
+		}
+		j.Output.Warning(rule.Message())
+		j.Pause()
+		go func() {
+			select {
+			case <-j.Config.Context.Done():
+			case <-time.After(rule.Duration):
+			}
+			j.Resume()
+		}()
+	case STOP_RULE_SKIP:
+		j.Output.Warning(rule.Message())
+		j.SkipQueue()
+	default:
+		j.Output.Warning(rule.Message())
+	}
+}
+
 //Stop the execution of the Job
 func (j *Job) Stop() {
 	j.Running = false
+	j.stopOnce.Do(func() { close(j.stopped) })
 	j.Config.Cancel()
 }
 
+//isStopped tells if the job has been stopped, for the goroutines running alongside the queue jobs
+func (j *Job) isStopped() bool {
+	select {
+	case <-j.stopped:
+		return true
+	default:
+		return false
+	}
+}
+
+//threadLimiter returns the limiter of the requests in flight (-t), shared by the queue jobs and the background
+//crawl. A new one is created for a queue job overriding the threads.
+func (j *Job) threadLimiter() chan bool {
+	j.limiterMutex.Lock()
+	defer j.limiterMutex.Unlock()
+	if j.limiter == nil || cap(j.limiter) != j.Config.Threads {
+		j.limiter = make(chan bool, j.Config.Threads)
+	}
+	return j.limiter
+}
+
 //Stop current, resume to next
 func (j *Job) Next() {
 	j.RunningJob = false
@@ -0,0 +1,87 @@
+package ffuf
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// listTestInput is an InputProvider going through a fixed list of inputs
+type listTestInput struct {
+	inputs   []map[string][]byte
+	position int
+	total    int
+}
+
+func (i *listTestInput) AddProvider(InputProviderConfig) error { return nil }
+func (i *listTestInput) Next() bool {
+	i.position++
+	return i.position <= len(i.inputs)
+}
+func (i *listTestInput) Position() int                                { return i.position }
+func (i *listTestInput) Reset()                                       { i.position = 0 }
+func (i *listTestInput) Value() map[string][]byte                     { return i.inputs[i.position-1] }
+func (i *listTestInput) Total() int                                   { return i.total }
+func (i *listTestInput) Skipped() int                                 { return 0 }
+func (i *listTestInput) ReplaceProviders([]InputProviderConfig) error { return nil }
+
+func TestBaselineInputs(t *testing.T) {
+	inputs := []map[string][]byte{
+		{"FUZZ": []byte("admin"), ORIGIN_KEYWORD: []byte("192.0.2.1")},
+		{"FUZZ": []byte("login"), ORIGIN_KEYWORD: []byte("192.0.2.1")},
+	}
+	for _, test := range []struct {
+		name      string
+		providers []InputProviderConfig
+		total     int
+		want      map[string][]byte
+	}{
+		{
+			"first input",
+			[]InputProviderConfig{{Name: "wordlist", Keyword: "FUZZ"}, {Name: "wordlist", Keyword: ORIGIN_KEYWORD}},
+			2,
+			map[string][]byte{"FUZZ": []byte("admin")},
+		},
+		{
+			"streamed input",
+			[]InputProviderConfig{{Name: "wordlist", Keyword: "FUZZ"}, {Name: "wordlist", Keyword: ORIGIN_KEYWORD}},
+			-1,
+			map[string][]byte{"FUZZ": []byte("")},
+		},
+		{
+			"command input",
+			[]InputProviderConfig{{Name: "command", Keyword: "FUZZ"}, {Name: "wordlist", Keyword: ORIGIN_KEYWORD}},
+			2,
+			map[string][]byte{"FUZZ": []byte("")},
+		},
+	} {
+		ctx, cancel := context.WithCancel(context.Background())
+		conf := NewConfig(ctx, cancel)
+		conf.InputProviders = test.providers
+		j := NewJob(&conf)
+		input := &listTestInput{inputs: inputs, total: test.total}
+		j.Input = input
+		if got := j.baselineInputs(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Baseline inputs of %s: was expecting %q, got %q", test.name, test.want, got)
+		}
+		if input.position != 0 {
+			t.Errorf("Baseline inputs of %s: the input was not reset for the run", test.name)
+		}
+		cancel()
+	}
+}
+
+func TestBaselineInputsSniper(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conf := NewConfig(ctx, cancel)
+	conf.InputMode = "sniper"
+	conf.InputProviders = []InputProviderConfig{{Name: "wordlist", Keyword: "FUZZ"}, {Name: "wordlist", Keyword: ORIGIN_KEYWORD}}
+	conf.SniperDefaults = []string{"1", "asc"}
+	j := NewJob(&conf)
+	j.Input = &listTestInput{total: 2}
+	want := map[string][]byte{"FUZZ": []byte("1"), SniperKeyword(0): []byte("1"), SniperKeyword(1): []byte("asc")}
+	if got := j.baselineInputs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Was expecting the default values of the positions %q, got %q", want, got)
+	}
+}
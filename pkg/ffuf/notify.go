@@ -0,0 +1,308 @@
+package ffuf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	//NOTIFY_EVENT_RESULTS sends the matched results
+	NOTIFY_EVENT_RESULTS = "results"
+	//NOTIFY_EVENT_JOB sends a message when a queue job is completed
+	NOTIFY_EVENT_JOB = "job"
+	//NOTIFY_EVENT_STOP sends a message when the run is stopped by a stop condition or interrupted
+	NOTIFY_EVENT_STOP = "stop"
+	//NOTIFY_EVENT_PROGRESS is the event of the messages sent at the progress milestones, defined as percentages
+	NOTIFY_EVENT_PROGRESS = "progress"
+)
+
+const (
+	NOTIFY_QUEUE_SIZE     = 1000
+	NOTIFY_FLUSH_INTERVAL = 5 * time.Second
+	NOTIFY_RETRIES        = 3
+)
+
+//NotifyResult is a matched result sent to the webhook
+type NotifyResult struct {
+	Input            map[string]string `json:"input"`
+	Url              string            `json:"url"`
+	StatusCode       int64             `json:"status"`
+	ContentLength    int64             `json:"length"`
+	ContentWords     int64             `json:"words"`
+	ContentLines     int64             `json:"lines"`
+	ContentType      string            `json:"content-type"`
+	RedirectLocation string            `json:"redirectlocation"`
+	Duration         time.Duration     `json:"duration"`
+}
+
+//NotifyEvent is a milestone of the run sent to the webhook
+type NotifyEvent struct {
+	Event    string `json:"event"`
+	Message  string `json:"message"`
+	Url      string `json:"url"`
+	Progress int    `json:"progress,omitempty"`
+	Requests int    `json:"requests"`
+	Matches  int    `json:"matches"`
+	Errors   int    `json:"errors"`
+}
+
+//Notifier posts the matched results to a webhook in batches. Results are queued and sent from a worker of its own,
+//so slow or failing webhooks never block the fuzzing threads.
+type Notifier struct {
+	config   *Config
+	client   *http.Client
+	template *template.Template
+	queue    chan NotifyResult
+	events   chan NotifyEvent
+	flush    chan chan bool
+}
+
+//NewNotifier creates a notifier for the configured webhook and starts its worker
+func NewNotifier(conf *Config) (*Notifier, error) {
+	n := &Notifier{
+		config: conf,
+		client: &http.Client{Timeout: time.Duration(conf.Timeout) * time.Second},
+		queue:  make(chan NotifyResult, NOTIFY_QUEUE_SIZE),
+		events: make(chan NotifyEvent, NOTIFY_QUEUE_SIZE),
+		flush:  make(chan chan bool),
+	}
+	if strings.Contains(conf.WebhookTemplate, "{{") {
+		t, err := template.New("webhook").Parse(conf.WebhookTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook template: %s", err)
+		}
+		n.template = t
+	} else if conf.WebhookTemplate != "json" && conf.WebhookTemplate != "slack" && conf.WebhookTemplate != "discord" {
+		return nil, fmt.Errorf("unknown webhook template %s, use json, slack, discord or a custom template", conf.WebhookTemplate)
+	}
+	go n.worker()
+	return n, nil
+}
+
+//Notify queues a matched response to be sent, dropping it if the queue is full
+func (n *Notifier) Notify(resp Response) {
+	res := NotifyResult{
+		Input:            make(map[string]string, len(resp.Request.Input)),
+		Url:              resp.Request.Url,
+		StatusCode:       resp.StatusCode,
+		ContentLength:    resp.ContentLength,
+		ContentWords:     resp.ContentWords,
+		ContentLines:     resp.ContentLines,
+		ContentType:      resp.ContentType,
+		RedirectLocation: resp.GetRedirectLocation(false),
+		Duration:         resp.Time,
+	}
+	for k, v := range resp.Request.Input {
+		res.Input[k] = string(v)
+	}
+	select {
+	case n.queue <- res:
+	default:
+		log.Printf("Webhook notification queue full, dropped a result for %s", res.Url)
+	}
+}
+
+//Enabled checks if the event is one of the events to send (-webhook-events)
+func (n *Notifier) Enabled(event string) bool {
+	for _, e := range n.config.WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+//NotifyEvent queues a milestone of the run to be sent after the results matched before it
+func (n *Notifier) NotifyEvent(ev NotifyEvent) {
+	select {
+	case n.events <- ev:
+	default:
+		log.Printf("Webhook notification queue full, dropped the %s event", ev.Event)
+	}
+}
+
+//Flush sends the queued results and waits for the worker to finish sending them
+func (n *Notifier) Flush() {
+	done := make(chan bool)
+	n.flush <- done
+	<-done
+}
+
+func (n *Notifier) worker() {
+	batch := make([]NotifyResult, 0, n.config.WebhookBatch)
+	ticker := time.NewTicker(NOTIFY_FLUSH_INTERVAL)
+	defer ticker.Stop()
+	for {
+		select {
+		case res := <-n.queue:
+			batch = append(batch, res)
+			if len(batch) >= n.config.WebhookBatch {
+				n.send(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				n.send(batch)
+				batch = batch[:0]
+			}
+		case ev := <-n.events:
+			// The results are sent first, the event may be the summary of them
+			for len(n.queue) > 0 {
+				batch = append(batch, <-n.queue)
+			}
+			if len(batch) > 0 {
+				n.send(batch)
+				batch = batch[:0]
+			}
+			n.sendEvent(ev)
+		case done := <-n.flush:
+			for len(n.queue) > 0 {
+				batch = append(batch, <-n.queue)
+				if len(batch) >= n.config.WebhookBatch {
+					n.send(batch)
+					batch = batch[:0]
+				}
+			}
+			if len(batch) > 0 {
+				n.send(batch)
+				batch = batch[:0]
+			}
+			for len(n.events) > 0 {
+				n.sendEvent(<-n.events)
+			}
+			done <- true
+		}
+	}
+}
+
+//send posts a batch to the webhook
+func (n *Notifier) send(batch []NotifyResult) {
+	body, err := n.payload(batch)
+	if err != nil {
+		log.Printf("Could not create the webhook payload: %s", err)
+		return
+	}
+	n.post(body)
+}
+
+//sendEvent posts a milestone of the run to the webhook
+func (n *Notifier) sendEvent(ev NotifyEvent) {
+	body, err := n.eventPayload(ev)
+	if err != nil {
+		log.Printf("Could not create the webhook payload: %s", err)
+		return
+	}
+	n.post(body)
+}
+
+//post sends a payload to the webhook, retrying with an increasing delay on failure
+func (n *Notifier) post(body []byte) {
+	for i := 0; i < NOTIFY_RETRIES; i++ {
+		if i > 0 {
+			time.Sleep(time.Duration(i) * time.Second)
+		}
+		resp, err := n.client.Post(n.config.WebhookURL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.Printf("Webhook notification failed: %s", err)
+			continue
+		}
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode < 300 {
+			return
+		}
+		log.Printf("Webhook notification failed with status %d", resp.StatusCode)
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			// Retrying will not help
+			return
+		}
+	}
+}
+
+//payload returns the request body for a batch of results in the configured format
+func (n *Notifier) payload(batch []NotifyResult) ([]byte, error) {
+	if n.template != nil {
+		var b bytes.Buffer
+		err := n.template.Execute(&b, map[string]interface{}{"Results": batch, "CommandLine": n.config.CommandLine, "ScanID": n.config.ScanID})
+		return b.Bytes(), err
+	}
+	switch n.config.WebhookTemplate {
+	case "slack":
+		return json.Marshal(map[string]string{"text": notifyText(n.config.ScanID, batch, 40000)})
+	case "discord":
+		return json.Marshal(map[string]string{"content": notifyText(n.config.ScanID, batch, 2000)})
+	}
+	return json.Marshal(map[string]interface{}{"scan_id": n.config.ScanID, "commandline": n.config.CommandLine, "results": batch})
+}
+
+//eventPayload returns the request body for a milestone of the run in the configured format
+func (n *Notifier) eventPayload(ev NotifyEvent) ([]byte, error) {
+	if n.template != nil {
+		var b bytes.Buffer
+		err := n.template.Execute(&b, map[string]interface{}{"Event": ev, "CommandLine": n.config.CommandLine, "ScanID": n.config.ScanID})
+		return b.Bytes(), err
+	}
+	switch n.config.WebhookTemplate {
+	case "slack":
+		return json.Marshal(map[string]string{"text": fmt.Sprintf("ffuf scan %s: %s", n.config.ScanID, ev.Message)})
+	case "discord":
+		return json.Marshal(map[string]string{"content": fmt.Sprintf("ffuf scan %s: %s", n.config.ScanID, ev.Message)})
+	}
+	return json.Marshal(map[string]interface{}{"scan_id": n.config.ScanID, "commandline": n.config.CommandLine, "event": ev})
+}
+
+//notifyText returns a human readable message for the chat webhooks, truncated to the maximum message length
+func notifyText(scanID string, batch []NotifyResult, maxLen int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ffuf scan %s found %d new result(s):\n", scanID, len(batch))
+	for i, r := range batch {
+		line := fmt.Sprintf("[Status: %d, Size: %d, Words: %d, Lines: %d] %s\n", r.StatusCode, r.ContentLength, r.ContentWords, r.ContentLines, r.Url)
+		if b.Len()+len(line) > maxLen-20 {
+			fmt.Fprintf(&b, "... and %d more", len(batch)-i)
+			break
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+//notifyEvent sends a milestone of the run to the webhook, if the event is one of the events to send
+func (j *Job) notifyEvent(ev NotifyEvent) {
+	if j.Notifier == nil || !j.Notifier.Enabled(ev.Event) {
+		return
+	}
+	j.ErrorMutex.Lock()
+	ev.Url = j.Config.Url
+	ev.Matches = j.matchCount
+	ev.Errors = j.ErrorCounter
+	j.ErrorMutex.Unlock()
+	j.Notifier.NotifyEvent(ev)
+}
+
+//notifyProgress sends the progress milestones (percentages of -webhook-events) reached by the current job. The 100%
+//milestone is sent once the job is done, instead of when its last request is sent.
+func (j *Job) notifyProgress(sent map[int]bool, done bool) {
+	if j.Notifier == nil || j.Total == 0 {
+		return
+	}
+	pct := j.Counter * 100 / j.Total
+	for _, m := range j.Config.WebhookMilestones {
+		if sent[m] || (m < 100 && pct < m) || (m == 100 && !done) {
+			continue
+		}
+		sent[m] = true
+		msg := fmt.Sprintf("%d%% of the job on %s sent (%d/%d requests)", m, j.Config.Url, j.Counter, j.Total)
+		if m == 100 {
+			msg = fmt.Sprintf("Job on %s done (%d requests)", j.Config.Url, j.Counter)
+		}
+		j.notifyEvent(NotifyEvent{Event: NOTIFY_EVENT_PROGRESS, Message: msg, Progress: m, Requests: j.requestCount + j.Counter})
+	}
+}
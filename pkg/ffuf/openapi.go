@@ -0,0 +1,258 @@
+package ffuf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+//ApiOperation is a single request template imported from an API definition
+type ApiOperation struct {
+	Method      string            `json:"method"`
+	Url         string            `json:"url"`
+	Data        string            `json:"data"`
+	ContentType string            `json:"content_type"`
+	Headers     map[string]string `json:"headers,omitempty"`
+}
+
+type openAPISpec struct {
+	Swagger     string                                `json:"swagger"`
+	OpenAPI     string                                `json:"openapi"`
+	Host        string                                `json:"host"`
+	BasePath    string                                `json:"basePath"`
+	Schemes     []string                              `json:"schemes"`
+	Servers     []openAPIServer                       `json:"servers"`
+	Paths       map[string]map[string]json.RawMessage `json:"paths"`
+	Definitions map[string]openAPISchema              `json:"definitions"`
+	Parameters  map[string]openAPIParameter           `json:"parameters"`
+	Components  struct {
+		Schemas    map[string]openAPISchema    `json:"schemas"`
+		Parameters map[string]openAPIParameter `json:"parameters"`
+	} `json:"components"`
+}
+
+type openAPIServer struct {
+	Url string `json:"url"`
+}
+
+type openAPIOperation struct {
+	Parameters  []openAPIParameter `json:"parameters"`
+	Consumes    []string           `json:"consumes"`
+	RequestBody *struct {
+		Content map[string]struct {
+			Schema openAPISchema `json:"schema"`
+		} `json:"content"`
+	} `json:"requestBody"`
+}
+
+type openAPIParameter struct {
+	Ref    string         `json:"$ref"`
+	Name   string         `json:"name"`
+	In     string         `json:"in"`
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref        string                   `json:"$ref"`
+	Type       string                   `json:"type"`
+	Properties map[string]openAPISchema `json:"properties"`
+	Items      *openAPISchema           `json:"items"`
+}
+
+var openAPIMethods = []string{"get", "post", "put", "patch", "delete", "head", "options"}
+
+//parseOpenAPI reads an OpenAPI 3 or Swagger 2 definition in JSON format and creates a request template for every
+//operation, with all of its path, query and body parameters replaced by the FUZZ keyword
+func parseOpenAPI(parseOpts *ConfigOptions, conf *Config) error {
+	content, err := ioutil.ReadFile(parseOpts.Input.OpenAPI)
+	if err != nil {
+		return fmt.Errorf("could not read the API definition: %s", err)
+	}
+	var spec openAPISpec
+	if err := json.Unmarshal(content, &spec); err != nil {
+		return fmt.Errorf("could not parse the API definition, only JSON format is supported: %s", err)
+	}
+	baseUrl := parseOpts.HTTP.URL
+	if baseUrl == "" {
+		baseUrl = spec.baseUrl()
+	}
+	if !strings.HasPrefix(baseUrl, "http") {
+		return fmt.Errorf("no absolute base URL in the API definition, define one with -u")
+	}
+	baseUrl = strings.TrimSuffix(baseUrl, "/")
+
+	paths := make([]string, 0, len(spec.Paths))
+	for p := range spec.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		item := spec.Paths[p]
+		var common []openAPIParameter
+		if raw, ok := item["parameters"]; ok {
+			_ = json.Unmarshal(raw, &common)
+		}
+		for _, method := range openAPIMethods {
+			raw, ok := item[method]
+			if !ok {
+				continue
+			}
+			var op openAPIOperation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return fmt.Errorf("could not parse operation %s %s: %s", strings.ToUpper(method), p, err)
+			}
+			apiop, fuzzable := spec.operation(baseUrl, p, method, append(common, op.Parameters...), op)
+			if fuzzable {
+				conf.ApiOperations = append(conf.ApiOperations, apiop)
+			}
+		}
+	}
+	if len(conf.ApiOperations) == 0 {
+		return fmt.Errorf("no operations with parameters to fuzz found in the API definition")
+	}
+	conf.Url = conf.ApiOperations[0].Url
+	conf.Method = conf.ApiOperations[0].Method
+	conf.Data = conf.ApiOperations[0].Data
+	return nil
+}
+
+//baseUrl returns the API base URL defined in the specification
+func (s *openAPISpec) baseUrl() string {
+	if len(s.Servers) > 0 {
+		return s.Servers[0].Url
+	}
+	if s.Host != "" {
+		scheme := "https"
+		if len(s.Schemes) > 0 {
+			scheme = s.Schemes[0]
+		}
+		return scheme + "://" + s.Host + s.BasePath
+	}
+	return ""
+}
+
+//operation builds the request template for an operation, and returns true if it has fuzzable parameters
+func (s *openAPISpec) operation(baseUrl, path, method string, params []openAPIParameter, op openAPIOperation) (ApiOperation, bool) {
+	apiop := ApiOperation{Method: strings.ToUpper(method)}
+	fuzzable := false
+	query := make([]string, 0)
+	form := make([]string, 0)
+	for _, p := range params {
+		p = s.resolveParameter(p)
+		switch p.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", "FUZZ")
+			fuzzable = true
+		case "query":
+			query = append(query, url.QueryEscape(p.Name)+"=FUZZ")
+			fuzzable = true
+		case "formData":
+			form = append(form, url.QueryEscape(p.Name)+"=FUZZ")
+			fuzzable = true
+		case "body":
+			if p.Schema != nil {
+				apiop.Data = s.schemaTemplate(*p.Schema, 0)
+				apiop.ContentType = "application/json"
+				fuzzable = true
+			}
+		}
+	}
+	if len(form) > 0 {
+		apiop.Data = strings.Join(form, "&")
+		apiop.ContentType = "application/x-www-form-urlencoded"
+	}
+	if op.RequestBody != nil {
+		if c, ok := op.RequestBody.Content["application/json"]; ok {
+			apiop.Data = s.schemaTemplate(c.Schema, 0)
+			apiop.ContentType = "application/json"
+			fuzzable = true
+		} else if c, ok := op.RequestBody.Content["application/x-www-form-urlencoded"]; ok {
+			schema := s.resolveSchema(c.Schema)
+			for _, name := range sortedProperties(schema) {
+				form = append(form, url.QueryEscape(name)+"=FUZZ")
+			}
+			apiop.Data = strings.Join(form, "&")
+			apiop.ContentType = "application/x-www-form-urlencoded"
+			fuzzable = fuzzable || len(form) > 0
+		}
+	}
+	apiop.Url = baseUrl + path
+	if len(query) > 0 {
+		apiop.Url += "?" + strings.Join(query, "&")
+	}
+	return apiop, fuzzable
+}
+
+//schemaTemplate returns a JSON document for the schema, with FUZZ keyword in place of every value
+func (s *openAPISpec) schemaTemplate(schema openAPISchema, depth int) string {
+	schema = s.resolveSchema(schema)
+	if depth > 5 {
+		// Recursive schema
+		return "null"
+	}
+	switch schema.Type {
+	case "array":
+		if schema.Items == nil {
+			return "[]"
+		}
+		return "[" + s.schemaTemplate(*schema.Items, depth+1) + "]"
+	case "integer", "number", "boolean":
+		return "FUZZ"
+	case "string":
+		return "\"FUZZ\""
+	}
+	if len(schema.Properties) == 0 {
+		if schema.Type == "object" {
+			return "{}"
+		}
+		return "\"FUZZ\""
+	}
+	props := make([]string, 0)
+	for _, name := range sortedProperties(schema) {
+		key, _ := json.Marshal(name)
+		props = append(props, string(key)+":"+s.schemaTemplate(schema.Properties[name], depth+1))
+	}
+	return "{" + strings.Join(props, ",") + "}"
+}
+
+//resolveSchema follows a local schema reference
+func (s *openAPISpec) resolveSchema(schema openAPISchema) openAPISchema {
+	for i := 0; i < 5 && schema.Ref != ""; i++ {
+		name := schema.Ref[strings.LastIndex(schema.Ref, "/")+1:]
+		if ref, ok := s.Components.Schemas[name]; ok && strings.HasPrefix(schema.Ref, "#/components/schemas/") {
+			schema = ref
+		} else if ref, ok := s.Definitions[name]; ok && strings.HasPrefix(schema.Ref, "#/definitions/") {
+			schema = ref
+		} else {
+			return openAPISchema{}
+		}
+	}
+	return schema
+}
+
+//resolveParameter follows a local parameter reference
+func (s *openAPISpec) resolveParameter(p openAPIParameter) openAPIParameter {
+	if p.Ref == "" {
+		return p
+	}
+	name := p.Ref[strings.LastIndex(p.Ref, "/")+1:]
+	if ref, ok := s.Components.Parameters[name]; ok {
+		return ref
+	}
+	if ref, ok := s.Parameters[name]; ok {
+		return ref
+	}
+	return p
+}
+
+func sortedProperties(schema openAPISchema) []string {
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
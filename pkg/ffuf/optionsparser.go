@@ -2,16 +2,21 @@ package ffuf
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
+	"net"
+	"net/http/httputil"
 	"net/textproto"
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pelletier/go-toml"
 )
@@ -26,133 +31,346 @@ type ConfigOptions struct {
 }
 
 type HTTPOptions struct {
-	Cookies           []string
-	Data              string
-	FollowRedirects   bool
-	Headers           []string
-	IgnoreBody        bool
-	Method            string
-	ProxyURL          string
-	Recursion         bool
-	RecursionDepth    int
-	RecursionStrategy string
-	ReplayProxyURL    string
-	SNI               string
-	Timeout           int
-	URL               string
+	Auth                  string
+	BodyMemory            int
+	Cookies               []string
+	Crawl                 bool
+	CrawlDepth            int
+	CrawlPages            int
+	CACert                string
+	ClientCert            string
+	ClientKey             string
+	Data                  string
+	FastSize              bool
+	FollowRedirects       bool
+	GRPCReflection        bool
+	Headers               []string
+	Http2                 bool
+	Http2PriorKnowledge   bool
+	ConnReuse             bool
+	IgnoreBody            bool
+	KeepAlive             bool
+	IPRate                int
+	IPThreads             int
+	MaxBodySize           int64
+	MaxIdleConns          int
+	Prewarm               int
+	Method                string
+	ProxyBackup           string
+	ProxyFallback         string
+	ProxyList             string
+	ProxyRotate           string
+	ProxyURL              string
+	Recursion             bool
+	RecursionBreadth      int
+	RecursionDepth        int
+	RecursionDepthBreadth int
+	RecursionExclude      string
+	RecursionFingerprint  int
+	RecursionInclude      string
+	RecursionLinks        bool
+	RecursionMaxJobs      int
+	RecursionStrategy     string
+	RecursionTruncate     string
+	RecursionWordlist     string
+	ReplayDir             string
+	ReplayMatch           []string
+	ReplayProxyURL        string
+	Resolve               []string
+	Resolvers             string
+	Retries               int
+	RetryDelay            float64
+	RetryOn               string
+	SNI                   string
+	TLSCiphers            string
+	TLSKeyLog             string
+	TLSMaxVersion         string
+	TLSMinVersion         string
+	Timeout               int
+	URL                   string
+	UnixSocket            string
 }
 
 type GeneralOptions struct {
-	AutoCalibration        bool
-	AutoCalibrationStrings []string
-	Colors                 bool
-	ConfigFile             string `toml:"-"`
-	Delay                  string
-	MaxTime                int
-	MaxTimeJob             int
-	Noninteractive         bool
-	Quiet                  bool
-	Rate                   int
-	ShowVersion            bool `toml:"-"`
-	StopOn403              bool
-	StopOnAll              bool
-	StopOnErrors           bool
-	Threads                int
-	Verbose                bool
+	AutoCalibration         bool
+	AutoCalibrationKeywords []string
+	AutoCalibrationPerHost  bool
+	AutoCalibrationStrategy string
+	AutoCalibrationStrings  []string
+	Colors                  bool
+	Columns                 string
+	ConfigFile              string `toml:"-"`
+	DedupRequests           bool
+	Delay                   string
+	HostErrors              int
+	Import                  string
+	JobOverrides            []string
+	MaxTime                 int
+	MaxTimeJob              int
+	Noninteractive          bool
+	Quiet                   bool
+	Rate                    int
+	RateAdaptive            bool
+	RateMode                string
+	RobotsDelay             bool
+	RobotsDelayMax          float64
+	Safe                    bool
+	SafeAllow               string
+	ScraperDir              string
+	ScraperFile             string
+	Scrapers                string
+	ShowVersion             bool `toml:"-"`
+	StatusActions           []string
+	StopOn403               bool
+	StopOnAll               bool
+	StopOnErrors            bool
+	StopRules               []string
+	Threads                 int
+	UpdateCheck             bool
+	UpdateURL               string
+	Verbose                 bool
+	WaitFor                 string
+	WaitForInterval         int
+	WaitForRegex            string
+	WaitForTimeout          int
 }
 
 type InputOptions struct {
+	AutoExtensions         bool
+	AutoExtensionsList     string
+	Denylist               string
+	DenylistFile           string
 	DirSearchCompat        bool
 	Extensions             string
+	HexWordlists           string
 	IgnoreWordlistComments bool
+	InputCommandMode       string
 	InputMode              string
 	InputNum               int
 	InputShell             string
 	Inputcommands          []string
+	OpenAPI                string
+	OriginIPs              string
+	PinnedInputs           string
+	Postman                string
 	Request                string
 	RequestProto           string
+	Shard                  string
+	ShardHash              bool
+	Transforms             []string
+	WSDL                   string
 	Wordlists              []string
 }
 
 type OutputOptions struct {
+	AppendOutput        bool
 	DebugLog            string
+	Diff                string
+	GroupDirectories    bool
+	JSONFields          string
 	OutputDirectory     string
 	OutputFile          string
 	OutputFormat        string
+	OutputFsync         bool
 	OutputSkipEmptyFile bool
+	Pcap                string
+	PostProcess         string
+	ProgressJSON        string
+	FilterStats         bool
+	StatusMatrix        bool
+	SummaryJSON         bool
+	TimeFormat          string
+	TimeZone            string
+	Webhook             string
+	WebhookBatch        int
+	WebhookEvents       string
+	WebhookTemplate     string
+	WordlistStats       bool
 }
 
 type FilterOptions struct {
-	Lines  string
-	Regexp string
-	Size   string
-	Status string
-	Time   string
-	Words  string
+	HeaderRegexp string
+	Lines        string
+	Regexp       string
+	Similarity   string
+	Size         string
+	Status       string
+	Time         string
+	Words        string
 }
 
 type MatcherOptions struct {
-	Lines  string
-	Regexp string
-	Size   string
-	Status string
-	Time   string
-	Words  string
+	HeaderRegexp string
+	JSONKeys     string
+	Lines        string
+	Mime         string
+	Regexp       string
+	Size         string
+	Status       string
+	Tags         string
+	Time         string
+	Words        string
 }
 
 //NewConfigOptions returns a newly created ConfigOptions struct with default values
 func NewConfigOptions() *ConfigOptions {
 	c := &ConfigOptions{}
 	c.Filter.Lines = ""
+	c.Filter.HeaderRegexp = ""
 	c.Filter.Regexp = ""
+	c.Filter.Similarity = ""
 	c.Filter.Size = ""
 	c.Filter.Status = ""
 	c.Filter.Time = ""
 	c.Filter.Words = ""
 	c.General.AutoCalibration = false
+	c.General.AutoCalibrationPerHost = false
+	c.General.AutoCalibrationStrategy = CALIBRATION_BASIC
 	c.General.Colors = false
+	c.General.Columns = ""
+	c.General.DedupRequests = false
 	c.General.Delay = ""
+	c.General.HostErrors = 0
+	c.General.Import = ""
+	c.General.JobOverrides = []string{}
 	c.General.MaxTime = 0
 	c.General.MaxTimeJob = 0
 	c.General.Noninteractive = false
 	c.General.Quiet = false
 	c.General.Rate = 0
+	c.General.RateAdaptive = false
+	c.General.RateMode = RATE_MODE_FIXED
+	c.General.RobotsDelay = false
+	c.General.RobotsDelayMax = 0
+	c.General.Safe = false
+	c.General.SafeAllow = ""
+	c.General.ScraperDir = ""
+	c.General.ScraperFile = ""
+	c.General.Scrapers = ""
 	c.General.ShowVersion = false
+	c.General.StatusActions = []string{}
 	c.General.StopOn403 = false
 	c.General.StopOnAll = false
 	c.General.StopOnErrors = false
+	c.General.StopRules = []string{}
 	c.General.Threads = 40
+	c.General.UpdateCheck = false
+	c.General.UpdateURL = ""
 	c.General.Verbose = false
+	c.General.WaitFor = ""
+	c.General.WaitForInterval = 5
+	c.General.WaitForRegex = ""
+	c.General.WaitForTimeout = 300
+	c.HTTP.Auth = ""
+	c.HTTP.BodyMemory = DEFAULT_BODY_MEMORY
+	c.HTTP.Crawl = false
+	c.HTTP.CrawlDepth = 2
+	c.HTTP.CrawlPages = 100
+	c.HTTP.CACert = ""
+	c.HTTP.ClientCert = ""
+	c.HTTP.ClientKey = ""
 	c.HTTP.Data = ""
+	c.HTTP.FastSize = false
 	c.HTTP.FollowRedirects = false
+	c.HTTP.GRPCReflection = false
+	c.HTTP.Http2 = false
+	c.HTTP.Http2PriorKnowledge = false
+	c.HTTP.ConnReuse = false
 	c.HTTP.IgnoreBody = false
+	c.HTTP.KeepAlive = false
+	c.HTTP.IPRate = 0
+	c.HTTP.IPThreads = 0
+	c.HTTP.MaxBodySize = DEFAULT_MAX_BODY_SIZE
+	c.HTTP.MaxIdleConns = DEFAULT_MAX_IDLE_CONNS
+	c.HTTP.Prewarm = 0
 	c.HTTP.Method = ""
+	c.HTTP.ProxyBackup = ""
+	c.HTTP.ProxyFallback = PROXY_FALLBACK_FAIL
+	c.HTTP.ProxyList = ""
+	c.HTTP.ProxyRotate = PROXY_ROTATE_ROUNDROBIN
 	c.HTTP.ProxyURL = ""
 	c.HTTP.Recursion = false
+	c.HTTP.RecursionBreadth = 0
 	c.HTTP.RecursionDepth = 0
+	c.HTTP.RecursionDepthBreadth = 0
+	c.HTTP.RecursionExclude = ""
+	c.HTTP.RecursionFingerprint = 0
+	c.HTTP.RecursionInclude = ""
+	c.HTTP.RecursionLinks = false
+	c.HTTP.RecursionMaxJobs = 0
 	c.HTTP.RecursionStrategy = "default"
+	c.HTTP.RecursionTruncate = ""
+	c.HTTP.RecursionWordlist = ""
+	c.HTTP.ReplayDir = ""
+	c.HTTP.ReplayMatch = []string{}
 	c.HTTP.ReplayProxyURL = ""
+	c.HTTP.Resolve = []string{}
+	c.HTTP.Resolvers = ""
+	c.HTTP.Retries = 1
+	c.HTTP.RetryDelay = 0.5
+	c.HTTP.RetryOn = ""
+	c.HTTP.TLSCiphers = ""
+	// Honour the key log file of the browsers and curl
+	c.HTTP.TLSKeyLog = os.Getenv("SSLKEYLOGFILE")
+	c.HTTP.TLSMaxVersion = ""
+	c.HTTP.TLSMinVersion = ""
 	c.HTTP.Timeout = 10
 	c.HTTP.SNI = ""
 	c.HTTP.URL = ""
+	c.HTTP.UnixSocket = ""
+	c.Input.AutoExtensions = false
+	c.Input.AutoExtensionsList = ".php,.asp,.aspx,.jsp,.html,.htm,.js,.json,.txt,.xml,.bak,.old,.zip"
+	c.Input.Denylist = ""
+	c.Input.DenylistFile = ""
 	c.Input.DirSearchCompat = false
 	c.Input.Extensions = ""
+	c.Input.HexWordlists = ""
 	c.Input.IgnoreWordlistComments = false
+	c.Input.InputCommandMode = "input"
 	c.Input.InputMode = "clusterbomb"
 	c.Input.InputNum = 100
+	c.Input.OpenAPI = ""
+	c.Input.OriginIPs = ""
+	c.Input.PinnedInputs = ""
+	c.Input.Postman = ""
 	c.Input.Request = ""
 	c.Input.RequestProto = "https"
+	c.Input.Shard = ""
+	c.Input.ShardHash = false
+	c.Input.WSDL = ""
 	c.Matcher.Lines = ""
+	c.Matcher.HeaderRegexp = ""
+	c.Matcher.JSONKeys = ""
+	c.Matcher.Mime = ""
 	c.Matcher.Regexp = ""
 	c.Matcher.Size = ""
 	c.Matcher.Status = "200,204,301,302,307,401,403,405"
+	c.Matcher.Tags = ""
 	c.Matcher.Time = ""
 	c.Matcher.Words = ""
+	c.Output.AppendOutput = false
 	c.Output.DebugLog = ""
+	c.Output.Diff = ""
+	c.Output.GroupDirectories = false
+	c.Output.JSONFields = ""
 	c.Output.OutputDirectory = ""
 	c.Output.OutputFile = ""
 	c.Output.OutputFormat = "json"
+	c.Output.OutputFsync = false
 	c.Output.OutputSkipEmptyFile = false
+	c.Output.Pcap = ""
+	c.Output.PostProcess = ""
+	c.Output.FilterStats = false
+	c.Output.WordlistStats = false
+	c.Output.StatusMatrix = false
+	c.Output.SummaryJSON = false
+	c.Output.ProgressJSON = ""
+	c.Output.TimeFormat = ""
+	c.Output.TimeZone = ""
+	c.Output.Webhook = ""
+	c.Output.WebhookBatch = 10
+	c.Output.WebhookEvents = NOTIFY_EVENT_RESULTS
+	c.Output.WebhookTemplate = "json"
 	return c
 }
 
@@ -165,8 +383,8 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 
 	var err error
 	var err2 error
-	if len(parseOpts.HTTP.URL) == 0 && parseOpts.Input.Request == "" {
-		errs.Add(fmt.Errorf("-u flag or -request flag is required"))
+	if len(parseOpts.HTTP.URL) == 0 && parseOpts.Input.Request == "" && parseOpts.Input.OpenAPI == "" && parseOpts.Input.Postman == "" && parseOpts.Input.WSDL == "" {
+		errs.Add(fmt.Errorf("-u flag, -request flag, -openapi flag, -postman flag or -wsdl flag is required"))
 	}
 
 	// prepare extensions
@@ -174,6 +392,54 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 		extensions := strings.Split(parseOpts.Input.Extensions, ",")
 		conf.Extensions = extensions
 	}
+	if parseOpts.Input.HexWordlists != "" {
+		for _, k := range strings.Split(parseOpts.Input.HexWordlists, ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				conf.HexWordlists = append(conf.HexWordlists, k)
+			}
+		}
+	}
+	// Prepare the denylist. Errors are returned right away, as fuzzing without the intended denylist is not safe
+	denyPatterns := make([]string, 0)
+	if parseOpts.Input.Denylist != "" {
+		for _, p := range strings.Split(parseOpts.Input.Denylist, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				denyPatterns = append(denyPatterns, p)
+			}
+		}
+	}
+	if parseOpts.Input.DenylistFile != "" {
+		patterns, err := readDenylistFile(parseOpts.Input.DenylistFile)
+		if err != nil {
+			return &conf, err
+		}
+		denyPatterns = append(denyPatterns, patterns...)
+	}
+	conf.SafeMode = parseOpts.General.Safe
+	if parseOpts.General.SafeAllow != "" {
+		for _, a := range strings.Split(parseOpts.General.SafeAllow, ",") {
+			if a = strings.TrimSpace(a); a != "" {
+				conf.SafeAllow = append(conf.SafeAllow, a)
+			}
+		}
+	}
+	if conf.SafeMode && !conf.safeAllowPayloads() {
+		denyPatterns = append(denyPatterns, safeDenyPatterns...)
+	}
+	if len(denyPatterns) > 0 {
+		conf.Denylist, err = NewDenylist(denyPatterns)
+		if err != nil {
+			return &conf, err
+		}
+	}
+	if parseOpts.Input.AutoExtensions {
+		conf.AutoExtensions = true
+		for _, ext := range strings.Split(parseOpts.Input.AutoExtensionsList, ",") {
+			if ext = strings.TrimSpace(ext); ext != "" {
+				conf.AutoExtensionCandidates = append(conf.AutoExtensionCandidates, ext)
+			}
+		}
+	}
 
 	// Convert cookies to a header
 	if len(parseOpts.HTTP.Cookies) > 0 {
@@ -183,6 +449,35 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 	//Prepare inputproviders
 	for _, v := range parseOpts.Input.Wordlists {
 		var wl []string
+		if InlineWordlist(v) {
+			v = LIST_PREFIX + v
+		}
+		if strings.HasPrefix(v, RANGE_PREFIX) {
+			// The range is generated internally, there's no file to read. An invalid range would leave the keyword
+			// without inputs, so the error is returned right away.
+			_, keyword, err := ParseInputRange(v)
+			if err != nil {
+				return &conf, err
+			}
+			conf.InputProviders = append(conf.InputProviders, InputProviderConfig{
+				Name:    "range",
+				Value:   v,
+				Keyword: keyword,
+			})
+			continue
+		}
+		if strings.HasPrefix(v, LIST_PREFIX) {
+			_, keyword, err := ParseInputList(v)
+			if err != nil {
+				return &conf, err
+			}
+			conf.InputProviders = append(conf.InputProviders, InputProviderConfig{
+				Name:    "list",
+				Value:   v,
+				Keyword: keyword,
+			})
+			continue
+		}
 		if runtime.GOOS == "windows" {
 			// Try to ensure that Windows file paths like C:\path\to\wordlist.txt:KEYWORD are treated properly
 			if FileExists(v) {
@@ -218,6 +513,14 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 			})
 		}
 	}
+	// The transformations change every input of the keyword, so the errors are returned right away
+	for _, v := range parseOpts.Input.Transforms {
+		keyword, stages, err := ParseInputTransform(v)
+		if err != nil {
+			return &conf, err
+		}
+		conf.InputTransforms[keyword] = append(conf.InputTransforms[keyword], stages...)
+	}
 	for _, v := range parseOpts.Input.Inputcommands {
 		ic := strings.SplitN(v, ":", 2)
 		if len(ic) == 2 {
@@ -237,16 +540,35 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 		}
 	}
 
+	// Candidate origin IPs are fed through their own keyword
+	if parseOpts.Input.OriginIPs != "" {
+		conf.OriginIPs = parseOpts.Input.OriginIPs
+		conf.InputProviders = append(conf.InputProviders, InputProviderConfig{
+			Name:    "wordlist",
+			Value:   parseOpts.Input.OriginIPs,
+			Keyword: ORIGIN_KEYWORD,
+		})
+	}
+
 	if len(conf.InputProviders) == 0 {
 		errs.Add(fmt.Errorf("Either -w or --input-cmd flag is required"))
 	}
+	if parseOpts.Input.PinnedInputs != "" {
+		if keywords := conf.InputKeywords(); len(keywords) != 1 || keywords[0] != "FUZZ" {
+			errs.Add(fmt.Errorf("Pinned inputs (-pin-inputs) need FUZZ as the only input keyword"))
+		}
+		conf.PinnedInputs, err = readPinnedInputs(parseOpts.Input.PinnedInputs)
+		if err != nil {
+			errs.Add(err)
+		}
+	}
 
 	// Prepare the request using body
 	if parseOpts.Input.Request != "" {
+		// The whole request template comes from the file, nothing sensible can be sent without it
 		err := parseRawRequest(parseOpts, &conf)
 		if err != nil {
-			errmsg := fmt.Sprintf("Could not parse raw request: %s", err)
-			errs.Add(fmt.Errorf(errmsg))
+			return &conf, fmt.Errorf("Could not parse raw request: %s", err)
 		}
 	}
 
@@ -255,36 +577,76 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 		conf.Url = parseOpts.HTTP.URL
 	}
 
+	// Prepare the request templates from API definition
+	imports := 0
+	for _, i := range []string{parseOpts.Input.OpenAPI, parseOpts.Input.Postman, parseOpts.Input.WSDL} {
+		if i != "" {
+			imports++
+		}
+	}
+	if imports > 1 {
+		errs.Add(fmt.Errorf("Only one of -openapi, -postman and -wsdl can be used at a time"))
+	} else if parseOpts.Input.OpenAPI != "" {
+		err := parseOpenAPI(parseOpts, &conf)
+		if err != nil {
+			errs.Add(fmt.Errorf("Could not parse API definition: %s", err))
+		}
+	} else if parseOpts.Input.Postman != "" {
+		if err := parsePostman(parseOpts, &conf); err != nil {
+			errs.Add(fmt.Errorf("Could not parse Postman collection: %s", err))
+		}
+	} else if parseOpts.Input.WSDL != "" {
+		if err := parseWSDL(parseOpts, &conf); err != nil {
+			errs.Add(fmt.Errorf("Could not parse WSDL document: %s", err))
+		}
+	}
+
 	// Prepare SNI
 	if parseOpts.HTTP.SNI != "" {
 		conf.SNI = parseOpts.HTTP.SNI
 	}
 
+	// Prepare DNS resolvers
+	if parseOpts.HTTP.Resolvers != "" {
+		for _, r := range strings.Split(parseOpts.HTTP.Resolvers, ",") {
+			r = strings.TrimSpace(r)
+			if _, _, err := net.SplitHostPort(r); err != nil {
+				// No port defined
+				r = net.JoinHostPort(r, "53")
+			}
+			conf.Resolvers = append(conf.Resolvers, r)
+		}
+	}
+
+	// Prepare the fixed addresses of the hosts, and the Unix domain socket replacing the TCP connections
+	for _, r := range parseOpts.HTTP.Resolve {
+		hs := strings.SplitN(r, ":", 2)
+		if len(hs) != 2 || len(strings.TrimSpace(hs[0])) == 0 || net.ParseIP(strings.Trim(strings.TrimSpace(hs[1]), "[]")) == nil {
+			errs.Add(fmt.Errorf("Host address override (-resolve) needs to be HOST:IP, got %s", r))
+			continue
+		}
+		conf.Resolve[strings.ToLower(strings.TrimSpace(hs[0]))] = strings.Trim(strings.TrimSpace(hs[1]), "[]")
+	}
+	if len(parseOpts.HTTP.UnixSocket) > 0 {
+		if len(parseOpts.HTTP.ProxyURL) > 0 || len(parseOpts.HTTP.ProxyList) > 0 || len(parseOpts.Input.OriginIPs) > 0 || len(conf.Resolve) > 0 {
+			errs.Add(fmt.Errorf("Unix domain socket (-unix) cannot be used with a proxy, -origin-ips or -resolve"))
+		} else if fi, err := os.Stat(parseOpts.HTTP.UnixSocket); err != nil || fi.Mode()&os.ModeSocket == 0 {
+			errs.Add(fmt.Errorf("Unix domain socket (-unix) %s is not a socket", parseOpts.HTTP.UnixSocket))
+		} else {
+			conf.UnixSocket = parseOpts.HTTP.UnixSocket
+		}
+	}
+
 	//Prepare headers and make canonical
 	for _, v := range parseOpts.HTTP.Headers {
 		hs := strings.SplitN(v, ":", 2)
 		if len(hs) == 2 {
-			// trim and make canonical
-			// except if used in custom defined header
-			var CanonicalNeeded = true
-			for _, a := range conf.CommandKeywords {
-				if strings.Contains(hs[0], a) {
-					CanonicalNeeded = false
-				}
-			}
-			// check if part of InputProviders
-			if CanonicalNeeded {
-				for _, b := range conf.InputProviders {
-					if strings.Contains(hs[0], b.Keyword) {
-						CanonicalNeeded = false
-					}
-				}
-			}
-			if CanonicalNeeded {
-				var CanonicalHeader = textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(hs[0]))
-				conf.Headers[CanonicalHeader] = strings.TrimSpace(hs[1])
+			name := headerName(hs[0], &conf)
+			if name == "Cookie" && parseOpts.Input.Request != "" && conf.Headers[name] != "" {
+				// Cookies (-b) are added to the ones of the raw request
+				conf.Headers[name] += "; " + strings.TrimSpace(hs[1])
 			} else {
-				conf.Headers[strings.TrimSpace(hs[0])] = strings.TrimSpace(hs[1])
+				conf.Headers[name] = strings.TrimSpace(hs[1])
 			}
 		} else {
 			errs.Add(fmt.Errorf("Header defined by -H needs to have a value. \":\" should be used as a separator"))
@@ -314,13 +676,98 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 
 	// Verify proxy url format
 	if len(parseOpts.HTTP.ProxyURL) > 0 {
-		_, err := url.Parse(parseOpts.HTTP.ProxyURL)
+		err := checkProxyURL(parseOpts.HTTP.ProxyURL)
 		if err != nil {
 			errs.Add(fmt.Errorf("Bad proxy url (-x) format: %s", err))
 		} else {
 			conf.ProxyURL = parseOpts.HTTP.ProxyURL
 		}
 	}
+	for _, b := range strings.Split(parseOpts.HTTP.ProxyBackup, ",") {
+		if b = strings.TrimSpace(b); b == "" {
+			continue
+		}
+		if err := checkProxyURL(b); err != nil {
+			errs.Add(fmt.Errorf("Bad backup proxy url (-proxy-backup) format: %s", err))
+		} else {
+			conf.ProxyBackups = append(conf.ProxyBackups, b)
+		}
+	}
+	if len(conf.ProxyBackups) > 0 && len(conf.ProxyURL) == 0 {
+		errs.Add(fmt.Errorf("Backup proxies (-proxy-backup) need a proxy (-x) to back up"))
+	}
+	conf.ProxyFallback = strings.ToLower(parseOpts.HTTP.ProxyFallback)
+	if conf.ProxyFallback == "" {
+		conf.ProxyFallback = PROXY_FALLBACK_FAIL
+	}
+	if conf.ProxyFallback != PROXY_FALLBACK_FAIL && conf.ProxyFallback != PROXY_FALLBACK_DIRECT {
+		errs.Add(fmt.Errorf("Unknown proxy fallback (-proxy-fallback): %s. Available values: %s, %s", conf.ProxyFallback, PROXY_FALLBACK_FAIL, PROXY_FALLBACK_DIRECT))
+	}
+	if len(parseOpts.HTTP.ProxyList) > 0 {
+		proxies, err := readProxyListFile(parseOpts.HTTP.ProxyList)
+		if err != nil {
+			errs.Add(fmt.Errorf("Proxy list (-proxy-list): %s", err))
+		}
+		for _, p := range proxies {
+			if err := checkProxyURL(p); err != nil {
+				errs.Add(fmt.Errorf("Bad proxy url in the proxy list (-proxy-list) %s: %s", p, err))
+			} else {
+				conf.ProxyList = append(conf.ProxyList, p)
+			}
+		}
+		if err == nil && len(proxies) == 0 {
+			errs.Add(fmt.Errorf("Proxy list (-proxy-list) %s has no proxies", parseOpts.HTTP.ProxyList))
+		}
+		if len(conf.ProxyBackups) > 0 {
+			errs.Add(fmt.Errorf("Backup proxies (-proxy-backup) cannot be used together with rotating proxies (-proxy-list)"))
+		}
+	}
+	conf.ProxyRotate = strings.ToLower(parseOpts.HTTP.ProxyRotate)
+	if conf.ProxyRotate != PROXY_ROTATE_ROUNDROBIN && conf.ProxyRotate != PROXY_ROTATE_RANDOM {
+		errs.Add(fmt.Errorf("Unknown proxy rotation (-proxy-rotate): %s. Available values: %s, %s", conf.ProxyRotate, PROXY_ROTATE_ROUNDROBIN, PROXY_ROTATE_RANDOM))
+	}
+	if len(conf.ProxyList) > 0 {
+		// The proxy (-x), if any, joins the rotation
+		proxies := conf.ProxyList
+		if len(conf.ProxyURL) > 0 {
+			proxies = append([]string{conf.ProxyURL}, proxies...)
+		}
+		pool, err := NewRotatingProxyPool(proxies, conf.ProxyRotate, conf.ProxyFallback)
+		if err == nil {
+			conf.ProxyPool = pool
+		}
+	} else if len(conf.ProxyURL) > 0 {
+		pool, err := NewProxyPool(append([]string{conf.ProxyURL}, conf.ProxyBackups...), conf.ProxyFallback)
+		if err == nil {
+			conf.ProxyPool = pool
+		}
+	}
+
+	// Prepare the TLS options. A client certificate or a TLS setting that cannot be used would make every request
+	// fail, so the errors are returned right away.
+	conf.ClientCert = parseOpts.HTTP.ClientCert
+	conf.ClientKey = parseOpts.HTTP.ClientKey
+	conf.CACert = parseOpts.HTTP.CACert
+	conf.TLSMinVersion = parseOpts.HTTP.TLSMinVersion
+	conf.TLSMaxVersion = parseOpts.HTTP.TLSMaxVersion
+	conf.TLSKeyLogFile = parseOpts.HTTP.TLSKeyLog
+	for _, c := range strings.Split(parseOpts.HTTP.TLSCiphers, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			conf.TLSCiphers = append(conf.TLSCiphers, c)
+		}
+	}
+	if conf.ClientKey != "" && conf.ClientCert == "" {
+		return &conf, fmt.Errorf("Client key (-ck) needs a client certificate (-cc)")
+	}
+	if _, err := NewTLSConfig(&conf); err != nil {
+		return &conf, err
+	}
+
+	if parseOpts.HTTP.Auth != "" {
+		if err := parseAuth(parseOpts.HTTP.Auth, &conf); err != nil {
+			errs.Add(err)
+		}
+	}
 
 	// Verify replayproxy url format
 	if len(parseOpts.HTTP.ReplayProxyURL) > 0 {
@@ -331,11 +778,15 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 			conf.ReplayProxyURL = parseOpts.HTTP.ReplayProxyURL
 		}
 	}
+	conf.ReplayDir = parseOpts.HTTP.ReplayDir
+	if (len(conf.ReplayDir) > 0 || len(parseOpts.HTTP.ReplayMatch) > 0) && len(conf.ReplayProxyURL) == 0 {
+		errs.Add(fmt.Errorf("Replay matchers (-replay-match) and recording (-replay-dir) need a replay proxy (-replay-proxy)"))
+	}
 
 	//Check the output file format option
 	if parseOpts.Output.OutputFile != "" {
 		//No need to check / error out if output file isn't defined
-		outputFormats := []string{"all", "json", "ejson", "html", "md", "csv", "ecsv"}
+		outputFormats := []string{"all", "json", "ejson", "html", "md", "csv", "ecsv", "sqlite", "ndjson"}
 		found := false
 		for _, f := range outputFormats {
 			if f == parseOpts.Output.OutputFormat {
@@ -347,21 +798,37 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 			errs.Add(fmt.Errorf("Unknown output file format (-of): %s", parseOpts.Output.OutputFormat))
 		}
 	}
+	if parseOpts.Output.AppendOutput && (parseOpts.Output.OutputFile == "" || (parseOpts.Output.OutputFormat != "json" && parseOpts.Output.OutputFormat != "ejson")) {
+		errs.Add(fmt.Errorf("Appending to the output file (-append-output) needs an output file (-o) in the json or ejson format (-of)"))
+	}
+	conf.AppendOutput = parseOpts.Output.AppendOutput
 
 	// Auto-calibration strings
 	if len(parseOpts.General.AutoCalibrationStrings) > 0 {
 		conf.AutoCalibrationStrings = parseOpts.General.AutoCalibrationStrings
 	}
-	// Using -acc implies -ac
-	if len(parseOpts.General.AutoCalibrationStrings) > 0 {
-		conf.AutoCalibration = true
-	}
 
 	if parseOpts.General.Rate < 0 {
 		conf.Rate = 0
 	} else {
 		conf.Rate = int64(parseOpts.General.Rate)
 	}
+	switch parseOpts.General.RateMode {
+	case RATE_MODE_FIXED, RATE_MODE_ADAPTIVE:
+		conf.RateMode = parseOpts.General.RateMode
+	default:
+		errs.Add(fmt.Errorf("Rate mode (-rate-mode) needs to be one of: %s, %s", RATE_MODE_FIXED, RATE_MODE_ADAPTIVE))
+	}
+	if parseOpts.General.RateAdaptive {
+		// -rate-adaptive is the shorthand of -rate-mode adaptive
+		conf.RateMode = RATE_MODE_ADAPTIVE
+	}
+	conf.RateAdaptive = conf.RateMode == RATE_MODE_ADAPTIVE
+	conf.RobotsDelay = parseOpts.General.RobotsDelay
+	if parseOpts.General.RobotsDelayMax < 0 {
+		errs.Add(fmt.Errorf("Maximum robots.txt Crawl-delay (-robots-delay-max) cannot be negative"))
+	}
+	conf.RobotsDelayMax = parseOpts.General.RobotsDelayMax
 
 	if conf.Method == "" {
 		if parseOpts.HTTP.Method == "" {
@@ -388,28 +855,311 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 	conf.IgnoreWordlistComments = parseOpts.Input.IgnoreWordlistComments
 	conf.DirSearchCompat = parseOpts.Input.DirSearchCompat
 	conf.Colors = parseOpts.General.Colors
+	for _, c := range strings.Split(parseOpts.General.Columns, ",") {
+		c = strings.ToLower(strings.TrimSpace(c))
+		if c == "" {
+			continue
+		}
+		known := false
+		for _, tc := range TerminalColumns {
+			known = known || tc == c
+		}
+		if !known {
+			errs.Add(fmt.Errorf("Unknown result column (-columns): %s. Available columns: %s", c, strings.Join(TerminalColumns, ", ")))
+			continue
+		}
+		conf.Columns = append(conf.Columns, c)
+	}
 	conf.InputNum = parseOpts.Input.InputNum
+	conf.InputCommandMode = strings.ToLower(parseOpts.Input.InputCommandMode)
+	if conf.InputCommandMode == INPUT_COMMAND_STREAM || conf.InputCommandMode == INPUT_COMMAND_BATCH {
+		// The command output is split to plain inputs, which are displayed as they are instead of their position
+		conf.CommandKeywords = make([]string, 0)
+	}
 	conf.InputMode = parseOpts.Input.InputMode
+	if parseOpts.Input.Shard != "" {
+		conf.Shard, conf.ShardCount, err = parseShard(parseOpts.Input.Shard)
+		if err != nil {
+			errs.Add(err)
+		}
+	}
+	conf.ShardHash = parseOpts.Input.ShardHash
+	if conf.ShardHash && conf.ShardCount == 0 {
+		errs.Add(fmt.Errorf("Input hash sharding (-shard-hash) needs the shard to be defined with -shard"))
+	}
 	conf.InputShell = parseOpts.Input.InputShell
 	conf.OutputFile = parseOpts.Output.OutputFile
+	if parseOpts.Output.JSONFields != "" {
+		selected := make(map[string]bool)
+		for _, f := range JSONFields {
+			selected[f] = false
+		}
+		for _, f := range strings.Split(parseOpts.Output.JSONFields, ",") {
+			f = strings.ToLower(strings.TrimSpace(f))
+			if f == "" {
+				continue
+			}
+			if _, ok := selected[f]; !ok {
+				errs.Add(fmt.Errorf("Unknown json output field (-json-fields): %s. Available fields: %s", f, strings.Join(JSONFields, ", ")))
+				continue
+			}
+			selected[f] = true
+		}
+		// The fields are written in the order of JSONFields
+		conf.JSONFields = make([]string, 0)
+		for _, f := range JSONFields {
+			if selected[f] {
+				conf.JSONFields = append(conf.JSONFields, f)
+			}
+		}
+	}
 	conf.OutputDirectory = parseOpts.Output.OutputDirectory
+	conf.OutputFsync = parseOpts.Output.OutputFsync
 	conf.OutputSkipEmptyFile = parseOpts.Output.OutputSkipEmptyFile
+	conf.PcapFile = parseOpts.Output.Pcap
+	conf.FilterStats = parseOpts.Output.FilterStats
+	conf.DiffFile = parseOpts.Output.Diff
+	conf.WordlistStats = parseOpts.Output.WordlistStats
+	conf.StatusMatrix = parseOpts.Output.StatusMatrix
+	conf.GroupDirectories = parseOpts.Output.GroupDirectories
+	conf.SummaryJSON = parseOpts.Output.SummaryJSON
+	if parseOpts.Output.ProgressJSON != "" {
+		if !IsProgressJSONTarget(parseOpts.Output.ProgressJSON) {
+			errs.Add(fmt.Errorf("Progress stream (-progress-json) must be \"stderr\" or the number of an open file descriptor, got: %s", parseOpts.Output.ProgressJSON))
+		}
+		conf.ProgressJSON = parseOpts.Output.ProgressJSON
+	}
+	conf.TimeFormat = parseOpts.Output.TimeFormat
+	conf.TimeZone = parseOpts.Output.TimeZone
+	if conf.TimeZone != "" {
+		conf.TimeLocation, err = time.LoadLocation(conf.TimeZone)
+		if err != nil {
+			errs.Add(fmt.Errorf("Timezone (-timezone) %s not recognized: %s", conf.TimeZone, err))
+		}
+	}
+	conf.WebhookURL = parseOpts.Output.Webhook
+	conf.WebhookTemplate = parseOpts.Output.WebhookTemplate
+	conf.WebhookBatch = parseOpts.Output.WebhookBatch
+	if conf.WebhookBatch < 1 {
+		errs.Add(fmt.Errorf("Webhook batch size (-webhook-batch) needs to be at least 1"))
+	}
+	for _, p := range strings.Split(parseOpts.Output.PostProcess, ",") {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		if _, err := newResultProcessors([]string{p}); err != nil {
+			errs.Add(err)
+			continue
+		}
+		conf.PostProcess = append(conf.PostProcess, p)
+	}
+	conf.WebhookEvents = make([]string, 0)
+	for _, e := range strings.Split(parseOpts.Output.WebhookEvents, ",") {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if e == "" {
+			continue
+		}
+		if pct, err := strconv.Atoi(strings.TrimSuffix(e, "%")); err == nil {
+			if pct < 1 || pct > 100 {
+				errs.Add(fmt.Errorf("Webhook progress milestone (-webhook-events) needs to be a percentage between 1 and 100: %s", e))
+			} else {
+				conf.WebhookMilestones = append(conf.WebhookMilestones, pct)
+			}
+			continue
+		}
+		if e != NOTIFY_EVENT_RESULTS && e != NOTIFY_EVENT_JOB && e != NOTIFY_EVENT_STOP {
+			errs.Add(fmt.Errorf("Unknown webhook event (-webhook-events): %s. Available events: %s, %s, %s or a percentage of progress", e, NOTIFY_EVENT_RESULTS, NOTIFY_EVENT_JOB, NOTIFY_EVENT_STOP))
+			continue
+		}
+		conf.WebhookEvents = append(conf.WebhookEvents, e)
+	}
+	if len(conf.WebhookMilestones) > 0 {
+		conf.WebhookEvents = append(conf.WebhookEvents, NOTIFY_EVENT_PROGRESS)
+	}
 	conf.IgnoreBody = parseOpts.HTTP.IgnoreBody
+	conf.FastSize = parseOpts.HTTP.FastSize
+	conf.MaxBodySize = parseOpts.HTTP.MaxBodySize
+	if conf.MaxBodySize < 1 {
+		errs.Add(fmt.Errorf("Maximum body size (-max-body-size) needs to be at least 1 byte"))
+	}
+	conf.BodyMemory = parseOpts.HTTP.BodyMemory
+	if conf.BodyMemory < 0 {
+		errs.Add(fmt.Errorf("Body memory budget (-body-memory) cannot be negative"))
+	}
+	conf.BodyStore = NewBodyStore(conf.MaxBodySize, int64(conf.BodyMemory)*1024*1024)
+	conf.Http2 = parseOpts.HTTP.Http2
+	conf.Http2PriorKnowledge = parseOpts.HTTP.Http2PriorKnowledge
+	// Reusing the connections, or opening them in advance, needs them to be kept alive
+	conf.ConnReuse = parseOpts.HTTP.ConnReuse
+	conf.KeepAlive = parseOpts.HTTP.KeepAlive || conf.ConnReuse || parseOpts.HTTP.Prewarm > 0
+	if parseOpts.HTTP.MaxIdleConns < 0 || parseOpts.HTTP.Prewarm < 0 {
+		errs.Add(fmt.Errorf("Idle connections (-max-idle) and pre-warmed connections (-prewarm) cannot be negative"))
+	} else {
+		conf.MaxIdleConns = parseOpts.HTTP.MaxIdleConns
+		conf.Prewarm = parseOpts.HTTP.Prewarm
+	}
+	if parseOpts.HTTP.IPThreads < 0 || parseOpts.HTTP.IPRate < 0 {
+		errs.Add(fmt.Errorf("Per IP threads (-ip-threads) and rate (-ip-rate) cannot be negative"))
+	} else {
+		conf.IPThreads = parseOpts.HTTP.IPThreads
+		conf.IPRate = int64(parseOpts.HTTP.IPRate)
+	}
+	if parseOpts.HTTP.Retries < 0 || parseOpts.HTTP.RetryDelay < 0 {
+		errs.Add(fmt.Errorf("Retries (-retries) and retry delay (-retry-delay) cannot be negative"))
+	} else {
+		conf.Retries = parseOpts.HTTP.Retries
+		conf.RetryDelay = parseOpts.HTTP.RetryDelay
+	}
+	if retryStatus, err := parseRetryStatus(parseOpts.HTTP.RetryOn); err != nil {
+		errs.Add(err)
+	} else {
+		conf.RetryStatus = retryStatus
+	}
+	if actions, err := parseStatusActions(parseOpts.General.StatusActions); err != nil {
+		errs.Add(err)
+	} else {
+		conf.StatusActions = actions
+	}
+	if len(parseOpts.General.WaitFor) > 0 {
+		if u, err := url.Parse(parseOpts.General.WaitFor); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			errs.Add(fmt.Errorf("Readiness check URL (-wait-for) needs to be an http or https URL"))
+		}
+		if parseOpts.General.WaitForInterval < 1 || parseOpts.General.WaitForTimeout < 1 {
+			errs.Add(fmt.Errorf("Readiness check interval (-wait-for-interval) and timeout (-wait-for-timeout) need to be at least 1 second"))
+		}
+		if _, err := regexp.Compile(parseOpts.General.WaitForRegex); err != nil {
+			errs.Add(fmt.Errorf("Readiness check regexp (-wait-for-regex) is invalid: %s", err))
+		}
+	}
+	conf.WaitFor = parseOpts.General.WaitFor
+	conf.WaitForInterval = parseOpts.General.WaitForInterval
+	conf.WaitForRegex = parseOpts.General.WaitForRegex
+	conf.WaitForTimeout = parseOpts.General.WaitForTimeout
 	conf.Quiet = parseOpts.General.Quiet
 	conf.StopOn403 = parseOpts.General.StopOn403
 	conf.StopOnAll = parseOpts.General.StopOnAll
 	conf.StopOnErrors = parseOpts.General.StopOnErrors
+	if parseOpts.General.HostErrors < 0 {
+		errs.Add(fmt.Errorf("Host error threshold (-host-errors) cannot be negative"))
+	} else {
+		conf.HostErrors = parseOpts.General.HostErrors
+	}
+	conf.DedupRequests = parseOpts.General.DedupRequests
+	if len(parseOpts.General.Import) > 0 && len(parseOpts.HTTP.ReplayProxyURL) == 0 && !parseOpts.HTTP.Recursion {
+		errs.Add(fmt.Errorf("Imported results (-import) need -replay-proxy to replay them or -recursion to recurse into their directories"))
+	}
+	conf.ImportFile = parseOpts.General.Import
+	for _, r := range parseOpts.General.StopRules {
+		rule, err := ParseStopRule(r)
+		if err != nil {
+			errs.Add(err)
+			continue
+		}
+		conf.StopRules = append(conf.StopRules, rule)
+	}
 	conf.FollowRedirects = parseOpts.HTTP.FollowRedirects
+	if parseOpts.HTTP.GRPCReflection {
+		target := strings.ToLower(parseOpts.HTTP.URL)
+		if !strings.HasPrefix(target, "grpc://") && !strings.HasPrefix(target, "grpcs://") {
+			errs.Add(fmt.Errorf("gRPC server reflection (-grpc-reflection) needs a grpc:// or grpcs:// target URL"))
+		} else if !strings.Contains(parseOpts.HTTP.URL, GRPC_SERVICE_KEYWORD) {
+			errs.Add(fmt.Errorf("gRPC server reflection (-grpc-reflection) needs the %s keyword in the target URL for the listed services", GRPC_SERVICE_KEYWORD))
+		}
+	}
+	conf.GRPCReflection = parseOpts.HTTP.GRPCReflection
 	conf.Recursion = parseOpts.HTTP.Recursion
+	conf.Crawl = parseOpts.HTTP.Crawl
+	conf.CrawlDepth = parseOpts.HTTP.CrawlDepth
+	conf.CrawlPages = parseOpts.HTTP.CrawlPages
+	conf.RecursionBreadth = parseOpts.HTTP.RecursionBreadth
 	conf.RecursionDepth = parseOpts.HTTP.RecursionDepth
+	conf.RecursionDepthBreadth = parseOpts.HTTP.RecursionDepthBreadth
+	conf.RecursionLinks = parseOpts.HTTP.RecursionLinks
+	if parseOpts.HTTP.RecursionFingerprint < 0 {
+		errs.Add(fmt.Errorf("Recursion fingerprint probes (-recursion-fingerprint) cannot be negative"))
+	} else {
+		conf.RecursionFingerprint = parseOpts.HTTP.RecursionFingerprint
+	}
 	conf.RecursionStrategy = parseOpts.HTTP.RecursionStrategy
+	if parseOpts.HTTP.RecursionMaxJobs < 0 {
+		errs.Add(fmt.Errorf("Maximum recursion jobs (-recursion-max-jobs) cannot be negative"))
+	} else {
+		conf.RecursionMaxJobs = parseOpts.HTTP.RecursionMaxJobs
+	}
+	if len(parseOpts.HTTP.RecursionInclude) > 0 {
+		if conf.RecursionInclude, err = regexp.Compile(parseOpts.HTTP.RecursionInclude); err != nil {
+			errs.Add(fmt.Errorf("Recursion include regexp (-recursion-include) is invalid: %s", err))
+		}
+	}
+	if len(parseOpts.HTTP.RecursionExclude) > 0 {
+		if conf.RecursionExclude, err = regexp.Compile(parseOpts.HTTP.RecursionExclude); err != nil {
+			errs.Add(fmt.Errorf("Recursion exclude regexp (-recursion-exclude) is invalid: %s", err))
+		}
+	}
+	if len(parseOpts.HTTP.RecursionTruncate) > 0 {
+		for _, v := range strings.Split(parseOpts.HTTP.RecursionTruncate, ",") {
+			limit, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil || limit < 0 {
+				errs.Add(fmt.Errorf("Recursion input limits (-recursion-truncate) need to be a comma separated list of numbers, got %s", parseOpts.HTTP.RecursionTruncate))
+				break
+			}
+			conf.RecursionInputLimits = append(conf.RecursionInputLimits, limit)
+		}
+	}
+	conf.RecursionWordlist = parseOpts.HTTP.RecursionWordlist
+	if conf.RecursionWordlist != "" {
+		if _, err := os.Stat(conf.RecursionWordlist); err != nil {
+			errs.Add(fmt.Errorf("Recursion wordlist (-recursion-wordlist) could not be read: %s", err))
+		}
+	}
 	conf.AutoCalibration = parseOpts.General.AutoCalibration
+	conf.AutoCalibrationPerHost = parseOpts.General.AutoCalibrationPerHost
+	conf.AutoCalibrationStrategy = strings.ToLower(parseOpts.General.AutoCalibrationStrategy)
+	if conf.AutoCalibrationStrategy == "" {
+		conf.AutoCalibrationStrategy = CALIBRATION_BASIC
+	}
+	validStrategy := false
+	for _, s := range CalibrationStrategies {
+		if conf.AutoCalibrationStrategy == s {
+			validStrategy = true
+		}
+	}
+	if !validStrategy {
+		errs.Add(fmt.Errorf("Unknown calibration strategy (-acs): %s. Available strategies: %s", conf.AutoCalibrationStrategy, strings.Join(CalibrationStrategies, ", ")))
+	}
+	for _, v := range parseOpts.General.AutoCalibrationKeywords {
+		keyword, templates, err := parseCalibrationKeyword(v, conf.AutoCalibrationStrategy)
+		if err != nil {
+			errs.Add(err)
+			continue
+		}
+		conf.AutoCalibrationKeywords[keyword] = templates
+	}
+	// Using -acc, -ac-keyword, -ach or a calibration strategy other than the default implies -ac
+	if len(conf.AutoCalibrationStrings) > 0 || len(conf.AutoCalibrationKeywords) > 0 || conf.AutoCalibrationPerHost || conf.AutoCalibrationStrategy != CALIBRATION_BASIC {
+		conf.AutoCalibration = true
+	}
 	conf.Threads = parseOpts.General.Threads
 	conf.Timeout = parseOpts.HTTP.Timeout
 	conf.MaxTime = parseOpts.General.MaxTime
 	conf.MaxTimeJob = parseOpts.General.MaxTimeJob
 	conf.Noninteractive = parseOpts.General.Noninteractive
+	conf.ScraperDir = parseOpts.General.ScraperDir
+	conf.ScraperFile = parseOpts.General.ScraperFile
+	conf.Scrapers = parseOpts.General.Scrapers
 	conf.Verbose = parseOpts.General.Verbose
+	conf.UpdateCheck = parseOpts.General.UpdateCheck
+	conf.UpdateURL = parseOpts.General.UpdateURL
+	if conf.UpdateCheck && conf.UpdateURL == "" {
+		// The releases of upstream ffuf are of another release line, there is no default endpoint to compare to
+		errs.Add(fmt.Errorf("The update check (-update-check) requires the version endpoint of the releases to compare to (-update-url)"))
+	}
+
+	// HTTP/2 prior knowledge implies HTTP/2
+	if conf.Http2PriorKnowledge {
+		conf.Http2 = true
+	}
 
 	// Handle copy as curl situation where POST method is implied by --data flag. If method is set to anything but GET, NOOP
 	if len(conf.Data) > 0 &&
@@ -422,20 +1172,80 @@ func ConfigFromOptions(parseOpts *ConfigOptions, ctx context.Context, cancel con
 
 	conf.CommandLine = strings.Join(os.Args, " ")
 
+	if conf.InputMode == "sniper" {
+		// Nothing would be sent without the injection positions, return right away
+		if err := prepareSniper(&conf); err != nil {
+			return &conf, err
+		}
+	}
+
 	for _, provider := range conf.InputProviders {
+		if provider.Keyword == ORIGIN_KEYWORD || conf.InputMode == "sniper" {
+			// The origin IP is used for connecting to the target, not in the request itself. The sniper mode keyword
+			// is injected to the marked positions instead.
+			continue
+		}
 		if !keywordPresent(provider.Keyword, &conf) {
 			errmsg := fmt.Sprintf("Keyword %s defined, but not found in headers, method, URL or POST data.", provider.Keyword)
-			errs.Add(fmt.Errorf(errmsg))
+			errs.Add(fmt.Errorf("%s", errmsg))
+		}
+	}
+
+	validCommandMode := false
+	for _, m := range InputCommandModes {
+		if conf.InputCommandMode == m {
+			validCommandMode = true
+		}
+	}
+	if !validCommandMode {
+		errs.Add(fmt.Errorf("Unknown input command mode (-input-cmd-mode): %s. Available modes: %s", conf.InputCommandMode, strings.Join(InputCommandModes, ", ")))
+	}
+
+	for k := range conf.InputTransforms {
+		found := false
+		for _, provider := range conf.InputProviders {
+			if provider.Keyword == k {
+				found = true
+			}
+		}
+		if !found {
+			errs.Add(fmt.Errorf("Input transformation keyword %s (-transform) does not match any input", k))
+		}
+	}
+	for _, k := range conf.HexWordlists {
+		found := false
+		for _, provider := range conf.InputProviders {
+			if provider.Keyword == k && provider.Name == "wordlist" {
+				found = true
+			}
+		}
+		if !found {
+			errs.Add(fmt.Errorf("Hex encoded wordlist keyword %s (-hex-wordlist) does not match any wordlist (-w)", k))
 		}
 	}
 
+	if len(conf.OriginIPs) > 0 && conf.ProxyPool != nil {
+		errs.Add(fmt.Errorf("Origin IP hunting (-origin-ips) cannot be used together with a proxy (-x, -proxy-list)"))
+	}
+	// The connections are pooled by host, so a connection to one origin IP would be reused for the others
+	if len(conf.OriginIPs) > 0 && conf.KeepAlive {
+		errs.Add(fmt.Errorf("Origin IP hunting (-origin-ips) cannot be used together with the connections kept alive (-keep-alive, -conn-reuse, -prewarm)"))
+	}
+	if len(conf.OriginIPs) > 0 && (conf.AuthScheme == AUTH_NTLM || conf.AuthScheme == AUTH_NEGOTIATE) {
+		errs.Add(fmt.Errorf("Origin IP hunting (-origin-ips) cannot be used together with NTLM authentication (-auth), which needs the connections kept alive"))
+	}
+
 	// Do checks for recursion mode
 	if parseOpts.HTTP.Recursion {
 		if !strings.HasSuffix(conf.Url, "FUZZ") {
 			errmsg := "When using -recursion the URL (-u) must end with FUZZ keyword."
-			errs.Add(fmt.Errorf(errmsg))
+			errs.Add(fmt.Errorf("%s", errmsg))
 		}
 	}
+	// Safe mode violations are returned right away, like the denylist errors
+	if err := checkSafeMode(&conf); err != nil {
+		return &conf, err
+	}
 	return &conf, errs.ErrorOrNil()
 }
 
@@ -447,6 +1257,7 @@ func parseRawRequest(parseOpts *ConfigOptions, conf *Config) error {
 	defer file.Close()
 
 	r := bufio.NewReader(file)
+	chunked := false
 
 	s, err := r.ReadString('\n')
 	if err != nil {
@@ -475,8 +1286,23 @@ func parseRawRequest(parseOpts *ConfigOptions, conf *Config) error {
 		if strings.EqualFold(p[0], "content-length") {
 			continue
 		}
+		if strings.EqualFold(strings.TrimSpace(p[0]), "transfer-encoding") && strings.Contains(strings.ToLower(p[1]), "chunked") {
+			// The body is decoded below, and sent with the length of the request after the keywords are replaced
+			chunked = true
+			continue
+		}
 
-		conf.Headers[strings.TrimSpace(p[0])] = strings.TrimSpace(p[1])
+		name := headerName(p[0], conf)
+		value := strings.TrimSpace(p[1])
+		if prev, ok := conf.Headers[name]; ok {
+			// Repeated headers are combined, as only a single value is kept for each of them
+			if name == "Cookie" {
+				value = prev + "; " + value
+			} else {
+				value = prev + ", " + value
+			}
+		}
+		conf.Headers[name] = value
 	}
 
 	// Handle case with the full http url in path. In that case,
@@ -498,6 +1324,13 @@ func parseRawRequest(parseOpts *ConfigOptions, conf *Config) error {
 	if err != nil {
 		return fmt.Errorf("could not read request body: %s", err)
 	}
+	if chunked {
+		decoded, err := ioutil.ReadAll(httputil.NewChunkedReader(bytes.NewReader(b)))
+		if err != nil {
+			return fmt.Errorf("could not decode chunked request body: %s", err)
+		}
+		b = decoded
+	}
 	conf.Data = string(b)
 
 	// Remove newline (typically added by the editor) at the end of the file
@@ -509,6 +1342,23 @@ func parseRawRequest(parseOpts *ConfigOptions, conf *Config) error {
 	return nil
 }
 
+//headerName returns the header name in its canonical form, unless it contains an input keyword that would be changed
+//by the canonicalization
+func headerName(name string, conf *Config) string {
+	name = strings.TrimSpace(name)
+	for _, a := range conf.CommandKeywords {
+		if strings.Contains(name, a) {
+			return name
+		}
+	}
+	for _, b := range conf.InputProviders {
+		if strings.Contains(name, b.Keyword) {
+			return name
+		}
+	}
+	return textproto.CanonicalMIMEHeaderKey(name)
+}
+
 func keywordPresent(keyword string, conf *Config) bool {
 	//Search for keyword from HTTP method, URL and POST data too
 	if strings.Contains(conf.Method, keyword) {
@@ -528,6 +1378,12 @@ func keywordPresent(keyword string, conf *Config) bool {
 			return true
 		}
 	}
+	//Search from the imported request templates
+	for _, op := range conf.ApiOperations {
+		if templateHasKeyword(op, map[string]bool{keyword: true}) {
+			return true
+		}
+	}
 	return false
 }
 
@@ -0,0 +1,300 @@
+package ffuf
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	pcapngSectionHeader   = 0x0A0D0D0A
+	pcapngInterface       = 0x00000001
+	pcapngEnhancedPacket  = 0x00000006
+	pcapngByteOrderMagic  = 0x1A2B3C4D
+	pcapngOptComment      = 1
+	pcapngOptUserAppl     = 4
+	pcapLinkTypeRaw       = 101
+	pcapMaxSegment        = 65000
+	tcpFlagFin            = 0x01
+	tcpFlagSyn            = 0x02
+	tcpFlagPsh            = 0x08
+	tcpFlagAck            = 0x10
+	pcapKeyLogFileSuffix  = ".keylog"
+	pcapTCPWindow         = 65535
+	pcapIPv4HeaderLength  = 20
+	pcapIPv6HeaderLength  = 40
+	pcapTCPHeaderLength   = 20
+	pcapIPProtocolTCP     = 6
+	pcapDefaultTTL        = 64
+	pcapIPv4DontFragment  = 0x4000
+	pcapIPv6VersionHeader = 0x60000000
+)
+
+//PcapWriter captures the traffic of the runner connections to a pcapng file (-pcap). The packets are recorded from
+//the connections themselves, so no capture privileges are needed: the TCP/IP headers are synthesized from the
+//connection addresses, and the payload is what was sent and received, encrypted for TLS. The TLS session keys are
+//written to a key log file next to the capture, for decrypting it in Wireshark. The section header carries the scan
+//ID and the command line.
+type PcapWriter struct {
+	mutex  sync.Mutex
+	file   *os.File
+	w      *bufio.Writer
+	keylog *os.File
+	err    error
+}
+
+//NewPcapWriter creates the capture file and its TLS key log file
+func NewPcapWriter(filename string, conf *Config) (*PcapWriter, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the capture file: %s", err)
+	}
+	keylog, err := os.OpenFile(filename+pcapKeyLogFileSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("could not create the TLS key log file: %s", err)
+	}
+	p := &PcapWriter{file: f, w: bufio.NewWriter(f), keylog: keylog}
+	comment := fmt.Sprintf("ffuf scan %s: %s", conf.ScanID, conf.CommandLine)
+	p.writeBlock(pcapngSectionHeader, func(b []byte) []byte {
+		b = appendUint32(b, pcapngByteOrderMagic)
+		b = appendUint16(b, 1)
+		b = appendUint16(b, 0)
+		// Section length is not known
+		b = append(b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff)
+		b = appendOption(b, pcapngOptComment, []byte(comment))
+		b = appendOption(b, pcapngOptUserAppl, []byte("ffuf v"+Version()))
+		return appendUint32(b, 0)
+	})
+	p.writeBlock(pcapngInterface, func(b []byte) []byte {
+		b = appendUint16(b, pcapLinkTypeRaw)
+		b = appendUint16(b, 0)
+		return appendUint32(b, 0)
+	})
+	return p, p.err
+}
+
+//KeyLog returns the writer of the TLS session keys, for tls.Config.KeyLogWriter
+func (p *PcapWriter) KeyLog() io.Writer {
+	return p.keylog
+}
+
+//Close flushes the capture and closes the files
+func (p *PcapWriter) Close() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	err := p.w.Flush()
+	p.file.Close()
+	p.keylog.Close()
+	return err
+}
+
+//Wrap returns the connection with its traffic recorded to the capture
+func (p *PcapWriter) Wrap(conn net.Conn) net.Conn {
+	local, lok := conn.LocalAddr().(*net.TCPAddr)
+	remote, rok := conn.RemoteAddr().(*net.TCPAddr)
+	if !lok || !rok {
+		return conn
+	}
+	c := &pcapConn{Conn: conn, p: p, local: local, remote: remote, seqOut: rand.Uint32(), seqIn: rand.Uint32()}
+	// The handshake has already been made, record one for the stream to be complete
+	p.mutex.Lock()
+	c.segment(true, tcpFlagSyn, nil)
+	c.seqOut++
+	c.segment(false, tcpFlagSyn|tcpFlagAck, nil)
+	c.seqIn++
+	c.segment(true, tcpFlagAck, nil)
+	p.mutex.Unlock()
+	return c
+}
+
+//writeBlock writes a pcapng block with the body built by the function. The mutex needs to be held, except when
+//creating the file.
+func (p *PcapWriter) writeBlock(blockType uint32, body func([]byte) []byte) {
+	if p.err != nil {
+		return
+	}
+	b := body(make([]byte, 8, 128))
+	for len(b)%4 != 0 {
+		b = append(b, 0)
+	}
+	length := uint32(len(b) + 4)
+	binary.LittleEndian.PutUint32(b[0:], blockType)
+	binary.LittleEndian.PutUint32(b[4:], length)
+	b = appendUint32(b, length)
+	if _, err := p.w.Write(b); err != nil {
+		p.err = err
+		log.Printf("Stopped writing the capture file: %s", err)
+	}
+}
+
+type pcapConn struct {
+	net.Conn
+	p         *PcapWriter
+	local     *net.TCPAddr
+	remote    *net.TCPAddr
+	seqOut    uint32
+	seqIn     uint32
+	closeOnce sync.Once
+}
+
+func (c *pcapConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.record(false, b[:n])
+	}
+	return n, err
+}
+
+func (c *pcapConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		c.record(true, b[:n])
+	}
+	return n, err
+}
+
+func (c *pcapConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.p.mutex.Lock()
+		c.segment(true, tcpFlagFin|tcpFlagAck, nil)
+		c.seqOut++
+		c.segment(false, tcpFlagFin|tcpFlagAck, nil)
+		c.seqIn++
+		c.segment(true, tcpFlagAck, nil)
+		c.p.mutex.Unlock()
+	})
+	return c.Conn.Close()
+}
+
+//record writes the payload sent (out) or received to the capture, split to segments fitting in an IP packet
+func (c *pcapConn) record(out bool, payload []byte) {
+	c.p.mutex.Lock()
+	defer c.p.mutex.Unlock()
+	for len(payload) > 0 {
+		n := len(payload)
+		if n > pcapMaxSegment {
+			n = pcapMaxSegment
+		}
+		c.segment(out, tcpFlagPsh|tcpFlagAck, payload[:n])
+		if out {
+			c.seqOut += uint32(n)
+		} else {
+			c.seqIn += uint32(n)
+		}
+		payload = payload[n:]
+	}
+}
+
+//segment writes a TCP segment to the capture. The mutex of the writer needs to be held.
+func (c *pcapConn) segment(out bool, flags byte, payload []byte) {
+	src, dst := c.local, c.remote
+	seq, ack := c.seqOut, c.seqIn
+	if !out {
+		src, dst = c.remote, c.local
+		seq, ack = c.seqIn, c.seqOut
+	}
+	if flags&tcpFlagAck == 0 {
+		ack = 0
+	}
+	tcp := make([]byte, pcapTCPHeaderLength, pcapTCPHeaderLength+len(payload))
+	binary.BigEndian.PutUint16(tcp[0:], uint16(src.Port))
+	binary.BigEndian.PutUint16(tcp[2:], uint16(dst.Port))
+	binary.BigEndian.PutUint32(tcp[4:], seq)
+	binary.BigEndian.PutUint32(tcp[8:], ack)
+	tcp[12] = (pcapTCPHeaderLength / 4) << 4
+	tcp[13] = flags
+	binary.BigEndian.PutUint16(tcp[14:], pcapTCPWindow)
+	tcp = append(tcp, payload...)
+
+	var packet []byte
+	var pseudo []byte
+	if src.IP.To4() != nil && dst.IP.To4() != nil {
+		ip := make([]byte, pcapIPv4HeaderLength)
+		ip[0] = 0x45
+		binary.BigEndian.PutUint16(ip[2:], uint16(pcapIPv4HeaderLength+len(tcp)))
+		binary.BigEndian.PutUint16(ip[6:], pcapIPv4DontFragment)
+		ip[8] = pcapDefaultTTL
+		ip[9] = pcapIPProtocolTCP
+		copy(ip[12:], src.IP.To4())
+		copy(ip[16:], dst.IP.To4())
+		binary.BigEndian.PutUint16(ip[10:], internetChecksum(ip))
+		pseudo = append(append([]byte{}, ip[12:20]...), 0, pcapIPProtocolTCP)
+		pseudo = appendUint16BE(pseudo, uint16(len(tcp)))
+		packet = ip
+	} else {
+		ip := make([]byte, pcapIPv6HeaderLength)
+		binary.BigEndian.PutUint32(ip[0:], pcapIPv6VersionHeader)
+		binary.BigEndian.PutUint16(ip[4:], uint16(len(tcp)))
+		ip[6] = pcapIPProtocolTCP
+		ip[7] = pcapDefaultTTL
+		copy(ip[8:], src.IP.To16())
+		copy(ip[24:], dst.IP.To16())
+		pseudo = append([]byte{}, ip[8:40]...)
+		pseudo = append(pseudo, 0, 0)
+		pseudo = appendUint16BE(pseudo, uint16(len(tcp)))
+		pseudo = append(pseudo, 0, 0, 0, pcapIPProtocolTCP)
+		packet = ip
+	}
+	binary.BigEndian.PutUint16(tcp[16:], internetChecksum(append(pseudo, tcp...)))
+	packet = append(packet, tcp...)
+
+	us := uint64(time.Now().UnixNano() / int64(time.Microsecond))
+	c.p.writeBlock(pcapngEnhancedPacket, func(b []byte) []byte {
+		b = appendUint32(b, 0)
+		b = appendUint32(b, uint32(us>>32))
+		b = appendUint32(b, uint32(us))
+		b = appendUint32(b, uint32(len(packet)))
+		b = appendUint32(b, uint32(len(packet)))
+		b = append(b, packet...)
+		for len(b)%4 != 0 {
+			b = append(b, 0)
+		}
+		return b
+	})
+}
+
+//internetChecksum returns the checksum of IP and TCP headers (RFC 1071)
+func internetChecksum(b []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(b); i += 2 {
+		sum += uint32(b[i])<<8 | uint32(b[i+1])
+	}
+	if len(b)%2 == 1 {
+		sum += uint32(b[len(b)-1]) << 8
+	}
+	for sum > 0xffff {
+		sum = (sum >> 16) + (sum & 0xffff)
+	}
+	return ^uint16(sum)
+}
+
+//appendOption appends a pcapng option, padded to 32 bits
+func appendOption(b []byte, code uint16, value []byte) []byte {
+	b = appendUint16(b, code)
+	b = appendUint16(b, uint16(len(value)))
+	b = append(b, value...)
+	for len(b)%4 != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v), byte(v>>8))
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func appendUint16BE(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
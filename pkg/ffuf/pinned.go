@@ -0,0 +1,67 @@
+package ffuf
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+//readPinnedInputs reads the inputs sent in every queue job from a file, one per line
+func readPinnedInputs(filename string) ([]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Pinned inputs: could not read %s: %s", filename, err)
+	}
+	defer f.Close()
+	inputs := make([]string, 0)
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || seen[line] {
+			continue
+		}
+		seen[line] = true
+		inputs = append(inputs, line)
+	}
+	return inputs, scanner.Err()
+}
+
+//runPinnedInputs sends the pinned inputs (-pin-inputs) before the inputs of the queue job. They are not part of the
+//progress of the job, and the wordlist entries equal to them are skipped.
+func (j *Job) runPinnedInputs(limiter chan bool, wg *sync.WaitGroup) {
+	for _, p := range j.Config.PinnedInputs {
+		j.CheckStop()
+		if !j.Running || j.skipQueue {
+			return
+		}
+		input := map[string][]byte{"FUZZ": []byte(p)}
+		if j.Config.Denylist != nil && j.Config.Denylist.DeniedInput(input) {
+			continue
+		}
+		j.pauseWg.Wait()
+		limiter <- true
+		wg.Add(1)
+		go func() {
+			defer func() { <-limiter }()
+			defer wg.Done()
+			threadStart := time.Now()
+			j.runTask(input, 0, 0)
+			j.sleepIfNeeded()
+			j.Rate.Throttle()
+			j.Rate.Tick(threadStart, time.Now())
+		}()
+	}
+}
+
+//pinnedInput checks if the input was already sent as one of the pinned inputs
+func (j *Job) pinnedInput(input map[string][]byte) bool {
+	if len(j.pinned) == 0 {
+		return false
+	}
+	v, ok := input["FUZZ"]
+	return ok && j.pinned[string(v)]
+}
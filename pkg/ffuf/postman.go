@@ -0,0 +1,231 @@
+package ffuf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var postmanVariableRegexp = regexp.MustCompile(`{{\s*([^{}]+?)\s*}}`)
+
+type postmanCollection struct {
+	Info struct {
+		Schema string `json:"schema"`
+	} `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Variable []postmanKeyValue `json:"variable"`
+}
+
+type postmanItem struct {
+	Name    string          `json:"name"`
+	Item    []postmanItem   `json:"item"`
+	Request *postmanRequest `json:"request"`
+}
+
+type postmanRequest struct {
+	Method string            `json:"method"`
+	Header []postmanKeyValue `json:"header"`
+	Url    postmanUrl        `json:"url"`
+	Body   *struct {
+		Mode       string            `json:"mode"`
+		Raw        string            `json:"raw"`
+		Urlencoded []postmanKeyValue `json:"urlencoded"`
+		Formdata   []postmanKeyValue `json:"formdata"`
+		Options    struct {
+			Raw struct {
+				Language string `json:"language"`
+			} `json:"raw"`
+		} `json:"options"`
+	} `json:"body"`
+}
+
+type postmanUrl struct {
+	Raw      string            `json:"raw"`
+	Variable []postmanKeyValue `json:"variable"`
+}
+
+//UnmarshalJSON accepts the URL both in string and in object form
+func (u *postmanUrl) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		u.Raw = raw
+		return nil
+	}
+	type plain postmanUrl
+	return json.Unmarshal(data, (*plain)(u))
+}
+
+type postmanKeyValue struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Type     string `json:"type"`
+	Disabled bool   `json:"disabled"`
+}
+
+//parsePostman reads a Postman v2.1 collection and creates a request template for every request in it. Variables
+//are replaced with the input keyword of the same name if one is defined, then with the collection variable value,
+//and with the FUZZ keyword otherwise.
+func parsePostman(parseOpts *ConfigOptions, conf *Config) error {
+	content, err := ioutil.ReadFile(parseOpts.Input.Postman)
+	if err != nil {
+		return fmt.Errorf("could not read the Postman collection: %s", err)
+	}
+	var collection postmanCollection
+	if err := json.Unmarshal(content, &collection); err != nil {
+		return fmt.Errorf("could not parse the Postman collection: %s", err)
+	}
+	if collection.Info.Schema != "" && !strings.Contains(collection.Info.Schema, "v2.1") {
+		return fmt.Errorf("unsupported collection schema %s, export the collection in v2.1 format", collection.Info.Schema)
+	}
+	keywords := make(map[string]bool)
+	for _, v := range parseOpts.Input.Wordlists {
+		if kw := strings.SplitN(v, ":", 2); len(kw) == 2 {
+			keywords[kw[1]] = true
+		}
+	}
+	for _, v := range parseOpts.Input.Inputcommands {
+		if kw := strings.SplitN(v, ":", 2); len(kw) == 2 {
+			keywords[kw[1]] = true
+		}
+	}
+	values := make(map[string]string)
+	for _, v := range collection.Variable {
+		if !v.Disabled && v.Value != "" {
+			values[v.Key] = v.Value
+		}
+	}
+	// The variables without a value are replaced with FUZZ keyword
+	fuzzed := map[string]bool{"FUZZ": true}
+	for kw := range keywords {
+		fuzzed[kw] = true
+	}
+	for _, req := range postmanRequests(collection.Item) {
+		op, err := postmanOperation(req, keywords, values, parseOpts.HTTP.URL)
+		if err != nil {
+			return err
+		}
+		if templateHasKeyword(op, fuzzed) {
+			conf.ApiOperations = append(conf.ApiOperations, op)
+		}
+	}
+	if len(conf.ApiOperations) == 0 {
+		return fmt.Errorf("no requests with variables to fuzz found in the Postman collection")
+	}
+	conf.Url = conf.ApiOperations[0].Url
+	conf.Method = conf.ApiOperations[0].Method
+	conf.Data = conf.ApiOperations[0].Data
+	return nil
+}
+
+//postmanRequests returns the requests of the collection, descending into folders
+func postmanRequests(items []postmanItem) []*postmanRequest {
+	reqs := make([]*postmanRequest, 0)
+	for _, item := range items {
+		if item.Request != nil {
+			reqs = append(reqs, item.Request)
+		}
+		reqs = append(reqs, postmanRequests(item.Item)...)
+	}
+	return reqs
+}
+
+//postmanOperation builds the request template for a single Postman request. The scheme and host of the request are
+//replaced with the ones of the base URL (-u) if defined.
+func postmanOperation(req *postmanRequest, keywords map[string]bool, values map[string]string, baseUrl string) (ApiOperation, error) {
+	op := ApiOperation{Method: strings.ToUpper(req.Method), Headers: make(map[string]string)}
+	if op.Method == "" {
+		op.Method = "GET"
+	}
+	replace := func(s string) string {
+		return postmanVariableRegexp.ReplaceAllStringFunc(s, func(m string) string {
+			name := postmanVariableRegexp.FindStringSubmatch(m)[1]
+			if keywords[name] {
+				return name
+			}
+			if v, ok := values[name]; ok {
+				return v
+			}
+			return "FUZZ"
+		})
+	}
+	rawUrl := req.Url.Raw
+	for _, v := range req.Url.Variable {
+		// Path variables in form of /users/:id
+		value := "{{" + v.Key + "}}"
+		if v.Value != "" {
+			value = v.Value
+		}
+		rawUrl = strings.ReplaceAll(rawUrl, "/:"+v.Key, "/"+value)
+	}
+	op.Url = replace(rawUrl)
+	if baseUrl != "" {
+		op.Url = postmanRebase(op.Url, baseUrl)
+	}
+	if !strings.Contains(op.Url, "://") {
+		return op, fmt.Errorf("no absolute URL for request %s %s, define the base URL with -u", op.Method, req.Url.Raw)
+	}
+	for _, h := range req.Header {
+		if !h.Disabled {
+			op.Headers[replace(h.Key)] = replace(h.Value)
+		}
+	}
+	if req.Body != nil {
+		switch req.Body.Mode {
+		case "raw":
+			op.Data = replace(req.Body.Raw)
+			if req.Body.Options.Raw.Language == "json" {
+				op.ContentType = "application/json"
+			} else if req.Body.Options.Raw.Language == "xml" {
+				op.ContentType = "application/xml"
+			}
+		case "urlencoded", "formdata":
+			// Multipart form data is sent url encoded, as the file fields cannot be fuzzed
+			fields := req.Body.Urlencoded
+			if req.Body.Mode == "formdata" {
+				fields = req.Body.Formdata
+			}
+			form := make([]string, 0)
+			for _, f := range fields {
+				if !f.Disabled && f.Type != "file" {
+					form = append(form, url.QueryEscape(replace(f.Key))+"="+replace(f.Value))
+				}
+			}
+			op.Data = strings.Join(form, "&")
+			op.ContentType = "application/x-www-form-urlencoded"
+		}
+	}
+	return op, nil
+}
+
+//postmanRebase replaces the scheme and host of a request URL with the ones of the base URL. The host of a request URL
+//without a scheme is a variable, eg. {{baseUrl}}/users.
+func postmanRebase(reqUrl, baseUrl string) string {
+	base := strings.TrimSuffix(baseUrl, "/")
+	rest := reqUrl
+	if i := strings.Index(reqUrl, "://"); i >= 0 {
+		rest = reqUrl[i+3:]
+	}
+	if i := strings.Index(rest, "/"); i >= 0 {
+		return base + rest[i:]
+	}
+	return base
+}
+
+//templateHasKeyword checks if the request template contains any of the keywords
+func templateHasKeyword(op ApiOperation, keywords map[string]bool) bool {
+	check := []string{op.Url, op.Data}
+	for k, v := range op.Headers {
+		check = append(check, k, v)
+	}
+	for _, s := range check {
+		for kw := range keywords {
+			if strings.Contains(s, kw) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package ffuf
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func parseTestPostman(t *testing.T, collection string, opts func(*ConfigOptions)) ([]ApiOperation, error) {
+	t.Helper()
+	parseOpts := &ConfigOptions{}
+	parseOpts.Input.Postman = filepath.Join(t.TempDir(), "collection.json")
+	if err := os.WriteFile(parseOpts.Input.Postman, []byte(collection), 0644); err != nil {
+		t.Fatalf("Could not write the collection: %s", err)
+	}
+	if opts != nil {
+		opts(parseOpts)
+	}
+	conf := &Config{}
+	err := parsePostman(parseOpts, conf)
+	return conf.ApiOperations, err
+}
+
+func TestParsePostman(t *testing.T) {
+	tests := []struct {
+		name       string
+		collection string
+		opts       func(*ConfigOptions)
+		expected   []ApiOperation
+		err        string
+	}{
+		{
+			name:       "variable without a value fuzzed",
+			collection: `{"item": [{"request": {"method": "get", "url": "http://example.com/users/{{id}}"}}]}`,
+			expected:   []ApiOperation{{Method: "GET", Url: "http://example.com/users/FUZZ", Headers: map[string]string{}}},
+		},
+		{
+			name: "collection variables and input keywords",
+			collection: `{"variable": [{"key": "host", "value": "http://example.com"}, {"key": "off", "value": "x", "disabled": true}],
+				"item": [{"request": {"url": {"raw": "{{host}}/{{ USER }}/{{off}}"}}}]}`,
+			opts:     func(o *ConfigOptions) { o.Input.Wordlists = []string{"users.txt:USER"} },
+			expected: []ApiOperation{{Method: "GET", Url: "http://example.com/USER/FUZZ", Headers: map[string]string{}}},
+		},
+		{
+			name: "path variables",
+			collection: `{"item": [{"request": {"method": "DELETE", "url": {"raw": "http://example.com/users/:id/posts/:post",
+				"variable": [{"key": "id"}, {"key": "post", "value": "1"}]}}}]}`,
+			expected: []ApiOperation{{Method: "DELETE", Url: "http://example.com/users/FUZZ/posts/1", Headers: map[string]string{}}},
+		},
+		{
+			name: "folders and requests without variables",
+			collection: `{"item": [{"name": "folder", "item": [
+				{"request": {"url": "http://example.com/static"}},
+				{"request": {"url": "http://example.com/{{a}}"}}]},
+				{"request": {"url": "http://example.com/FUZZ"}}]}`,
+			expected: []ApiOperation{
+				{Method: "GET", Url: "http://example.com/FUZZ", Headers: map[string]string{}},
+				{Method: "GET", Url: "http://example.com/FUZZ", Headers: map[string]string{}},
+			},
+		},
+		{
+			name: "headers and raw json body",
+			collection: `{"item": [{"request": {"method": "POST", "url": "http://example.com/login",
+				"header": [{"key": "X-Token", "value": "{{token}}"}, {"key": "X-Off", "value": "1", "disabled": true}],
+				"body": {"mode": "raw", "raw": "{\"user\": \"admin\"}", "options": {"raw": {"language": "json"}}}}}]}`,
+			expected: []ApiOperation{{Method: "POST", Url: "http://example.com/login", Data: `{"user": "admin"}`, ContentType: "application/json", Headers: map[string]string{"X-Token": "FUZZ"}}},
+		},
+		{
+			name: "form data without the file fields",
+			collection: `{"item": [{"request": {"method": "POST", "url": "http://example.com/upload",
+				"body": {"mode": "formdata", "formdata": [{"key": "na me", "value": "{{name}}"}, {"key": "file", "type": "file"}, {"key": "off", "value": "1", "disabled": true}]}}}]}`,
+			expected: []ApiOperation{{Method: "POST", Url: "http://example.com/upload", Data: "na+me=FUZZ", ContentType: "application/x-www-form-urlencoded", Headers: map[string]string{}}},
+		},
+		{
+			name:       "base URL from -u",
+			collection: `{"item": [{"request": {"url": "{{baseUrl}}/api/{{id}}"}}]}`,
+			opts:       func(o *ConfigOptions) { o.HTTP.URL = "https://target.example.com:8443/" },
+			expected:   []ApiOperation{{Method: "GET", Url: "https://target.example.com:8443/api/FUZZ", Headers: map[string]string{}}},
+		},
+		{
+			name:       "relative URL",
+			collection: `{"item": [{"request": {"url": "/api/{{id}}"}}]}`,
+			err:        "no absolute URL",
+		},
+		{
+			name:       "unsupported schema",
+			collection: `{"info": {"schema": "https://schema.getpostman.com/json/collection/v1.0.0/collection.json"}, "item": []}`,
+			err:        "unsupported collection schema",
+		},
+		{
+			name:       "nothing to fuzz",
+			collection: `{"item": [{"request": {"url": "http://example.com/"}}]}`,
+			err:        "no requests with variables",
+		},
+		{
+			name:       "invalid json",
+			collection: `{"item": [`,
+			err:        "could not parse the Postman collection",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, err := parseTestPostman(t, tt.collection, tt.opts)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Expected an error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(ops, tt.expected) {
+				t.Errorf("Expected the requests %+v, got %+v", tt.expected, ops)
+			}
+		})
+	}
+}
+
+func TestPostmanRebase(t *testing.T) {
+	tests := []struct {
+		reqUrl   string
+		baseUrl  string
+		expected string
+	}{
+		{"http://example.com/api/FUZZ", "https://target", "https://target/api/FUZZ"},
+		{"http://example.com/api/FUZZ", "https://target/", "https://target/api/FUZZ"},
+		{"http://example.com", "https://target:8443", "https://target:8443"},
+		{"http://example.com/?q=FUZZ", "http://127.0.0.1", "http://127.0.0.1/?q=FUZZ"},
+		{"FUZZ/api/FUZZ", "https://target", "https://target/api/FUZZ"},
+		{"/api/FUZZ", "https://target", "https://target/api/FUZZ"},
+	}
+	for _, tt := range tests {
+		if got := postmanRebase(tt.reqUrl, tt.baseUrl); got != tt.expected {
+			t.Errorf("postmanRebase(%q, %q): expected %q, got %q", tt.reqUrl, tt.baseUrl, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package ffuf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	//POSTPROCESS_DEDUP_URL drops the results for URLs already seen in any of the queue jobs
+	POSTPROCESS_DEDUP_URL = "dedup-url"
+	//POSTPROCESS_DEDUP_BODY drops the results with a response status and body already seen in any of the queue jobs
+	POSTPROCESS_DEDUP_BODY = "dedup-body"
+	//POSTPROCESS_COLLAPSE_REDIRECTS keeps only the first of the results redirecting to the same location
+	POSTPROCESS_COLLAPSE_REDIRECTS = "collapse-redirects"
+	//POSTPROCESS_TAG tags the results as backup-file, directory, redirect or denied
+	POSTPROCESS_TAG = "tag"
+)
+
+//PostProcessors are the accepted values of -postprocess
+var PostProcessors = []string{POSTPROCESS_DEDUP_URL, POSTPROCESS_DEDUP_BODY, POSTPROCESS_COLLAPSE_REDIRECTS, POSTPROCESS_TAG}
+
+//backupSuffixes are the file name endings of the results tagged as backup-file
+var backupSuffixes = []string{".bak", ".old", ".orig", ".backup", ".save", ".swp", ".tmp", "~", ".zip", ".tar", ".tar.gz", ".tgz", ".gz", ".7z", ".rar", ".sql"}
+
+//AddTag adds a tag to the response, shown with the result and included in the output files
+func (resp *Response) AddTag(tag string) {
+	for _, t := range resp.Tags {
+		if t == tag {
+			return
+		}
+	}
+	resp.Tags = append(resp.Tags, tag)
+}
+
+//newResultProcessors returns the built-in result processors of -postprocess, in the order given
+func newResultProcessors(names []string) ([]ResultProcessor, error) {
+	processors := make([]ResultProcessor, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case POSTPROCESS_DEDUP_URL:
+			processors = append(processors, dedupProcessor(func(resp *Response) string {
+				return resp.Request.Url
+			}))
+		case POSTPROCESS_DEDUP_BODY:
+			processors = append(processors, dedupProcessor(func(resp *Response) string {
+				body := resp.Body()
+				if len(body) == 0 {
+					// Empty responses tell nothing of the resources
+					return ""
+				}
+				sum := sha256.Sum256(body)
+				return fmt.Sprintf("%d:%s", resp.StatusCode, hex.EncodeToString(sum[:]))
+			}))
+		case POSTPROCESS_COLLAPSE_REDIRECTS:
+			processors = append(processors, dedupProcessor(redirectLocation))
+		case POSTPROCESS_TAG:
+			processors = append(processors, tagProcessor)
+		default:
+			return processors, fmt.Errorf("Unknown result post-processor (-postprocess): %s. Available processors: %s", name, strings.Join(PostProcessors, ", "))
+		}
+	}
+	return processors, nil
+}
+
+//dedupProcessor returns a processor dropping the results with a key already seen, over all of the queue jobs of the
+//run. The results with an empty key are kept.
+func dedupProcessor(key func(resp *Response) string) ResultProcessor {
+	var mutex sync.Mutex
+	seen := make(map[string]bool)
+	return func(resp *Response) bool {
+		k := key(resp)
+		if k == "" {
+			return true
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		if seen[k] {
+			return false
+		}
+		seen[k] = true
+		return true
+	}
+}
+
+//redirectLocation returns the absolute redirect location of the response, or an empty string if it's not a redirect
+func redirectLocation(resp *Response) string {
+	if resp.GetRedirectLocation(false) == "" {
+		return ""
+	}
+	return resp.GetRedirectLocation(true)
+}
+
+//tagProcessor tags the backup files, directories, redirects and the responses denying access
+func tagProcessor(resp *Response) bool {
+	p := resp.Request.Url
+	if u, err := url.Parse(resp.Request.Url); err == nil {
+		p = u.Path
+	}
+	p = strings.ToLower(p)
+	for _, s := range backupSuffixes {
+		if strings.HasSuffix(p, s) {
+			resp.AddTag("backup-file")
+			break
+		}
+	}
+	location := redirectLocation(resp)
+	if strings.HasSuffix(p, "/") || (location != "" && location == resp.Request.Url+"/") {
+		resp.AddTag("directory")
+	} else if location != "" {
+		resp.AddTag("redirect")
+	}
+	if resp.StatusCode == 401 || resp.StatusCode == 403 {
+		resp.AddTag("denied")
+	}
+	return true
+}
+
+//processResult runs the built-in result processors and the ones of the configuration on a matched response, and
+//returns false if one of them dropped the result
+func (j *Job) processResult(resp *Response) bool {
+	for _, p := range j.resultProcessors {
+		if !p(resp) {
+			return false
+		}
+	}
+	for _, p := range j.Config.ResultProcessors {
+		if !p(resp) {
+			return false
+		}
+	}
+	return true
+}
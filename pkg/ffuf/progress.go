@@ -1,15 +1,49 @@
 package ffuf
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
 	"time"
 )
 
 type Progress struct {
-	StartedAt  time.Time
-	ReqCount   int
-	ReqTotal   int
-	ReqSec     int64
-	QueuePos   int
-	QueueTotal int
-	ErrorCount int
+	StartedAt    time.Time
+	ReqCount     int
+	ReqTotal     int
+	ReqSec       int64
+	QueuePos     int
+	QueueTotal   int
+	ErrorCount   int
+	ErrorClasses map[string]int
+}
+
+//IsProgressJSONTarget returns true if the value of -progress-json is "stderr" or a file descriptor number
+func IsProgressJSONTarget(target string) bool {
+	if target == "stderr" {
+		return true
+	}
+	fd, err := strconv.Atoi(target)
+	return err == nil && fd > 0
+}
+
+//NewProgressJSONWriter returns the writer of the progress stream (-progress-json), stderr or a file descriptor the
+//caller opened for the process, eg. with 3>progress.ndjson
+func NewProgressJSONWriter(target string) (io.Writer, error) {
+	if target == "stderr" {
+		return os.Stderr, nil
+	}
+	fd, err := strconv.Atoi(target)
+	if err != nil || fd <= 0 {
+		return nil, fmt.Errorf("invalid progress stream: %s", target)
+	}
+	f := os.NewFile(uintptr(fd), fmt.Sprintf("fd%d", fd))
+	if f == nil {
+		return nil, fmt.Errorf("file descriptor %d of the progress stream is not open", fd)
+	}
+	if _, err := f.Stat(); err != nil {
+		return nil, fmt.Errorf("file descriptor %d of the progress stream is not open: %s", fd, err)
+	}
+	return f, nil
 }
@@ -0,0 +1,198 @@
+package ffuf
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+//PROXY_FAILURE_THRESHOLD is the number of consecutive failed connections to a proxy after which it is considered down
+const PROXY_FAILURE_THRESHOLD = 5
+
+const (
+	//PROXY_FALLBACK_FAIL stops the run once all of the proxies are down
+	PROXY_FALLBACK_FAIL = "fail"
+	//PROXY_FALLBACK_DIRECT continues the run without a proxy once all of the proxies are down
+	PROXY_FALLBACK_DIRECT = "direct"
+)
+
+const (
+	//PROXY_ROTATE_ROUNDROBIN gives every request the next proxy of the list in turn
+	PROXY_ROTATE_ROUNDROBIN = "roundrobin"
+	//PROXY_ROTATE_RANDOM gives every request a random proxy of the list
+	PROXY_ROTATE_RANDOM = "random"
+)
+
+//ErrProxyUnavailable is the request error once all of the proxies are down, and the run may not continue without one
+var ErrProxyUnavailable = errors.New("proxy unavailable")
+
+//ProxyPool monitors the health of the proxies through the results of the connections made to them. A proxy failing
+//PROXY_FAILURE_THRESHOLD connections in a row is considered down. Without rotation the proxy (-x) is used until it
+//is down, and then replaced by the next backup (-proxy-backup). With rotation (-proxy-list) every request gets the
+//next proxy in turn, or a random one, and the proxies that are down are removed from the rotation. Once all of the
+//proxies are down, the fallback policy decides between failing and connecting directly.
+type ProxyPool struct {
+	mutex    sync.Mutex
+	proxies  []*url.URL
+	alive    []*url.URL
+	failures map[string]int
+	next     int
+	lastErr  error
+	fallback string
+	rotate   string
+	//OnChange gets a description of every switch of the proxy in use
+	OnChange func(string)
+}
+
+func NewProxyPool(proxies []string, fallback string) (*ProxyPool, error) {
+	pool := ProxyPool{fallback: fallback, failures: make(map[string]int)}
+	for _, p := range proxies {
+		pu, err := url.Parse(p)
+		if err != nil {
+			return &pool, err
+		}
+		pool.proxies = append(pool.proxies, pu)
+	}
+	pool.alive = append(pool.alive, pool.proxies...)
+	return &pool, nil
+}
+
+//NewRotatingProxyPool returns a pool rotating the proxies per request, in turn or randomly (PROXY_ROTATE_*)
+func NewRotatingProxyPool(proxies []string, rotate string, fallback string) (*ProxyPool, error) {
+	pool, err := NewProxyPool(proxies, fallback)
+	pool.rotate = rotate
+	return pool, err
+}
+
+//Proxy returns the proxy to use for the request, to be used as the Proxy function of http.Transport
+func (p *ProxyPool) Proxy(req *http.Request) (*url.URL, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if len(p.alive) > 0 {
+		switch p.rotate {
+		case PROXY_ROTATE_ROUNDROBIN:
+			proxy := p.alive[p.next%len(p.alive)]
+			p.next++
+			return proxy, nil
+		case PROXY_ROTATE_RANDOM:
+			return p.alive[rand.Intn(len(p.alive))], nil
+		}
+		return p.alive[0], nil
+	}
+	if p.fallback == PROXY_FALLBACK_DIRECT {
+		return nil, nil
+	}
+	return nil, ErrProxyUnavailable
+}
+
+//DialResult records the result of a connection. Only the connections to the proxies in use are counted.
+func (p *ProxyPool) DialResult(addr string, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	index := -1
+	for i, pu := range p.alive {
+		if addr == proxyAddr(pu) {
+			index = i
+			break
+		}
+	}
+	if index == -1 || (p.rotate == "" && index != 0) {
+		return
+	}
+	if err == nil {
+		p.failures[addr] = 0
+		return
+	}
+	p.failures[addr]++
+	p.lastErr = err
+	if p.failures[addr] < PROXY_FAILURE_THRESHOLD {
+		return
+	}
+	failed := p.alive[index].String()
+	p.alive = append(p.alive[:index], p.alive[index+1:]...)
+	var msg string
+	if len(p.alive) > 0 && p.rotate != "" {
+		msg = fmt.Sprintf("Proxy %s is down, removed it from the rotation (%d left)", failed, len(p.alive))
+	} else if len(p.alive) > 0 {
+		msg = fmt.Sprintf("Proxy %s is down, switching to the backup proxy %s", failed, p.alive[0])
+	} else if p.fallback == PROXY_FALLBACK_DIRECT {
+		msg = fmt.Sprintf("Proxy %s is down, continuing without a proxy", failed)
+	}
+	if msg != "" && p.OnChange != nil {
+		p.OnChange(msg)
+	}
+}
+
+//Error returns the description of the proxy failure once all of the proxies are down
+func (p *ProxyPool) Error() string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	names := make([]string, 0, len(p.proxies))
+	for _, pu := range p.proxies {
+		names = append(names, pu.String())
+	}
+	msg := fmt.Sprintf("Proxy %s is unreachable after %d failed connections", names[0], PROXY_FAILURE_THRESHOLD)
+	if len(names) > 1 {
+		msg = fmt.Sprintf("All of the proxies (%s) are unreachable", strings.Join(names, ", "))
+	}
+	if p.lastErr != nil {
+		msg += fmt.Sprintf(": %s", p.lastErr)
+	}
+	return msg
+}
+
+//readProxyListFile reads the proxy URLs from a file, one per line
+func readProxyListFile(filename string) ([]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %s", filename, err)
+	}
+	defer f.Close()
+	proxies := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			proxies = append(proxies, line)
+		}
+	}
+	return proxies, scanner.Err()
+}
+
+//checkProxyURL parses a proxy URL, which needs to be an HTTP, HTTPS or SOCKS5 one
+func checkProxyURL(proxy string) error {
+	pu, err := url.Parse(proxy)
+	if err != nil {
+		return err
+	}
+	switch pu.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("unsupported scheme %q, available schemes: http, https, socks5, socks5h", pu.Scheme)
+	}
+	if pu.Host == "" {
+		return fmt.Errorf("missing host")
+	}
+	return nil
+}
+
+//proxyAddr returns the address the proxy is connected to, with the default port of the scheme if none is defined
+func proxyAddr(pu *url.URL) string {
+	if pu.Port() != "" {
+		return pu.Host
+	}
+	port := "80"
+	switch pu.Scheme {
+	case "https":
+		port = "443"
+	case "socks5", "socks5h":
+		port = "1080"
+	}
+	return net.JoinHostPort(pu.Hostname(), port)
+}
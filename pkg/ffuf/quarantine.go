@@ -0,0 +1,88 @@
+package ffuf
+
+import (
+	"net/url"
+	"sync"
+)
+
+//QuarantinedHost is a host whose remaining inputs were skipped after too many consecutive request errors
+type QuarantinedHost struct {
+	Host      string `json:"host"`
+	Errors    int    `json:"errors"`
+	LastError string `json:"last_error"`
+	Skipped   int    `json:"skipped"`
+}
+
+//HostQuarantine counts the consecutive request errors of each host, and quarantines the hosts reaching the threshold
+//of -host-errors, so that a dead target does not use up the time of a run against many hosts
+type HostQuarantine struct {
+	mutex       sync.Mutex
+	threshold   int
+	errors      map[string]int
+	quarantined map[string]*QuarantinedHost
+	order       []string
+}
+
+func NewHostQuarantine(threshold int) *HostQuarantine {
+	return &HostQuarantine{
+		threshold:   threshold,
+		errors:      make(map[string]int),
+		quarantined: make(map[string]*QuarantinedHost),
+		order:       make([]string, 0),
+	}
+}
+
+//quarantineHost returns the host of the request URL the errors are counted for, as scheme://host
+func quarantineHost(reqUrl string) string {
+	u, err := url.Parse(reqUrl)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+//Skip returns true if the host is quarantined, counting the skipped input
+func (q *HostQuarantine) Skip(host string) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if qh, ok := q.quarantined[host]; ok {
+		qh.Skipped++
+		return true
+	}
+	return false
+}
+
+//Fail counts a failed request to the host, and returns true if the host got quarantined because of it
+func (q *HostQuarantine) Fail(host, class string) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if _, ok := q.quarantined[host]; ok {
+		return false
+	}
+	q.errors[host]++
+	if q.errors[host] < q.threshold {
+		return false
+	}
+	q.quarantined[host] = &QuarantinedHost{Host: host, Errors: q.errors[host], LastError: class}
+	q.order = append(q.order, host)
+	delete(q.errors, host)
+	return true
+}
+
+//Succeed resets the error count of the host after a response
+func (q *HostQuarantine) Succeed(host string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	delete(q.errors, host)
+}
+
+//Hosts returns the quarantined hosts in the order they were quarantined
+func (q *HostQuarantine) Hosts() []QuarantinedHost {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	hosts := make([]QuarantinedHost, 0, len(q.order))
+	for _, h := range q.order {
+		hosts = append(hosts, *q.quarantined[h])
+	}
+	return hosts
+}
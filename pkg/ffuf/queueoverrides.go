@@ -0,0 +1,121 @@
+package ffuf
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//QUEUE_OVERRIDE_FILTER_PREFIX prefixes the names of the filters a queue job adds to the ones of the run, keeping them
+//apart from the filters changed interactively while the job runs
+const QUEUE_OVERRIDE_FILTER_PREFIX = "queue-"
+
+//QueueJobOverrides replace settings of the run for a queued job while it runs, such as fewer threads and extra
+//filters for a sensitive subtree. They are attached to the jobs interactively with "queueset", or with -job-override
+//rules when the jobs are queued.
+type QueueJobOverrides struct {
+	// Threads of the job, 0 keeps the threads of the run
+	Threads int
+	Delay   optRange
+	// Filters added to the ones of the run, by filter name
+	Filters map[string]FilterProvider
+}
+
+//SetDelay sets the delay of the job, a float or a range of floats in seconds like -p
+func (o *QueueJobOverrides) SetDelay(value string) error {
+	return o.Delay.Initialize(value)
+}
+
+//String returns the overrides for listing the queue, eg. "threads=2 delay=0.50 size=42"
+func (o *QueueJobOverrides) String() string {
+	parts := make([]string, 0)
+	if o.Threads > 0 {
+		parts = append(parts, fmt.Sprintf("threads=%d", o.Threads))
+	}
+	if o.Delay.HasDelay {
+		if o.Delay.IsRange {
+			parts = append(parts, fmt.Sprintf("delay=%.2f-%.2f", o.Delay.Min, o.Delay.Max))
+		} else {
+			parts = append(parts, fmt.Sprintf("delay=%.2f", o.Delay.Min))
+		}
+	}
+	names := make([]string, 0, len(o.Filters))
+	for name := range o.Filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s=%s", name, o.Filters[name].Repr()))
+	}
+	return strings.Join(parts, " ")
+}
+
+//QueueOverrideRule attaches the overrides to the queued jobs with URLs matching the pattern (-job-override)
+type QueueOverrideRule struct {
+	Pattern   *regexp.Regexp
+	Overrides QueueJobOverrides
+}
+
+//SetQueueOverrides attaches the overrides to a queued job by its index in QueuedJobs, replacing the earlier ones. They
+//are applied when the job starts, so the active job at index 0 cannot be changed.
+func (j *Job) SetQueueOverrides(index int, overrides QueueJobOverrides) error {
+	j.queueMutex.Lock()
+	defer j.queueMutex.Unlock()
+	if index == 0 {
+		return fmt.Errorf("The overrides of the currently running job cannot be changed")
+	}
+	pos := j.queuepos + index - 1
+	if index < 0 || pos >= len(j.queuejobs) {
+		return fmt.Errorf("No such queued job")
+	}
+	j.queuejobs[pos].Overrides = &overrides
+	return nil
+}
+
+//queueOverridesFor returns the overrides of the first -job-override rule matching the URL of a new queue job
+func (j *Job) queueOverridesFor(jobUrl string) *QueueJobOverrides {
+	for _, rule := range j.Config.QueueOverrideRules {
+		if rule.Pattern.MatchString(jobUrl) {
+			overrides := rule.Overrides
+			return &overrides
+		}
+	}
+	return nil
+}
+
+//applyQueueOverrides replaces the settings of the run with the overrides of the queue job about to start
+func (j *Job) applyQueueOverrides(overrides *QueueJobOverrides) {
+	j.resetQueueOverrides()
+	if overrides == nil {
+		return
+	}
+	j.baseThreads = j.Config.Threads
+	j.baseDelay = j.Config.Delay
+	if overrides.Threads > 0 {
+		j.Config.Threads = overrides.Threads
+	}
+	if overrides.Delay.HasDelay {
+		j.Config.Delay = overrides.Delay
+	}
+	for name, f := range overrides.Filters {
+		j.Config.Filters[QUEUE_OVERRIDE_FILTER_PREFIX+name] = f
+	}
+	j.overridden = true
+	j.Output.Info(fmt.Sprintf("Overrides for the queued job: %s", overrides.String()))
+}
+
+//resetQueueOverrides restores the settings of the run after a queue job with overrides
+func (j *Job) resetQueueOverrides() {
+	if !j.overridden {
+		return
+	}
+	j.Config.Threads = j.baseThreads
+	j.Config.Delay = j.baseDelay
+	for name := range j.Config.Filters {
+		if strings.HasPrefix(name, QUEUE_OVERRIDE_FILTER_PREFIX) {
+			delete(j.Config.Filters, name)
+		}
+	}
+	j.overridden = false
+}
@@ -2,10 +2,32 @@ package ffuf
 
 import (
 	"container/ring"
+	"context"
+	"fmt"
+	"log"
+	"math"
 	"sync"
 	"time"
 )
 
+const (
+	//RATE_MODE_FIXED throttles the requests to -rate only
+	RATE_MODE_FIXED = "fixed"
+	//RATE_MODE_ADAPTIVE adjusts the rate to the congestion signals of the target, up to -rate
+	RATE_MODE_ADAPTIVE = "adaptive"
+	//RATE_ADAPTIVE_INTERVAL is the minimum time between the increases of the adaptive rate
+	RATE_ADAPTIVE_INTERVAL = time.Second
+	//RATE_ADAPTIVE_DECREASE is the multiplier of the adaptive rate when the target shows signs of congestion
+	RATE_ADAPTIVE_DECREASE = 0.5
+	//RATE_ADAPTIVE_STEPS is the number of additive increases the adaptive rate takes to climb from zero to the rate of
+	//the previous congestion
+	RATE_ADAPTIVE_STEPS = 20
+	//RATE_ADAPTIVE_LATENCY_FACTOR is how many times slower than the fastest seen the responses need to get to back off
+	RATE_ADAPTIVE_LATENCY_FACTOR = 2
+	//RATE_ADAPTIVE_LATENCY_MIN is how much slower than the fastest seen the responses need to get to back off
+	RATE_ADAPTIVE_LATENCY_MIN = 50 * time.Millisecond
+)
+
 type RateThrottle struct {
 	rateCounter       *ring.Ring
 	RateAdjustment    float64
@@ -13,6 +35,22 @@ type RateThrottle struct {
 	Config            *Config
 	RateMutex         sync.Mutex
 	lastAdjustment    time.Time
+	pinnedRate        int64
+	adaptiveRate      float64
+	congestedRate     float64
+	peakRate          float64
+	baseLatency       time.Duration
+	lastCongestion    int
+	lastDecision      time.Time
+}
+
+//RateStats is a snapshot of the rate throttle state
+type RateStats struct {
+	Target     int64           // requests per second aimed at, from -rate or pinned manually. 0 for unlimited
+	Current    int64           // measured requests per second
+	Adjustment time.Duration   // pause between the requests of a single thread
+	Pinned     bool            // the rate has been set manually and is not adjusted automatically
+	Samples    []time.Duration // durations of the requests in the measurement window
 }
 
 func NewRateThrottle(conf *Config) *RateThrottle {
@@ -27,6 +65,12 @@ func NewRateThrottle(conf *Config) *RateThrottle {
 
 //CurrentRate calculates requests/second value from circular list of rate
 func (r *RateThrottle) CurrentRate() int64 {
+	r.RateMutex.Lock()
+	defer r.RateMutex.Unlock()
+	return r.currentRate()
+}
+
+func (r *RateThrottle) currentRate() int64 {
 	n := r.rateCounter.Len()
 	var total int64
 	total = 0
@@ -63,25 +107,98 @@ func (r *RateThrottle) Tick(start, end time.Time) {
 }
 
 func (r *RateThrottle) Throttle() {
-	if r.Config.Rate == 0 {
+	r.RateMutex.Lock()
+	adjustment := r.RateAdjustment
+	throttle := r.Config.Rate > 0 || r.pinnedRate > 0 || r.Config.RateAdaptive
+	r.RateMutex.Unlock()
+	if !throttle {
 		// No throttling
 		return
 	}
-	if r.RateAdjustment > 0.0 {
-		delayNS := float64(time.Second.Nanoseconds()) * r.RateAdjustment
+	if adjustment > 0.0 {
+		delayNS := float64(time.Second.Nanoseconds()) * adjustment
 		time.Sleep(time.Nanosecond * time.Duration(delayNS))
 	}
 }
 
-//Adjust changes the RateAdjustment value, which is multiplier of second to pause between requests in a thread
-func (r *RateThrottle) Adjust() {
-	if r.RateAdjustmentPos < r.Config.Threads {
-		// Do not adjust if we don't have enough data yet
-		return
+//Stats returns the current state of the rate throttle
+func (r *RateThrottle) Stats() RateStats {
+	r.RateMutex.Lock()
+	defer r.RateMutex.Unlock()
+	stats := RateStats{
+		Target:     r.Config.Rate,
+		Current:    r.currentRate(),
+		Adjustment: time.Duration(float64(time.Second.Nanoseconds()) * r.RateAdjustment),
+		Pinned:     r.pinnedRate > 0,
+		Samples:    make([]time.Duration, 0, r.rateCounter.Len()),
+	}
+	if stats.Pinned {
+		stats.Target = r.pinnedRate
+	} else if r.adaptiveRate > 0 {
+		stats.Target = int64(r.adaptiveRate)
+	}
+	r.rateCounter.Do(func(v interface{}) {
+		if val, ok := v.(int64); ok {
+			stats.Samples = append(stats.Samples, time.Duration(val))
+		}
+	})
+	return stats
+}
+
+//Pin sets the rate manually to the given requests per second, disabling the automatic adjustment until Unpin is called
+func (r *RateThrottle) Pin(rate int64) error {
+	if rate <= 0 {
+		return fmt.Errorf("Rate needs to be a positive number of requests per second")
 	}
 	r.RateMutex.Lock()
 	defer r.RateMutex.Unlock()
-	currentRate := r.CurrentRate()
+	r.pinnedRate = rate
+	// Every thread pauses for the time left of its share of a second after the average request duration. The
+	// measured durations include the current pause.
+	pause := float64(r.Config.Threads) / float64(rate)
+	var total, n int64
+	r.rateCounter.Do(func(v interface{}) {
+		if val, ok := v.(int64); ok {
+			total += val
+			n++
+		}
+	})
+	if n > 0 {
+		pause -= float64(total/n)/float64(time.Second.Nanoseconds()) - r.RateAdjustment
+	}
+	if pause < 0 {
+		pause = 0
+	}
+	r.RateAdjustment = pause
+	return nil
+}
+
+//Unpin returns to the automatic rate adjustment towards the configured rate
+func (r *RateThrottle) Unpin() {
+	r.RateMutex.Lock()
+	defer r.RateMutex.Unlock()
+	r.pinnedRate = 0
+	r.RateAdjustment = 0
+	r.RateAdjustmentPos = 0
+	r.lastAdjustment = time.Now()
+	r.adaptiveRate = 0
+	r.congestedRate = 0
+}
+
+//Adjust changes the RateAdjustment value, which is multiplier of second to pause between requests in a thread. The
+//congestion is the number of 429 and 5xx responses and timeouts so far, driving the adaptive rate (-rate-mode adaptive).
+func (r *RateThrottle) Adjust(congestion int) {
+	r.RateMutex.Lock()
+	defer r.RateMutex.Unlock()
+	if r.Config.RateAdaptive && r.pinnedRate == 0 {
+		r.adjustAdaptive(congestion)
+		return
+	}
+	if r.Config.Rate == 0 || r.pinnedRate > 0 || r.RateAdjustmentPos < r.Config.Threads {
+		// Do not adjust without a rate limit or a manually set rate, or if we don't have enough data yet
+		return
+	}
+	currentRate := r.currentRate()
 
 	if r.RateAdjustment == 0.0 {
 		if currentRate > r.Config.Rate {
@@ -104,3 +221,149 @@ func (r *RateThrottle) Adjust() {
 	r.lastAdjustment = time.Now()
 	r.RateAdjustmentPos = 0
 }
+
+//adjustAdaptive is the AIMD controller of -rate-mode adaptive. The rate is halved when 429 or 5xx responses have
+//been received or requests have timed out, or the response times have doubled from the fastest seen, during the
+//measurement window. Otherwise it is increased every second by a step relative to the rate the congestion was last
+//seen at, up to -rate, or without it up to the fastest rate the target has kept up with before being throttled. The
+//decisions are written to the debug log. The RateMutex needs to be held.
+func (r *RateThrottle) adjustAdaptive(congestion int) {
+	if congestion < r.lastCongestion {
+		r.lastCongestion = congestion
+	}
+	if r.RateAdjustmentPos < r.Config.Threads {
+		// Not enough requests since the previous decision
+		return
+	}
+	current := float64(r.currentRate())
+	latency := r.latency()
+	if r.baseLatency == 0 || latency < r.baseLatency {
+		r.baseLatency = latency
+	}
+	if r.RateAdjustment == 0 && current > r.peakRate {
+		r.peakRate = current
+	}
+	if r.Config.Rate > 0 {
+		r.peakRate = float64(r.Config.Rate)
+	}
+	if r.adaptiveRate == 0 {
+		r.adaptiveRate = r.peakRate
+	}
+	slow := latency > r.baseLatency*RATE_ADAPTIVE_LATENCY_FACTOR && latency-r.baseLatency > RATE_ADAPTIVE_LATENCY_MIN
+	previous := r.adaptiveRate
+	if congestion > r.lastCongestion || slow {
+		r.congestedRate = r.adaptiveRate
+		r.adaptiveRate = math.Max(r.adaptiveRate*RATE_ADAPTIVE_DECREASE, 1)
+		if slow {
+			log.Printf("Adaptive rate: responses slowed down to %s from %s, decreasing from %.0f to %.0f req/sec", latency, r.baseLatency, previous, r.adaptiveRate)
+		} else {
+			log.Printf("Adaptive rate: %d new 429, 5xx or timeout signals, decreasing from %.0f to %.0f req/sec", congestion-r.lastCongestion, previous, r.adaptiveRate)
+		}
+	} else if time.Since(r.lastDecision) < RATE_ADAPTIVE_INTERVAL {
+		// Back off right away, but ramp up slowly
+		return
+	} else {
+		step := r.peakRate
+		if r.congestedRate > 0 {
+			step = r.congestedRate
+		}
+		r.adaptiveRate = math.Min(r.adaptiveRate+math.Max(step/RATE_ADAPTIVE_STEPS, 1), r.peakRate)
+		if r.adaptiveRate != previous {
+			log.Printf("Adaptive rate: no congestion, increasing from %.0f to %.0f req/sec", previous, r.adaptiveRate)
+		}
+	}
+	if r.Config.Rate == 0 && r.adaptiveRate >= r.peakRate {
+		// Recovered, let the target show if it can keep up with more
+		r.RateAdjustment = 0
+	} else {
+		pause := float64(r.Config.Threads)/r.adaptiveRate - latency.Seconds()
+		r.RateAdjustment = math.Max(pause, 0)
+	}
+	r.lastCongestion = congestion
+	r.lastDecision = time.Now()
+	r.lastAdjustment = r.lastDecision
+	r.RateAdjustmentPos = 0
+}
+
+//latency returns the average duration of the requests in the measurement window without the throttling pause. The
+//RateMutex needs to be held.
+func (r *RateThrottle) latency() time.Duration {
+	var total, n int64
+	r.rateCounter.Do(func(v interface{}) {
+		if val, ok := v.(int64); ok {
+			total += val
+			n++
+		}
+	})
+	if n == 0 {
+		return 0
+	}
+	latency := time.Duration(total/n) - time.Duration(float64(time.Second)*r.RateAdjustment)
+	if latency < 0 {
+		return 0
+	}
+	return latency
+}
+
+//GlobalLimiter caps the combined request rate of every job sharing it, on top of the rate throttle of each job. It's
+//meant for running multiple jobs in the same process, set through Config.GlobalLimiter.
+type GlobalLimiter struct {
+	rate     int64
+	interval time.Duration
+	next     time.Time
+	mutex    sync.Mutex
+}
+
+//NewGlobalLimiter returns a limiter allowing the given number of requests per second in total
+func NewGlobalLimiter(rate int64) *GlobalLimiter {
+	return &GlobalLimiter{rate: rate}
+}
+
+//NewIntervalLimiter returns a limiter keeping at least the given interval between the requests, for limits slower
+//than one request per second
+func NewIntervalLimiter(interval time.Duration) *GlobalLimiter {
+	return &GlobalLimiter{interval: interval}
+}
+
+//Rate returns the requests per second allowed by the limiter, 0 for unlimited
+func (g *GlobalLimiter) Rate() int64 {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.rate
+}
+
+//SetRate changes the requests per second allowed by the limiter, 0 for unlimited
+func (g *GlobalLimiter) SetRate(rate int64) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.rate = rate
+}
+
+//Wait blocks until a request may be sent. An error is returned if the context is cancelled while waiting.
+func (g *GlobalLimiter) Wait(ctx context.Context) error {
+	g.mutex.Lock()
+	step := g.interval
+	if g.rate > 0 {
+		step = time.Second / time.Duration(g.rate)
+	}
+	if step <= 0 {
+		g.mutex.Unlock()
+		return nil
+	}
+	// Reserve the next free slot, the requests are spread evenly over the second
+	now := time.Now()
+	slot := g.next
+	if slot.Before(now) {
+		slot = now
+	}
+	g.next = slot.Add(step)
+	g.mutex.Unlock()
+	if wait := time.Until(slot); wait > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package ffuf
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+//replayMatch checks if a matched response is to be replayed through the replay proxy. Without replay matchers
+//(-replay-match) every match is replayed, otherwise only the ones matching any of them.
+func (j *Job) replayMatch(resp Response) bool {
+	if len(j.Config.ReplayMatchers) == 0 {
+		return true
+	}
+	for _, m := range j.Config.ReplayMatchers {
+		if match, err := m.Filter(&resp); err == nil && match {
+			return true
+		}
+	}
+	return false
+}
+
+//recordReplay writes a replayed request and its response to the replay directory (-replay-dir). The files are
+//numbered in the order of the replays, and named after the status code of the response.
+func (j *Job) recordReplay(resp Response) {
+	j.ErrorMutex.Lock()
+	j.replayCount++
+	n := j.replayCount
+	j.ErrorMutex.Unlock()
+	if err := os.MkdirAll(j.Config.ReplayDir, 0750); err != nil {
+		j.Output.Error(fmt.Sprintf("Could not create the replay directory: %s", err))
+		return
+	}
+	content := fmt.Sprintf("%s\n---- ↑ Request ---- Response ↓ ----\n\n%s", resp.Request.Raw, resp.Raw)
+	filename := filepath.Join(j.Config.ReplayDir, fmt.Sprintf("%06d-%d.txt", n, resp.StatusCode))
+	if err := ioutil.WriteFile(filename, []byte(content), 0640); err != nil {
+		j.Output.Error(fmt.Sprintf("Could not record the replayed request: %s", err))
+	}
+}
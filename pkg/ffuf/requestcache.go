@@ -0,0 +1,139 @@
+package ffuf
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync"
+)
+
+//RequestCache remembers the requests sent during the run by a hash of their method, URL, headers and body, so that the
+//identical requests of overlapping wordlists or queue jobs are sent only once (-dedup-requests). The matched responses
+//are kept without their bodies, to attribute them to the inputs of the skipped duplicates.
+type RequestCache struct {
+	mutex   sync.Mutex
+	seen    map[string]*requestCacheEntry
+	skipped int
+}
+
+//requestCacheEntry is a request sent during the run, the duplicates wait for it to finish
+type requestCacheEntry struct {
+	done   chan struct{}
+	resp   *Response
+	failed bool
+}
+
+func NewRequestCache() *RequestCache {
+	return &RequestCache{seen: make(map[string]*requestCacheEntry)}
+}
+
+//requestKey returns the hash identifying the request in the cache. The same request sent to another origin IP
+//(-origin-ips) is not a duplicate.
+func requestKey(req *Request) string {
+	h := sha256.New()
+	h.Write([]byte(req.Method + "\n" + req.Url + "\n"))
+	if origin, ok := req.Input[ORIGIN_KEYWORD]; ok {
+		h.Write([]byte("origin: " + string(origin) + "\n"))
+	}
+	names := make([]string, 0, len(req.Headers))
+	for k := range req.Headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		h.Write([]byte(k + ": " + req.Headers[k] + "\n"))
+	}
+	h.Write([]byte("\n"))
+	h.Write(req.Data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+//Seen records the request, and returns true if it was sent before, along with the matched response of the earlier
+//request if it matched. A duplicate of a request still in flight waits for it to finish, and is sent instead if the
+//earlier request fails. The request is finished with Finish when Seen returns false.
+func (c *RequestCache) Seen(ctx context.Context, key string) (*Response, bool) {
+	c.mutex.Lock()
+	for {
+		entry, ok := c.seen[key]
+		if !ok {
+			c.seen[key] = &requestCacheEntry{done: make(chan struct{})}
+			c.mutex.Unlock()
+			return nil, false
+		}
+		select {
+		case <-entry.done:
+			c.skipped++
+			c.mutex.Unlock()
+			return entry.resp, true
+		default:
+		}
+		c.mutex.Unlock()
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			// The job is stopping
+			return nil, true
+		}
+		c.mutex.Lock()
+	}
+}
+
+//Finish releases the duplicates waiting for the request
+func (c *RequestCache) Finish(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry := c.seen[key]
+	if entry.failed {
+		// One of the duplicates is sent instead
+		delete(c.seen, key)
+	}
+	close(entry.done)
+}
+
+//Store keeps the matched response of the request, without its body
+func (c *RequestCache) Store(key string, resp *Response) {
+	cached := Response{
+		StatusCode:    resp.StatusCode,
+		Headers:       resp.Headers,
+		ContentLength: resp.ContentLength,
+		ContentWords:  resp.ContentWords,
+		ContentLines:  resp.ContentLines,
+		ContentType:   resp.ContentType,
+		Proto:         resp.Proto,
+		Time:          resp.Time,
+		ScraperData:   resp.ScraperData,
+		Tags:          append([]string{}, resp.Tags...),
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.seen[key].resp = &cached
+}
+
+//Fail marks the request failed, so that one of its duplicates is sent once it is finished
+func (c *RequestCache) Fail(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.seen[key].failed = true
+}
+
+//Skipped returns the number of duplicate requests skipped
+func (c *RequestCache) Skipped() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.skipped
+}
+
+//cachedResult outputs the cached response of an earlier identical request as the result of the input, tagged as cached
+func (j *Job) cachedResult(cached *Response, req Request) {
+	resp := *cached
+	resp.Request = &req
+	resp.Tags = append([]string{}, cached.Tags...)
+	resp.AddTag("cached")
+	j.incMatch()
+	j.Output.Result(resp)
+	if j.Notifier != nil && j.Notifier.Enabled(NOTIFY_EVENT_RESULTS) {
+		j.Notifier.Notify(resp)
+	}
+	j.updateProgress()
+}
@@ -0,0 +1,41 @@
+package ffuf
+
+import (
+	"testing"
+)
+
+func TestRequestKey(t *testing.T) {
+	base := func() *Request {
+		return &Request{
+			Method:  "GET",
+			Url:     "http://localhost/admin",
+			Headers: map[string]string{"Accept": "*/*", "X-Test": "1"},
+			Input:   map[string][]byte{"FUZZ": []byte("admin")},
+		}
+	}
+	key := requestKey(base())
+	same := base()
+	same.Input["FUZZ"] = []byte("other input, same request")
+	if requestKey(same) != key {
+		t.Errorf("Was expecting the same request to have the same key")
+	}
+	for name, change := range map[string]func(*Request){
+		"method": func(r *Request) { r.Method = "POST" },
+		"url":    func(r *Request) { r.Url = "http://localhost/admin/" },
+		"header": func(r *Request) { r.Headers["X-Test"] = "2" },
+		"body":   func(r *Request) { r.Data = []byte("a=1") },
+		"origin": func(r *Request) { r.Input[ORIGIN_KEYWORD] = []byte("192.0.2.1") },
+	} {
+		req := base()
+		change(req)
+		if requestKey(req) == key {
+			t.Errorf("Was expecting a different key for a different %s", name)
+		}
+	}
+	first, second := base(), base()
+	first.Input[ORIGIN_KEYWORD] = []byte("192.0.2.1")
+	second.Input[ORIGIN_KEYWORD] = []byte("192.0.2.2")
+	if requestKey(first) == requestKey(second) {
+		t.Errorf("Was expecting different keys for different origin IPs")
+	}
+}
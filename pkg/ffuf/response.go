@@ -3,9 +3,13 @@ package ffuf
 import (
 	"net/http"
 	"net/url"
+	"regexp"
+	"strings"
 	"time"
 )
 
+var redirectSchemeRegexp = regexp.MustCompile(`^\s*([a-zA-Z][a-zA-Z0-9+.-]*):`)
+
 // Response struct holds the meaningful data returned from request and is meant for passing to filters
 type Response struct {
 	StatusCode    int64
@@ -16,16 +20,32 @@ type Response struct {
 	ContentLines  int64
 	ContentType   string
 	Cancelled     bool
+	Truncated     bool
+	Proto         string
 	Request       *Request
 	Raw           string
 	ResultFile    string
 	Time          time.Duration
+	ScraperData   map[string][]string
+	Tags          []string
+	stored        *storedBody
 }
 
 //free memory
 func (resp *Response) MakeFreeMemory() {
 	resp.Data = nil
 	resp.Raw = ""
+	if resp.stored != nil {
+		resp.stored.release()
+	}
+}
+
+//Body returns the response body, read back from its temporary file if it was spilled to disk (-body-memory)
+func (resp *Response) Body() []byte {
+	if resp.Data == nil && resp.stored != nil {
+		return resp.stored.spilled()
+	}
+	return resp.Data
 }
 
 // GetRedirectLocation returns the redirect location for a 3xx redirect HTTP response
@@ -55,6 +75,20 @@ func (resp *Response) GetRedirectLocation(absolute bool) string {
 	return redirectLocation
 }
 
+//RedirectScheme returns the scheme of the redirect location if it points to a scheme other than http or https, like
+//ftp, gopher or a custom application scheme
+func (resp *Response) RedirectScheme() string {
+	m := redirectSchemeRegexp.FindStringSubmatch(resp.GetRedirectLocation(false))
+	if m == nil {
+		return ""
+	}
+	scheme := strings.ToLower(m[1])
+	if scheme == "http" || scheme == "https" {
+		return ""
+	}
+	return scheme
+}
+
 func NewResponse(httpresp *http.Response, req *Request) Response {
 	var resp Response
 	resp.Request = req
@@ -62,6 +96,7 @@ func NewResponse(httpresp *http.Response, req *Request) Response {
 	resp.ContentType = httpresp.Header.Get("Content-Type")
 	resp.Headers = httpresp.Header
 	resp.Cancelled = false
+	resp.Proto = httpresp.Proto
 	resp.Raw = ""
 	resp.ResultFile = ""
 	return resp
@@ -0,0 +1,103 @@
+package ffuf
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//RETRY_MAX_DELAY caps the backoff delay between the retries, and the Retry-After delay requested by the target
+const RETRY_MAX_DELAY = 60 * time.Second
+
+//parseRetryStatus parses the comma separated list of status codes of -retry-on
+func parseRetryStatus(value string) ([]int64, error) {
+	codes := make([]int64, 0)
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		code, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || code < 100 || code > 599 {
+			return nil, fmt.Errorf("Retry status code (-retry-on) needs to be an HTTP status code, got %s", v)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+//retryStatus returns true if the response status is one of the -retry-on status codes, or to be retried by
+//-status-action
+func (j *Job) retryStatus(resp Response) bool {
+	if j.statusAction(resp) == STATUS_ACTION_RETRY {
+		return true
+	}
+	for _, code := range j.Config.RetryStatus {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+//retryDelay returns the delay before the retry attempt: the delay of -retry-delay doubled on every attempt, with up to
+//a half of it added or removed as jitter so that the threads do not retry in lockstep. The Retry-After delay of the
+//response is used instead when the target asks for one.
+func (j *Job) retryDelay(attempt int, resp *Response) time.Duration {
+	if resp != nil {
+		if d, ok := retryAfter(resp); ok {
+			return d
+		}
+	}
+	base := time.Duration(j.Config.RetryDelay * float64(time.Second))
+	if base <= 0 {
+		return 0
+	}
+	delay := RETRY_MAX_DELAY
+	// Compared before doubling, so that a large attempt count does not overflow the delay
+	if attempt >= 0 && base <= RETRY_MAX_DELAY>>uint(attempt) {
+		delay = base << uint(attempt)
+	}
+	jitter := time.Duration(rand.Int63n(int64(delay))) - delay/2
+	return delay + jitter
+}
+
+//retryAfter returns the delay of the Retry-After header of the response, given in seconds or as an HTTP date
+func retryAfter(resp *Response) (time.Duration, bool) {
+	values, ok := resp.Headers["Retry-After"]
+	if !ok || len(values) == 0 {
+		return 0, false
+	}
+	value := strings.TrimSpace(values[0])
+	var delay time.Duration
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		delay = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(value); err == nil {
+		delay = time.Until(t)
+	} else {
+		return 0, false
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	if delay > RETRY_MAX_DELAY {
+		delay = RETRY_MAX_DELAY
+	}
+	return delay, true
+}
+
+//waitRetry sleeps for the delay before a retry, returning false if the job was stopped meanwhile
+func (j *Job) waitRetry(delay time.Duration) bool {
+	if delay <= 0 {
+		return j.Config.Context.Err() == nil
+	}
+	select {
+	case <-j.Config.Context.Done():
+		return false
+	case <-time.After(delay):
+		return true
+	}
+}
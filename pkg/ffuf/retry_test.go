@@ -0,0 +1,90 @@
+package ffuf
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryDelay float64
+		attempt    int
+		expected   time.Duration
+	}{
+		{"first attempt", 1, 0, time.Second},
+		{"doubled", 1, 1, 2 * time.Second},
+		{"doubled twice", 0.5, 2, 2 * time.Second},
+		{"capped", 1, 6, RETRY_MAX_DELAY},
+		{"capped without overflow", 1.5, 40, RETRY_MAX_DELAY},
+		// 2^30+1 nanoseconds doubled 34 times wraps around to 2^34 nanoseconds
+		{"capped without wrapping around", 1.073741825, 34, RETRY_MAX_DELAY},
+		{"capped with the largest attempt", 1, 1<<31 - 1, RETRY_MAX_DELAY},
+		{"no delay", 0, 3, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := &Job{Config: &Config{RetryDelay: tt.retryDelay}}
+			for i := 0; i < 100; i++ {
+				// Up to a half of the delay is added or removed as jitter
+				d := j.retryDelay(tt.attempt, nil)
+				if d < tt.expected/2 || d > tt.expected*3/2 {
+					t.Fatalf("Expected a delay of %s with jitter, got %s", tt.expected, d)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryDelayRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter []string
+		expected   time.Duration
+		tolerance  time.Duration
+	}{
+		{"seconds", []string{"5"}, 5 * time.Second, 0},
+		{"seconds with whitespace", []string{" 7 "}, 7 * time.Second, 0},
+		{"zero", []string{"0"}, 0, 0},
+		{"negative", []string{"-3"}, 0, 0},
+		{"capped", []string{"3600"}, RETRY_MAX_DELAY, 0},
+		{"HTTP date", []string{time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)}, 10 * time.Second, 2 * time.Second},
+		{"HTTP date in the past", []string{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)}, 0, 0},
+		{"HTTP date capped", []string{time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)}, RETRY_MAX_DELAY, 0},
+		{"first value used", []string{"2", "30"}, 2 * time.Second, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The backoff delay would be way longer than any of the Retry-After delays
+			j := &Job{Config: &Config{RetryDelay: 1000}}
+			resp := &Response{Headers: map[string][]string{"Retry-After": tt.retryAfter}}
+			d := j.retryDelay(3, resp)
+			if d < tt.expected-tt.tolerance || d > tt.expected+tt.tolerance {
+				t.Errorf("Expected a delay of %s, got %s", tt.expected, d)
+			}
+		})
+	}
+}
+
+func TestRetryDelayWithoutRetryAfter(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string][]string
+	}{
+		{"no headers", nil},
+		{"other headers", map[string][]string{"Content-Type": {"text/html"}}},
+		{"empty header", map[string][]string{"Retry-After": {}}},
+		{"invalid value", map[string][]string{"Retry-After": {"soon"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := &Job{Config: &Config{RetryDelay: 1}}
+			// Falls back to the backoff delay of -retry-delay
+			d := j.retryDelay(1, &Response{Headers: tt.headers})
+			if d < time.Second || d > 3*time.Second {
+				t.Errorf("Expected the backoff delay of 2s with jitter, got %s", d)
+			}
+		})
+	}
+}
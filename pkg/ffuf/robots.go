@@ -0,0 +1,96 @@
+package ffuf
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//robotsDelay fetches the robots.txt of the target, and returns the Crawl-delay it states for ffuf, capped to
+//-robots-delay-max. Zero is returned if there's no delay or the robots.txt cannot be fetched.
+func (j *Job) robotsDelay() time.Duration {
+	u, err := url.Parse(j.Config.Url)
+	if err != nil || u.Host == "" || containsKeyword(u.Scheme+"://"+u.Host, j.Config) {
+		j.Output.Warning("The target host is fuzzed, Crawl-delay of robots.txt cannot be applied")
+		return 0
+	}
+	req := NewRequest(j.Config)
+	req.Url = u.Scheme + "://" + u.Host + "/robots.txt"
+	req.Method = "GET"
+	userAgent := fmt.Sprintf("%s v%s", "Fuzz Faster U Fool", Version())
+	for k, v := range j.Config.Headers {
+		if !containsKeyword(k, j.Config) && !containsKeyword(v, j.Config) {
+			req.Headers[k] = v
+			if strings.EqualFold(k, "user-agent") {
+				userAgent = v
+			}
+		}
+	}
+	resp, err := j.Runner.Execute(&req)
+	defer resp.MakeFreeMemory()
+	if err != nil || resp.StatusCode != 200 {
+		return 0
+	}
+	seconds, ok := parseCrawlDelay(resp.Body(), userAgent)
+	if !ok {
+		return 0
+	}
+	if j.Config.RobotsDelayMax > 0 && seconds > j.Config.RobotsDelayMax {
+		seconds = j.Config.RobotsDelayMax
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+//parseCrawlDelay returns the Crawl-delay of the robots.txt group matching the user agent, or the one of the *
+//group. A group matches if its user agent name is a part of the user agent, or is "ffuf".
+func parseCrawlDelay(robots []byte, userAgent string) (float64, bool) {
+	var delay, anyDelay float64
+	var found, anyFound bool
+	agents := make([]string, 0)
+	inRules := false
+	scanner := bufio.NewScanner(bytes.NewReader(robots))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		field := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := strings.TrimSpace(parts[1])
+		if field == "user-agent" {
+			if inRules {
+				// A new group starts
+				agents = agents[:0]
+				inRules = false
+			}
+			agents = append(agents, strings.ToLower(value))
+			continue
+		}
+		inRules = true
+		if field != "crawl-delay" {
+			continue
+		}
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil || seconds < 0 {
+			continue
+		}
+		for _, a := range agents {
+			if a == "*" {
+				anyDelay, anyFound = seconds, true
+			} else if a == "ffuf" || strings.Contains(strings.ToLower(userAgent), a) {
+				delay, found = seconds, true
+			}
+		}
+	}
+	if found {
+		return delay, true
+	}
+	return anyDelay, anyFound
+}
@@ -0,0 +1,71 @@
+package ffuf
+
+import (
+	"fmt"
+	"strings"
+)
+
+//SAFE_ALLOW_PAYLOADS is the -safe-allow value permitting the dangerous payload patterns in safe mode
+const SAFE_ALLOW_PAYLOADS = "payloads"
+
+//safeUnsafeMethods are the state-changing methods refused in safe mode
+var safeUnsafeMethods = []string{"POST", "PUT", "DELETE", "PATCH"}
+
+//safeDenyPatterns are denylist patterns of payloads known to destroy data or disrupt the target, refused in safe mode
+var safeDenyPatterns = []string{
+	`regex:(?i)\b(drop|truncate|alter)\s+(table|database|schema)\b`,
+	`regex:(?i)\bdelete\s+from\b`,
+	`regex:(?i)\bupdate\s+\S+\s+set\b`,
+	`regex:(?i)\b(shutdown|xp_cmdshell)\b`,
+	`regex:(?i)\b(sleep|pg_sleep|benchmark)\s*\(`,
+	`regex:(?i)\bwaitfor\s+delay\b`,
+	`regex:(?i)[;&|` + "`" + `]\s*(rm|mkfs|dd|reboot|halt|poweroff|kill|killall)\b`,
+	`regex:\brm\s+-[a-zA-Z]*[rf]`,
+	`:(){`,
+}
+
+//SafeMethodAllowed checks if the method may be sent with the current safe mode settings
+func (c *Config) SafeMethodAllowed(method string) bool {
+	if !c.SafeMode {
+		return true
+	}
+	method = strings.ToUpper(method)
+	for _, m := range c.SafeAllow {
+		if strings.ToUpper(m) == method {
+			return true
+		}
+	}
+	for _, m := range safeUnsafeMethods {
+		if m == method {
+			return false
+		}
+	}
+	return true
+}
+
+//safeAllowPayloads checks if the dangerous payload patterns have been permitted in safe mode
+func (c *Config) safeAllowPayloads() bool {
+	for _, a := range c.SafeAllow {
+		if strings.ToLower(a) == SAFE_ALLOW_PAYLOADS {
+			return true
+		}
+	}
+	return false
+}
+
+//checkSafeMode returns an error if safe mode is enabled and the configured requests use a state-changing method
+func checkSafeMode(conf *Config) error {
+	if !conf.SafeMode {
+		return nil
+	}
+	methods := []string{conf.Method}
+	for _, op := range conf.ApiOperations {
+		methods = append(methods, op.Method)
+	}
+	for _, m := range methods {
+		if !conf.SafeMethodAllowed(m) {
+			return fmt.Errorf("Safe mode (-safe) refuses to send %s requests. Permit the method with -safe-allow %s if you are sure", strings.ToUpper(m), strings.ToUpper(m))
+		}
+	}
+	return nil
+}
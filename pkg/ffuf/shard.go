@@ -0,0 +1,65 @@
+package ffuf
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//parseShard parses a -shard value N/M to the shard number N and the shard count M
+func parseShard(value string) (int, int, error) {
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) == 2 {
+		shard, serr := strconv.Atoi(strings.TrimSpace(parts[0]))
+		count, cerr := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if serr == nil && cerr == nil && count > 0 && shard >= 1 && shard <= count {
+			return shard, count, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("Shard (-shard) needs to be in format N/M where N is between 1 and M, got %s", value)
+}
+
+//InShard checks if an input belongs to the shard of this instance. The inputs are assigned to the shards by their
+//position, every Mth input starting from the Nth, or by a hash of their values with -shard-hash. Both are the same
+//on every instance given the same wordlists, so the shards never overlap.
+func (c *Config) InShard(position int, input map[string][]byte) bool {
+	if c.ShardCount <= 1 {
+		return true
+	}
+	if c.ShardHash {
+		return int(shardHash(input)%uint64(c.ShardCount)) == c.Shard-1
+	}
+	return (position-1)%c.ShardCount == c.Shard-1
+}
+
+//ShardTotal returns the number of inputs out of the total assigned to the shard of this instance by position. The
+//number of inputs assigned by their hash is not known beforehand, so the total is returned as is.
+func (c *Config) ShardTotal(total int) int {
+	if c.ShardCount <= 1 || c.ShardHash || total < 0 {
+		return total
+	}
+	if total < c.Shard {
+		return 0
+	}
+	return (total-c.Shard)/c.ShardCount + 1
+}
+
+//shardHash hashes the keywords and their values of an input, in sorted keyword order
+func shardHash(input map[string][]byte) uint64 {
+	keywords := make([]string, 0, len(input))
+	for k := range input {
+		keywords = append(keywords, k)
+	}
+	sort.Strings(keywords)
+	h := sha256.New()
+	for _, k := range keywords {
+		h.Write([]byte(k))
+		h.Write([]byte{'='})
+		h.Write(input[k])
+		h.Write([]byte{0})
+	}
+	return binary.BigEndian.Uint64(h.Sum(nil))
+}
@@ -0,0 +1,58 @@
+package ffuf
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+//SNAPSHOT_TIME_FORMAT is the timestamp format in the file names of the result snapshots
+const SNAPSHOT_TIME_FORMAT = "20060102-150405"
+
+//SaveSnapshot writes all of the results so far to a new timestamped file without stopping the job, and returns the
+//name of the file. The format defaults to the output file format (-of). The snapshot is written next to the output
+//file (-o) if one is defined, and to the working directory otherwise.
+func (j *Job) SaveSnapshot(format string) (string, error) {
+	if format == "" {
+		format = j.Config.OutputFormat
+	}
+	if format == "" {
+		format = "json"
+	}
+	base := "ffuf-" + j.Config.ScanID
+	if j.Config.ScanID == "" {
+		base = "ffuf"
+	}
+	if j.Config.OutputFile != "" {
+		base = strings.TrimSuffix(j.Config.OutputFile, filepath.Ext(j.Config.OutputFile))
+	}
+	filename := fmt.Sprintf("%s-snapshot-%s", base, time.Now().Format(SNAPSHOT_TIME_FORMAT))
+	if format != "all" {
+		// With all of the formats, every file gets the extension of its own format
+		filename += "." + format
+	}
+	return filename, j.Output.SaveFile(filename, format)
+}
+
+//snapshotMonitor saves a snapshot of the results on the snapshot signal (SIGUSR1), where available
+func (j *Job) snapshotMonitor() {
+	signals := snapshotSignals()
+	if len(signals) == 0 {
+		return
+	}
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, signals...)
+	go func() {
+		for range sigChan {
+			filename, err := j.SaveSnapshot("")
+			if err != nil {
+				j.Output.Error(fmt.Sprintf("Could not save the results snapshot: %s", err))
+			} else {
+				j.Output.Info(fmt.Sprintf("Results snapshot saved to %s", filename))
+			}
+		}
+	}()
+}
@@ -0,0 +1,12 @@
+// +build !windows
+
+package ffuf
+
+import (
+	"os"
+	"syscall"
+)
+
+func snapshotSignals() []os.Signal {
+	return []os.Signal{syscall.SIGUSR1}
+}
@@ -0,0 +1,10 @@
+// +build windows
+
+package ffuf
+
+import "os"
+
+//snapshotSignals returns no signals, snapshots are saved only from the interactive console on Windows
+func snapshotSignals() []os.Signal {
+	return []os.Signal{}
+}
@@ -0,0 +1,96 @@
+package ffuf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//SNIPER_MARKER encloses the injection positions of the request template in sniper mode: §default value§
+const SNIPER_MARKER = "§"
+
+//SniperKeyword returns the placeholder keyword the injection position of the given index is replaced with in the
+//request template
+func SniperKeyword(index int) string {
+	return fmt.Sprintf("%s%d%s", SNIPER_MARKER, index+1, SNIPER_MARKER)
+}
+
+//IsSniperKeyword checks if the input keyword is an injection position placeholder of sniper mode
+func IsSniperKeyword(keyword string) bool {
+	return strings.HasPrefix(keyword, SNIPER_MARKER) && strings.HasSuffix(keyword, SNIPER_MARKER)
+}
+
+//prepareSniper replaces the marked injection positions of the request template with placeholder keywords, and stores
+//their default values sent while the other positions are fuzzed
+func prepareSniper(conf *Config) error {
+	if keywords := conf.InputKeywords(); len(keywords) != 1 {
+		return fmt.Errorf("Sniper mode (-mode sniper) needs exactly one input keyword, got %d", len(keywords))
+	}
+	conf.SniperDefaults = make([]string, 0)
+	var err error
+	replace := func(s string) string {
+		if err != nil {
+			return s
+		}
+		var res string
+		res, err = markSniperPositions(s, conf)
+		return res
+	}
+	conf.Method = replace(conf.Method)
+	conf.Url = replace(conf.Url)
+	conf.Data = replace(conf.Data)
+	headers := make(map[string]string, len(conf.Headers))
+	// Headers are gone through in a sorted order to keep the position numbering stable
+	for _, k := range sortedHeaderNames(conf.Headers) {
+		headers[k] = replace(conf.Headers[k])
+	}
+	conf.Headers = headers
+	if err != nil {
+		return err
+	}
+	if len(conf.SniperDefaults) == 0 {
+		return fmt.Errorf("Sniper mode (-mode sniper) needs the injection positions marked with %sdefault value%s in the URL, method, headers or POST data", SNIPER_MARKER, SNIPER_MARKER)
+	}
+	return nil
+}
+
+//markSniperPositions replaces the §default§ markers of the string with the placeholder keywords
+func markSniperPositions(s string, conf *Config) (string, error) {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, SNIPER_MARKER)
+		if start < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		end := strings.Index(s[start+len(SNIPER_MARKER):], SNIPER_MARKER)
+		if end < 0 {
+			return "", fmt.Errorf("Sniper mode (-mode sniper) injection position without a closing %s", SNIPER_MARKER)
+		}
+		b.WriteString(s[:start])
+		b.WriteString(SniperKeyword(len(conf.SniperDefaults)))
+		conf.SniperDefaults = append(conf.SniperDefaults, s[start+len(SNIPER_MARKER):start+len(SNIPER_MARKER)+end])
+		s = s[start+2*len(SNIPER_MARKER)+end:]
+	}
+}
+
+//SniperInput returns the input injecting the value to the position of the given index, with the other positions at
+//their default values
+func (c *Config) SniperInput(keyword string, index int, value []byte) map[string][]byte {
+	input := make(map[string][]byte, len(c.SniperDefaults)+1)
+	for i, def := range c.SniperDefaults {
+		input[SniperKeyword(i)] = []byte(def)
+	}
+	input[SniperKeyword(index)] = value
+	input[keyword] = value
+	return input
+}
+
+func sortedHeaderNames(headers map[string]string) []string {
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
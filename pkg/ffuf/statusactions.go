@@ -0,0 +1,79 @@
+package ffuf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	//STATUS_ACTION_RETRY retries the request like the status codes of -retry-on
+	STATUS_ACTION_RETRY = "retry"
+	//STATUS_ACTION_IGNORE drops the response without matching it or counting it for the stop rules
+	STATUS_ACTION_IGNORE = "ignore"
+	//STATUS_ACTION_ERROR counts the response as a request error of the status class
+	STATUS_ACTION_ERROR = "error"
+	//STATUS_ACTION_MATCH matches the response regardless of the matchers and filters
+	STATUS_ACTION_MATCH = "match"
+)
+
+//StatusActions are the accepted actions of -status-action
+var StatusActions = []string{STATUS_ACTION_RETRY, STATUS_ACTION_IGNORE, STATUS_ACTION_ERROR, STATUS_ACTION_MATCH}
+
+//parseStatusActions parses the CODES:ACTION entries of -status-action to a table of the action of every status code.
+//The codes are a comma separated list of status codes and ranges, eg. "500-599:error" or "401,403:ignore". The later
+//entries override the earlier ones.
+func parseStatusActions(entries []string) (map[int64]string, error) {
+	actions := make(map[int64]string)
+	for _, entry := range entries {
+		i := strings.LastIndex(entry, ":")
+		if i < 0 {
+			return actions, fmt.Errorf("Status action (-status-action) needs to be CODES:ACTION, got %s", entry)
+		}
+		codes, action := strings.TrimSpace(entry[:i]), strings.ToLower(strings.TrimSpace(entry[i+1:]))
+		valid := false
+		for _, a := range StatusActions {
+			if a == action {
+				valid = true
+			}
+		}
+		if !valid {
+			return actions, fmt.Errorf("Unknown status action %s in %s. Available actions: %s", action, entry, strings.Join(StatusActions, ", "))
+		}
+		for _, c := range strings.Split(codes, ",") {
+			c = strings.TrimSpace(c)
+			min, max, err := parseStatusRange(c)
+			if err != nil {
+				return actions, fmt.Errorf("Invalid status code %s in status action (-status-action) %s", c, entry)
+			}
+			for code := min; code <= max; code++ {
+				actions[code] = action
+			}
+		}
+	}
+	return actions, nil
+}
+
+//parseStatusRange parses a status code, or a range of them such as 500-599
+func parseStatusRange(value string) (int64, int64, error) {
+	parts := strings.SplitN(value, "-", 2)
+	min, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	max := min
+	if len(parts) == 2 {
+		if max, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+			return 0, 0, err
+		}
+	}
+	if min < 100 || max > 599 || min > max {
+		return 0, 0, fmt.Errorf("not an HTTP status code")
+	}
+	return min, max, nil
+}
+
+//statusAction returns the action of -status-action for the response status, or an empty string if there's none
+func (j *Job) statusAction(resp Response) string {
+	return j.Config.StatusActions[resp.StatusCode]
+}
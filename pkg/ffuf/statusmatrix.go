@@ -0,0 +1,100 @@
+package ffuf
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const depthPrefix = "depth "
+
+//StatusMatrix keeps count of the response status codes per directory depth and file extension of the requests
+type StatusMatrix struct {
+	mutex  sync.Mutex
+	depths map[string]map[int64]int
+	exts   map[string]map[int64]int
+}
+
+func NewStatusMatrix() *StatusMatrix {
+	return &StatusMatrix{
+		depths: make(map[string]map[int64]int),
+		exts:   make(map[string]map[int64]int),
+	}
+}
+
+//Add counts the response status for the requested URL
+func (m *StatusMatrix) Add(reqUrl string, status int64) {
+	u, err := url.Parse(reqUrl)
+	if err != nil {
+		return
+	}
+	p := strings.TrimPrefix(u.Path, "/")
+	depth := fmt.Sprintf("%s%d", depthPrefix, strings.Count(strings.TrimSuffix(p, "/"), "/")+1)
+	ext := "(none)"
+	if strings.HasSuffix(p, "/") || p == "" {
+		ext = "(directory)"
+	} else if e := path.Ext(p); e != "" {
+		ext = strings.ToLower(e)
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	addStatus(m.depths, depth, status)
+	addStatus(m.exts, ext, status)
+}
+
+func addStatus(counts map[string]map[int64]int, key string, status int64) {
+	if _, ok := counts[key]; !ok {
+		counts[key] = make(map[int64]int)
+	}
+	counts[key][status]++
+}
+
+//Report returns the status code distributions, the most common status codes first
+func (m *StatusMatrix) Report() string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	var b strings.Builder
+	b.WriteString("Status codes per directory depth:\n")
+	writeStatusRows(&b, m.depths, depthLess)
+	b.WriteString("Status codes per extension:\n")
+	writeStatusRows(&b, m.exts, func(a, b string) bool { return a < b })
+	return b.String()
+}
+
+//depthLess orders the directory depth rows numerically, so that depth 10 comes after depth 2
+func depthLess(a, b string) bool {
+	da, _ := strconv.Atoi(strings.TrimPrefix(a, depthPrefix))
+	db, _ := strconv.Atoi(strings.TrimPrefix(b, depthPrefix))
+	return da < db
+}
+
+func writeStatusRows(b *strings.Builder, counts map[string]map[int64]int, less func(a, b string) bool) {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+	for _, k := range keys {
+		total := 0
+		statuses := make([]int64, 0, len(counts[k]))
+		for s, c := range counts[k] {
+			statuses = append(statuses, s)
+			total += c
+		}
+		sort.Slice(statuses, func(i, j int) bool {
+			if counts[k][statuses[i]] == counts[k][statuses[j]] {
+				return statuses[i] < statuses[j]
+			}
+			return counts[k][statuses[i]] > counts[k][statuses[j]]
+		})
+		cols := make([]string, 0, len(statuses))
+		for _, s := range statuses {
+			cols = append(cols, fmt.Sprintf("%d: %d (%.1f%%)", s, counts[k][s], float64(counts[k][s])*100/float64(total)))
+		}
+		fmt.Fprintf(b, " :: %-14s: %6d requests, %s\n", k, total, strings.Join(cols, ", "))
+	}
+}
@@ -0,0 +1,57 @@
+package ffuf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStatusMatrixReport(t *testing.T) {
+	m := NewStatusMatrix()
+	for i, u := range []string{
+		"http://example.com/",
+		"http://example.com/index.PHP",
+		"http://example.com/a/b/c/d/e/f/g/h/i/j",
+		"http://example.com/admin/",
+		"http://example.com/admin/login.php",
+		"http://example.com/admin/login.php",
+		"http://example.com/README",
+	} {
+		status := int64(200)
+		if i%2 == 1 {
+			status = 404
+		}
+		m.Add(u, status)
+	}
+	m.Add("http://[::1", 500)
+	expected := strings.Join([]string{
+		"Status codes per directory depth:",
+		" :: depth 1       :      4 requests, 200: 2 (50.0%), 404: 2 (50.0%)",
+		" :: depth 2       :      2 requests, 200: 1 (50.0%), 404: 1 (50.0%)",
+		" :: depth 10      :      1 requests, 200: 1 (100.0%)",
+		"Status codes per extension:",
+		" :: (directory)   :      2 requests, 200: 1 (50.0%), 404: 1 (50.0%)",
+		" :: (none)        :      2 requests, 200: 2 (100.0%)",
+		" :: .php          :      3 requests, 404: 2 (66.7%), 200: 1 (33.3%)",
+		"",
+	}, "\n")
+	if report := m.Report(); report != expected {
+		t.Errorf("Expected the report:\n%s\ngot:\n%s", expected, report)
+	}
+}
+
+func TestDepthLess(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"depth 2", "depth 10", true},
+		{"depth 10", "depth 2", false},
+		{"depth 1", "depth 1", false},
+		{"depth 9", "depth 11", true},
+	}
+	for _, tt := range tests {
+		if got := depthLess(tt.a, tt.b); got != tt.expected {
+			t.Errorf("depthLess(%q, %q): expected %t, got %t", tt.a, tt.b, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package ffuf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	//STOP_RULE_STOP stops the run
+	STOP_RULE_STOP = "stop"
+	//STOP_RULE_PAUSE pauses the job for the duration of the rule
+	STOP_RULE_PAUSE = "pause"
+	//STOP_RULE_SKIP skips the current queue job, continuing with the next one
+	STOP_RULE_SKIP = "skip"
+	//STOP_RULE_ALERT prints a warning and continues
+	STOP_RULE_ALERT = "alert"
+	//STOP_RULE_MIN_SAMPLES is the number of responses a ratio rule without a window needs to see before it applies
+	STOP_RULE_MIN_SAMPLES = 50
+)
+
+//StopRuleActions are the accepted actions of the stop rules (-stop-rule)
+var StopRuleActions = []string{STOP_RULE_STOP, STOP_RULE_PAUSE, STOP_RULE_SKIP, STOP_RULE_ALERT}
+
+//StopRule is a condition on the responses of the job, and the action taken when it is met. The responses with one of
+//the status codes, and the failed requests if Errors is set or their error class is one of ErrorClasses, are counted
+//over the last Window responses, or over the whole job without a window. The rule is met when the count exceeds Count
+//or their share exceeds Ratio, or both if both are set.
+type StopRule struct {
+	Rule         string        `json:"rule"`
+	Action       string        `json:"action"`
+	Duration     time.Duration `json:"duration"`
+	Status       []int64       `json:"status"`
+	Errors       bool          `json:"errors"`
+	ErrorClasses []string      `json:"error_classes"`
+	Ratio        float64       `json:"ratio"`
+	Count        int           `json:"count"`
+	Window       int           `json:"window"`
+	Min          int           `json:"min"`
+	// The stop reason and message of the built-in rules of -sf and -sa
+	reason  string
+	message string
+}
+
+//ParseStopRule parses a rule of the form ACTION [DURATION] if CONDITION..., for example
+//"stop if status=403 ratio>0.8 window=100" or "pause 60s if status=429 count>20". The conditions are status=CODES
+//(a comma separated list of status codes and ranges, "error" for failed requests, or error:CLASS for the requests
+//failed with an error of one of ErrorClasses, eg. error:dns), ratio>SHARE, count>NUMBER, window=RESPONSES and
+//min=RESPONSES for the number of responses a ratio needs before it applies.
+func ParseStopRule(value string) (StopRule, error) {
+	rule := StopRule{Rule: strings.TrimSpace(value)}
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return rule, fmt.Errorf("Empty stop rule (-stop-rule)")
+	}
+	rule.Action = strings.ToLower(fields[0])
+	fields = fields[1:]
+	validAction := false
+	for _, a := range StopRuleActions {
+		if rule.Action == a {
+			validAction = true
+		}
+	}
+	if !validAction {
+		return rule, fmt.Errorf("Unknown stop rule action %s in \"%s\". Available actions: %s", rule.Action, rule.Rule, strings.Join(StopRuleActions, ", "))
+	}
+	if rule.Action == STOP_RULE_PAUSE {
+		if len(fields) == 0 {
+			return rule, fmt.Errorf("Stop rule \"%s\" needs a pause duration, eg. \"pause 60s if ...\"", rule.Rule)
+		}
+		d, err := parseStopRuleDuration(fields[0])
+		if err != nil {
+			return rule, fmt.Errorf("Invalid pause duration in stop rule \"%s\": %s", rule.Rule, err)
+		}
+		rule.Duration = d
+		fields = fields[1:]
+	}
+	if len(fields) > 0 && strings.ToLower(fields[0]) == "if" {
+		fields = fields[1:]
+	}
+	conditions := make(map[string]bool)
+	for _, f := range fields {
+		key, err := rule.parseCondition(f)
+		if err != nil {
+			return rule, fmt.Errorf("Invalid condition %s in stop rule \"%s\": %s", f, rule.Rule, err)
+		}
+		conditions[key] = true
+	}
+	if !conditions["status"] {
+		return rule, fmt.Errorf("Stop rule \"%s\" needs the responses to count, eg. status=403", rule.Rule)
+	}
+	if !conditions["ratio"] && !conditions["count"] {
+		return rule, fmt.Errorf("Stop rule \"%s\" needs a ratio>SHARE or count>NUMBER condition", rule.Rule)
+	}
+	return rule, nil
+}
+
+//parseStopRuleDuration parses a duration such as 60s or 5m, or a plain number of seconds
+func parseStopRuleDuration(value string) (time.Duration, error) {
+	if secs, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(secs * float64(time.Second)), nil
+	}
+	return time.ParseDuration(value)
+}
+
+//parseCondition parses a single KEY=VALUE or KEY>VALUE condition of the rule, and returns the key
+func (r *StopRule) parseCondition(cond string) (string, error) {
+	i := strings.IndexAny(cond, "=>")
+	if i <= 0 {
+		return "", fmt.Errorf("expected KEY=VALUE or KEY>VALUE")
+	}
+	key, op, value := strings.ToLower(cond[:i]), cond[i:i+1], cond[i+1:]
+	wantOp := "="
+	if key == "ratio" || key == "count" {
+		wantOp = ">"
+	}
+	if op != wantOp {
+		return key, fmt.Errorf("%s needs to be compared with %s", key, wantOp)
+	}
+	var err error
+	switch key {
+	case "status":
+		for _, s := range strings.Split(value, ",") {
+			if strings.ToLower(s) == "error" || strings.ToLower(s) == "errors" {
+				r.Errors = true
+				continue
+			}
+			if strings.HasPrefix(strings.ToLower(s), "error:") {
+				class := strings.TrimPrefix(strings.ToLower(s), "error:")
+				if !validErrorClass(class) {
+					return key, fmt.Errorf("unknown error class %s, available classes: %s", class, strings.Join(ErrorClasses, ", "))
+				}
+				r.ErrorClasses = append(r.ErrorClasses, class)
+				continue
+			}
+			var min, max int64
+			if parts := strings.SplitN(s, "-", 2); len(parts) == 2 {
+				min, err = strconv.ParseInt(parts[0], 10, 64)
+				if err == nil {
+					max, err = strconv.ParseInt(parts[1], 10, 64)
+				}
+			} else {
+				min, err = strconv.ParseInt(s, 10, 64)
+				max = min
+			}
+			if err != nil || min > max {
+				return key, fmt.Errorf("invalid status code %s", s)
+			}
+			for code := min; code <= max; code++ {
+				r.Status = append(r.Status, code)
+			}
+		}
+	case "ratio":
+		r.Ratio, err = strconv.ParseFloat(value, 64)
+		if err == nil && (r.Ratio <= 0 || r.Ratio >= 1) {
+			err = fmt.Errorf("the ratio needs to be between 0 and 1")
+		}
+	case "count":
+		r.Count, err = strconv.Atoi(value)
+		if err == nil && r.Count < 0 {
+			err = fmt.Errorf("the count cannot be negative")
+		}
+	case "window":
+		r.Window, err = strconv.Atoi(value)
+		if err == nil && r.Window < 1 {
+			err = fmt.Errorf("the window needs to be at least one response")
+		}
+	case "min":
+		r.Min, err = strconv.Atoi(value)
+	default:
+		err = fmt.Errorf("unknown condition, expected status, ratio, count, window or min")
+	}
+	return key, err
+}
+
+//Message returns the message printed when the rule is met
+func (r StopRule) Message() string {
+	if r.message != "" {
+		return r.message
+	}
+	switch r.Action {
+	case STOP_RULE_STOP:
+		return fmt.Sprintf("Stop rule \"%s\" met, exiting.", r.Rule)
+	case STOP_RULE_PAUSE:
+		return fmt.Sprintf("Stop rule \"%s\" met, pausing for %s.", r.Rule, r.Duration)
+	case STOP_RULE_SKIP:
+		return fmt.Sprintf("Stop rule \"%s\" met, continuing with the next job if one exists.", r.Rule)
+	}
+	return fmt.Sprintf("Stop rule \"%s\" met.", r.Rule)
+}
+
+//Reason returns the stop reason of the run summary when a stop rule stops the run
+func (r StopRule) Reason() string {
+	if r.reason != "" {
+		return r.reason
+	}
+	return STOP_RULE
+}
+
+//validErrorClass checks if the class is one of ErrorClasses
+func validErrorClass(class string) bool {
+	for _, c := range ErrorClasses {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+//matches returns true if the rule counts the response status, or the request failed with the error class
+func (r StopRule) matches(status int64, errClass string) bool {
+	if errClass != "" {
+		if r.Errors {
+			return true
+		}
+		for _, c := range r.ErrorClasses {
+			if c == errClass {
+				return true
+			}
+		}
+		return false
+	}
+	for _, s := range r.Status {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+//builtinStopRules returns the rules of the -sf and -sa options
+func builtinStopRules(conf *Config) []StopRule {
+	rules := make([]StopRule, 0)
+	if conf.StopOn403 || conf.StopOnAll {
+		rules = append(rules, StopRule{Rule: "stop if status=403 ratio>0.95", Action: STOP_RULE_STOP, Status: []int64{403}, Ratio: 0.95, Min: STOP_RULE_MIN_SAMPLES + 1,
+			reason: STOP_403, message: "Getting an unusual amount of 403 responses, exiting."})
+	}
+	if conf.StopOnAll {
+		rules = append(rules, StopRule{Rule: "stop if status=429 ratio>0.2", Action: STOP_RULE_STOP, Status: []int64{429}, Ratio: 0.2, Min: STOP_RULE_MIN_SAMPLES + 1,
+			reason: STOP_429, message: "Getting an unusual amount of 429 responses, exiting."})
+	}
+	return rules
+}
+
+//stopRuleState counts the responses of a rule
+type stopRuleState struct {
+	rule   StopRule
+	window []bool
+	pos    int
+	seen   int
+	hits   int
+}
+
+//StopRules evaluates the stop rules against the responses of the current job
+type StopRules struct {
+	mutex  sync.Mutex
+	states []*stopRuleState
+}
+
+func NewStopRules(rules []StopRule) *StopRules {
+	s := &StopRules{states: make([]*stopRuleState, 0, len(rules))}
+	for _, r := range rules {
+		s.states = append(s.states, &stopRuleState{rule: r, window: make([]bool, r.Window)})
+	}
+	return s
+}
+
+//Observe counts a response status, or the error class of a failed request, for the rules
+func (s *StopRules) Observe(status int64, errClass string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, st := range s.states {
+		hit := st.rule.matches(status, errClass)
+		if len(st.window) == 0 {
+			st.seen++
+			if hit {
+				st.hits++
+			}
+			continue
+		}
+		if st.seen == len(st.window) && st.window[st.pos] {
+			// The oldest response falls out of the window
+			st.hits--
+		}
+		if st.seen < len(st.window) {
+			st.seen++
+		}
+		st.window[st.pos] = hit
+		st.pos = (st.pos + 1) % len(st.window)
+		if hit {
+			st.hits++
+		}
+	}
+}
+
+//Check returns the first rule met, if any. The counts of the rule are reset, so that it is met again only by new
+//responses.
+func (s *StopRules) Check() (StopRule, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, st := range s.states {
+		if st.met() {
+			st.reset()
+			return st.rule, true
+		}
+	}
+	return StopRule{}, false
+}
+
+//Reset clears the counts of every rule, when starting a new queue job
+func (s *StopRules) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, st := range s.states {
+		st.reset()
+	}
+}
+
+func (st *stopRuleState) met() bool {
+	if (st.rule.Ratio == 0 || st.rule.Count > 0) && st.hits <= st.rule.Count {
+		// A rule without a ratio has a count condition, even if the count is zero
+		return false
+	}
+	if st.rule.Ratio > 0 {
+		min := st.rule.Min
+		if min == 0 {
+			min = len(st.window)
+			if min == 0 {
+				min = STOP_RULE_MIN_SAMPLES
+			}
+		}
+		if st.seen == 0 || st.seen < min || float64(st.hits)/float64(st.seen) <= st.rule.Ratio {
+			return false
+		}
+	}
+	return true
+}
+
+func (st *stopRuleState) reset() {
+	st.pos, st.seen, st.hits = 0, 0, 0
+	for i := range st.window {
+		st.window[i] = false
+	}
+}
@@ -0,0 +1,228 @@
+package ffuf
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	STOP_COMPLETED   = "completed"
+	STOP_INTERRUPTED = "interrupted"
+	STOP_MAXTIME     = "maxtime"
+	STOP_403         = "403"
+	STOP_429         = "429"
+	STOP_ERRORS      = "errors"
+	STOP_ERROR       = "error"
+	STOP_PROXY       = "proxy"
+	STOP_RULE        = "rule"
+	STOP_SHUTDOWN    = "shutdown"
+)
+
+//SUMMARY_TOP_LENGTHS is the number of the most common response content lengths in the summary
+const SUMMARY_TOP_LENGTHS = 10
+
+//Summary is the machine-readable verdict of a run, written to stderr with -summary-json and to the output file
+type Summary struct {
+	ScanID            string            `json:"scan_id"`
+	CommandLine       string            `json:"commandline"`
+	StartTime         time.Time         `json:"start_time"`
+	EndTime           time.Time         `json:"end_time"`
+	Duration          float64           `json:"duration"` // seconds
+	Jobs              int               `json:"jobs"`
+	Requests          int               `json:"requests"`
+	RequestsPerSecond float64           `json:"requests_per_second"`
+	Matches           int               `json:"matches"`
+	Denied            int               `json:"denied"`
+	Duplicates        int               `json:"duplicates"`
+	Errors            int               `json:"errors"`
+	ErrorClasses      map[string]int    `json:"errors_by_class"`
+	StatusCodes       map[int64]int     `json:"status_codes"`
+	TopLengths        []LengthCount     `json:"top_lengths"`
+	StopReason        string            `json:"stop_reason"`
+	Message           string            `json:"message,omitempty"`
+	Filters           []FilterStat      `json:"filters,omitempty"`
+	Quarantined       []QuarantinedHost `json:"quarantined_hosts,omitempty"`
+	Coverage          []JobCoverage     `json:"coverage,omitempty"`
+}
+
+//LengthCount is the number of responses of a content length
+type LengthCount struct {
+	Length    int64 `json:"length"`
+	Responses int   `json:"responses"`
+}
+
+//ResponseStats keeps count of the response status codes and content lengths of the run
+type ResponseStats struct {
+	mutex    sync.Mutex
+	statuses map[int64]int
+	lengths  map[int64]int
+}
+
+func NewResponseStats() *ResponseStats {
+	return &ResponseStats{
+		statuses: make(map[int64]int),
+		lengths:  make(map[int64]int),
+	}
+}
+
+//Observe counts the status code and the content length of a response
+func (r *ResponseStats) Observe(resp *Response) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.statuses[resp.StatusCode]++
+	r.lengths[resp.ContentLength]++
+}
+
+//Statuses returns the number of responses per status code
+func (r *ResponseStats) Statuses() map[int64]int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	statuses := make(map[int64]int, len(r.statuses))
+	for k, v := range r.statuses {
+		statuses[k] = v
+	}
+	return statuses
+}
+
+//TopLengths returns the n most common content lengths, the most common first
+func (r *ResponseStats) TopLengths(n int) []LengthCount {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	lengths := make([]LengthCount, 0, len(r.lengths))
+	for l, c := range r.lengths {
+		lengths = append(lengths, LengthCount{Length: l, Responses: c})
+	}
+	sort.Slice(lengths, func(i, j int) bool {
+		if lengths[i].Responses != lengths[j].Responses {
+			return lengths[i].Responses > lengths[j].Responses
+		}
+		return lengths[i].Length < lengths[j].Length
+	})
+	if len(lengths) > n {
+		lengths = lengths[:n]
+	}
+	return lengths
+}
+
+//NewErrorSummary returns the summary of a run that failed before the job was started
+func NewErrorSummary(conf *Config, err error) Summary {
+	now := time.Now()
+	s := Summary{StartTime: now, EndTime: now, ErrorClasses: map[string]int{}, StatusCodes: map[int64]int{}, TopLengths: []LengthCount{}, StopReason: STOP_ERROR}
+	if conf != nil {
+		s.ScanID = conf.ScanID
+		s.CommandLine = conf.CommandLine
+	}
+	if err != nil {
+		s.Message = err.Error()
+	}
+	return s
+}
+
+//Summary returns the summary of the job run so far
+func (j *Job) Summary() Summary {
+	j.ErrorMutex.Lock()
+	defer j.ErrorMutex.Unlock()
+	end := time.Now()
+	s := Summary{
+		ScanID:       j.Config.ScanID,
+		CommandLine:  j.Config.CommandLine,
+		StartTime:    j.startTime,
+		EndTime:      end,
+		Duration:     end.Sub(j.startTime).Seconds(),
+		Jobs:         j.jobsProcessed,
+		Requests:     j.requestCount,
+		Matches:      j.matchCount,
+		Denied:       j.deniedInputs,
+		Errors:       j.ErrorCounter,
+		ErrorClasses: make(map[string]int),
+		StatusCodes:  j.responseStats.Statuses(),
+		TopLengths:   j.responseStats.TopLengths(SUMMARY_TOP_LENGTHS),
+		StopReason:   j.stopReason,
+		Message:      strings.TrimSpace(j.Error),
+	}
+	if s.Duration > 0 {
+		s.RequestsPerSecond = float64(s.Requests) / s.Duration
+	}
+	for k, v := range j.errorClasses {
+		s.ErrorClasses[k] = v
+	}
+	if s.StopReason == "" {
+		s.StopReason = STOP_COMPLETED
+	}
+	if j.filterStats != nil {
+		s.Filters = j.filterStats.Stats()
+	}
+	if j.requestCache != nil {
+		s.Duplicates = j.requestCache.Skipped()
+	}
+	if j.quarantine != nil {
+		s.Quarantined = j.quarantine.Hosts()
+	}
+	if j.coverage != nil {
+		s.Coverage = j.coverage.Stats()
+	}
+	return s
+}
+
+//WriteSummary writes the summary as a single line of JSON
+func WriteSummary(w io.Writer, s Summary) error {
+	js, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", js)
+	return err
+}
+
+const (
+	ERROR_DNS                = "dns"
+	ERROR_TLS                = "tls"
+	ERROR_TIMEOUT            = "timeout"
+	ERROR_CONNECTION_REFUSED = "connection-refused"
+	ERROR_CONNECTION_RESET   = "connection-reset"
+	ERROR_REDIRECTS          = "redirects"
+	ERROR_PROXY              = "proxy"
+	ERROR_PREPARE            = "prepare"
+	ERROR_SAFE               = "safe"
+	ERROR_STATUS             = "status"
+	ERROR_OTHER              = "other"
+)
+
+//ErrorClasses are the categories the errors are counted by in the progress, the summary and the output files, and
+//that the stop rules can count with status=error:CLASS
+var ErrorClasses = []string{ERROR_DNS, ERROR_TLS, ERROR_TIMEOUT, ERROR_CONNECTION_REFUSED, ERROR_CONNECTION_RESET, ERROR_REDIRECTS, ERROR_PROXY, ERROR_PREPARE, ERROR_SAFE, ERROR_STATUS, ERROR_OTHER}
+
+//errorClass returns the category of a request error
+func errorClass(err error) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var certErr x509.CertificateInvalidError
+	var authErr x509.UnknownAuthorityError
+	var hostErr x509.HostnameError
+	switch {
+	case errors.Is(err, ErrProxyUnavailable), strings.Contains(err.Error(), "proxyconnect"), strings.Contains(err.Error(), "socks connect"):
+		return ERROR_PROXY
+	case errors.As(err, &dnsErr):
+		return ERROR_DNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ERROR_CONNECTION_REFUSED
+	case errors.Is(err, syscall.ECONNRESET):
+		return ERROR_CONNECTION_RESET
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ERROR_TIMEOUT
+	case errors.As(err, &certErr), errors.As(err, &authErr), errors.As(err, &hostErr), strings.Contains(err.Error(), "tls:"):
+		return ERROR_TLS
+	case strings.Contains(err.Error(), "stopped after"):
+		return ERROR_REDIRECTS
+	}
+	return ERROR_OTHER
+}
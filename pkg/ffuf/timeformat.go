@@ -0,0 +1,42 @@
+package ffuf
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TIME_FORMAT_RFC3339     = "rfc3339"
+	TIME_FORMAT_RFC3339NANO = "rfc3339nano"
+	TIME_FORMAT_RFC1123     = "rfc1123"
+	TIME_FORMAT_UNIX        = "unix"
+	TIME_FORMAT_UNIXMS      = "unixms"
+)
+
+//timeLayouts are the named time formats of -time-format, anything else is a Go time layout
+var timeLayouts = map[string]string{
+	TIME_FORMAT_RFC3339:     time.RFC3339,
+	TIME_FORMAT_RFC3339NANO: time.RFC3339Nano,
+	TIME_FORMAT_RFC1123:     time.RFC1123Z,
+}
+
+//FormatTime formats a time of the output files and the results in the -time-format and -timezone. Without them,
+//the layout given is used in the timezone of the time.
+func (c *Config) FormatTime(t time.Time, layout string) string {
+	if c.TimeLocation != nil {
+		t = t.In(c.TimeLocation)
+	}
+	switch strings.ToLower(c.TimeFormat) {
+	case "":
+		return t.Format(layout)
+	case TIME_FORMAT_UNIX:
+		return strconv.FormatInt(t.Unix(), 10)
+	case TIME_FORMAT_UNIXMS:
+		return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+	}
+	if named, ok := timeLayouts[strings.ToLower(c.TimeFormat)]; ok {
+		return t.Format(named)
+	}
+	return t.Format(c.TimeFormat)
+}
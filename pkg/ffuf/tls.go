@@ -0,0 +1,116 @@
+package ffuf
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+//TLSVersions are the accepted values of -tls-min and -tls-max
+var TLSVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+//NewTLSConfig returns the TLS client configuration of the runners. The certificates of the targets are not verified,
+//unless a CA bundle (-ca-cert) is defined, in which case they need to be signed by it or by a system CA.
+func NewTLSConfig(conf *Config) (*tls.Config, error) {
+	tlsConf := &tls.Config{
+		InsecureSkipVerify: true,
+		Renegotiation:      tls.RenegotiateOnceAsClient,
+		ServerName:         conf.SNI,
+	}
+	if conf.ClientCert != "" {
+		key := conf.ClientKey
+		if key == "" {
+			// The key may be in the same PEM file as the certificate
+			key = conf.ClientCert
+		}
+		cert, err := tls.LoadX509KeyPair(conf.ClientCert, key)
+		if err != nil {
+			return tlsConf, fmt.Errorf("Could not load the client certificate: %s", err)
+		}
+		tlsConf.Certificates = []tls.Certificate{cert}
+	}
+	if conf.CACert != "" {
+		pem, err := ioutil.ReadFile(conf.CACert)
+		if err != nil {
+			return tlsConf, fmt.Errorf("Could not read the CA bundle: %s", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return tlsConf, fmt.Errorf("No certificates found in the CA bundle %s", conf.CACert)
+		}
+		tlsConf.RootCAs = pool
+		tlsConf.InsecureSkipVerify = false
+	}
+	for _, v := range []struct {
+		name    string
+		value   string
+		version *uint16
+	}{{"-tls-min", conf.TLSMinVersion, &tlsConf.MinVersion}, {"-tls-max", conf.TLSMaxVersion, &tlsConf.MaxVersion}} {
+		if v.value == "" {
+			continue
+		}
+		version, ok := TLSVersions[v.value]
+		if !ok {
+			return tlsConf, fmt.Errorf("Unknown TLS version (%s): %s. Available versions: %s", v.name, v.value, strings.Join(tlsVersionNames(), ", "))
+		}
+		*v.version = version
+	}
+	if tlsConf.MinVersion != 0 && tlsConf.MaxVersion != 0 && tlsConf.MinVersion > tlsConf.MaxVersion {
+		return tlsConf, fmt.Errorf("Minimum TLS version (-tls-min) is greater than the maximum (-tls-max)")
+	}
+	if len(conf.TLSCiphers) > 0 {
+		suites := make(map[string]uint16)
+		// The insecure suites are included on purpose, for fuzzing legacy appliances
+		for _, s := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+			suites[s.Name] = s.ID
+		}
+		for _, name := range conf.TLSCiphers {
+			id, ok := suites[name]
+			if !ok {
+				return tlsConf, fmt.Errorf("Unknown TLS cipher suite (-tls-ciphers): %s", name)
+			}
+			tlsConf.CipherSuites = append(tlsConf.CipherSuites, id)
+		}
+	}
+	// Session keys for decrypting the captured traffic
+	if conf.TLSKeyLog != nil && conf.Pcap != nil {
+		tlsConf.KeyLogWriter = io.MultiWriter(conf.TLSKeyLog, conf.Pcap.KeyLog())
+	} else if conf.TLSKeyLog != nil {
+		tlsConf.KeyLogWriter = conf.TLSKeyLog
+	} else if conf.Pcap != nil {
+		tlsConf.KeyLogWriter = conf.Pcap.KeyLog()
+	}
+	return tlsConf, nil
+}
+
+//NewTLSKeyLog opens the TLS key log file (-tls-keylog). The keys are appended, like the browsers honouring
+//SSLKEYLOGFILE do, so that the file may be shared with them.
+func NewTLSKeyLog(filename string) (*os.File, error) {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("could not open the TLS key log file: %s", err)
+	}
+	return f, nil
+}
+
+func tlsVersionNames() []string {
+	names := make([]string, 0, len(TLSVersions))
+	for k := range TLSVersions {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
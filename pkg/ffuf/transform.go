@@ -0,0 +1,48 @@
+package ffuf
+
+import (
+	"fmt"
+	"strings"
+)
+
+//InputTransformations are the operations available in the input transformation stages of -transform
+var InputTransformations = []string{"original", "upper", "lower", "capitalize", "urlencode", "doubleurlencode", "base64"}
+
+//ParseInputTransform parses an input transformation pipeline KEYWORD:STAGE[;STAGE...]. Every stage is a comma
+//separated list of operations, each producing its own variant of the input. Values starting with a dot are
+//extensions appended to the input. For example "FUZZ:original,.php,.bak;urlencode" produces the input, and the input
+//with each of the extensions, URL encoded.
+func ParseInputTransform(value string) (string, [][]string, error) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", nil, fmt.Errorf("Invalid input transformation %s, expected KEYWORD:STAGE[;STAGE...]", value)
+	}
+	stages := make([][]string, 0)
+	for _, stage := range strings.Split(parts[1], ";") {
+		ops := make([]string, 0)
+		for _, op := range strings.Split(stage, ",") {
+			op = strings.TrimSpace(op)
+			if op == "" {
+				continue
+			}
+			if !strings.HasPrefix(op, ".") && !knownTransformation(op) {
+				return "", nil, fmt.Errorf("Unknown input transformation %s. Available transformations: %s, or .extension", op, strings.Join(InputTransformations, ", "))
+			}
+			ops = append(ops, op)
+		}
+		if len(ops) == 0 {
+			return "", nil, fmt.Errorf("Empty stage in input transformation %s", value)
+		}
+		stages = append(stages, ops)
+	}
+	return parts[0], stages, nil
+}
+
+func knownTransformation(op string) bool {
+	for _, t := range InputTransformations {
+		if op == t {
+			return true
+		}
+	}
+	return false
+}
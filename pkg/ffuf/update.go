@@ -0,0 +1,175 @@
+package ffuf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//UPDATE_TIMEOUT is the time the update check may take, it never delays the scan itself
+const UPDATE_TIMEOUT = 10 * time.Second
+
+//UpdateInfo is the response of the version endpoint. Both the GitHub release format (tag_name, html_url) and a
+//custom format (version, url) are accepted. A custom endpoint may list the latest versions of wordlists too.
+type UpdateInfo struct {
+	Version   string           `json:"version"`
+	TagName   string           `json:"tag_name"`
+	Url       string           `json:"url"`
+	HtmlUrl   string           `json:"html_url"`
+	Wordlists []UpdateWordlist `json:"wordlists"`
+}
+
+//UpdateWordlist describes the latest published version of a wordlist, matched to the used wordlists by file name
+type UpdateWordlist struct {
+	Name    string    `json:"name"`
+	SHA256  string    `json:"sha256"`
+	Updated time.Time `json:"updated"`
+	Url     string    `json:"url"`
+}
+
+//checkUpdates fetches the version endpoint and warns about an outdated binary and wordlists
+func (j *Job) checkUpdates() {
+	info, err := fetchUpdateInfo(j.Config.UpdateURL)
+	if err != nil {
+		log.Printf("Update check failed: %s", err)
+		return
+	}
+	for _, warning := range info.warnings(VERSION, j.Config.InputProviders) {
+		j.Output.Warning(warning)
+	}
+}
+
+func fetchUpdateInfo(endpoint string) (UpdateInfo, error) {
+	var info UpdateInfo
+	client := http.Client{Timeout: UPDATE_TIMEOUT}
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return info, err
+	}
+	req.Header.Set("User-Agent", fmt.Sprintf("%s v%s", "Fuzz Faster U Fool", Version()))
+	resp, err := client.Do(req)
+	if err != nil {
+		return info, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return info, fmt.Errorf("version endpoint returned status %d", resp.StatusCode)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return info, err
+	}
+	err = json.Unmarshal(body, &info)
+	return info, err
+}
+
+//warnings returns the messages about the outdated binary and wordlists
+func (u UpdateInfo) warnings(current string, providers []InputProviderConfig) []string {
+	warnings := make([]string, 0)
+	latest := u.Version
+	if latest == "" {
+		latest = u.TagName
+	}
+	if latest != "" && newerVersion(latest, current) {
+		link := u.Url
+		if link == "" {
+			link = u.HtmlUrl
+		}
+		msg := fmt.Sprintf("A newer version of ffuf is available: %s (running %s)", strings.TrimPrefix(latest, "v"), current)
+		if link != "" {
+			msg += ", " + link
+		}
+		warnings = append(warnings, msg)
+	}
+	for _, p := range providers {
+		if p.Name != "wordlist" {
+			continue
+		}
+		for _, w := range u.Wordlists {
+			if w.Name != filepath.Base(p.Value) {
+				continue
+			}
+			if wordlistOutdated(p.Value, w) {
+				msg := fmt.Sprintf("Wordlist %s is outdated", p.Value)
+				if !w.Updated.IsZero() {
+					msg += fmt.Sprintf(", updated version published %s", w.Updated.Format("2006-01-02"))
+				}
+				if w.Url != "" {
+					msg += ": " + w.Url
+				}
+				warnings = append(warnings, msg)
+			}
+		}
+	}
+	return warnings
+}
+
+//wordlistOutdated checks if the local wordlist file is older than the published one. The file is outdated if its
+//checksum differs, and it was last modified before the publication time when both are known.
+func wordlistOutdated(filename string, w UpdateWordlist) bool {
+	stat, err := os.Stat(filename)
+	if err != nil {
+		return false
+	}
+	if !w.Updated.IsZero() && !stat.ModTime().Before(w.Updated) {
+		return false
+	}
+	if w.SHA256 == "" {
+		return !w.Updated.IsZero()
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return false
+	}
+	return !strings.EqualFold(hex.EncodeToString(h.Sum(nil)), w.SHA256)
+}
+
+//newerVersion checks if the version is newer than the current one, comparing the numeric parts of the versions
+func newerVersion(version, current string) bool {
+	a := versionParts(version)
+	b := versionParts(current)
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	return false
+}
+
+func versionParts(version string) []int {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	parts := make([]int, 0)
+	for _, p := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
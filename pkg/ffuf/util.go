@@ -1,6 +1,7 @@
 package ffuf
 
 import (
+	cryptorand "crypto/rand"
 	"fmt"
 	"math/rand"
 	"os"
@@ -18,6 +19,18 @@ func RandomString(n int) string {
 	return string(s)
 }
 
+//NewScanID returns a random (version 4) UUID identifying a run
+func NewScanID() string {
+	b := make([]byte, 16)
+	if _, err := cryptorand.Read(b); err != nil {
+		// Fall back to the non-cryptographic generator, the ID does not need to be unpredictable
+		rand.Read(b)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
 //UniqStringSlice returns an unordered slice of unique strings. The duplicates are dropped
 func UniqStringSlice(inslice []string) []string {
 	found := map[string]bool{}
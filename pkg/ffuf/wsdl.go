@@ -0,0 +1,285 @@
+package ffuf
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+const (
+	SOAP11_ENVELOPE_NS = "http://schemas.xmlsoap.org/soap/envelope/"
+	SOAP12_ENVELOPE_NS = "http://www.w3.org/2003/05/soap-envelope"
+	WSDL_SOAP12_NS     = "http://schemas.xmlsoap.org/wsdl/soap12/"
+)
+
+type wsdlDefinitions struct {
+	TargetNamespace string `xml:"targetNamespace,attr"`
+	Types           struct {
+		Schemas []xsdSchema `xml:"schema"`
+	} `xml:"types"`
+	Messages  []wsdlMessage  `xml:"message"`
+	PortTypes []wsdlPortType `xml:"portType"`
+	Bindings  []wsdlBinding  `xml:"binding"`
+	Services  []struct {
+		Ports []struct {
+			Binding string `xml:"binding,attr"`
+			Address struct {
+				XMLName  xml.Name
+				Location string `xml:"location,attr"`
+			} `xml:"address"`
+		} `xml:"port"`
+	} `xml:"service"`
+}
+
+type xsdSchema struct {
+	TargetNamespace    string           `xml:"targetNamespace,attr"`
+	ElementFormDefault string           `xml:"elementFormDefault,attr"`
+	Elements           []xsdElement     `xml:"element"`
+	ComplexTypes       []xsdComplexType `xml:"complexType"`
+}
+
+type xsdElement struct {
+	Name        string          `xml:"name,attr"`
+	Type        string          `xml:"type,attr"`
+	Ref         string          `xml:"ref,attr"`
+	ComplexType *xsdComplexType `xml:"complexType"`
+}
+
+type xsdComplexType struct {
+	Name      string       `xml:"name,attr"`
+	Sequence  []xsdElement `xml:"sequence>element"`
+	All       []xsdElement `xml:"all>element"`
+	Extension struct {
+		Base     string       `xml:"base,attr"`
+		Sequence []xsdElement `xml:"sequence>element"`
+	} `xml:"complexContent>extension"`
+}
+
+type wsdlMessage struct {
+	Name  string `xml:"name,attr"`
+	Parts []struct {
+		Name    string `xml:"name,attr"`
+		Element string `xml:"element,attr"`
+		Type    string `xml:"type,attr"`
+	} `xml:"part"`
+}
+
+type wsdlPortType struct {
+	Name       string `xml:"name,attr"`
+	Operations []struct {
+		Name  string `xml:"name,attr"`
+		Input struct {
+			Message string `xml:"message,attr"`
+		} `xml:"input"`
+	} `xml:"operation"`
+}
+
+type wsdlBinding struct {
+	Name        string `xml:"name,attr"`
+	Type        string `xml:"type,attr"`
+	SOAPBinding struct {
+		XMLName xml.Name
+	} `xml:"binding"`
+	Operations []struct {
+		Name          string `xml:"name,attr"`
+		SOAPOperation struct {
+			SOAPAction string `xml:"soapAction,attr"`
+		} `xml:"operation"`
+	} `xml:"operation"`
+}
+
+//parseWSDL reads a WSDL 1.1 document and creates a SOAP request template for every operation, with the FUZZ
+//keyword in place of every value of the request envelope
+func parseWSDL(parseOpts *ConfigOptions, conf *Config) error {
+	content, err := ioutil.ReadFile(parseOpts.Input.WSDL)
+	if err != nil {
+		return fmt.Errorf("could not read the WSDL document: %s", err)
+	}
+	var defs wsdlDefinitions
+	if err := xml.Unmarshal(content, &defs); err != nil {
+		return fmt.Errorf("could not parse the WSDL document: %s", err)
+	}
+	seen := make(map[string]bool)
+	for _, service := range defs.Services {
+		for _, port := range service.Ports {
+			binding := defs.binding(localName(port.Binding))
+			if binding == nil || binding.SOAPBinding.XMLName.Local == "" || port.Address.Location == "" {
+				// Not a SOAP binding
+				continue
+			}
+			endpoint := port.Address.Location
+			if parseOpts.HTTP.URL != "" {
+				endpoint = parseOpts.HTTP.URL
+			}
+			soap12 := binding.SOAPBinding.XMLName.Space == WSDL_SOAP12_NS
+			for _, op := range binding.Operations {
+				if seen[op.Name] {
+					// Same operation through another binding, usually SOAP 1.1 and 1.2 variants
+					continue
+				}
+				seen[op.Name] = true
+				body := defs.operationBody(localName(binding.Type), op.Name)
+				if !strings.Contains(body, "FUZZ") {
+					continue
+				}
+				conf.ApiOperations = append(conf.ApiOperations, soapOperation(endpoint, op.SOAPOperation.SOAPAction, body, soap12))
+			}
+		}
+	}
+	if len(conf.ApiOperations) == 0 {
+		return fmt.Errorf("no SOAP operations with parameters to fuzz found in the WSDL document")
+	}
+	conf.Url = conf.ApiOperations[0].Url
+	conf.Method = conf.ApiOperations[0].Method
+	conf.Data = conf.ApiOperations[0].Data
+	return nil
+}
+
+//soapOperation returns the request template for a SOAP operation
+func soapOperation(endpoint, action, body string, soap12 bool) ApiOperation {
+	op := ApiOperation{Method: "POST", Url: endpoint, Headers: make(map[string]string)}
+	envelopeNS := SOAP11_ENVELOPE_NS
+	if soap12 {
+		envelopeNS = SOAP12_ENVELOPE_NS
+		op.ContentType = "application/soap+xml; charset=utf-8"
+		if action != "" {
+			op.ContentType += "; action=\"" + action + "\""
+		}
+	} else {
+		op.ContentType = "text/xml; charset=utf-8"
+		op.Headers["SOAPAction"] = "\"" + action + "\""
+	}
+	op.Data = "<?xml version=\"1.0\" encoding=\"utf-8\"?>\n" +
+		"<soap:Envelope xmlns:soap=\"" + envelopeNS + "\"><soap:Body>" + body + "</soap:Body></soap:Envelope>"
+	return op
+}
+
+//binding returns the binding with the given name
+func (d *wsdlDefinitions) binding(name string) *wsdlBinding {
+	for i := range d.Bindings {
+		if d.Bindings[i].Name == name {
+			return &d.Bindings[i]
+		}
+	}
+	return nil
+}
+
+//operationBody returns the SOAP body template for the input message of an operation
+func (d *wsdlDefinitions) operationBody(portType, operation string) string {
+	var msg *wsdlMessage
+	for _, pt := range d.PortTypes {
+		if pt.Name != portType {
+			continue
+		}
+		for _, op := range pt.Operations {
+			if op.Name == operation {
+				msg = d.message(localName(op.Input.Message))
+			}
+		}
+	}
+	if msg == nil {
+		return ""
+	}
+	var b strings.Builder
+	rpc := false
+	for _, part := range msg.Parts {
+		if part.Element != "" {
+			// Document style, the part is the body element
+			d.elementTemplate(&b, xsdElement{Ref: part.Element}, xsdSchema{}, true, 0)
+		} else {
+			rpc = true
+			b.WriteString("<" + part.Name + ">FUZZ</" + part.Name + ">")
+		}
+	}
+	if rpc {
+		// RPC style, the parts are wrapped in an element named after the operation
+		return "<tns:" + operation + " xmlns:tns=\"" + d.TargetNamespace + "\">" + b.String() + "</tns:" + operation + ">"
+	}
+	return b.String()
+}
+
+func (d *wsdlDefinitions) message(name string) *wsdlMessage {
+	for i := range d.Messages {
+		if d.Messages[i].Name == name {
+			return &d.Messages[i]
+		}
+	}
+	return nil
+}
+
+//elementTemplate writes an XML template of the schema element, with the FUZZ keyword in place of simple values
+func (d *wsdlDefinitions) elementTemplate(b *strings.Builder, el xsdElement, schema xsdSchema, root bool, depth int) {
+	if el.Ref != "" {
+		if ref, s := d.findElement(localName(el.Ref)); ref != nil {
+			el = *ref
+			schema = s
+		} else {
+			el = xsdElement{Name: localName(el.Ref)}
+		}
+	}
+	if schema.TargetNamespace == "" {
+		schema.TargetNamespace = d.TargetNamespace
+	}
+	name := el.Name
+	if root || schema.ElementFormDefault == "qualified" {
+		name = "tns:" + name
+	}
+	b.WriteString("<" + name)
+	if root {
+		b.WriteString(" xmlns:tns=\"" + schema.TargetNamespace + "\"")
+	}
+	b.WriteString(">")
+	ct := el.ComplexType
+	if ct == nil && el.Type != "" {
+		ct = d.findComplexType(localName(el.Type))
+	}
+	if ct == nil {
+		b.WriteString("FUZZ")
+	} else if depth < 5 {
+		for _, child := range d.complexTypeElements(ct, 0) {
+			d.elementTemplate(b, child, schema, false, depth+1)
+		}
+	}
+	b.WriteString("</" + name + ">")
+}
+
+//complexTypeElements returns the child elements of a complex type, including the ones of the extended base type
+func (d *wsdlDefinitions) complexTypeElements(ct *xsdComplexType, depth int) []xsdElement {
+	elements := make([]xsdElement, 0)
+	if ct.Extension.Base != "" && depth < 5 {
+		if base := d.findComplexType(localName(ct.Extension.Base)); base != nil {
+			elements = append(elements, d.complexTypeElements(base, depth+1)...)
+		}
+		elements = append(elements, ct.Extension.Sequence...)
+	}
+	elements = append(elements, ct.Sequence...)
+	return append(elements, ct.All...)
+}
+
+func (d *wsdlDefinitions) findElement(name string) (*xsdElement, xsdSchema) {
+	for _, s := range d.Types.Schemas {
+		for i := range s.Elements {
+			if s.Elements[i].Name == name {
+				return &s.Elements[i], s
+			}
+		}
+	}
+	return nil, xsdSchema{}
+}
+
+func (d *wsdlDefinitions) findComplexType(name string) *xsdComplexType {
+	for _, s := range d.Types.Schemas {
+		for i := range s.ComplexTypes {
+			if s.ComplexTypes[i].Name == name {
+				return &s.ComplexTypes[i]
+			}
+		}
+	}
+	return nil
+}
+
+//localName strips the namespace prefix of a qualified name
+func localName(qname string) string {
+	return qname[strings.LastIndex(qname, ":")+1:]
+}
@@ -0,0 +1,121 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//CALIBRATION_RANGE_PERCENT is the maximum spread of varying calibration values, relative to the smallest one, still
+//filtered as a range with the advanced strategy
+const CALIBRATION_RANGE_PERCENT = 10
+
+//CALIBRATION_SIMILARITY is the similarity threshold of the body similarity filter created by the advanced strategy
+const CALIBRATION_SIMILARITY = "90"
+
+//Calibrator creates the autocalibration filters with the strategy of the configuration
+type Calibrator struct{}
+
+func NewCalibrator() *Calibrator {
+	return &Calibrator{}
+}
+
+//Filters analyzes the calibration responses and returns the filters matching them
+func (c *Calibrator) Filters(conf *ffuf.Config, responses []ffuf.Response) (map[string]ffuf.FilterProvider, error) {
+	if simFilter, ok := conf.Filters["similarity"].(*SimilarityFilter); ok {
+		// The calibration responses are the baselines of the similarity filter
+		for i := range responses {
+			simFilter.AddBaseline(&responses[i])
+		}
+	}
+	filters := make(map[string]ffuf.FilterProvider)
+	if len(responses) == 0 {
+		return filters, nil
+	}
+	sizes := make([]int64, 0)
+	words := make([]int64, 0)
+	lines := make([]int64, 0)
+	for _, r := range responses {
+		if r.ContentLength > 0 {
+			// Only add if we have an actual size of responses
+			sizes = append(sizes, r.ContentLength)
+		}
+		if r.ContentWords > 0 {
+			// Only add if we have an actual word length of response
+			words = append(words, r.ContentWords)
+		}
+		if r.ContentLines > 1 {
+			// Only add if we have an actual word length of response
+			lines = append(lines, r.ContentLines)
+		}
+	}
+	advanced := conf.AutoCalibrationStrategy == ffuf.CALIBRATION_ADVANCED
+	stable := false
+	for _, d := range []struct {
+		name   string
+		values []int64
+	}{{"size", sizes}, {"word", words}, {"line", lines}} {
+		if len(d.values) == 0 {
+			continue
+		}
+		value, ok := calibrationValue(d.values, advanced)
+		if !ok {
+			continue
+		}
+		stable = true
+		f, err := NewFilterByName(d.name, value)
+		if err != nil {
+			return filters, err
+		}
+		filters[d.name] = f
+	}
+	if advanced && !stable && len(responses) > 1 {
+		// Every probe got a different response, compare the bodies instead
+		f, err := NewSimilarityFilter(CALIBRATION_SIMILARITY)
+		if err != nil {
+			return filters, err
+		}
+		sim := f.(*SimilarityFilter)
+		for i := range responses {
+			sim.AddBaseline(&responses[i])
+		}
+		filters["similarity"] = sim
+	}
+	return filters, nil
+}
+
+//calibrationValue returns the filter value for the values of a single response metric. The basic strategies filter
+//every value seen. The advanced strategy only filters the values shared by multiple responses, or a range if all of
+//them differ only a little. The second return value is false if no filter should be created.
+func calibrationValue(values []int64, advanced bool) (string, bool) {
+	uniq := make([]int64, 0)
+	count := make(map[int64]int)
+	min, max := values[0], values[0]
+	for _, v := range values {
+		if count[v] == 0 {
+			uniq = append(uniq, v)
+		}
+		count[v]++
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	selected := make([]string, 0)
+	for _, v := range uniq {
+		if !advanced || count[v] > 1 || len(values) == 1 {
+			selected = append(selected, strconv.FormatInt(v, 10))
+		}
+	}
+	if len(selected) > 0 {
+		return strings.Join(selected, ","), true
+	}
+	if (max-min)*100 <= min*CALIBRATION_RANGE_PERCENT {
+		return fmt.Sprintf("%d-%d", min, max), true
+	}
+	return "", false
+}
@@ -0,0 +1,75 @@
+package filter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+func calibrationConfig(strategy string) *ffuf.Config {
+	conf := ffuf.NewConfig(context.Background(), func() {})
+	conf.AutoCalibrationStrategy = strategy
+	return &conf
+}
+
+func TestCalibrationValue(t *testing.T) {
+	for i, test := range []struct {
+		values   []int64
+		advanced bool
+		expected string
+		ok       bool
+	}{
+		{[]int64{100, 200, 100}, false, "100,200", true},
+		{[]int64{100, 200, 300}, false, "100,200,300", true},
+		{[]int64{100}, true, "100", true},
+		{[]int64{100, 200, 100}, true, "100", true},
+		{[]int64{100, 104, 108}, true, "100-108", true},
+		{[]int64{100, 200, 300}, true, "", false},
+	} {
+		value, ok := calibrationValue(test.values, test.advanced)
+		if value != test.expected || ok != test.ok {
+			t.Errorf("Calibration value test %d: expected %s (%t), got %s (%t)", i, test.expected, test.ok, value, ok)
+		}
+	}
+}
+
+func TestCalibratorFiltersBasic(t *testing.T) {
+	responses := []ffuf.Response{
+		{ContentLength: 100, ContentWords: 10, ContentLines: 1},
+		{ContentLength: 120, ContentWords: 10, ContentLines: 3},
+	}
+	filters, err := NewCalibrator().Filters(calibrationConfig(ffuf.CALIBRATION_BASIC), responses)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if filters["size"].Repr() != "100,120" || filters["word"].Repr() != "10" || filters["line"].Repr() != "3" {
+		t.Errorf("Unexpected calibration filters: %v", filters)
+	}
+}
+
+func TestCalibratorFiltersAdvancedSimilarity(t *testing.T) {
+	responses := make([]ffuf.Response, 0)
+	for i, input := range []string{"first", "second", "third"} {
+		page := similarityResponse(similarityPage, input)
+		page.ContentLength = int64(100 * (i + 1))
+		page.ContentWords = int64(10 * (i + 1))
+		page.ContentLines = int64(2 * (i + 1))
+		responses = append(responses, *page)
+	}
+	filters, err := NewCalibrator().Filters(calibrationConfig(ffuf.CALIBRATION_ADVANCED), responses)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(filters) != 1 {
+		t.Fatalf("Was expecting only the similarity filter, got %v", filters)
+	}
+	sim, ok := filters["similarity"].(*SimilarityFilter)
+	if !ok || sim.Baselines() != 3 {
+		t.Fatalf("Was expecting a similarity filter with 3 baselines")
+	}
+	filtered, _ := sim.Filter(similarityResponse(similarityPage, "fourth"))
+	if !filtered {
+		t.Errorf("Was expecting a similar page to be filtered")
+	}
+}
@@ -3,7 +3,6 @@ package filter
 import (
 	"flag"
 	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/ffuf/ffuf/pkg/ffuf"
@@ -28,6 +27,24 @@ func NewFilterByName(name string, value string) (ffuf.FilterProvider, error) {
 	if name == "time" {
 		return NewTimeFilter(value)
 	}
+	if name == "headerregexp" {
+		return NewHeaderRegexpFilter(value)
+	}
+	if name == "similarity" {
+		return NewSimilarityFilter(value)
+	}
+	if name == "mime" {
+		return NewMimeMismatchFilter(value)
+	}
+	if name == "origin" {
+		return NewOriginFilter(value)
+	}
+	if name == "jsonkeys" {
+		return NewJSONKeysFilter(value)
+	}
+	if name == "tags" {
+		return NewTagFilter(value)
+	}
 	return nil, fmt.Errorf("Could not create filter with name %s", name)
 }
 
@@ -49,6 +66,54 @@ func AddFilter(conf *ffuf.Config, name string, option string) error {
 	return err
 }
 
+//matcherFlags maps the matcher options to the filter names, for the matchers defined by their options
+var matcherFlags = map[string]string{
+	"mc":         "status",
+	"mjson-keys": "jsonkeys",
+	"ml":         "line",
+	"mmime":      "mime",
+	"mr":         "regexp",
+	"mr-header":  "headerregexp",
+	"ms":         "size",
+	"mt":         "time",
+	"mtags":      "tags",
+	"mw":         "word",
+}
+
+//grpcStatusMatch is the default status matcher of the grpc:// and grpcs:// targets: any gRPC status code but
+//UNIMPLEMENTED, the one of the unknown services and methods
+const grpcStatusMatch = "0-11,13-16"
+
+//defaultStatusMatch returns the default status matcher (-mc) for the target URL. The gRPC targets have their own, and
+//the WebSocket ones match the 101 Switching Protocols of a successful upgrade as well.
+func defaultStatusMatch(target, status string) string {
+	target = strings.ToLower(target)
+	if strings.HasPrefix(target, "grpc://") || strings.HasPrefix(target, "grpcs://") {
+		return grpcStatusMatch
+	}
+	if strings.HasPrefix(target, "ws://") || strings.HasPrefix(target, "wss://") {
+		return "101," + status
+	}
+	return status
+}
+
+//AddReplayMatcher adds a matcher of the replay proxy to Config, from a MATCHER:VALUE definition of -replay-match
+func AddReplayMatcher(conf *ffuf.Config, definition string) error {
+	parts := strings.SplitN(definition, ":", 2)
+	name, ok := matcherFlags[strings.TrimPrefix(strings.TrimSpace(parts[0]), "-")]
+	if !ok || len(parts) < 2 {
+		return fmt.Errorf("Bad replay matcher (-replay-match) %s, expected MATCHER:VALUE of the matcher options: mc, mjson-keys, ml, mmime, mr, mr-header, ms, mt, mtags or mw", definition)
+	}
+	if conf.ReplayMatchers[name] != nil {
+		return fmt.Errorf("Replay matcher (-replay-match) %s is defined more than once", parts[0])
+	}
+	newf, err := NewFilterByName(name, parts[1])
+	if err == nil {
+		conf.ReplayMatchers[name] = newf
+	}
+	return err
+}
+
 //RemoveFilter removes a filter of a given type
 func RemoveFilter(conf *ffuf.Config, name string) {
 	delete(conf.Filters, name)
@@ -65,63 +130,44 @@ func AddMatcher(conf *ffuf.Config, name string, option string) error {
 
 //CalibrateIfNeeded runs a self-calibration task for filtering options (if needed) by requesting random resources and acting accordingly
 func CalibrateIfNeeded(j *ffuf.Job) error {
-	var err error
+	simFilter, simSet := j.Config.Filters["similarity"].(*SimilarityFilter)
 	if !j.Config.AutoCalibration {
-		return nil
-	}
-	// Handle the calibration
-	responses, err := j.CalibrateResponses()
-	if err != nil {
-		return err
-	}
-	if len(responses) > 0 {
-		err = calibrateFilters(j, responses)
-	}
-	return err
-}
-
-func calibrateFilters(j *ffuf.Job, responses []ffuf.Response) error {
-	sizeCalib := make([]string, 0)
-	wordCalib := make([]string, 0)
-	lineCalib := make([]string, 0)
-	for _, r := range responses {
-		if r.ContentLength > 0 {
-			// Only add if we have an actual size of responses
-			sizeCalib = append(sizeCalib, strconv.FormatInt(r.ContentLength, 10))
+		if !simSet {
+			return nil
 		}
-		if r.ContentWords > 0 {
-			// Only add if we have an actual word length of response
-			wordCalib = append(wordCalib, strconv.FormatInt(r.ContentWords, 10))
+		// The calibration responses are the baseline of the similarity filter
+		responses, err := j.CalibrateResponses("")
+		if err != nil {
+			return err
 		}
-		if r.ContentLines > 1 {
-			// Only add if we have an actual word length of response
-			lineCalib = append(lineCalib, strconv.FormatInt(r.ContentLines, 10))
+		for i := range responses {
+			simFilter.AddBaseline(&responses[i])
+			responses[i].MakeFreeMemory()
 		}
+		return nil
 	}
+	if j.Config.AutoCalibrationPerHost {
+		// Every host is calibrated when the first request is sent to it
+		return nil
+	}
+	return j.Calibrate()
+}
 
-	//Remove duplicates
-	sizeCalib = ffuf.UniqStringSlice(sizeCalib)
-	wordCalib = ffuf.UniqStringSlice(wordCalib)
-	lineCalib = ffuf.UniqStringSlice(lineCalib)
-
-	if len(sizeCalib) > 0 {
-		err := AddFilter(j.Config, "size", strings.Join(sizeCalib, ","))
-		if err != nil {
-			return err
-		}
+//OriginBaselineIfNeeded requests the target through its regular (CDN) address and adds a filter of the responses of
+//candidate origin IPs differing from it, so that a result needs to both pass the matchers and serve the target
+func OriginBaselineIfNeeded(j *ffuf.Job) error {
+	if len(j.Config.OriginIPs) == 0 {
+		return nil
 	}
-	if len(wordCalib) > 0 {
-		err := AddFilter(j.Config, "word", strings.Join(wordCalib, ","))
-		if err != nil {
-			return err
-		}
+	resp, err := j.BaselineResponse()
+	if err != nil {
+		return err
 	}
-	if len(lineCalib) > 0 {
-		err := AddFilter(j.Config, "line", strings.Join(lineCalib, ","))
-		if err != nil {
-			return err
-		}
+	newf, err := NewOriginFilter(fmt.Sprintf("%d:%d", resp.StatusCode, resp.ContentLength))
+	if err != nil {
+		return err
 	}
+	j.Config.Filters["origin"] = newf
 	return nil
 }
 
@@ -146,16 +192,38 @@ func SetupFilters(parseOpts *ffuf.ConfigOptions, conf *ffuf.Config) error {
 		if f.Name == "mr" {
 			matcherSet = true
 		}
+		if f.Name == "mr-header" {
+			matcherSet = true
+		}
+		if f.Name == "mmime" {
+			matcherSet = true
+			warningIgnoreBody = true
+		}
 		if f.Name == "mt" {
 			matcherSet = true
 		}
+		if f.Name == "mjson-keys" {
+			matcherSet = true
+			warningIgnoreBody = true
+		}
+		if f.Name == "mtags" {
+			matcherSet = true
+			warningIgnoreBody = true
+		}
 		if f.Name == "mw" {
 			matcherSet = true
 			warningIgnoreBody = true
 		}
 	})
 	if statusSet || !matcherSet {
-		if err := AddMatcher(conf, "status", parseOpts.Matcher.Status); err != nil {
+		status := parseOpts.Matcher.Status
+		if !statusSet && len(conf.OriginIPs) > 0 {
+			// The origin baseline filter decides, whatever the status of the target is
+			status = "all"
+		} else if !statusSet {
+			status = defaultStatusMatch(conf.Url, status)
+		}
+		if err := AddMatcher(conf, "status", status); err != nil {
 			errs.Add(err)
 		}
 	}
@@ -176,6 +244,17 @@ func SetupFilters(parseOpts *ffuf.ConfigOptions, conf *ffuf.Config) error {
 			errs.Add(err)
 		}
 	}
+	if parseOpts.Filter.HeaderRegexp != "" {
+		if err := AddFilter(conf, "headerregexp", parseOpts.Filter.HeaderRegexp); err != nil {
+			errs.Add(err)
+		}
+	}
+	if parseOpts.Filter.Similarity != "" {
+		warningIgnoreBody = true
+		if err := AddFilter(conf, "similarity", parseOpts.Filter.Similarity); err != nil {
+			errs.Add(err)
+		}
+	}
 	if parseOpts.Filter.Words != "" {
 		warningIgnoreBody = true
 		if err := AddFilter(conf, "word", parseOpts.Filter.Words); err != nil {
@@ -203,6 +282,16 @@ func SetupFilters(parseOpts *ffuf.ConfigOptions, conf *ffuf.Config) error {
 			errs.Add(err)
 		}
 	}
+	if parseOpts.Matcher.HeaderRegexp != "" {
+		if err := AddMatcher(conf, "headerregexp", parseOpts.Matcher.HeaderRegexp); err != nil {
+			errs.Add(err)
+		}
+	}
+	if parseOpts.Matcher.Mime != "" {
+		if err := AddMatcher(conf, "mime", parseOpts.Matcher.Mime); err != nil {
+			errs.Add(err)
+		}
+	}
 	if parseOpts.Matcher.Words != "" {
 		if err := AddMatcher(conf, "word", parseOpts.Matcher.Words); err != nil {
 			errs.Add(err)
@@ -214,12 +303,35 @@ func SetupFilters(parseOpts *ffuf.ConfigOptions, conf *ffuf.Config) error {
 		}
 	}
 	if parseOpts.Matcher.Time != "" {
-		if err := AddFilter(conf, "time", parseOpts.Matcher.Time); err != nil {
+		if err := AddMatcher(conf, "time", parseOpts.Matcher.Time); err != nil {
+			errs.Add(err)
+		}
+	}
+	if parseOpts.Matcher.JSONKeys != "" {
+		if err := AddMatcher(conf, "jsonkeys", parseOpts.Matcher.JSONKeys); err != nil {
+			errs.Add(err)
+		}
+	}
+	if parseOpts.Matcher.Tags != "" {
+		if err := AddMatcher(conf, "tags", parseOpts.Matcher.Tags); err != nil {
+			errs.Add(err)
+		}
+	}
+	for _, m := range parseOpts.HTTP.ReplayMatch {
+		if err := AddReplayMatcher(conf, m); err != nil {
+			errs.Add(err)
+		}
+	}
+	for _, o := range parseOpts.General.JobOverrides {
+		rule, err := ParseQueueOverrideRule(o)
+		if err != nil {
 			errs.Add(err)
+			continue
 		}
+		conf.QueueOverrideRules = append(conf.QueueOverrideRules, rule)
 	}
 	if conf.IgnoreBody && warningIgnoreBody {
-		fmt.Printf("*** Warning: possible undesired combination of -ignore-body and the response options: fl,fs,fw,ml,ms and mw.\n")
+		fmt.Printf("*** Warning: possible undesired combination of -ignore-body and the response options: fl,fs,fsim,fw,mjson-keys,ml,mmime,ms,mtags and mw.\n")
 	}
 	return errs.ErrorOrNil()
 }
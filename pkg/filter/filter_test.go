@@ -2,6 +2,8 @@ package filter
 
 import (
 	"testing"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
 )
 
 func TestNewFilterByName(t *testing.T) {
@@ -49,3 +51,49 @@ func TestNewFilterByNameNotFound(t *testing.T) {
 		t.Errorf("Was expecing an error with invalid filter name")
 	}
 }
+
+func TestBodyNeeds(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		value string
+		needs int64
+	}{
+		{"status", "200", 0},
+		{"size", "42", 0},
+		{"time", ">100", 0},
+		{"headerregexp", "Server: nginx", 0},
+		{"mime", "all", 512},
+		{"word", "42", ffuf.BODY_ALL},
+		{"regexp", "admin", ffuf.BODY_ALL},
+	} {
+		f, err := NewFilterByName(test.name, test.value)
+		if err != nil {
+			t.Fatalf("Could not create the %s filter: %s", test.name, err)
+		}
+		needs := int64(ffuf.BODY_ALL)
+		if bn, ok := f.(ffuf.BodyNeeds); ok {
+			needs = bn.BodyNeeds()
+		}
+		if needs != test.needs {
+			t.Errorf("The %s filter was expected to need %d bytes of the body, got %d", test.name, test.needs, needs)
+		}
+	}
+}
+
+func TestDefaultStatusMatch(t *testing.T) {
+	for _, test := range []struct {
+		target string
+		want   string
+	}{
+		{"http://localhost/FUZZ", "200,301"},
+		{"https://localhost/FUZZ", "200,301"},
+		{"grpc://localhost:50051/package.Service/FUZZ", grpcStatusMatch},
+		{"GRPCS://localhost:50051/package.Service/FUZZ", grpcStatusMatch},
+		{"ws://localhost/FUZZ", "101,200,301"},
+		{"WSS://localhost/FUZZ", "101,200,301"},
+	} {
+		if got := defaultStatusMatch(test.target, "200,301"); got != test.want {
+			t.Errorf("Default status matcher of %s: was expecting %s, got %s", test.target, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//HeaderRegexpFilter matches a regular expression against the response headers only, formatted as "Name: value" lines.
+//^ and $ match at the beginning and end of each header line.
+type HeaderRegexpFilter struct {
+	Value    *regexp.Regexp
+	valueRaw string
+}
+
+func NewHeaderRegexpFilter(value string) (ffuf.FilterProvider, error) {
+	re, err := regexp.Compile("(?m)" + value)
+	if err != nil {
+		return &HeaderRegexpFilter{}, fmt.Errorf("Header regexp filter or matcher (-fr-header / -mr-header): invalid value: %s", value)
+	}
+	return &HeaderRegexpFilter{Value: re, valueRaw: value}, nil
+}
+
+func (f *HeaderRegexpFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Value string `json:"value"`
+	}{
+		Value: f.valueRaw,
+	})
+}
+
+func (f *HeaderRegexpFilter) Filter(response *ffuf.Response) (bool, error) {
+	names := make([]string, 0, len(response.Headers))
+	for k := range response.Headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	matchheaders := ""
+	for _, k := range names {
+		for _, iv := range response.Headers[k] {
+			matchheaders += k + ": " + iv + "\n"
+		}
+	}
+	pattern := "(?m)" + f.valueRaw
+	if response.Request != nil {
+		for keyword, inputitem := range response.Request.Input {
+			pattern = strings.ReplaceAll(pattern, keyword, regexp.QuoteMeta(string(inputitem)))
+		}
+	}
+	matched, err := regexp.MatchString(pattern, matchheaders)
+	if err != nil {
+		return false, nil
+	}
+	return matched, nil
+}
+
+//BodyNeeds returns 0, the filter does not need the response body
+func (f *HeaderRegexpFilter) BodyNeeds() int64 {
+	return 0
+}
+
+func (f *HeaderRegexpFilter) Repr() string {
+	return f.valueRaw
+}
+
+func (f *HeaderRegexpFilter) ReprVerbose() string {
+	return fmt.Sprintf("Header regexp: %s", f.valueRaw)
+}
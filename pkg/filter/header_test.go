@@ -0,0 +1,49 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+func TestNewHeaderRegexpFilter(t *testing.T) {
+	f, _ := NewHeaderRegexpFilter("Server: ngi(nx)?")
+	if !strings.Contains(f.Repr(), "Server: ngi(nx)?") {
+		t.Errorf("Header regexp filter was expected to have a regexp value")
+	}
+}
+
+func TestNewHeaderRegexpFilterError(t *testing.T) {
+	_, err := NewHeaderRegexpFilter("r((")
+	if err == nil {
+		t.Errorf("Was expecting an error from errenous input data")
+	}
+}
+
+func TestHeaderRegexpFiltering(t *testing.T) {
+	f, _ := NewHeaderRegexpFilter("(?i)^Set-Cookie: session=FUZZ")
+	for i, test := range []struct {
+		headers map[string][]string
+		body    string
+		output  bool
+	}{
+		{map[string][]string{"Set-Cookie": {"session=admin; HttpOnly"}}, "", true},
+		{map[string][]string{"Server": {"nginx"}, "Set-Cookie": {"other=1", "session=admin"}}, "", true},
+		{map[string][]string{"Set-Cookie": {"session=guest"}}, "", false},
+		{map[string][]string{"X-Set-Cookie": {"session=admin"}}, "", false},
+		{map[string][]string{}, "Set-Cookie: session=admin", false},
+	} {
+		resp := ffuf.Response{
+			Headers: test.headers,
+			Data:    []byte(test.body),
+			Request: &ffuf.Request{
+				Input: map[string][]byte{"FUZZ": []byte("admin")},
+			},
+		}
+		filterReturn, _ := f.Filter(&resp)
+		if filterReturn != test.output {
+			t.Errorf("Filter test %d: Was expecing filter return value of %t but got %t", i, test.output, filterReturn)
+		}
+	}
+}
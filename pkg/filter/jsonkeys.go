@@ -0,0 +1,86 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//JSONKeysFilter matches JSON responses by the number of object keys in the body, at any depth. Responses without a
+//JSON Content-Type, or with a body that does not parse, never match.
+type JSONKeysFilter struct {
+	Value []ffuf.ValueRange
+}
+
+func NewJSONKeysFilter(value string) (ffuf.FilterProvider, error) {
+	var intranges []ffuf.ValueRange
+	for _, sv := range strings.Split(value, ",") {
+		vr, err := ffuf.ValueRangeFromString(sv)
+		if err != nil {
+			return &JSONKeysFilter{}, fmt.Errorf("JSON key count matcher (-mjson-keys): invalid value: %s", sv)
+		}
+		intranges = append(intranges, vr)
+	}
+	return &JSONKeysFilter{Value: intranges}, nil
+}
+
+func (f *JSONKeysFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Value string `json:"value"`
+	}{
+		Value: f.Repr(),
+	})
+}
+
+func (f *JSONKeysFilter) Filter(response *ffuf.Response) (bool, error) {
+	if !strings.Contains(mediaType(response.ContentType), "json") {
+		return false, nil
+	}
+	var doc interface{}
+	if err := json.Unmarshal(response.Body(), &doc); err != nil {
+		return false, nil
+	}
+	keys := int64(jsonKeyCount(doc))
+	for _, iv := range f.Value {
+		if iv.Min <= keys && keys <= iv.Max {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *JSONKeysFilter) Repr() string {
+	var strval []string
+	for _, iv := range f.Value {
+		if iv.Min == iv.Max {
+			strval = append(strval, strconv.Itoa(int(iv.Min)))
+		} else {
+			strval = append(strval, strconv.Itoa(int(iv.Min))+"-"+strconv.Itoa(int(iv.Max)))
+		}
+	}
+	return strings.Join(strval, ",")
+}
+
+func (f *JSONKeysFilter) ReprVerbose() string {
+	return fmt.Sprintf("Response JSON keys: %s", f.Repr())
+}
+
+//jsonKeyCount returns the number of object keys in a decoded JSON value, including the ones of nested objects
+func jsonKeyCount(v interface{}) int {
+	count := 0
+	switch val := v.(type) {
+	case map[string]interface{}:
+		count += len(val)
+		for _, child := range val {
+			count += jsonKeyCount(child)
+		}
+	case []interface{}:
+		for _, child := range val {
+			count += jsonKeyCount(child)
+		}
+	}
+	return count
+}
@@ -0,0 +1,44 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+func TestNewJSONKeysFilter(t *testing.T) {
+	f, _ := NewJSONKeysFilter("2,5-10,20")
+	if !strings.Contains(f.Repr(), "2,5-10,20") {
+		t.Errorf("JSON keys filter was expected to have 3 values")
+	}
+}
+
+func TestNewJSONKeysFilterError(t *testing.T) {
+	_, err := NewJSONKeysFilter("invalid")
+	if err == nil {
+		t.Errorf("Was expecting an error from errenous input data")
+	}
+}
+
+func TestJSONKeysFiltering(t *testing.T) {
+	f, _ := NewJSONKeysFilter("3")
+	for i, test := range []struct {
+		contentType string
+		body        string
+		output      bool
+	}{
+		{"application/json", `{"a": 1, "b": 2, "c": 3}`, true},
+		{"application/json; charset=utf-8", `{"a": {"b": 1, "c": [1, 2]}}`, true},
+		{"application/problem+json", `[{"a": 1}, {"b": 2}, {"c": 3}]`, true},
+		{"application/json", `{"a": 1, "b": 2}`, false},
+		{"application/json", `{"a": 1, "b": 2, "c": 3`, false},
+		{"text/html", `{"a": 1, "b": 2, "c": 3}`, false},
+	} {
+		resp := ffuf.Response{ContentType: test.contentType, Data: []byte(test.body)}
+		filterReturn, _ := f.Filter(&resp)
+		if filterReturn != test.output {
+			t.Errorf("Filter test %d: Was expecing filter return value of %t but got %t", i, test.output, filterReturn)
+		}
+	}
+}
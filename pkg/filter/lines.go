@@ -42,8 +42,7 @@ func (f *LineFilter) MarshalJSON() ([]byte, error) {
 }
 
 func (f *LineFilter) Filter(response *ffuf.Response) (bool, error) {
-	linesSize := len(strings.Split(string(response.Data), "\n"))
-	response.MakeFreeMemory() // making response body nil
+	linesSize := len(strings.Split(string(response.Body()), "\n"))
 	for _, iv := range f.Value {
 		if iv.Min <= int64(linesSize) && int64(linesSize) <= iv.Max {
 			return true, nil
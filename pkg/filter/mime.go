@@ -0,0 +1,125 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//MimeMismatchFilter matches responses whose content type sniffed from the body (magic bytes) differs from the one
+//declared in the Content-Type header. The value is "all" for any mismatch, or a comma separated list of the sniffed
+//content types to match.
+type MimeMismatchFilter struct {
+	Value    []string
+	valueRaw string
+}
+
+func NewMimeMismatchFilter(value string) (ffuf.FilterProvider, error) {
+	types := make([]string, 0)
+	for _, t := range strings.Split(value, ",") {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" {
+			continue
+		}
+		if t != "all" && !strings.Contains(t, "/") {
+			return &MimeMismatchFilter{}, fmt.Errorf("MIME mismatch matcher (-mmime): invalid value: %s", value)
+		}
+		types = append(types, t)
+	}
+	if len(types) == 0 {
+		return &MimeMismatchFilter{}, fmt.Errorf("MIME mismatch matcher (-mmime): invalid value: %s", value)
+	}
+	return &MimeMismatchFilter{Value: types, valueRaw: value}, nil
+}
+
+func (f *MimeMismatchFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Value string `json:"value"`
+	}{
+		Value: f.valueRaw,
+	})
+}
+
+func (f *MimeMismatchFilter) Filter(response *ffuf.Response) (bool, error) {
+	body := response.Body()
+	if len(body) == 0 {
+		return false, nil
+	}
+	declared := mediaType(response.ContentType)
+	sniffed := mediaType(http.DetectContentType(body))
+	if !mimeMismatch(declared, sniffed) {
+		return false, nil
+	}
+	for _, t := range f.Value {
+		if t == "all" || t == sniffed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+//BodyNeeds returns the number of bytes the content type is sniffed from
+func (f *MimeMismatchFilter) BodyNeeds() int64 {
+	return 512
+}
+
+func (f *MimeMismatchFilter) Repr() string {
+	return f.valueRaw
+}
+
+func (f *MimeMismatchFilter) ReprVerbose() string {
+	return fmt.Sprintf("Sniffed content type differs from Content-Type: %s", f.valueRaw)
+}
+
+//mediaType returns the lowercase media type of a content type, without its parameters
+func mediaType(contentType string) string {
+	t, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t = strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	}
+	return strings.ToLower(t)
+}
+
+//mimeMismatch checks if the sniffed media type contradicts the declared one. The sniffing only recognizes a few
+//text types, so any textual declared type is accepted for plain text, and a generic binary type for binary content.
+func mimeMismatch(declared, sniffed string) bool {
+	if declared == "" || sniffed == "" || declared == sniffed {
+		return false
+	}
+	switch sniffed {
+	case "application/octet-stream":
+		// Unrecognized binary content
+		return textualMediaType(declared)
+	case "text/plain":
+		return !textualMediaType(declared)
+	case "text/html":
+		return !strings.Contains(declared, "html")
+	case "text/xml":
+		return !strings.Contains(declared, "xml")
+	}
+	if declared == "application/octet-stream" {
+		return false
+	}
+	sniffedTop := strings.SplitN(sniffed, "/", 2)[0]
+	if sniffedTop == strings.SplitN(declared, "/", 2)[0] && sniffedTop != "application" {
+		// Variants of the same image, audio, video or font format, like image/jpg for image/jpeg
+		return false
+	}
+	return strings.TrimPrefix(sniffed, "application/x-") != strings.TrimPrefix(declared, "application/")
+}
+
+func textualMediaType(t string) bool {
+	if strings.HasPrefix(t, "text/") {
+		return true
+	}
+	for _, s := range []string{"json", "xml", "javascript", "ecmascript", "yaml", "x-www-form-urlencoded", "graphql"} {
+		if strings.Contains(t, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+func TestNewMimeMismatchFilter(t *testing.T) {
+	f, _ := NewMimeMismatchFilter("all")
+	if f.Repr() != "all" {
+		t.Errorf("MIME mismatch filter was expected to have a value of all")
+	}
+	fp, _ := NewMimeMismatchFilter("text/plain, Application/Zip")
+	mf := fp.(*MimeMismatchFilter)
+	if len(mf.Value) != 2 || mf.Value[1] != "application/zip" {
+		t.Errorf("MIME mismatch filter was expected to have two lowercase types, got %v", mf.Value)
+	}
+}
+
+func TestNewMimeMismatchFilterError(t *testing.T) {
+	for _, value := range []string{"", "zip", "all,html"} {
+		_, err := NewMimeMismatchFilter(value)
+		if err == nil {
+			t.Errorf("Was expecting an error from errenous input data %s", value)
+		}
+	}
+}
+
+func TestMimeMismatchFiltering(t *testing.T) {
+	f, _ := NewMimeMismatchFilter("all")
+	zip := "PK\x03\x04\x14\x00\x00\x00\x08\x00"
+	for i, test := range []struct {
+		contentType string
+		body        string
+		output      bool
+	}{
+		{"text/html; charset=utf-8", "<!DOCTYPE html><html></html>", false},
+		{"application/json", `{"status": "ok"}`, false},
+		{"application/json", "<html><body>Internal error</body></html>", true},
+		{"text/plain", "<?php echo 'source'; ?>", false},
+		{"application/octet-stream", "<?php echo 'source'; ?>", true},
+		{"image/png", "plain text", true},
+		{"application/zip", zip, false},
+		{"application/octet-stream", zip, false},
+		{"text/html", zip, true},
+		{"text/html", "\x00\x01\x02\x03binary", true},
+		{"image/jpg", "\xff\xd8\xff\xe0", false},
+		{"", "<html></html>", false},
+		{"text/plain", "", false},
+	} {
+		resp := ffuf.Response{
+			ContentType: test.contentType,
+			Data:        []byte(test.body),
+		}
+		filterReturn, _ := f.Filter(&resp)
+		if filterReturn != test.output {
+			t.Errorf("Filter test %d: Was expecing filter return value of %t but got %t", i, test.output, filterReturn)
+		}
+	}
+
+	f, _ = NewMimeMismatchFilter("application/zip")
+	resp := ffuf.Response{ContentType: "text/html", Data: []byte("plain text")}
+	if match, _ := f.Filter(&resp); match {
+		t.Errorf("Was expecting a mismatch of another sniffed type not to match")
+	}
+	resp = ffuf.Response{ContentType: "text/html", Data: []byte(zip)}
+	if match, _ := f.Filter(&resp); !match {
+		t.Errorf("Was expecting a mismatch of the listed sniffed type to match")
+	}
+}
@@ -0,0 +1,65 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+// Allowed relative difference in response size for an origin to be considered serving the same application
+const OriginSizeTolerance = 0.1
+
+//OriginFilter filters the responses of the candidate origin IPs (-origin-ips) differing from the baseline response of
+//the target in status or, beyond OriginSizeTolerance, in size
+
+type OriginFilter struct {
+	status int64
+	size   int64
+}
+
+func NewOriginFilter(value string) (ffuf.FilterProvider, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return &OriginFilter{}, fmt.Errorf("Origin filter: invalid baseline value: %s", value)
+	}
+	status, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return &OriginFilter{}, fmt.Errorf("Origin filter: invalid baseline status: %s", parts[0])
+	}
+	size, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return &OriginFilter{}, fmt.Errorf("Origin filter: invalid baseline size: %s", parts[1])
+	}
+	return &OriginFilter{status: status, size: size}, nil
+}
+
+func (f *OriginFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Value string `json:"value"`
+	}{
+		Value: f.Repr(),
+	})
+}
+
+func (f *OriginFilter) Filter(response *ffuf.Response) (bool, error) {
+	if response.StatusCode != f.status {
+		return true, nil
+	}
+	if f.size == 0 {
+		return response.ContentLength != 0, nil
+	}
+	diff := math.Abs(float64(response.ContentLength-f.size)) / float64(f.size)
+	return diff > OriginSizeTolerance, nil
+}
+
+func (f *OriginFilter) Repr() string {
+	return fmt.Sprintf("%d:%d", f.status, f.size)
+}
+
+func (f *OriginFilter) ReprVerbose() string {
+	return fmt.Sprintf("Origin baseline: status %d, size %d (+/- %d%%)", f.status, f.size, int(OriginSizeTolerance*100))
+}
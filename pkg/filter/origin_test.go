@@ -0,0 +1,43 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+func TestNewOriginFilter(t *testing.T) {
+	f, _ := NewOriginFilter("200:1000")
+	if f.Repr() != "200:1000" {
+		t.Errorf("Origin filter was expected to have baseline 200:1000")
+	}
+}
+
+func TestNewOriginFilterError(t *testing.T) {
+	_, err := NewOriginFilter("200")
+	if err == nil {
+		t.Errorf("Was expecting an error from errenous input data")
+	}
+}
+
+func TestOriginFiltering(t *testing.T) {
+	f, _ := NewOriginFilter("200:1000")
+	for i, test := range []struct {
+		status int64
+		size   int64
+		output bool
+	}{
+		{200, 1000, false},
+		{200, 950, false},
+		{200, 1100, false},
+		{200, 1101, true},
+		{200, 10, true},
+		{403, 1000, true},
+	} {
+		resp := ffuf.Response{StatusCode: test.status, ContentLength: test.size}
+		filterReturn, _ := f.Filter(&resp)
+		if filterReturn != test.output {
+			t.Errorf("Filter test %d: Was expecing filter return value of %t but got %t", i, test.output, filterReturn)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//queueOverrideFilters are the filter flags accepted in the queue job overrides, by the name of their filter
+var queueOverrideFilters = map[string]string{
+	"fc": "status",
+	"fl": "line",
+	"fr": "regexp",
+	"fs": "size",
+	"ft": "time",
+	"fw": "word",
+}
+
+//ParseQueueJobOverrides parses the settings of a queue job, as KEY=VALUE pairs: threads=N, delay=SECONDS like -p,
+//and the filters fc, fl, fr, fs, ft and fw added to the ones of the run
+func ParseQueueJobOverrides(settings []string) (ffuf.QueueJobOverrides, error) {
+	overrides := ffuf.QueueJobOverrides{Filters: make(map[string]ffuf.FilterProvider)}
+	if len(settings) == 0 {
+		return overrides, fmt.Errorf("No settings to override, eg. \"threads=2 delay=0.5 fs=42\"")
+	}
+	for _, s := range settings {
+		kv := strings.SplitN(s, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return overrides, fmt.Errorf("Queue job setting needs to be KEY=VALUE: %s", s)
+		}
+		key, value := strings.ToLower(kv[0]), kv[1]
+		switch key {
+		case "threads":
+			threads, err := strconv.Atoi(value)
+			if err != nil || threads < 1 {
+				return overrides, fmt.Errorf("Queue job threads need to be a positive number: %s", value)
+			}
+			overrides.Threads = threads
+		case "delay":
+			if err := overrides.SetDelay(value); err != nil {
+				return overrides, err
+			}
+		default:
+			name, ok := queueOverrideFilters[key]
+			if !ok {
+				return overrides, fmt.Errorf("Unknown queue job setting %s. Available settings: threads, delay, fc, fl, fr, fs, ft and fw", key)
+			}
+			f, err := NewFilterByName(name, value)
+			if err != nil {
+				return overrides, err
+			}
+			overrides.Filters[name] = f
+		}
+	}
+	return overrides, nil
+}
+
+//ParseQueueOverrideRule parses a -job-override rule of the form SETTINGS for REGEX, eg. "threads=2 fs=42 for /admin/",
+//attaching the settings to the queued jobs with URLs matching the regular expression
+func ParseQueueOverrideRule(value string) (ffuf.QueueOverrideRule, error) {
+	var rule ffuf.QueueOverrideRule
+	fields := strings.Fields(value)
+	if len(fields) < 3 || fields[len(fields)-2] != "for" {
+		return rule, fmt.Errorf("Queue job override (-job-override) needs to be SETTINGS for REGEX, eg. \"threads=2 fs=42 for /admin/\": %s", value)
+	}
+	pattern, err := regexp.Compile(fields[len(fields)-1])
+	if err != nil {
+		return rule, fmt.Errorf("Invalid queue job override (-job-override) pattern: %s", err)
+	}
+	overrides, err := ParseQueueJobOverrides(fields[:len(fields)-2])
+	if err != nil {
+		return rule, err
+	}
+	rule.Pattern = pattern
+	rule.Overrides = overrides
+	return rule, nil
+}
@@ -0,0 +1,43 @@
+package filter
+
+import (
+	"testing"
+)
+
+func TestParseQueueOverrideRule(t *testing.T) {
+	rule, err := ParseQueueOverrideRule("threads=2 delay=0.5-1 fs=42 fc=403 for /admin/")
+	if err != nil {
+		t.Fatalf("Was not expecting an error: %s", err)
+	}
+	if !rule.Pattern.MatchString("https://example.com/admin/FUZZ") || rule.Pattern.MatchString("https://example.com/FUZZ") {
+		t.Errorf("Rule pattern was expected to match the admin subtree only")
+	}
+	if rule.Overrides.Threads != 2 {
+		t.Errorf("Was expecting 2 threads but got %d", rule.Overrides.Threads)
+	}
+	if _, ok := rule.Overrides.Filters["size"]; !ok {
+		t.Errorf("Was expecting a size filter")
+	}
+	if _, ok := rule.Overrides.Filters["status"]; !ok {
+		t.Errorf("Was expecting a status filter")
+	}
+	if rule.Overrides.String() != "threads=2 delay=0.50-1.00 size=42 status=403" {
+		t.Errorf("Unexpected overrides repr: %s", rule.Overrides.String())
+	}
+}
+
+func TestParseQueueOverrideRuleError(t *testing.T) {
+	for _, value := range []string{
+		"threads=2",
+		"for /admin/",
+		"threads=0 for /admin/",
+		"threads=2 for (",
+		"mc=200 for /admin/",
+		"fs=abc for /admin/",
+		"delay=1-2-3 for /admin/",
+	} {
+		if _, err := ParseQueueOverrideRule(value); err == nil {
+			t.Errorf("Was expecting an error from errenous input data: %s", value)
+		}
+	}
+}
@@ -38,14 +38,13 @@ func (f *RegexpFilter) Filter(response *ffuf.Response) (bool, error) {
 		}
 	}
 	matchdata := []byte(matchheaders)
-	matchdata = append(matchdata, response.Data...)
+	matchdata = append(matchdata, response.Body()...)
 	pattern := f.valueRaw
 	for keyword, inputitem := range response.Request.Input {
 		pattern = strings.ReplaceAll(pattern, keyword, regexp.QuoteMeta(string(inputitem)))
 	}
 	matched, err := regexp.Match(pattern, matchdata)
 	matchdata = nil
-	response.MakeFreeMemory() //test 3
 	if err != nil {
 		return false, nil
 	}
@@ -0,0 +1,177 @@
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"math/bits"
+	"net/url"
+	"strconv"
+	"sync"
+	"unicode"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//SIMILARITY_SHINGLE is the number of consecutive words hashed together as a single feature of the body
+const SIMILARITY_SHINGLE = 3
+
+//SimilarityFilter filters responses with bodies similar to baseline responses, usually the autocalibration
+//responses for nonexistent resources. Bodies are compared by the simhash of their normalized content, so that soft-404
+//pages varying only by the reflected input or by numbers such as timestamps are considered equal.
+type SimilarityFilter struct {
+	threshold int // minimum similarity percentage to filter
+	baselines []similarityHash
+	mutex     sync.RWMutex
+	valueRaw  string
+}
+
+type similarityHash struct {
+	hash  uint64
+	empty bool
+}
+
+func NewSimilarityFilter(value string) (ffuf.FilterProvider, error) {
+	threshold, err := strconv.Atoi(value)
+	if err != nil || threshold < 1 || threshold > 100 {
+		return &SimilarityFilter{}, fmt.Errorf("Similarity filter (-fsim): invalid value: %s, expected a percentage between 1 and 100", value)
+	}
+	return &SimilarityFilter{threshold: threshold, baselines: make([]similarityHash, 0), valueRaw: value}, nil
+}
+
+func (f *SimilarityFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Value string `json:"value"`
+	}{
+		Value: f.valueRaw,
+	})
+}
+
+//AddBaseline adds a response the filtered responses are compared to
+func (f *SimilarityFilter) AddBaseline(response *ffuf.Response) {
+	h := responseSimhash(response)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.baselines = append(f.baselines, h)
+}
+
+//Baselines returns the number of baseline responses
+func (f *SimilarityFilter) Baselines() int {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return len(f.baselines)
+}
+
+func (f *SimilarityFilter) Filter(response *ffuf.Response) (bool, error) {
+	h := responseSimhash(response)
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	for _, b := range f.baselines {
+		if similarity(h, b) >= f.threshold {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *SimilarityFilter) Repr() string {
+	return f.valueRaw
+}
+
+func (f *SimilarityFilter) ReprVerbose() string {
+	return fmt.Sprintf("Response body similarity to %d baseline(s): >= %s%%", f.Baselines(), f.valueRaw)
+}
+
+//similarity returns the similarity of two hashed bodies as a percentage
+func similarity(a, b similarityHash) int {
+	if a.empty || b.empty {
+		if a.empty && b.empty {
+			return 100
+		}
+		return 0
+	}
+	return 100 * (64 - bits.OnesCount64(a.hash^b.hash)) / 64
+}
+
+//responseSimhash returns the simhash of the normalized response body
+func responseSimhash(response *ffuf.Response) similarityHash {
+	var input map[string][]byte
+	if response.Request != nil {
+		input = response.Request.Input
+	}
+	words := bodyWords(normalizeBody(response.Body(), input))
+	if len(words) == 0 {
+		return similarityHash{empty: true}
+	}
+	return similarityHash{hash: simhash(words)}
+}
+
+//normalizeBody replaces the input values reflected in the body, in their raw, URL and HTML encoded forms
+func normalizeBody(body []byte, input map[string][]byte) []byte {
+	for _, value := range input {
+		if len(value) == 0 {
+			continue
+		}
+		for _, v := range []string{string(value), url.QueryEscape(string(value)), url.PathEscape(string(value)), html.EscapeString(string(value))} {
+			body = bytes.ReplaceAll(body, []byte(v), []byte(" FFUFINPUT "))
+		}
+	}
+	return body
+}
+
+//bodyWords splits the body to lowercase words, replacing numbers with a single placeholder
+func bodyWords(body []byte) []string {
+	words := make([]string, 0)
+	fields := bytes.FieldsFunc(body, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, f := range fields {
+		w := string(bytes.ToLower(f))
+		numeric := true
+		for _, r := range w {
+			if !unicode.IsDigit(r) {
+				numeric = false
+				break
+			}
+		}
+		if numeric {
+			w = "0"
+		}
+		words = append(words, w)
+	}
+	return words
+}
+
+//simhash returns the 64 bit simhash of the word shingles
+func simhash(words []string) uint64 {
+	var weights [64]int
+	shingle := SIMILARITY_SHINGLE
+	if len(words) < shingle {
+		shingle = len(words)
+	}
+	h := fnv.New64a()
+	for i := 0; i+shingle <= len(words); i++ {
+		h.Reset()
+		for _, w := range words[i : i+shingle] {
+			_, _ = h.Write([]byte(w))
+			_, _ = h.Write([]byte{0})
+		}
+		sum := h.Sum64()
+		for b := 0; b < 64; b++ {
+			if sum&(1<<uint(b)) != 0 {
+				weights[b]++
+			} else {
+				weights[b]--
+			}
+		}
+	}
+	var hash uint64
+	for b := 0; b < 64; b++ {
+		if weights[b] > 0 {
+			hash |= 1 << uint(b)
+		}
+	}
+	return hash
+}
@@ -0,0 +1,65 @@
+package filter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+const similarityPage = `<html><head><title>Page not found</title></head><body><h1>Not Found</h1>
+<p>The requested URL /%s was not found on this server. Please check the spelling, or go back to the front page
+and try browsing from there. Request ID %s, served at %s by the frontend cluster.</p>
+<footer>Example Corporation, all rights reserved</footer></body></html>`
+
+func similarityResponse(page, input string) *ffuf.Response {
+	return &ffuf.Response{
+		Data: []byte(page),
+		Request: &ffuf.Request{
+			Input: map[string][]byte{"FUZZ": []byte(input)},
+		},
+	}
+}
+
+func TestNewSimilarityFilter(t *testing.T) {
+	fp, _ := NewSimilarityFilter("90")
+	f := fp.(*SimilarityFilter)
+	if f.threshold != 90 {
+		t.Errorf("Similarity filter was expected to have a threshold of 90")
+	}
+}
+
+func TestNewSimilarityFilterError(t *testing.T) {
+	for _, value := range []string{"", "0", "101", "abc", "90%"} {
+		_, err := NewSimilarityFilter(value)
+		if err == nil {
+			t.Errorf("Was expecting an error from errenous input data %s", value)
+		}
+	}
+}
+
+func TestSimilarityFiltering(t *testing.T) {
+	fp, _ := NewSimilarityFilter("90")
+	f := fp.(*SimilarityFilter)
+
+	nomatch := similarityResponse("anything", "admin")
+	if filtered, _ := f.Filter(nomatch); filtered {
+		t.Errorf("Was not expecting the filter to match without baselines")
+	}
+
+	f.AddBaseline(similarityResponse(fmt.Sprintf(similarityPage, "xkcdqwerty", "1234", "10:01:02"), "xkcdqwerty"))
+	for i, test := range []struct {
+		response *ffuf.Response
+		output   bool
+	}{
+		{similarityResponse(fmt.Sprintf(similarityPage, "a-much-longer-reflected-input", "98765", "23:59:59"), "a-much-longer-reflected-input"), true},
+		{similarityResponse(fmt.Sprintf(similarityPage, "a%20b", "1", "00:00:00"), "a b"), true},
+		{similarityResponse("<html><head><title>Admin</title></head><body><form action=/login><input name=user><input name=password type=password></form></body></html>", "admin"), false},
+		{similarityResponse("", "admin"), false},
+	} {
+		filterReturn, _ := f.Filter(test.response)
+		if filterReturn != test.output {
+			t.Errorf("Filter test %d: Was expecing filter return value of %t but got %t", i, test.output, filterReturn)
+		}
+	}
+}
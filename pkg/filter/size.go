@@ -51,6 +51,11 @@ func (f *SizeFilter) Filter(response *ffuf.Response) (bool, error) {
 	return false, nil
 }
 
+//BodyNeeds returns 0, the filter does not need the response body
+func (f *SizeFilter) BodyNeeds() int64 {
+	return 0
+}
+
 func (f *SizeFilter) Repr() string {
 	var strval []string
 	for _, iv := range f.Value {
@@ -64,6 +64,11 @@ func (f *StatusFilter) Filter(response *ffuf.Response) (bool, error) {
 	return false, nil
 }
 
+//BodyNeeds returns 0, the filter does not need the response body
+func (f *StatusFilter) BodyNeeds() int64 {
+	return 0
+}
+
 func (f *StatusFilter) Repr() string {
 	var strval []string
 	for _, iv := range f.Value {
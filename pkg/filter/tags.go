@@ -0,0 +1,106 @@
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//TagFilter matches HTML responses by the number of elements (start tags) in the body. The comments, the doctype and
+//the content of the script and style elements are not counted. Responses without an HTML Content-Type never match.
+type TagFilter struct {
+	Value []ffuf.ValueRange
+}
+
+func NewTagFilter(value string) (ffuf.FilterProvider, error) {
+	var intranges []ffuf.ValueRange
+	for _, sv := range strings.Split(value, ",") {
+		vr, err := ffuf.ValueRangeFromString(sv)
+		if err != nil {
+			return &TagFilter{}, fmt.Errorf("HTML tag count matcher (-mtags): invalid value: %s", sv)
+		}
+		intranges = append(intranges, vr)
+	}
+	return &TagFilter{Value: intranges}, nil
+}
+
+func (f *TagFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Value string `json:"value"`
+	}{
+		Value: f.Repr(),
+	})
+}
+
+func (f *TagFilter) Filter(response *ffuf.Response) (bool, error) {
+	if !strings.Contains(mediaType(response.ContentType), "html") {
+		return false, nil
+	}
+	tags := int64(htmlTagCount(response.Body()))
+	for _, iv := range f.Value {
+		if iv.Min <= tags && tags <= iv.Max {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *TagFilter) Repr() string {
+	var strval []string
+	for _, iv := range f.Value {
+		if iv.Min == iv.Max {
+			strval = append(strval, strconv.Itoa(int(iv.Min)))
+		} else {
+			strval = append(strval, strconv.Itoa(int(iv.Min))+"-"+strconv.Itoa(int(iv.Max)))
+		}
+	}
+	return strings.Join(strval, ",")
+}
+
+func (f *TagFilter) ReprVerbose() string {
+	return fmt.Sprintf("Response HTML tags: %s", f.Repr())
+}
+
+//htmlTagCount returns the number of start tags in the HTML document
+func htmlTagCount(body []byte) int {
+	count := 0
+	lower := bytes.ToLower(body)
+	for i := 0; i < len(lower); i++ {
+		if lower[i] != '<' || i+1 >= len(lower) {
+			continue
+		}
+		rest := lower[i+1:]
+		if bytes.HasPrefix(rest, []byte("!--")) {
+			end := bytes.Index(rest[3:], []byte("-->"))
+			if end < 0 {
+				break
+			}
+			i += 3 + end + 3
+			continue
+		}
+		if rest[0] < 'a' || rest[0] > 'z' {
+			// End tags, the doctype, processing instructions and a literal <
+			continue
+		}
+		count++
+		for _, raw := range []string{"script", "style"} {
+			if bytes.HasPrefix(rest, []byte(raw)) && len(rest) > len(raw) && !isTagNameByte(rest[len(raw)]) {
+				end := bytes.Index(rest, []byte("</"+raw))
+				if end < 0 {
+					return count
+				}
+				i += end
+				break
+			}
+		}
+	}
+	return count
+}
+
+func isTagNameByte(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-'
+}
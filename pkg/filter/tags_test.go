@@ -0,0 +1,43 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+func TestNewTagFilter(t *testing.T) {
+	f, _ := NewTagFilter("2,5-10,20")
+	if !strings.Contains(f.Repr(), "2,5-10,20") {
+		t.Errorf("Tag filter was expected to have 3 values")
+	}
+}
+
+func TestNewTagFilterError(t *testing.T) {
+	_, err := NewTagFilter("invalid")
+	if err == nil {
+		t.Errorf("Was expecting an error from errenous input data")
+	}
+}
+
+func TestTagFiltering(t *testing.T) {
+	f, _ := NewTagFilter("4")
+	for i, test := range []struct {
+		contentType string
+		body        string
+		output      bool
+	}{
+		{"text/html", "<!DOCTYPE html><html><body><p>a</p><br/></body></html>", true},
+		{"text/html; charset=utf-8", "<HTML><!-- <p> <div> --><Body><p>1 < 2</p><img src=x></Body></HTML>", true},
+		{"text/html", "<html><head><script>if (a<b) { x = '<div>' }</script></head><body></body></html>", true},
+		{"text/html", "<html><body><p>a</p></body></html>", false},
+		{"application/json", "<html><body><p>a</p><br></body></html>", false},
+	} {
+		resp := ffuf.Response{ContentType: test.contentType, Data: []byte(test.body)}
+		filterReturn, _ := f.Filter(&resp)
+		if filterReturn != test.output {
+			t.Errorf("Filter test %d: Was expecing filter return value of %t but got %t", i, test.output, filterReturn)
+		}
+	}
+}
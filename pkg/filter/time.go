@@ -10,28 +10,40 @@ import (
 )
 
 type TimeFilter struct {
-	ms       int64 // milliseconds since first response byte
-	gt       bool  // filter if response time is greater than
-	lt       bool  // filter if response time is less than
-	valueRaw string
+	conditions []timeCondition
+	valueRaw   string
 }
 
-func NewTimeFilter(value string) (ffuf.FilterProvider, error) {
-	var milliseconds int64
-	gt, lt := false, false
-
-	gt = strings.HasPrefix(value, ">")
-	lt = strings.HasPrefix(value, "<")
-
-	if (!lt && !gt) || (lt && gt) {
-		return &TimeFilter{}, fmt.Errorf("Time filter or matcher (-ft / -mt): invalid value: %s", value)
-	}
+type timeCondition struct {
+	ms    int64 // milliseconds since first response byte
+	gt    bool  // filter if response time is greater than
+	lt    bool  // filter if response time is less than
+	value ffuf.ValueRange
+}
 
-	milliseconds, err := strconv.ParseInt(value[1:], 10, 64)
-	if err != nil {
-		return &TimeFilter{}, fmt.Errorf("Time filter or matcher (-ft / -mt): invalid value: %s", value)
+//NewTimeFilter creates a response time filter from a comma separated list of conditions in milliseconds: >N and <N
+//for response times greater or less than N, and N or N-M for an exact value or a range
+func NewTimeFilter(value string) (ffuf.FilterProvider, error) {
+	var conditions []timeCondition
+	for _, sv := range strings.Split(value, ",") {
+		sv = strings.TrimSpace(sv)
+		gt := strings.HasPrefix(sv, ">")
+		lt := strings.HasPrefix(sv, "<")
+		if gt || lt {
+			milliseconds, err := strconv.ParseInt(sv[1:], 10, 64)
+			if err != nil {
+				return &TimeFilter{}, fmt.Errorf("Time filter or matcher (-ft / -mt): invalid value: %s", value)
+			}
+			conditions = append(conditions, timeCondition{ms: milliseconds, gt: gt, lt: lt})
+			continue
+		}
+		vr, err := ffuf.ValueRangeFromString(sv)
+		if err != nil {
+			return &TimeFilter{}, fmt.Errorf("Time filter or matcher (-ft / -mt): invalid value: %s", value)
+		}
+		conditions = append(conditions, timeCondition{value: vr})
 	}
-	return &TimeFilter{ms: milliseconds, gt: gt, lt: lt, valueRaw: value}, nil
+	return &TimeFilter{conditions: conditions, valueRaw: value}, nil
 }
 
 func (f *TimeFilter) MarshalJSON() ([]byte, error) {
@@ -43,20 +55,28 @@ func (f *TimeFilter) MarshalJSON() ([]byte, error) {
 }
 
 func (f *TimeFilter) Filter(response *ffuf.Response) (bool, error) {
-	if f.gt {
-		if response.Time.Milliseconds() > f.ms {
-			return true, nil
-		}
-
-	} else if f.lt {
-		if response.Time.Milliseconds() < f.ms {
+	ms := response.Time.Milliseconds()
+	for _, c := range f.conditions {
+		if c.gt {
+			if ms > c.ms {
+				return true, nil
+			}
+		} else if c.lt {
+			if ms < c.ms {
+				return true, nil
+			}
+		} else if ms >= c.value.Min && ms <= c.value.Max {
 			return true, nil
 		}
 	}
-
 	return false, nil
 }
 
+//BodyNeeds returns 0, the filter does not need the response body
+func (f *TimeFilter) BodyNeeds() int64 {
+	return 0
+}
+
 func (f *TimeFilter) Repr() string {
 	return f.valueRaw
 }
@@ -12,19 +12,30 @@ func TestNewTimeFilter(t *testing.T) {
 
 	f := fp.(*TimeFilter)
 
-	if !f.gt || f.lt {
+	if !f.conditions[0].gt || f.conditions[0].lt {
 		t.Errorf("Time filter was expected to have greater-than")
 	}
 
-	if f.ms != 100 {
+	if f.conditions[0].ms != 100 {
 		t.Errorf("Time filter was expected to have ms == 100")
 	}
+
+	fp, _ = NewTimeFilter("<10,200-300,450")
+	f = fp.(*TimeFilter)
+	if len(f.conditions) != 3 {
+		t.Errorf("Time filter was expected to have 3 conditions, got %d", len(f.conditions))
+	}
+	if f.conditions[1].value.Min != 200 || f.conditions[1].value.Max != 300 {
+		t.Errorf("Time filter was expected to have a range of 200-300")
+	}
 }
 
 func TestNewTimeFilterError(t *testing.T) {
-	_, err := NewTimeFilter("100>")
-	if err == nil {
-		t.Errorf("Was expecting an error from errenous input data")
+	for _, value := range []string{"100>", ">", "300-200", ">100,foo"} {
+		_, err := NewTimeFilter(value)
+		if err == nil {
+			t.Errorf("Was expecting an error from errenous input data %s", value)
+		}
 	}
 }
 
@@ -52,3 +63,30 @@ func TestTimeFiltering(t *testing.T) {
 		}
 	}
 }
+
+func TestTimeFilteringList(t *testing.T) {
+	f, _ := NewTimeFilter("<10,200-300,>5000")
+
+	for i, test := range []struct {
+		input  int64
+		output bool
+	}{
+		{2, true},
+		{10, false},
+		{199, false},
+		{200, true},
+		{300, true},
+		{301, false},
+		{5000, false},
+		{5001, true},
+	} {
+		resp := ffuf.Response{
+			Data: []byte("dahhhhhtaaaaa"),
+			Time: time.Duration(test.input * int64(time.Millisecond)),
+		}
+		filterReturn, _ := f.Filter(&resp)
+		if filterReturn != test.output {
+			t.Errorf("Filter test %d: Was expecing filter return value of %t but got %t", i, test.output, filterReturn)
+		}
+	}
+}
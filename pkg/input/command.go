@@ -2,6 +2,8 @@ package input
 
 import (
 	"bytes"
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strconv"
@@ -74,3 +76,78 @@ func (c *CommandInput) Value() []byte {
 func (c *CommandInput) Total() int {
 	return c.config.InputNum
 }
+
+//commandReader reads the inputs from the output of a command. In stream mode the command is run once and its output
+//is read as it is produced. In batch mode the command is run again every time the output of the previous run has
+//been consumed, with FFUF_NUM telling the number of the batch, until a run produces no output or -input-num batches
+//have been read.
+type commandReader struct {
+	config  *ffuf.Config
+	command string
+	shell   string
+	batch   int
+	buffer  bytes.Buffer
+	stdout  io.ReadCloser
+	cmd     *exec.Cmd
+	done    bool
+}
+
+func newCommandReader(value string, conf *ffuf.Config) (*commandReader, error) {
+	r := commandReader{config: conf, command: value, shell: SHELL_CMD}
+	if conf.InputShell != "" {
+		r.shell = conf.InputShell
+	}
+	if conf.InputCommandMode == ffuf.INPUT_COMMAND_STREAM {
+		r.cmd = exec.Command(r.shell, SHELL_ARG, r.command)
+		stdout, err := r.cmd.StdoutPipe()
+		if err != nil {
+			return &r, err
+		}
+		if err := r.cmd.Start(); err != nil {
+			return &r, fmt.Errorf("Could not start input command: %s", err)
+		}
+		r.stdout = stdout
+	}
+	return &r, nil
+}
+
+//Read reads the command output, running the command for the next batch when needed
+func (r *commandReader) Read(p []byte) (int, error) {
+	if r.stdout != nil {
+		n, err := r.stdout.Read(p)
+		if err == io.EOF {
+			// Reap the process, its exit status does not matter once the output has been read
+			_ = r.cmd.Wait()
+		}
+		return n, err
+	}
+	for r.buffer.Len() == 0 {
+		if r.done || !r.nextBatch() {
+			r.done = true
+			return 0, io.EOF
+		}
+	}
+	return r.buffer.Read(p)
+}
+
+//nextBatch runs the command for the next batch to the buffer, and returns false if there is no more inputs
+func (r *commandReader) nextBatch() bool {
+	if r.config.InputNum > 0 && r.batch >= r.config.InputNum {
+		return false
+	}
+	cmd := exec.Command(r.shell, SHELL_ARG, r.command)
+	cmd.Env = append(os.Environ(), "FFUF_NUM="+strconv.Itoa(r.batch))
+	cmd.Stdout = &r.buffer
+	r.batch++
+	if err := cmd.Run(); err != nil && r.buffer.Len() == 0 {
+		return false
+	}
+	if r.buffer.Len() == 0 {
+		return false
+	}
+	if b := r.buffer.Bytes(); b[len(b)-1] != '\n' {
+		// Keep the last line of the batch from joining the first line of the next one
+		r.buffer.WriteByte('\n')
+	}
+	return true
+}
@@ -11,12 +11,16 @@ type MainInputProvider struct {
 	Config      *ffuf.Config
 	position    int
 	msbIterator int
+	current     map[string][]byte
+	skipped     int
+	sharded     int
+	sniperPos   int
 }
 
 func NewInputProvider(conf *ffuf.Config) (ffuf.InputProvider, ffuf.Multierror) {
 	validmode := false
 	errs := ffuf.NewMultierror()
-	for _, mode := range []string{"clusterbomb", "pitchfork"} {
+	for _, mode := range []string{"clusterbomb", "pitchfork", "sniper"} {
 		if conf.InputMode == mode {
 			validmode = true
 		}
@@ -36,10 +40,56 @@ func NewInputProvider(conf *ffuf.Config) (ffuf.InputProvider, ffuf.Multierror) {
 	return &mainip, errs
 }
 
+//AddProvider adds the inputprovider of the configuration. The inputs of a keyword that already has an inputprovider
+//are merged to the ones of it.
 func (i *MainInputProvider) AddProvider(provider ffuf.InputProviderConfig) error {
-	if provider.Name == "command" {
+	last := len(i.Providers)
+	if err := i.addProvider(provider); err != nil {
+		return err
+	}
+	for idx, existing := range i.Providers[:last] {
+		if existing.Keyword() == provider.Keyword {
+			i.Providers[idx] = mergeInputs(existing, i.Providers[last], i.Config)
+			i.Providers = i.Providers[:last]
+			break
+		}
+	}
+	return nil
+}
+
+func (i *MainInputProvider) addProvider(provider ffuf.InputProviderConfig) error {
+	if provider.Name == "command" && (i.Config.InputCommandMode == ffuf.INPUT_COMMAND_STREAM || i.Config.InputCommandMode == ffuf.INPUT_COMMAND_BATCH) {
+		reader, err := newCommandReader(provider.Value, i.Config)
+		if err != nil {
+			return err
+		}
+		if i.streamable() {
+			i.Providers = append(i.Providers, newStreamInput(provider.Keyword, reader, i.Config))
+		} else {
+			// Combined with other wordlists, the command output is needed in memory
+			wl := WordlistInput{keyword: provider.Keyword, config: i.Config}
+			if err := wl.read(reader); err != nil {
+				return err
+			}
+			i.Providers = append(i.Providers, &wl)
+		}
+	} else if provider.Name == "command" {
 		newcomm, _ := NewCommandInput(provider.Keyword, provider.Value, i.Config)
 		i.Providers = append(i.Providers, newcomm)
+	} else if provider.Name == "range" {
+		newrange, err := NewRangeInput(provider.Keyword, provider.Value, i.Config)
+		if err != nil {
+			return err
+		}
+		i.Providers = append(i.Providers, newrange)
+	} else if provider.Name == "list" {
+		newlist, err := NewListInput(provider.Keyword, provider.Value, i.Config)
+		if err != nil {
+			return err
+		}
+		i.Providers = append(i.Providers, newlist)
+	} else if provider.Value == "-" && i.streamable() {
+		i.Providers = append(i.Providers, NewStdinInput(provider.Keyword, i.Config))
 	} else {
 		// Default to wordlist
 		newwl, err := NewWordlistInput(provider.Keyword, provider.Value, i.Config)
@@ -48,7 +98,29 @@ func (i *MainInputProvider) AddProvider(provider ffuf.InputProviderConfig) error
 		}
 		i.Providers = append(i.Providers, newwl)
 	}
-	return nil
+	if stages, ok := i.Config.InputTransforms[provider.Keyword]; ok {
+		last := len(i.Providers) - 1
+		i.Providers[last] = newTransformInput(i.Providers[last], stages)
+	}
+	return i.checkSniperInput()
+}
+
+//checkSniperInput rejects the last added inputprovider if it is streamed in sniper mode, that needs to go through the
+//wordlist once per injection position
+func (i *MainInputProvider) checkSniperInput() error {
+	last := len(i.Providers) - 1
+	if i.Config.InputMode != "sniper" || last < 0 || i.Providers[last].Total() >= 0 {
+		return nil
+	}
+	keyword := i.Providers[last].Keyword()
+	i.Providers = i.Providers[:last]
+	return fmt.Errorf("Sniper mode (-mode sniper) cannot be used with streamed input for keyword %s", keyword)
+}
+
+//streamable checks if a wordlist read from stdin or the output of an input command can be streamed instead of reading
+//it to memory first. Combinations with other wordlists need to go through it multiple times.
+func (i *MainInputProvider) streamable() bool {
+	return len(i.Config.InputProviders) == 1 && i.Config.InputMode != "sniper"
 }
 
 //Position will return the current position of progress
@@ -56,17 +128,58 @@ func (i *MainInputProvider) Position() int {
 	return i.position
 }
 
-//Next will increment the cursor position, and return a boolean telling if there's inputs left
+//Next will increment the cursor position, and return a boolean telling if there's inputs left. Inputs matching the
+//denylist, or belonging to other shards, are skipped.
 func (i *MainInputProvider) Next() bool {
-	if i.position >= i.Total() {
-		return false
+	for i.hasNext() {
+		i.position++
+		i.current = i.value()
+		if !i.Config.InShard(i.position, i.current) {
+			i.sharded++
+			continue
+		}
+		if i.Config.Denylist == nil || !i.Config.Denylist.DeniedInput(i.current) {
+			return true
+		}
+		i.skipped++
 	}
-	i.position++
-	return true
+	return false
+}
+
+//hasNext checks if there are inputs left, asking the inputprovider when their total is not known
+func (i *MainInputProvider) hasNext() bool {
+	if total := i.total(); total >= 0 {
+		return i.position < total
+	}
+	return i.Providers[0].Next()
 }
 
 //Value returns a map of inputs for keywords
 func (i *MainInputProvider) Value() map[string][]byte {
+	return i.current
+}
+
+//ReplaceProviders replaces the inputproviders with new ones created from the configuration, keeping the current
+//ones if any of them fails
+func (i *MainInputProvider) ReplaceProviders(providers []ffuf.InputProviderConfig) error {
+	old := i.Providers
+	i.Providers = make([]ffuf.InternalInputProvider, 0, len(providers))
+	for _, p := range providers {
+		if err := i.AddProvider(p); err != nil {
+			i.Providers = old
+			return err
+		}
+	}
+	i.Reset()
+	return nil
+}
+
+//Skipped returns the number of inputs skipped because of the denylist since the last reset
+func (i *MainInputProvider) Skipped() int {
+	return i.skipped
+}
+
+func (i *MainInputProvider) value() map[string][]byte {
 	retval := make(map[string][]byte)
 	if i.Config.InputMode == "clusterbomb" {
 		retval = i.clusterbombValue()
@@ -74,6 +187,9 @@ func (i *MainInputProvider) Value() map[string][]byte {
 	if i.Config.InputMode == "pitchfork" {
 		retval = i.pitchforkValue()
 	}
+	if i.Config.InputMode == "sniper" {
+		retval = i.sniperValue()
+	}
 	return retval
 }
 
@@ -84,6 +200,9 @@ func (i *MainInputProvider) Reset() {
 	}
 	i.position = 0
 	i.msbIterator = 0
+	i.skipped = 0
+	i.sharded = 0
+	i.sniperPos = 0
 }
 
 //pitchforkValue returns a map of keyword:value pairs including all inputs.
@@ -134,6 +253,31 @@ func (i *MainInputProvider) clusterbombValue() map[string][]byte {
 	return values
 }
 
+//sniperValue returns a map of keyword:value pairs for the sniper mode injection positions. The single wordlist is
+//injected to each position in turn, while the other positions keep their default values.
+func (i *MainInputProvider) sniperValue() map[string][]byte {
+	p := i.sniperProvider()
+	if p == nil {
+		return make(map[string][]byte)
+	}
+	if !p.Next() {
+		// Wordlist exhausted, continue with the next position
+		p.ResetPosition()
+		i.sniperPos++
+	}
+	values := i.Config.SniperInput(p.Keyword(), i.sniperPos, p.Value())
+	p.IncrementPosition()
+	return values
+}
+
+//sniperProvider returns the inputprovider of the sniper mode wordlist
+func (i *MainInputProvider) sniperProvider() ffuf.InternalInputProvider {
+	if len(i.Providers) == 0 {
+		return nil
+	}
+	return i.Providers[0]
+}
+
 func (i *MainInputProvider) clusterbombIteratorReset() {
 	for index, p := range i.Providers {
 		if index < i.msbIterator {
@@ -145,8 +289,24 @@ func (i *MainInputProvider) clusterbombIteratorReset() {
 	}
 }
 
-//Total returns the amount of input combinations available
+//Total returns the amount of input combinations available for the shard (-shard), or -1 if unknown for streamed
+//input. The inputs of a shard assigned by their hash are known only once skipped, so the total goes down until the
+//inputs have been gone through.
 func (i *MainInputProvider) Total() int {
+	total := i.total()
+	if i.Config.ShardHash && total >= 0 {
+		return total - i.sharded
+	}
+	return i.Config.ShardTotal(total)
+}
+
+//total returns the amount of input combinations available, or -1 if unknown for streamed input. Streamed input is
+//not accepted in sniper mode, so its total is always known.
+func (i *MainInputProvider) total() int {
+	if i.Config.InputMode != "sniper" && len(i.Providers) == 1 && i.Providers[0].Total() < 0 {
+		// Streamed input
+		return -1
+	}
 	count := 0
 	if i.Config.InputMode == "pitchfork" {
 		for _, p := range i.Providers {
@@ -161,5 +321,10 @@ func (i *MainInputProvider) Total() int {
 			count = count * p.Total()
 		}
 	}
+	if i.Config.InputMode == "sniper" {
+		if p := i.sniperProvider(); p != nil {
+			count = p.Total() * len(i.Config.SniperDefaults)
+		}
+	}
 	return count
 }
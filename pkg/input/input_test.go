@@ -0,0 +1,108 @@
+package input
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+func newTestInputProvider(t *testing.T, conf *ffuf.Config) *MainInputProvider {
+	t.Helper()
+	ip, errs := NewInputProvider(conf)
+	if err := errs.ErrorOrNil(); err != nil {
+		t.Fatalf("Could not create the inputprovider: %s", err)
+	}
+	return ip.(*MainInputProvider)
+}
+
+//collectInputs goes through the inputs, checking that the position advances by one for each of them
+func collectInputs(t *testing.T, ip *MainInputProvider, keywords ...string) []string {
+	t.Helper()
+	inputs := make([]string, 0)
+	for ip.Next() {
+		if ip.Position() != len(inputs)+1 {
+			t.Fatalf("Position %d after %d inputs", ip.Position(), len(inputs))
+		}
+		values := make([]string, 0, len(keywords))
+		for _, kw := range keywords {
+			values = append(values, string(ip.Value()[kw]))
+		}
+		inputs = append(inputs, strings.Join(values, ","))
+	}
+	return inputs
+}
+
+func TestSniperInputs(t *testing.T) {
+	conf := &ffuf.Config{
+		InputMode:      "sniper",
+		InputProviders: []ffuf.InputProviderConfig{{Name: "list", Keyword: "FUZZ", Value: "list:a,b,c"}},
+		SniperDefaults: []string{"x", "y"},
+	}
+	ip := newTestInputProvider(t, conf)
+	if ip.Total() != 6 {
+		t.Errorf("Expected a total of 6 inputs, got %d", ip.Total())
+	}
+	keywords := []string{"FUZZ", ffuf.SniperKeyword(0), ffuf.SniperKeyword(1)}
+	expected := []string{"a,a,y", "b,b,y", "c,c,y", "a,x,a", "b,x,b", "c,x,c"}
+	for round := 0; round < 2; round++ {
+		inputs := collectInputs(t, ip, keywords...)
+		if strings.Join(inputs, " ") != strings.Join(expected, " ") {
+			t.Errorf("Round %d: expected inputs %v, got %v", round, expected, inputs)
+		}
+		if ip.Position() != 6 {
+			t.Errorf("Round %d: expected the position 6 at the end, got %d", round, ip.Position())
+		}
+		// Going through the inputs again, like for a recursion job, starts from the first position
+		ip.Reset()
+	}
+}
+
+func TestSniperStreamCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The test command needs a POSIX shell")
+	}
+	conf := &ffuf.Config{
+		InputMode:        "sniper",
+		InputCommandMode: ffuf.INPUT_COMMAND_STREAM,
+		InputProviders:   []ffuf.InputProviderConfig{{Name: "command", Keyword: "FUZZ", Value: "printf 'a\\nb\\n'"}},
+		SniperDefaults:   []string{"x", "y"},
+	}
+	ip := newTestInputProvider(t, conf)
+	// The command output is read to memory for going through it once per injection position
+	if ip.Total() != 4 {
+		t.Errorf("Expected a total of 4 inputs, got %d", ip.Total())
+	}
+	inputs := collectInputs(t, ip, ffuf.SniperKeyword(0), ffuf.SniperKeyword(1))
+	expected := []string{"a,y", "b,y", "x,a", "x,b"}
+	if strings.Join(inputs, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected inputs %v, got %v", expected, inputs)
+	}
+}
+
+func TestSniperStreamedInputRejected(t *testing.T) {
+	conf := &ffuf.Config{InputMode: "sniper", SniperDefaults: []string{"x"}}
+	ip := MainInputProvider{Config: conf}
+	// A streamed inputprovider does not know its total
+	ip.Providers = append(ip.Providers, newStreamInput("FUZZ", strings.NewReader("a\nb\n"), conf))
+	if err := ip.checkSniperInput(); err == nil {
+		t.Errorf("Expected streamed input to be rejected in sniper mode")
+	}
+	if len(ip.Providers) != 0 {
+		t.Errorf("Expected the streamed inputprovider to be removed, got %d inputproviders", len(ip.Providers))
+	}
+}
+
+func TestStreamedInputs(t *testing.T) {
+	conf := &ffuf.Config{InputMode: "clusterbomb"}
+	ip := MainInputProvider{Config: conf}
+	ip.Providers = append(ip.Providers, newStreamInput("FUZZ", strings.NewReader("a\nb\nc\n"), conf))
+	if ip.Total() != -1 {
+		t.Errorf("Expected an unknown total for streamed input, got %d", ip.Total())
+	}
+	inputs := collectInputs(t, &ip, "FUZZ")
+	if strings.Join(inputs, " ") != "a b c" {
+		t.Errorf("Expected inputs [a b c], got %v", inputs)
+	}
+}
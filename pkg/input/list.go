@@ -0,0 +1,43 @@
+package input
+
+import (
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//NewListInput returns the inputs listed inline in the wordlist value, with the extensions applied like to the lines
+//of a wordlist file
+func NewListInput(keyword string, value string, conf *ffuf.Config) (*WordlistInput, error) {
+	wl := WordlistInput{keyword: keyword, config: conf, data: make([][]byte, 0)}
+	values, _, err := ffuf.ParseInputList(value)
+	if err != nil {
+		return &wl, err
+	}
+	for _, v := range values {
+		entries, err := wordlistEntries(conf, keyword, v)
+		if err != nil {
+			return &wl, err
+		}
+		wl.data = append(wl.data, entries...)
+	}
+	return &wl, nil
+}
+
+//mergeInputs merges the inputs of two inputproviders of the same keyword to a single wordlist, in the order of the
+//providers and without the duplicates
+func mergeInputs(first ffuf.InternalInputProvider, second ffuf.InternalInputProvider, conf *ffuf.Config) *WordlistInput {
+	wl := WordlistInput{keyword: first.Keyword(), config: conf, data: make([][]byte, 0, first.Total()+second.Total())}
+	seen := make(map[string]bool, first.Total()+second.Total())
+	for _, p := range []ffuf.InternalInputProvider{first, second} {
+		p.ResetPosition()
+		for p.Next() {
+			v := p.Value()
+			if !seen[string(v)] {
+				seen[string(v)] = true
+				wl.data = append(wl.data, v)
+			}
+			p.IncrementPosition()
+		}
+		p.ResetPosition()
+	}
+	return &wl
+}
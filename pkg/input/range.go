@@ -0,0 +1,56 @@
+package input
+
+import (
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//RangeInput generates a numeric or date sequence as the inputs, without reading a wordlist file
+type RangeInput struct {
+	config   *ffuf.Config
+	keyword  string
+	position int
+	inputs   ffuf.InputRange
+}
+
+func NewRangeInput(keyword string, value string, conf *ffuf.Config) (*RangeInput, error) {
+	inputs, _, err := ffuf.ParseInputRange(value)
+	if err != nil {
+		return &RangeInput{}, err
+	}
+	return &RangeInput{config: conf, keyword: keyword, inputs: inputs}, nil
+}
+
+//Keyword returns the keyword assigned to this InternalInputProvider
+func (r *RangeInput) Keyword() string {
+	return r.keyword
+}
+
+//Position will return the current position in the range
+func (r *RangeInput) Position() int {
+	return r.position
+}
+
+//ResetPosition resets the position back to the beginning of the range
+func (r *RangeInput) ResetPosition() {
+	r.position = 0
+}
+
+//IncrementPosition will increment the current position in the range
+func (r *RangeInput) IncrementPosition() {
+	r.position += 1
+}
+
+//Next will return a boolean telling if there's values left in the range
+func (r *RangeInput) Next() bool {
+	return r.position < r.inputs.Total()
+}
+
+//Value returns the formatted value at the current position of the range
+func (r *RangeInput) Value() []byte {
+	return r.inputs.Value(r.position)
+}
+
+//Total returns the number of values in the range
+func (r *RangeInput) Total() int {
+	return r.inputs.Total()
+}
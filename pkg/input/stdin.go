@@ -0,0 +1,107 @@
+package input
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"os"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//StdinInput streams the wordlist from stdin, so that the inputs can be piped from a generator without knowing their
+//count beforehand. The inputs read are kept in memory only if the job needs to go through them again, e.g. for
+//recursion jobs.
+type StdinInput struct {
+	config   *ffuf.Config
+	keyword  string
+	scanner  *bufio.Scanner
+	data     [][]byte
+	offset   int // position of the first entry in data
+	position int
+	keep     bool
+	eof      bool
+}
+
+func NewStdinInput(keyword string, conf *ffuf.Config) *StdinInput {
+	return newStreamInput(keyword, os.Stdin, conf)
+}
+
+func newStreamInput(keyword string, reader io.Reader, conf *ffuf.Config) *StdinInput {
+	return &StdinInput{
+		config:  conf,
+		keyword: keyword,
+		scanner: bufio.NewScanner(reader),
+		data:    make([][]byte, 0),
+		keep:    conf.Recursion || conf.Crawl || conf.AutoExtensions || len(conf.ApiOperations) > 0,
+	}
+}
+
+//Keyword returns the keyword assigned to this InternalInputProvider
+func (s *StdinInput) Keyword() string {
+	return s.keyword
+}
+
+//Position will return the current position in the input stream
+func (s *StdinInput) Position() int {
+	return s.position
+}
+
+//ResetPosition goes back to the beginning of the inputs read if they are kept, and continues from the current
+//point of the stream otherwise
+func (s *StdinInput) ResetPosition() {
+	if s.keep {
+		s.position = 0
+		return
+	}
+	s.position = 0
+	s.offset = 0
+	s.data = s.data[:0]
+}
+
+//IncrementPosition will increment the current position in the input stream
+func (s *StdinInput) IncrementPosition() {
+	s.position += 1
+}
+
+//Next reads the next line from the stream if needed, and returns a boolean telling if there's inputs left
+func (s *StdinInput) Next() bool {
+	if s.position < s.offset+len(s.data) {
+		return true
+	}
+	for !s.eof {
+		if !s.scanner.Scan() {
+			s.eof = true
+			break
+		}
+		entries, err := wordlistEntries(s.config, s.keyword, s.scanner.Text())
+		if err != nil {
+			log.Printf("Skipping wordlist entry for keyword %s: %s", s.keyword, err)
+			continue
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		if !s.keep {
+			// Every entry read so far has been consumed
+			s.offset = s.position
+			s.data = s.data[:0]
+		}
+		s.data = append(s.data, entries...)
+		return true
+	}
+	return false
+}
+
+//Value returns the input at current cursor position
+func (s *StdinInput) Value() []byte {
+	return s.data[s.position-s.offset]
+}
+
+//Total returns -1 as the number of inputs is not known before the stream ends
+func (s *StdinInput) Total() int {
+	if s.eof && s.keep {
+		return len(s.data)
+	}
+	return -1
+}
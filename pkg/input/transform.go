@@ -0,0 +1,118 @@
+package input
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//TransformInput wraps an InternalInputProvider, producing a variant of every input for each of the operations of a
+//transformation stage. The stages of a pipeline are chained wrappers, multiplying the total of the inputs.
+type TransformInput struct {
+	provider  ffuf.InternalInputProvider
+	ops       []string
+	variant   int
+	cachePos  int
+	cacheVal  []byte
+	haveCache bool
+}
+
+//newTransformInput wraps the provider in a TransformInput for each stage of the pipeline
+func newTransformInput(provider ffuf.InternalInputProvider, stages [][]string) ffuf.InternalInputProvider {
+	for _, ops := range stages {
+		provider = &TransformInput{provider: provider, ops: ops}
+	}
+	return provider
+}
+
+//Keyword returns the keyword of the wrapped InternalInputProvider
+func (t *TransformInput) Keyword() string {
+	return t.provider.Keyword()
+}
+
+//Position returns the current position, counting every variant of the inputs
+func (t *TransformInput) Position() int {
+	return t.provider.Position()*len(t.ops) + t.variant
+}
+
+//ResetPosition resets the position of the wrapped InternalInputProvider and the variant
+func (t *TransformInput) ResetPosition() {
+	t.provider.ResetPosition()
+	t.variant = 0
+	t.haveCache = false
+}
+
+//IncrementPosition advances to the next variant, or to the next input of the wrapped InternalInputProvider
+func (t *TransformInput) IncrementPosition() {
+	t.variant++
+	if t.variant == len(t.ops) {
+		t.variant = 0
+		t.provider.IncrementPosition()
+	}
+}
+
+//Next tells if the wrapped InternalInputProvider has inputs left
+func (t *TransformInput) Next() bool {
+	return t.provider.Next()
+}
+
+//Value returns the current variant of the input
+func (t *TransformInput) Value() []byte {
+	// The input is read only once for all of its variants, as reading it may be expensive, e.g. for commands
+	if !t.haveCache || t.cachePos != t.provider.Position() {
+		t.cacheVal = t.provider.Value()
+		t.cachePos = t.provider.Position()
+		t.haveCache = true
+	}
+	return transform(t.ops[t.variant], t.cacheVal)
+}
+
+//Total returns the total of the wrapped InternalInputProvider multiplied by the number of variants, or -1 if unknown
+func (t *TransformInput) Total() int {
+	total := t.provider.Total()
+	if total < 0 {
+		return total
+	}
+	return total * len(t.ops)
+}
+
+//transform applies a single transformation operation to the input
+func transform(op string, value []byte) []byte {
+	if strings.HasPrefix(op, ".") {
+		return append(append(make([]byte, 0, len(value)+len(op)), value...), op...)
+	}
+	switch op {
+	case "upper":
+		return bytes.ToUpper(value)
+	case "lower":
+		return bytes.ToLower(value)
+	case "capitalize":
+		if len(value) == 0 {
+			return value
+		}
+		return append(bytes.ToUpper(value[:1]), bytes.ToLower(value[1:])...)
+	case "urlencode":
+		return urlEncode(value)
+	case "doubleurlencode":
+		return urlEncode(urlEncode(value))
+	case "base64":
+		return []byte(base64.StdEncoding.EncodeToString(value))
+	}
+	return value
+}
+
+//urlEncode percent-encodes all of the bytes other than the unreserved characters of RFC 3986
+func urlEncode(value []byte) []byte {
+	var b bytes.Buffer
+	for _, c := range value {
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.Bytes()
+}
@@ -2,6 +2,9 @@ package input
 
 import (
 	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
 	"os"
 	"regexp"
 	"strings"
@@ -9,6 +12,8 @@ import (
 	"github.com/ffuf/ffuf/pkg/ffuf"
 )
 
+var extRegexp = regexp.MustCompile(`(?i)%ext%`)
+
 type WordlistInput struct {
 	config   *ffuf.Config
 	data     [][]byte
@@ -102,51 +107,85 @@ func (w *WordlistInput) readFile(path string) error {
 		}
 	}
 	defer file.Close()
+	return w.read(file)
+}
 
+//read reads the inputs line by line from the reader to a byte slice
+func (w *WordlistInput) read(r io.Reader) error {
 	var data [][]byte
-	var ok bool
-	reader := bufio.NewScanner(file)
-	re := regexp.MustCompile(`(?i)%ext%`)
+	reader := bufio.NewScanner(r)
+	line := 0
 	for reader.Scan() {
-		if w.config.DirSearchCompat && len(w.config.Extensions) > 0 {
-			text := []byte(reader.Text())
-			if re.Match(text) {
-				for _, ext := range w.config.Extensions {
-					contnt := re.ReplaceAll(text, []byte(ext))
-					data = append(data, []byte(contnt))
-				}
-			} else {
-				text := reader.Text()
-
-				if w.config.IgnoreWordlistComments {
-					text, ok = stripComments(text)
-					if !ok {
-						continue
-					}
-				}
-				data = append(data, []byte(text))
-			}
-		} else {
-			text := reader.Text()
-
-			if w.config.IgnoreWordlistComments {
-				text, ok = stripComments(text)
-				if !ok {
-					continue
-				}
-			}
-			data = append(data, []byte(text))
-			if w.keyword == "FUZZ" && len(w.config.Extensions) > 0 {
-				for _, ext := range w.config.Extensions {
-					data = append(data, []byte(text+ext))
-				}
-			}
+		line++
+		entries, err := wordlistEntries(w.config, w.keyword, reader.Text())
+		if err != nil {
+			return fmt.Errorf("Wordlist for keyword %s, line %d: %s", w.keyword, line, err)
 		}
+		data = append(data, entries...)
 	}
 	w.data = data
 	return reader.Err()
 }
 
+//wordlistEntries returns the inputs created from a single wordlist line, with the extensions applied. The lines of
+//hex encoded wordlists are decoded to the raw bytes first.
+func wordlistEntries(conf *ffuf.Config, keyword string, text string) ([][]byte, error) {
+	var ok bool
+	var err error
+	entries := make([][]byte, 0, 1)
+	hexEncoded := inSlice(keyword, conf.HexWordlists)
+	if conf.DirSearchCompat && len(conf.Extensions) > 0 {
+		if !hexEncoded && extRegexp.MatchString(text) {
+			for _, ext := range conf.Extensions {
+				entries = append(entries, []byte(extRegexp.ReplaceAllString(text, ext)))
+			}
+			return entries, nil
+		}
+		if conf.IgnoreWordlistComments {
+			text, ok = stripComments(text)
+			if !ok {
+				return entries, nil
+			}
+		}
+		if hexEncoded {
+			text, err = decodeHexEntry(text)
+		}
+		return append(entries, []byte(text)), err
+	}
+	if conf.IgnoreWordlistComments {
+		text, ok = stripComments(text)
+		if !ok {
+			return entries, nil
+		}
+	}
+	if hexEncoded {
+		if text, err = decodeHexEntry(text); err != nil {
+			return entries, err
+		}
+	}
+	entries = append(entries, []byte(text))
+	if keyword == "FUZZ" && len(conf.Extensions) > 0 {
+		for _, ext := range conf.Extensions {
+			entries = append(entries, []byte(text+ext))
+		}
+	}
+	return entries, nil
+}
+
+//decodeHexEntry decodes a hex encoded wordlist line. An optional 0x prefix and whitespace between the bytes are
+//allowed, e.g. "0xdeadbeef" or "de ad be ef".
+func decodeHexEntry(text string) (string, error) {
+	text = strings.Join(strings.Fields(text), "")
+	if strings.HasPrefix(text, "0x") || strings.HasPrefix(text, "0X") {
+		text = text[2:]
+	}
+	decoded, err := hex.DecodeString(text)
+	if err != nil {
+		return "", fmt.Errorf("Invalid hex encoded entry: %s", err)
+	}
+	return string(decoded), nil
+}
+
 // stripComments removes all kind of comments from the word
 func stripComments(text string) (string, bool) {
 	// If the line starts with a # ignoring any space on the left,
@@ -163,3 +202,12 @@ func stripComments(text string) (string, bool) {
 	}
 	return text[:index], true
 }
+
+func inSlice(key string, slice []string) bool {
+	for _, v := range slice {
+		if v == key {
+			return true
+		}
+	}
+	return false
+}
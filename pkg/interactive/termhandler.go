@@ -75,6 +75,29 @@ func (i *interactive) handleInput(in []byte) {
 					i.Job.Output.Info("Output file successfully saved!")
 				}
 			}
+		case "output":
+			if len(args) < 2 {
+				i.Job.Output.Error("Please define the filename")
+			} else if len(args) > 2 {
+				i.Job.Output.Error("Too many arguments for \"output\"")
+			} else {
+				if err := i.Job.SetOutputFile(args[1]); err != nil {
+					i.Job.Output.Error(fmt.Sprintf("%s", err))
+				} else {
+					i.paused = i.Job.Paused
+					i.Job.Output.Info(fmt.Sprintf("Writing the output to %s", args[1]))
+				}
+			}
+		case "snapshot":
+			if len(args) > 2 {
+				i.Job.Output.Error("Too many arguments for \"snapshot\"")
+			} else {
+				format := ""
+				if len(args) == 2 {
+					format = args[1]
+				}
+				i.saveSnapshot(format)
+			}
 		case "fc":
 			if len(args) < 2 {
 				i.Job.Output.Error("Please define a value for status code filter, or \"none\" for removing it")
@@ -120,6 +143,14 @@ func (i *interactive) handleInput(in []byte) {
 				i.updateFilter("time", args[1])
 				i.Job.Output.Info("New response time filter value set")
 			}
+		case "rate":
+			if len(args) > 2 {
+				i.Job.Output.Error("Too many arguments for \"rate\"")
+			} else if len(args) == 2 {
+				i.updateRate(args[1])
+			} else {
+				i.printRate()
+			}
 		case "queueshow":
 			i.printQueue()
 		case "queuedel":
@@ -130,6 +161,12 @@ func (i *interactive) handleInput(in []byte) {
 			} else {
 				i.deleteQueue(args[1])
 			}
+		case "queueset":
+			if len(args) < 3 {
+				i.Job.Output.Error("Please define the index of a queued job and the settings to override, eg. \"queueset 2 threads=2 fs=42\"")
+			} else {
+				i.setQueueOverrides(args[1], args[2:])
+			}
 		case "queueskip":
 			i.Job.SkipQueue()
 			i.Job.Output.Info("Skipping to the next queued job")
@@ -176,6 +213,64 @@ func (i *interactive) updateFilter(name, value string) {
 	}
 }
 
+func (i *interactive) updateRate(value string) {
+	if value == "auto" {
+		i.Job.Rate.Unpin()
+		i.Job.Output.Info("Returned to the automatic rate adjustment")
+		return
+	}
+	rate, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		i.Job.Output.Error(fmt.Sprintf("Not a number: %s", value))
+		return
+	}
+	if err := i.Job.Rate.Pin(rate); err != nil {
+		i.Job.Output.Error(err.Error())
+		return
+	}
+	i.Job.Output.Info(fmt.Sprintf("Rate pinned to %d requests per second", rate))
+}
+
+func (i *interactive) printRate() {
+	stats := i.Job.Rate.Stats()
+	target := "unlimited"
+	if stats.Target > 0 {
+		target = fmt.Sprintf("%d req/sec", stats.Target)
+	}
+	if stats.Pinned {
+		target += " (pinned)"
+	}
+	i.Job.Output.Raw(fmt.Sprintf("Rate target: %s, measured: %d req/sec, pause per request: %s\n", target, stats.Current, stats.Adjustment))
+	if len(stats.Samples) > 0 {
+		min, max, total := stats.Samples[0], stats.Samples[0], time.Duration(0)
+		for _, s := range stats.Samples {
+			if s < min {
+				min = s
+			}
+			if s > max {
+				max = s
+			}
+			total += s
+		}
+		i.Job.Output.Raw(fmt.Sprintf("Request durations of the last %d requests: min %s, avg %s, max %s\n", len(stats.Samples), min, total/time.Duration(len(stats.Samples)), max))
+	}
+}
+
+func (i *interactive) saveSnapshot(format string) {
+	switch format {
+	case "", "all", "json", "ejson", "html", "md", "csv", "ecsv", "sqlite", "ndjson":
+	default:
+		i.Job.Output.Error(fmt.Sprintf("Unknown output format: %s", format))
+		return
+	}
+	filename, err := i.Job.SaveSnapshot(format)
+	if err != nil {
+		i.Job.Output.Error(fmt.Sprintf("%s", err))
+	} else {
+		i.Job.Output.Info(fmt.Sprintf("Results snapshot saved to %s", filename))
+	}
+}
+
 func (i *interactive) printQueue() {
 	if len(i.Job.QueuedJobs()) > 0 {
 		i.Job.Output.Raw("Queued recursion jobs:\n")
@@ -184,6 +279,9 @@ func (i *interactive) printQueue() {
 			if index == 0 {
 				postfix = " (active job)"
 			}
+			if job.Overrides != nil {
+				postfix += " (" + job.Overrides.String() + ")"
+			}
 			i.Job.Output.Raw(fmt.Sprintf(" [%d] : %s%s\n", index, job.Url, postfix))
 		}
 	} else {
@@ -206,6 +304,24 @@ func (i *interactive) deleteQueue(in string) {
 		}
 	}
 }
+func (i *interactive) setQueueOverrides(in string, settings []string) {
+	index, err := strconv.Atoi(in)
+	if err != nil {
+		i.Job.Output.Warning(fmt.Sprintf("Not a number: %s", in))
+		return
+	}
+	overrides, err := filter.ParseQueueJobOverrides(settings)
+	if err != nil {
+		i.Job.Output.Error(err.Error())
+		return
+	}
+	if err := i.Job.SetQueueOverrides(index, overrides); err != nil {
+		i.Job.Output.Warning(fmt.Sprintf("%s. Use \"queueshow\" to list the jobs in queue", err))
+		return
+	}
+	i.Job.Output.Info(fmt.Sprintf("Overrides of queued job %d set: %s", index, overrides.String()))
+}
+
 func (i *interactive) printBanner() {
 	i.Job.Output.Raw("entering interactive mode\ntype \"help\" for a list of commands, or ENTER to resume.\n")
 }
@@ -237,13 +353,17 @@ available commands:
  fw [value]             - (re)configure word count filter %s
  fs [value]             - (re)configure size filter %s
  ft [value]				- (re)configure time filter %s
+ rate [value]           - show the request rate, pin it to [value] req/sec, or "auto" to adjust automatically
  queueshow              - show recursive job queue
  queuedel [number]      - delete a recursion job in the queue
+ queueset [number] [settings] - override threads=N, delay=SECONDS and add fc, fl, fr, fs, ft or fw filters for a queued job
  queueskip              - advance to the next queued recursion job
  restart                - restart and resume the current ffuf job
  resume                 - resume current ffuf job (or: ENTER) 
  show                   - show results for the current job
  savejson [filename]    - save current matches to a file
+ output [filename]      - write the output file (-o) to another path from now on, resuming if paused on a write failure
+ snapshot [format]      - save all matches so far to a timestamped file, in the -of format by default
  help                   - you are looking at it
 `
 	i.Job.Output.Raw(fmt.Sprintf(help, fc, fl, fw, fs, ft))
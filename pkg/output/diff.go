@@ -0,0 +1,95 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//DiffEntry is a result of the diff between two scans. The old status and length are set for the changed results.
+type DiffEntry struct {
+	Url       string            `json:"url"`
+	Input     map[string]string `json:"input"`
+	Status    int64             `json:"status"`
+	Length    int64             `json:"length"`
+	OldStatus *int64            `json:"old_status,omitempty"`
+	OldLength *int64            `json:"old_length,omitempty"`
+}
+
+//ResultDiff holds the results of a scan that are new, removed or changed compared to a baseline scan
+type ResultDiff struct {
+	New     []DiffEntry `json:"new"`
+	Removed []DiffEntry `json:"removed"`
+	Changed []DiffEntry `json:"changed"`
+}
+
+func newDiffEntry(r ffuf.Result) DiffEntry {
+	input := make(map[string]string, len(r.Input))
+	for k, v := range r.Input {
+		if k != "FFUFHASH" {
+			input[k] = string(v)
+		}
+	}
+	return DiffEntry{Url: r.Url, Input: input, Status: r.StatusCode, Length: r.ContentLength}
+}
+
+//DiffResults compares the results of a scan to the ones of a baseline scan, matching them by their URL and inputs. A
+//result is changed if its status code or length differ.
+func DiffResults(baseline, current []ffuf.Result) ResultDiff {
+	diff := ResultDiff{New: make([]DiffEntry, 0), Removed: make([]DiffEntry, 0), Changed: make([]DiffEntry, 0)}
+	old := make(map[string]ffuf.Result, len(baseline))
+	for _, r := range baseline {
+		old[resultKey(r)] = r
+	}
+	seen := make(map[string]bool, len(current))
+	for _, r := range current {
+		key := resultKey(r)
+		seen[key] = true
+		o, ok := old[key]
+		if !ok {
+			diff.New = append(diff.New, newDiffEntry(r))
+			continue
+		}
+		if o.StatusCode != r.StatusCode || o.ContentLength != r.ContentLength {
+			e := newDiffEntry(r)
+			e.OldStatus = &o.StatusCode
+			e.OldLength = &o.ContentLength
+			diff.Changed = append(diff.Changed, e)
+		}
+	}
+	for _, r := range baseline {
+		if !seen[resultKey(r)] {
+			diff.Removed = append(diff.Removed, newDiffEntry(r))
+		}
+	}
+	for _, entries := range [][]DiffEntry{diff.New, diff.Removed, diff.Changed} {
+		sort.SliceStable(entries, func(a, b int) bool { return entries[a].Url < entries[b].Url })
+	}
+	return diff
+}
+
+//Report returns the diff as a printable list, + for the new results, - for the removed and ~ for the changed ones
+func (d ResultDiff) Report() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Compared to the baseline: %d new, %d removed and %d changed results\n", len(d.New), len(d.Removed), len(d.Changed))
+	for _, e := range d.New {
+		fmt.Fprintf(&b, " + %s [Status: %d, Size: %d]\n", e.Url, e.Status, e.Length)
+	}
+	for _, e := range d.Removed {
+		fmt.Fprintf(&b, " - %s [Status: %d, Size: %d]\n", e.Url, e.Status, e.Length)
+	}
+	for _, e := range d.Changed {
+		status := fmt.Sprintf("%d", e.Status)
+		if *e.OldStatus != e.Status {
+			status = fmt.Sprintf("%d -> %d", *e.OldStatus, e.Status)
+		}
+		size := fmt.Sprintf("%d", e.Length)
+		if *e.OldLength != e.Length {
+			size = fmt.Sprintf("%d -> %d (%+d)", *e.OldLength, e.Length, e.Length-*e.OldLength)
+		}
+		fmt.Fprintf(&b, " ~ %s [Status: %s, Size: %s]\n", e.Url, status, size)
+	}
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+//OUTPUT_SNAPSHOT_INTERVAL is how often the rewritten output file is synced to the disk, unless -fsync syncs every write
+const OUTPUT_SNAPSHOT_INTERVAL = 30 * time.Second
+
+//writeAtomic writes an output file through a temporary file next to it, which is renamed over the output file once
+//fully written. A crash in the middle of the write leaves the previous version of the file in place instead of a
+//truncated one. When durable, the temporary file and the directory are synced to the disk around the rename, so
+//the new version survives a system crash as well.
+func writeAtomic(filename string, durable bool, write func(string) error) error {
+	dir, base := filepath.Split(filename)
+	tmpfile := filepath.Join(dir, fmt.Sprintf(".%s.%d.tmp", base, os.Getpid()))
+	err := write(tmpfile)
+	if err == nil && durable {
+		err = syncPath(tmpfile)
+	}
+	if err == nil {
+		err = os.Rename(tmpfile, filename)
+	}
+	if err != nil {
+		os.Remove(tmpfile)
+		return err
+	}
+	if durable {
+		if dir == "" {
+			dir = "."
+		}
+		// Not all of the platforms support syncing a directory, the file itself is already on the disk
+		syncPath(dir)
+	}
+	return nil
+}
+
+//syncPath commits a file or a directory to the disk
+func syncPath(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
@@ -9,7 +9,7 @@ import (
 	"github.com/ffuf/ffuf/pkg/ffuf"
 )
 
-var staticheaders = []string{"url", "redirectlocation", "position", "status_code", "content_length", "content_words", "content_lines", "content_type", "duration", "resultfile"}
+var staticheaders = []string{"url", "redirectlocation", "position", "status_code", "content_length", "content_words", "content_lines", "content_type", "duration", "resultfile", "scraper", "redirectscheme", "duration_bucket"}
 
 func writeCSV(filename string, config *ffuf.Config, res []ffuf.Result, encode bool) error {
 	header := make([]string, 0)
@@ -22,9 +22,8 @@ func writeCSV(filename string, config *ffuf.Config, res []ffuf.Result, encode bo
 	w := csv.NewWriter(f)
 	defer w.Flush()
 
-	for _, inputprovider := range config.InputProviders {
-		header = append(header, inputprovider.Keyword)
-	}
+	keywords := resultKeywords(config)
+	header = append(header, keywords...)
 	header = append(header, staticheaders...)
 
 	if err := w.Write(header); err != nil {
@@ -39,7 +38,7 @@ func writeCSV(filename string, config *ffuf.Config, res []ffuf.Result, encode bo
 			r.Input = inputs
 		}
 
-		err := w.Write(toCSV(r))
+		err := w.Write(toCSV(r, keywords))
 		if err != nil {
 			return err
 		}
@@ -51,10 +50,10 @@ func base64encode(in []byte) string {
 	return base64.StdEncoding.EncodeToString(in)
 }
 
-func toCSV(r ffuf.Result) []string {
+func toCSV(r ffuf.Result, keywords []string) []string {
 	res := make([]string, 0)
-	for _, v := range r.Input {
-		res = append(res, string(v))
+	for _, k := range keywords {
+		res = append(res, string(r.Input[k]))
 	}
 	res = append(res, r.Url)
 	res = append(res, r.RedirectLocation)
@@ -66,5 +65,8 @@ func toCSV(r ffuf.Result) []string {
 	res = append(res, r.ContentType)
 	res = append(res, r.Duration.String())
 	res = append(res, r.ResultFile)
+	res = append(res, scraperText(r.ScraperData))
+	res = append(res, r.RedirectScheme)
+	res = append(res, durationBucket(r.Duration))
 	return res
 }
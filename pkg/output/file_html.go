@@ -9,10 +9,13 @@ import (
 )
 
 type htmlFileOutput struct {
+	ScanID      string
 	CommandLine string
 	Time        string
 	Keys        []string
 	Results     []ffuf.Result
+	Histogram   []latencyBucket
+	Summary     *summaryOutput
 }
 
 const (
@@ -61,6 +64,44 @@ const (
 
 		<pre>{{ .CommandLine }}</pre>
 		<pre>{{ .Time }}</pre>
+		<pre>Scan ID: {{ .ScanID }}</pre>
+
+   {{ with .Summary }}
+   <h5>Summary</h5>
+   <table id="ffufsummary" class="striped">
+        <tbody>
+            <tr><td>Requests</td><td>{{ .Requests }} in {{ printf "%.1f" .Duration }}s ({{ printf "%.1f" .RequestsPerSecond }} req/sec)</td></tr>
+            <tr><td>Jobs</td><td>{{ .Jobs }}</td></tr>
+            <tr><td>Matches</td><td>{{ .Matches }}</td></tr>
+            <tr><td>Errors</td><td>{{ .Errors }}{{ if .ErrorsByClass }} ({{ .ErrorsByClass }}){{ end }}</td></tr>
+            <tr><td>Status codes</td><td>{{ range $i, $s := .Statuses }}{{ if $i }}, {{ end }}{{ $s.Status }}: {{ $s.Responses }}{{ end }}</td></tr>
+            <tr><td>Top lengths</td><td>{{ range $i, $l := .TopLengths }}{{ if $i }}, {{ end }}{{ $l.Length }}: {{ $l.Responses }}{{ end }}</td></tr>
+            <tr><td>Stop reason</td><td>{{ .StopReason }}</td></tr>
+        </tbody>
+   </table>
+   <br />
+   {{ end }}
+
+   <h5>Response times</h5>
+   <table id="ffufhistogram" class="striped">
+        <thead>
+          <tr>
+              <th>Duration</th>
+              <th>Results</th>
+              <th></th>
+          </tr>
+        </thead>
+        <tbody>
+            {{ range .Histogram }}
+                <tr>
+                    <td>{{ .Label }}</td>
+                    <td>{{ .Count }} ({{ .Percent }}%)</td>
+                    <td style="width: 60%;"><div style="background-color: #26a69a; height: 1em; width: {{ .Percent }}%;"></div></td>
+                </tr>
+            {{ end }}
+        </tbody>
+   </table>
+   <br />
 
    <table id="ffufreport">
         <thead>
@@ -79,7 +120,9 @@ const (
 			  <th>Lines</th>
 			  <th>Type</th>
         <th>Duration</th>
+        <th>Duration bucket</th>
 			  <th>Resultfile</th>
+			  <th>Scraper</th>
           </tr>
         </thead>
 
@@ -94,14 +137,16 @@ const (
                         <td>{{ $value | printf "%s" }}</td>
                     {{ end }}
                     <td><a href="{{ $result.Url }}">{{ $result.Url }}</a></td>
-                    <td><a href="{{ $result.RedirectLocation }}">{{ $result.RedirectLocation }}</a></td>
+                    <td><a href="{{ $result.RedirectLocation }}">{{ $result.RedirectLocation }}</a>{{ if $result.RedirectScheme }} [{{ $result.RedirectScheme }}]{{ end }}</td>
                     <td>{{ $result.Position }}</td>
                     <td>{{ $result.ContentLength }}</td>
                     <td>{{ $result.ContentWords }}</td>
 					<td>{{ $result.ContentLines }}</td>
 					<td>{{ $result.ContentType }}</td>
           <td>{{ $result.Duration }}</td>
+          <td>{{ durationBucket $result.Duration }}</td>
                     <td>{{ $result.ResultFile }}</td>
+                    <td>{{ range $name, $values := $result.ScraperData }}{{ $name }}: {{ range $i, $v := $values }}{{ if $i }}, {{ end }}{{ $v }}{{ end }}<br>{{ end }}</td>
                 </tr>
             {{ end }}
         </tbody>
@@ -178,21 +223,19 @@ func colorizeResults(results []ffuf.Result) []ffuf.Result {
 	return newResults
 }
 
-func writeHTML(filename string, config *ffuf.Config, results []ffuf.Result) error {
+func writeHTML(filename string, config *ffuf.Config, results []ffuf.Result, summary *ffuf.Summary) error {
 	results = colorizeResults(results)
 
-	ti := time.Now()
-
-	keywords := make([]string, 0)
-	for _, inputprovider := range config.InputProviders {
-		keywords = append(keywords, inputprovider.Keyword)
-	}
+	keywords := resultKeywords(config)
 
 	outHTML := htmlFileOutput{
+		ScanID:      config.ScanID,
 		CommandLine: config.CommandLine,
-		Time:        ti.Format(time.RFC3339),
+		Time:        config.FormatTime(time.Now(), time.RFC3339),
 		Results:     results,
 		Keys:        keywords,
+		Histogram:   latencyHistogram(results),
+		Summary:     newSummaryOutput(summary),
 	}
 
 	f, err := os.Create(filename)
@@ -202,7 +245,7 @@ func writeHTML(filename string, config *ffuf.Config, results []ffuf.Result) erro
 	defer f.Close()
 
 	templateName := "output.html"
-	t := template.New(templateName).Delims("{{", "}}")
+	t := template.New(templateName).Delims("{{", "}}").Funcs(template.FuncMap{"durationBucket": durationBucket})
 	_, err = t.Parse(htmlTemplate)
 	if err != nil {
 		return err
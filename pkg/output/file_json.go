@@ -1,97 +1,251 @@
 package output
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
-	"io/ioutil"
+	"io"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/ffuf/ffuf/pkg/ffuf"
 )
 
-type ejsonFileOutput struct {
-	CommandLine string        `json:"commandline"`
-	Time        string        `json:"time"`
-	Results     []ffuf.Result `json:"results"`
-	Config      *ffuf.Config  `json:"config"`
-}
-
 type JsonResult struct {
-	Input            map[string]string `json:"input"`
-	Position         int               `json:"position"`
-	StatusCode       int64             `json:"status"`
-	ContentLength    int64             `json:"length"`
-	ContentWords     int64             `json:"words"`
-	ContentLines     int64             `json:"lines"`
-	ContentType      string            `json:"content-type"`
-	RedirectLocation string            `json:"redirectlocation"`
-	Duration         time.Duration     `json:"duration"`
-	ResultFile       string            `json:"resultfile"`
-	Url              string            `json:"url"`
-	Host             string            `json:"host"`
+	Input            map[string]string   `json:"input"`
+	Position         int                 `json:"position"`
+	StatusCode       int64               `json:"status"`
+	ContentLength    int64               `json:"length"`
+	ContentWords     int64               `json:"words"`
+	ContentLines     int64               `json:"lines"`
+	ContentType      string              `json:"content-type"`
+	RedirectLocation string              `json:"redirectlocation"`
+	RedirectScheme   string              `json:"redirectscheme,omitempty"`
+	Duration         time.Duration       `json:"duration"`
+	ResultFile       string              `json:"resultfile"`
+	Url              string              `json:"url"`
+	Host             string              `json:"host"`
+	ScraperData      map[string][]string `json:"scraper,omitempty"`
+	Tags             []string            `json:"tags,omitempty"`
+	ScanID           string              `json:"scan_id,omitempty"`
+	Timestamp        string              `json:"timestamp,omitempty"`
 }
 
-type jsonFileOutput struct {
-	Results []string `json:"results"`
+//Result returns the ffuf result of the json result
+func (jr JsonResult) Result() ffuf.Result {
+	input := make(map[string][]byte, len(jr.Input))
+	for k, v := range jr.Input {
+		input[k] = []byte(v)
+	}
+	return ffuf.Result{
+		Input:            input,
+		Position:         jr.Position,
+		StatusCode:       jr.StatusCode,
+		ContentLength:    jr.ContentLength,
+		ContentWords:     jr.ContentWords,
+		ContentLines:     jr.ContentLines,
+		ContentType:      jr.ContentType,
+		RedirectLocation: jr.RedirectLocation,
+		RedirectScheme:   jr.RedirectScheme,
+		Duration:         jr.Duration,
+		ResultFile:       jr.ResultFile,
+		Url:              jr.Url,
+		Host:             jr.Host,
+		ScraperData:      jr.ScraperData,
+		Timestamp:        parseTimestamp(jr.Timestamp),
+	}
 }
 
-func writeEJSON(filename string, config *ffuf.Config, res []ffuf.Result) error {
-	t := time.Now()
-	outJSON := ejsonFileOutput{
-		CommandLine: config.CommandLine,
-		Time:        t.Format(time.RFC3339),
-		Results:     res,
+//newJsonResult returns the result with the inputs as plain strings, for the json and ndjson output files
+func newJsonResult(r ffuf.Result, config *ffuf.Config) JsonResult {
+	timestamp := ""
+	if !r.Timestamp.IsZero() {
+		timestamp = config.FormatTime(r.Timestamp, time.RFC3339Nano)
 	}
+	strinput := make(map[string]string, len(r.Input))
+	for k, v := range r.Input {
+		strinput[k] = string(v)
+	}
+	return JsonResult{
+		Input:            strinput,
+		Position:         r.Position,
+		StatusCode:       r.StatusCode,
+		ContentLength:    r.ContentLength,
+		ContentWords:     r.ContentWords,
+		ContentLines:     r.ContentLines,
+		ContentType:      r.ContentType,
+		RedirectLocation: r.RedirectLocation,
+		RedirectScheme:   r.RedirectScheme,
+		Duration:         r.Duration,
+		ResultFile:       r.ResultFile,
+		Url:              r.Url,
+		Host:             r.Host,
+		ScraperData:      r.ScraperData,
+		Tags:             r.Tags,
+		ScanID:           config.ScanID,
+		Timestamp:        timestamp,
+	}
+}
 
-	outBytes, err := json.Marshal(outJSON)
+//writeEJSON streams the results to the file one at a time, so that large result sets are never marshaled to memory
+//as a whole. The document is the same as json.Marshal of EJSONDocument would produce, apart from line breaks.
+func writeEJSON(filename string, config *ffuf.Config, res []ffuf.Result, errorClasses map[string]int, summary *ffuf.Summary) error {
+	f, err := os.Create(filename)
 	if err != nil {
 		return err
 	}
-	err = ioutil.WriteFile(filename, outBytes, 0644)
+	w := bufio.NewWriter(f)
+	err = encodeEJSON(w, config, res, errorClasses, summary)
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+//encodeEJSON writes the fields of EJSONDocument in order, encoding the results array element by element
+func encodeEJSON(w io.Writer, config *ffuf.Config, res []ffuf.Result, errorClasses map[string]int, summary *ffuf.Summary) error {
+	enc := json.NewEncoder(w)
+	field := func(prefix string, v interface{}) error {
+		if _, err := io.WriteString(w, prefix); err != nil {
+			return err
+		}
+		return enc.Encode(v)
+	}
+	if err := field(`{"schema_version":`, EJSON_SCHEMA_VERSION); err != nil {
+		return err
+	}
+	if err := field(`,"scan_id":`, config.ScanID); err != nil {
+		return err
+	}
+	if err := field(`,"commandline":`, config.CommandLine); err != nil {
+		return err
+	}
+	if err := field(`,"time":`, config.FormatTime(time.Now(), time.RFC3339)); err != nil {
+		return err
+	}
+	if err := field(`,"calibration":`, config.CalibrationLog.Reports()); err != nil {
+		return err
+	}
+	if errorClasses == nil {
+		errorClasses = map[string]int{}
+	}
+	if err := field(`,"errors":`, errorClasses); err != nil {
+		return err
+	}
+	if err := field(`,"summary":`, summary); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, `,"results":[`); err != nil {
+		return err
+	}
+	for i, r := range res {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(newEJSONResult(r, config)); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, `],"config":null}`)
+	return err
+}
+
+//writeJSON writes the results to a json output file in the document format of upstream ffuf, with the inputs as plain
+//strings and only the result fields of -json-fields. The config is left out apart from the output format, which tells
+//the file apart from an ejson one when it is read back.
+func writeJSON(filename string, config *ffuf.Config, res []ffuf.Result, summary *ffuf.Summary) error {
+	f, err := os.Create(filename)
 	if err != nil {
 		return err
 	}
-	return nil
+	w := bufio.NewWriter(f)
+	err = encodeJSON(w, config, res, summary)
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
 }
 
-func writeJSON(filename string, config *ffuf.Config, res []ffuf.Result) error {
-	jsonRes := []string{}
-	for _, r := range res {
-		strinput := make(map[string]string)
-		for k, v := range r.Input {
-			strinput[k] = string(v)
+//encodeJSON writes the fields of the json output file in order, encoding the results array element by element
+func encodeJSON(w io.Writer, config *ffuf.Config, res []ffuf.Result, summary *ffuf.Summary) error {
+	enc := json.NewEncoder(w)
+	field := func(prefix string, v interface{}) error {
+		if _, err := io.WriteString(w, prefix); err != nil {
+			return err
 		}
-		jsonRes = append(jsonRes, r.Url)
-		jsonRes = append(jsonRes, "\n")
+		return enc.Encode(v)
+	}
+	if err := field(`{"commandline":`, config.CommandLine); err != nil {
+		return err
+	}
+	if err := field(`,"time":`, config.FormatTime(time.Now(), time.RFC3339)); err != nil {
+		return err
+	}
+	if err := field(`,"scan_id":`, config.ScanID); err != nil {
+		return err
 	}
-	/*
-		outJSON := jsonFileOutput{
-			Results: jsonRes,
+	if _, err := io.WriteString(w, `,"results":[`); err != nil {
+		return err
+	}
+	for i, r := range res {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
 		}
-		outBytes, err := json.Marshal(outJSON)
+		obj, err := jsonResultFields(newJsonResult(r, config), config.JSONFields)
 		if err != nil {
 			return err
 		}
-	*/
-	/*
-		err = ioutil.WriteFile(filename, outBytes, 0644)
-		if err != nil {
+		if _, err := w.Write(obj); err != nil {
 			return err
 		}
-	*/
-	outBytes := strings.Join(jsonRes, "")
-
-	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
-	if err != nil {
-		return err
 	}
-
-	defer f.Close()
-
-	if _, err = f.Write([]byte(outBytes)); err != nil {
+	if err := field(`],"summary":`, summary); err != nil {
 		return err
 	}
+	_, err := io.WriteString(w, `,"config":{"outputformat":"json"}}`)
+	return err
+}
 
-	return nil
+//jsonResultFields encodes the fields of the result as a JSON object, in the order of ffuf.JSONFields. The fields
+//left out as empty are not written even when selected.
+func jsonResultFields(jr JsonResult, fields []string) ([]byte, error) {
+	data, err := json.Marshal(jr)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	selected := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		selected[f] = true
+	}
+	var b bytes.Buffer
+	b.WriteString("{")
+	for _, f := range ffuf.JSONFields {
+		v, ok := values[f]
+		if !ok || !selected[f] {
+			continue
+		}
+		if b.Len() > 1 {
+			b.WriteString(",")
+		}
+		name, _ := json.Marshal(f)
+		b.Write(name)
+		b.WriteString(":")
+		b.Write(v)
+	}
+	b.WriteString("}")
+	return b.Bytes(), nil
 }
@@ -13,26 +13,32 @@ const (
 
   Command line : ` + "`{{.CommandLine}}`" + `
   Time: ` + "{{ .Time }}" + `
-
-  {{ range .Keys }}| {{ . }} {{ end }}| URL | Redirectlocation | Position | Status Code | Content Length | Content Words | Content Lines | Content Type | Duration | ResultFile |
-  {{ range .Keys }}| :- {{ end }}| :-- | :--------------- | :---- | :------- | :---------- | :------------- | :------------ | :--------- | :----------- |
-  {{range .Results}}{{ range $keyword, $value := .Input }}| {{ $value | printf "%s" }} {{ end }}| {{ .Url }} | {{ .RedirectLocation }} | {{ .Position }} | {{ .StatusCode }} | {{ .ContentLength }} | {{ .ContentWords }} | {{ .ContentLines }} | {{ .ContentType }} | {{ .Duration}} | {{ .ResultFile }} |
+  Scan ID: ` + "{{ .ScanID }}" + `
+{{ with .Summary }}
+  ## Summary
+
+  | Requests | Duration | Req/sec | Jobs | Matches | Errors | Status codes | Top lengths | Stop reason |
+  | :------- | :------- | :------ | :--- | :------ | :----- | :----------- | :---------- | :---------- |
+  | {{ .Requests }} | {{ printf "%.1f" .Duration }}s | {{ printf "%.1f" .RequestsPerSecond }} | {{ .Jobs }} | {{ .Matches }} | {{ .Errors }}{{ if .ErrorsByClass }} ({{ .ErrorsByClass }}){{ end }} | {{ range $i, $s := .Statuses }}{{ if $i }}, {{ end }}{{ $s.Status }}: {{ $s.Responses }}{{ end }} | {{ range $i, $l := .TopLengths }}{{ if $i }}, {{ end }}{{ $l.Length }}: {{ $l.Responses }}{{ end }} | {{ .StopReason }} |
+
+  ## Results
+{{ end }}
+  {{ range .Keys }}| {{ . }} {{ end }}| URL | Redirectlocation | Position | Status Code | Content Length | Content Words | Content Lines | Content Type | Duration | ResultFile | Scraper |
+  {{ range .Keys }}| :- {{ end }}| :-- | :--------------- | :---- | :------- | :---------- | :------------- | :------------ | :--------- | :----------- | :------ |
+  {{range .Results}}{{ range $keyword, $value := .Input }}| {{ $value | printf "%s" }} {{ end }}| {{ .Url }} | {{ .RedirectLocation }}{{ if .RedirectScheme }} [{{ .RedirectScheme }}]{{ end }} | {{ .Position }} | {{ .StatusCode }} | {{ .ContentLength }} | {{ .ContentWords }} | {{ .ContentLines }} | {{ .ContentType }} | {{ .Duration}} | {{ .ResultFile }} | {{ range $name, $values := .ScraperData }}{{ $name }}: {{ range $i, $v := $values }}{{ if $i }}, {{ end }}{{ $v }}{{ end }}; {{ end }}|
   {{end}}` // The template format is not pretty but follows the markdown guide
 )
 
-func writeMarkdown(filename string, config *ffuf.Config, res []ffuf.Result) error {
-	ti := time.Now()
-
-	keywords := make([]string, 0)
-	for _, inputprovider := range config.InputProviders {
-		keywords = append(keywords, inputprovider.Keyword)
-	}
+func writeMarkdown(filename string, config *ffuf.Config, res []ffuf.Result, summary *ffuf.Summary) error {
+	keywords := resultKeywords(config)
 
 	outMD := htmlFileOutput{
+		ScanID:      config.ScanID,
 		CommandLine: config.CommandLine,
-		Time:        ti.Format(time.RFC3339),
+		Time:        config.FormatTime(time.Now(), time.RFC3339),
 		Results:     res,
 		Keys:        keywords,
+		Summary:     newSummaryOutput(summary),
 	}
 
 	f, err := os.Create(filename)
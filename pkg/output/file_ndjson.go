@@ -0,0 +1,64 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//ndjsonWriter appends every result to a line delimited JSON file as soon as it is matched. It is not safe for
+//concurrent use, Stdoutput creates it and serializes the writes under its streamMutex.
+type ndjsonWriter struct {
+	filename string
+	file     *os.File
+	fsync    bool
+	config   *ffuf.Config
+}
+
+func newNDJSONWriter(filename string, fsync bool, config *ffuf.Config) (*ndjsonWriter, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &ndjsonWriter{filename: filename, file: f, fsync: fsync, config: config}, nil
+}
+
+//Write appends a single result to the file, syncing it to the disk if requested
+func (w *ndjsonWriter) Write(r ffuf.Result) error {
+	line, err := ndjsonLine(w.config, r)
+	if err != nil {
+		return err
+	}
+	if _, err := w.file.Write(line); err != nil {
+		return err
+	}
+	if w.fsync {
+		return w.file.Sync()
+	}
+	return nil
+}
+
+//writeNDJSON writes all of the results to a line delimited JSON file
+func writeNDJSON(filename string, config *ffuf.Config, res []ffuf.Result) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	for _, r := range res {
+		line, err := ndjsonLine(config, r)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ndjsonLine(config *ffuf.Config, r ffuf.Result) ([]byte, error) {
+	line, err := json.Marshal(newJsonResult(r, config))
+	return append(line, '\n'), err
+}
@@ -0,0 +1,49 @@
+package output
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+func TestNDJSONConcurrentResults(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conf := ffuf.NewConfig(ctx, cancel)
+	conf.OutputFile = filepath.Join(t.TempDir(), "concurrent.ndjson")
+	conf.OutputFormat = "ndjson"
+	conf.Quiet = true
+	s := NewStdoutput(&conf)
+	urls := sendTestResults(t, s, 200)
+	if err := s.Finalize(); err != nil {
+		t.Fatalf("Could not finalize the output: %s", err)
+	}
+	f, err := os.Open(conf.OutputFile)
+	if err != nil {
+		t.Fatalf("Could not open the output file: %s", err)
+	}
+	defer f.Close()
+	lines := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines++
+		var res struct {
+			Url string `json:"url"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &res); err != nil {
+			t.Fatalf("Line %d is not a JSON result: %s", lines, err)
+		}
+		if !urls[res.Url] {
+			t.Errorf("Unexpected or duplicate result on line %d: %s", lines, res.Url)
+		}
+		delete(urls, res.Url)
+	}
+	if len(urls) != 0 {
+		t.Errorf("%d results are missing from the output file", len(urls))
+	}
+}
@@ -0,0 +1,128 @@
+package output
+
+import (
+	"time"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+var sqliteResultIndexes = []string{"url", "status", "length", "words", "duration", "timestamp"}
+
+//The tables of the SQLite output, in the order of sqliteResultTables
+const (
+	sqliteScanTable = iota
+	sqliteResultsTable
+	sqliteInputsTable
+	sqliteScraperTable
+)
+
+//sqliteResultWriter writes the results to a SQLite database as soon as they are matched. The inputs table holds the
+//keyword values and the scraper table the scraped data of each result, both referencing the results table by
+//result_id. The scan table describes the run the results belong to. The indexes are created when the writer is
+//closed. It is not safe for concurrent use, Stdoutput serializes the writes under its streamMutex.
+type sqliteResultWriter struct {
+	filename string
+	db       *sqliteWriter
+	config   *ffuf.Config
+	keywords []string
+}
+
+func newSQLiteResultWriter(filename string, fsync bool, config *ffuf.Config) (*sqliteResultWriter, error) {
+	db, err := newSQLiteWriter(filename, sqliteResultTables(), fsync)
+	if err != nil {
+		return nil, err
+	}
+	db.Insert(sqliteScanTable, []interface{}{config.ScanID, config.CommandLine})
+	if err := db.Flush(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqliteResultWriter{filename: filename, db: db, config: config, keywords: resultKeywords(config)}, nil
+}
+
+//Write appends a single result to the database, syncing it to the disk if requested
+func (w *sqliteResultWriter) Write(r ffuf.Result) error {
+	w.insert(r)
+	return w.db.Flush()
+}
+
+//Close creates the indexes and closes the database
+func (w *sqliteResultWriter) Close() error {
+	return w.db.Close()
+}
+
+func (w *sqliteResultWriter) insert(r ffuf.Result) {
+	// The INTEGER PRIMARY KEY column is an alias of the rowid, and stored as NULL
+	id := w.db.Insert(sqliteResultsTable, []interface{}{
+		nil,
+		r.Url,
+		r.RedirectLocation,
+		int64(r.Position),
+		r.StatusCode,
+		r.ContentLength,
+		r.ContentWords,
+		r.ContentLines,
+		r.ContentType,
+		// Duration in nanoseconds
+		int64(r.Duration),
+		r.ResultFile,
+		r.Host,
+		w.config.FormatTime(r.Timestamp.UTC(), time.RFC3339Nano),
+		r.RedirectScheme,
+	})
+	for _, k := range w.keywords {
+		if v, ok := r.Input[k]; ok {
+			w.db.Insert(sqliteInputsTable, []interface{}{id, k, string(v)})
+		}
+	}
+	for _, name := range sortedKeys(r.ScraperData) {
+		for _, v := range r.ScraperData[name] {
+			w.db.Insert(sqliteScraperTable, []interface{}{id, name, v})
+		}
+	}
+}
+
+//writeSQLite writes all of the results to a SQLite database
+func writeSQLite(filename string, config *ffuf.Config, res []ffuf.Result) error {
+	w, err := newSQLiteResultWriter(filename, false, config)
+	if err != nil {
+		return err
+	}
+	for _, r := range res {
+		w.insert(r)
+	}
+	return w.Close()
+}
+
+func sqliteResultTables() []sqliteTable {
+	scan := sqliteTable{
+		name: "scan",
+		sql:  "CREATE TABLE scan (scan_id TEXT, commandline TEXT)",
+	}
+	results := sqliteTable{
+		name: "results",
+		sql:  "CREATE TABLE results (id INTEGER PRIMARY KEY, url TEXT, redirectlocation TEXT, position INTEGER, status INTEGER, length INTEGER, words INTEGER, lines INTEGER, content_type TEXT, duration INTEGER, resultfile TEXT, host TEXT, timestamp TEXT, redirectscheme TEXT)",
+	}
+	columns := map[string]int{"url": 1, "status": 4, "length": 5, "words": 6, "duration": 9, "timestamp": 12}
+	for _, c := range sqliteResultIndexes {
+		results.indexes = append(results.indexes, sqliteIndex{
+			name:    "results_" + c,
+			sql:     "CREATE INDEX results_" + c + " ON results (" + c + ")",
+			columns: []int{columns[c]},
+		})
+	}
+	inputs := sqliteTable{
+		name: "inputs",
+		sql:  "CREATE TABLE inputs (result_id INTEGER, keyword TEXT, value TEXT)",
+		indexes: []sqliteIndex{{
+			name:    "inputs_keyword_value",
+			sql:     "CREATE INDEX inputs_keyword_value ON inputs (keyword, value)",
+			columns: []int{1, 2},
+		}},
+	}
+	scraped := sqliteTable{
+		name: "scraper",
+		sql:  "CREATE TABLE scraper (result_id INTEGER, name TEXT, value TEXT)",
+	}
+	return []sqliteTable{scan, results, inputs, scraped}
+}
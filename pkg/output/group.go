@@ -0,0 +1,72 @@
+package output
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//DirectoryGroup is the number of matched results under a directory
+type DirectoryGroup struct {
+	Directory string
+	Results   int
+}
+
+//resultDirectory returns the parent directory of the result URL, with its scheme and host
+func resultDirectory(resultUrl string) string {
+	u, err := url.Parse(resultUrl)
+	if err != nil {
+		return resultUrl
+	}
+	dir := u.Path
+	if i := strings.LastIndex(dir, "/"); i >= 0 {
+		dir = dir[:i+1]
+	} else {
+		dir = "/"
+	}
+	return u.Scheme + "://" + u.Host + dir
+}
+
+//GroupByDirectory counts the results by their parent directory, in the order of the directories
+func GroupByDirectory(results []ffuf.Result) []DirectoryGroup {
+	counts := make(map[string]int)
+	for _, r := range results {
+		counts[resultDirectory(r.Url)]++
+	}
+	groups := make([]DirectoryGroup, 0, len(counts))
+	for dir, n := range counts {
+		groups = append(groups, DirectoryGroup{Directory: dir, Results: n})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Directory < groups[j].Directory })
+	return groups
+}
+
+//DirectoryReport returns the number of results per directory as a printable table
+func DirectoryReport(results []ffuf.Result) string {
+	var b strings.Builder
+	b.WriteString("Results by directory:\n")
+	for _, g := range GroupByDirectory(results) {
+		fmt.Fprintf(&b, " :: %6d  %s\n", g.Results, g.Directory)
+	}
+	return b.String()
+}
+
+//printGroupHeader prints the directory of the result above it when it differs from the one of the previous result
+//(-group-dirs), along with the number of results in the directory so far
+func (s *Stdoutput) printGroupHeader(res ffuf.Result) {
+	s.groupMutex.Lock()
+	defer s.groupMutex.Unlock()
+	if s.groupCounts == nil {
+		s.groupCounts = make(map[string]int)
+	}
+	dir := resultDirectory(res.Url)
+	s.groupCounts[dir]++
+	if dir == s.groupDir {
+		return
+	}
+	s.groupDir = dir
+	fmt.Printf("%s[DIR] %s (%d so far)\n", TERMINAL_CLEAR_LINE, dir, s.groupCounts[dir])
+}
@@ -0,0 +1,57 @@
+package output
+
+import (
+	"time"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//latencyBucket is a response time range of the result duration histogram
+type latencyBucket struct {
+	Label   string
+	Max     time.Duration
+	Count   int
+	Percent int
+}
+
+//latencyBuckets are the upper bounds and labels of the response time ranges, the last one is unbounded
+var latencyBuckets = []latencyBucket{
+	{Label: "<50ms", Max: 50 * time.Millisecond},
+	{Label: "50-100ms", Max: 100 * time.Millisecond},
+	{Label: "100-250ms", Max: 250 * time.Millisecond},
+	{Label: "250-500ms", Max: 500 * time.Millisecond},
+	{Label: "500ms-1s", Max: time.Second},
+	{Label: "1-2.5s", Max: 2500 * time.Millisecond},
+	{Label: "2.5-5s", Max: 5 * time.Second},
+	{Label: ">=5s"},
+}
+
+//latencyBucketIndex returns the index of the response time range of a duration
+func latencyBucketIndex(d time.Duration) int {
+	for i, b := range latencyBuckets[:len(latencyBuckets)-1] {
+		if d < b.Max {
+			return i
+		}
+	}
+	return len(latencyBuckets) - 1
+}
+
+//durationBucket returns the label of the response time range of a duration
+func durationBucket(d time.Duration) string {
+	return latencyBuckets[latencyBucketIndex(d)].Label
+}
+
+//latencyHistogram counts the results in each response time range
+func latencyHistogram(results []ffuf.Result) []latencyBucket {
+	histogram := make([]latencyBucket, len(latencyBuckets))
+	copy(histogram, latencyBuckets)
+	for _, r := range results {
+		histogram[latencyBucketIndex(r.Duration)].Count++
+	}
+	if len(results) > 0 {
+		for i := range histogram {
+			histogram[i].Percent = histogram[i].Count * 100 / len(results)
+		}
+	}
+	return histogram
+}
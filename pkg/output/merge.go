@@ -0,0 +1,169 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//ReadResultFile reads the results of an ejson, json or ndjson output file, such as the output of a single shard
+//(-shard), or of a json or ejson output file of upstream ffuf
+func ReadResultFile(filename string) ([]ffuf.Result, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var ejson map[string]json.RawMessage
+	if err := json.Unmarshal(data, &ejson); err == nil {
+		if raw, ok := ejson["results"]; ok {
+			version := 0
+			if rawVersion, ok := ejson["schema_version"]; ok {
+				if err := json.Unmarshal(rawVersion, &version); err != nil {
+					return nil, fmt.Errorf("%s: invalid schema_version: %s", filename, err)
+				}
+			}
+			if version > EJSON_SCHEMA_VERSION {
+				return nil, fmt.Errorf("%s: ejson schema version %d is newer than the supported version %d", filename, version, EJSON_SCHEMA_VERSION)
+			}
+			if version == 0 && upstreamJSONOutput(ejson["config"]) {
+				jresults := make([]JsonResult, 0)
+				if err := json.Unmarshal(raw, &jresults); err != nil {
+					return nil, fmt.Errorf("%s: %s", filename, err)
+				}
+				results := make([]ffuf.Result, 0, len(jresults))
+				for _, jr := range jresults {
+					r := jr.Result()
+					delete(r.Input, "FFUFHASH")
+					results = append(results, r)
+				}
+				return results, nil
+			}
+			eresults := make([]EJSONResult, 0)
+			if err := json.Unmarshal(raw, &eresults); err != nil {
+				return nil, fmt.Errorf("%s: %s", filename, err)
+			}
+			results := make([]ffuf.Result, 0, len(eresults))
+			for _, er := range eresults {
+				r := er.Result()
+				if version == 0 {
+					// The hash of upstream ffuf differs between the scans
+					delete(r.Input, "FFUFHASH")
+				}
+				results = append(results, r)
+			}
+			return results, nil
+		}
+	}
+	results := make([]ffuf.Result, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var jr JsonResult
+		if err := json.Unmarshal(scanner.Bytes(), &jr); err != nil {
+			return nil, fmt.Errorf("%s: line %d is not an ejson or ndjson result: %s", filename, line, err)
+		}
+		results = append(results, jr.Result())
+	}
+	return results, scanner.Err()
+}
+
+//upstreamJSONOutput checks if the config of an output file is the one of an upstream ffuf json output, which has the
+//inputs as plain strings instead of base64
+func upstreamJSONOutput(raw json.RawMessage) bool {
+	var config struct {
+		OutputFormat string `json:"outputformat"`
+	}
+	if len(raw) == 0 || json.Unmarshal(raw, &config) != nil {
+		return false
+	}
+	return config.OutputFormat == "json"
+}
+
+//MergeResults combines the results of several shards to the order of a single run, by their input position, leaving
+//out the duplicates of shards merged more than once
+func MergeResults(sets ...[]ffuf.Result) []ffuf.Result {
+	seen := make(map[string]bool)
+	merged := make([]ffuf.Result, 0)
+	for _, results := range sets {
+		for _, r := range results {
+			key := resultKey(r)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, r)
+		}
+	}
+	sort.SliceStable(merged, func(a, b int) bool {
+		return merged[a].Position < merged[b].Position
+	})
+	return merged
+}
+
+//AppendResults adds the results to the earlier ones of the output file (-append-output). The earlier results of the
+//same URL and inputs are replaced by the new ones, which are kept in their order after the rest.
+func AppendResults(earlier, results []ffuf.Result) []ffuf.Result {
+	replaced := make(map[string]bool, len(results))
+	for _, r := range results {
+		replaced[resultKey(r)] = true
+	}
+	appended := make([]ffuf.Result, 0, len(earlier)+len(results))
+	for _, r := range earlier {
+		if !replaced[resultKey(r)] {
+			appended = append(appended, r)
+		}
+	}
+	return append(appended, results...)
+}
+
+//MergeKeywords returns the input keywords of the results, for the configuration of the merged output
+func MergeKeywords(results []ffuf.Result) []ffuf.InputProviderConfig {
+	keywords := make(map[string]bool)
+	for _, r := range results {
+		for k := range r.Input {
+			keywords[k] = true
+		}
+	}
+	providers := make([]ffuf.InputProviderConfig, 0, len(keywords))
+	for k := range keywords {
+		providers = append(providers, ffuf.InputProviderConfig{Name: "wordlist", Keyword: k})
+	}
+	sort.Slice(providers, func(a, b int) bool { return providers[a].Keyword < providers[b].Keyword })
+	return providers
+}
+
+//parseTimestamp parses a result timestamp written in the default format, the ones of other -time-format values are
+//left out
+func parseTimestamp(value string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, value)
+	return t
+}
+
+//resultKey identifies a result by its URL and inputs
+func resultKey(r ffuf.Result) string {
+	parts := []string{r.Url}
+	keywords := make([]string, 0, len(r.Input))
+	for k := range r.Input {
+		keywords = append(keywords, k)
+	}
+	sort.Strings(keywords)
+	for _, k := range keywords {
+		if k == "FFUFHASH" {
+			continue
+		}
+		parts = append(parts, k+"="+string(r.Input[k]))
+	}
+	return strings.Join(parts, "\x00")
+}
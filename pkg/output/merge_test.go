@@ -0,0 +1,70 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+func testResult(url string, fuzz string, status int64) ffuf.Result {
+	return ffuf.Result{Url: url, Input: map[string][]byte{"FUZZ": []byte(fuzz), "FFUFHASH": []byte(fuzz + "hash")}, StatusCode: status}
+}
+
+func TestAppendResults(t *testing.T) {
+	earlier := []ffuf.Result{
+		testResult("http://example.com/a", "a", 200),
+		testResult("http://example.com/b", "b", 200),
+		testResult("http://example.com/c", "c", 200),
+	}
+	tests := []struct {
+		name     string
+		results  []ffuf.Result
+		expected []string
+	}{
+		{
+			name:     "new results after the earlier ones",
+			results:  []ffuf.Result{testResult("http://example.com/d", "d", 200)},
+			expected: []string{"a 200", "b 200", "c 200", "d 200"},
+		},
+		{
+			name: "replaced results moved after the rest in their new order",
+			results: []ffuf.Result{
+				testResult("http://example.com/c", "c", 403),
+				testResult("http://example.com/a", "a", 301),
+			},
+			expected: []string{"b 200", "c 403", "a 301"},
+		},
+		{
+			name:     "the hash does not tell the results apart",
+			results:  []ffuf.Result{{Url: "http://example.com/b", Input: map[string][]byte{"FUZZ": []byte("b"), "FFUFHASH": []byte("other")}, StatusCode: 500}},
+			expected: []string{"a 200", "c 200", "b 500"},
+		},
+		{
+			name:     "same URL with a different input kept",
+			results:  []ffuf.Result{testResult("http://example.com/a", "x", 404)},
+			expected: []string{"a 200", "b 200", "c 200", "x 404"},
+		},
+		{
+			name:     "no new results",
+			results:  []ffuf.Result{},
+			expected: []string{"a 200", "b 200", "c 200"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appended := AppendResults(earlier, tt.results)
+			got := make([]string, 0, len(appended))
+			for _, r := range appended {
+				got = append(got, fmt.Sprintf("%s %d", r.Input["FUZZ"], r.StatusCode))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected results %v, got %v", tt.expected, got)
+			}
+		})
+	}
+	if len(earlier) != 3 || earlier[0].StatusCode != 200 {
+		t.Errorf("Expected the earlier results to be left unchanged")
+	}
+}
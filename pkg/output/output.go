@@ -1,6 +1,9 @@
 package output
 
 import (
+	"sort"
+	"strings"
+
 	"github.com/ffuf/ffuf/pkg/ffuf"
 )
 
@@ -8,3 +11,29 @@ func NewOutputProviderByName(name string, conf *ffuf.Config) ffuf.OutputProvider
 	//We have only one outputprovider at the moment
 	return NewStdoutput(conf)
 }
+
+//resultKeywords returns the input keywords in sorted order, matching the iteration order of result inputs in templates
+func resultKeywords(config *ffuf.Config) []string {
+	keywords := config.InputKeywords()
+	sort.Strings(keywords)
+	return keywords
+}
+
+//scraperText returns the scraped data of a result on a single line, ordered by rule name
+func scraperText(data map[string][]string) string {
+	parts := make([]string, 0, len(data))
+	for _, name := range sortedKeys(data) {
+		parts = append(parts, name+": "+strings.Join(data[name], ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+//sortedKeys returns the rule names of the scraped data in sorted order
+func sortedKeys(data map[string][]string) []string {
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,70 @@
+package output
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//PROGRESS_JSON_INTERVAL is the minimum interval of the lines of the progress stream (-progress-json). The progress
+//of a finished job is always written.
+const PROGRESS_JSON_INTERVAL = time.Second
+
+//progressLine is a line of the progress stream. The total is -1 for streamed input of unknown length, and the ETA of
+//the job in seconds is null until it can be estimated.
+type progressLine struct {
+	Time         string         `json:"time"`
+	ScanID       string         `json:"scan_id"`
+	Requests     int            `json:"requests"`
+	Total        int            `json:"total"`
+	Rate         int64          `json:"rate"`
+	Job          int            `json:"job"`
+	Jobs         int            `json:"jobs"`
+	Errors       int            `json:"errors"`
+	ErrorClasses map[string]int `json:"errors_by_class"`
+	Elapsed      float64        `json:"elapsed"`
+	ETA          *float64       `json:"eta"`
+}
+
+//writeProgressJSON writes the progress as a line of the progress stream, at most once per PROGRESS_JSON_INTERVAL
+func (s *Stdoutput) writeProgressJSON(status ffuf.Progress) {
+	s.progressMutex.Lock()
+	defer s.progressMutex.Unlock()
+	finished := status.ReqTotal >= 0 && status.ReqCount >= status.ReqTotal
+	if finished {
+		if s.progressDone == status.QueuePos {
+			return
+		}
+		s.progressDone = status.QueuePos
+	} else if time.Since(s.progressTime) < PROGRESS_JSON_INTERVAL {
+		return
+	}
+	s.progressTime = time.Now()
+
+	line := progressLine{
+		Time:         s.config.FormatTime(s.progressTime, time.RFC3339Nano),
+		ScanID:       s.config.ScanID,
+		Requests:     status.ReqCount,
+		Total:        status.ReqTotal,
+		Rate:         status.ReqSec,
+		Job:          status.QueuePos,
+		Jobs:         status.QueueTotal,
+		Errors:       status.ErrorCount,
+		ErrorClasses: status.ErrorClasses,
+		Elapsed:      time.Since(status.StartedAt).Seconds(),
+	}
+	if finished {
+		eta := 0.0
+		line.ETA = &eta
+	} else if status.ReqTotal >= 0 && status.ReqSec > 0 {
+		eta := float64(status.ReqTotal-status.ReqCount) / float64(status.ReqSec)
+		line.ETA = &eta
+	}
+	data, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+	// A closed stream does not stop the run
+	_, _ = s.config.ProgressJSONWriter.Write(append(data, '\n'))
+}
@@ -0,0 +1,164 @@
+package output
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//EJSON_SCHEMA_VERSION is the version of the ejson output file schema. It is increased whenever a field is removed or
+//renamed, or its type changes. New fields may be added without changing the version.
+const EJSON_SCHEMA_VERSION = 1
+
+//EJSONDocument is the schema of the ejson output file. The summary is null while the run has not finished.
+type EJSONDocument struct {
+	SchemaVersion int                      `json:"schema_version"`
+	ScanID        string                   `json:"scan_id"`
+	CommandLine   string                   `json:"commandline"`
+	Time          string                   `json:"time"`
+	Calibration   []ffuf.CalibrationReport `json:"calibration"`
+	Errors        map[string]int           `json:"errors"`
+	Summary       *ffuf.Summary            `json:"summary"`
+	Results       []EJSONResult            `json:"results"`
+	// Always null, the configuration is not written to the file
+	Config interface{} `json:"config"`
+}
+
+//EJSONResult is a result of the ejson output file. The input values are base64 encoded, the duration is in
+//nanoseconds and the timestamp is in the -time-format and -timezone.
+type EJSONResult struct {
+	Input            map[string][]byte   `json:"input"`
+	Position         int                 `json:"position"`
+	StatusCode       int64               `json:"status"`
+	ContentLength    int64               `json:"length"`
+	ContentWords     int64               `json:"words"`
+	ContentLines     int64               `json:"lines"`
+	ContentType      string              `json:"content-type"`
+	RedirectLocation string              `json:"redirectlocation"`
+	RedirectScheme   string              `json:"redirectscheme"`
+	Url              string              `json:"url"`
+	Duration         time.Duration       `json:"duration"`
+	ResultFile       string              `json:"resultfile"`
+	Host             string              `json:"host"`
+	Timestamp        string              `json:"timestamp"`
+	ScraperData      map[string][]string `json:"scraper"`
+	Tags             []string            `json:"tags,omitempty"`
+}
+
+func newEJSONResult(r ffuf.Result, config *ffuf.Config) EJSONResult {
+	return EJSONResult{
+		Input:            r.Input,
+		Position:         r.Position,
+		StatusCode:       r.StatusCode,
+		ContentLength:    r.ContentLength,
+		ContentWords:     r.ContentWords,
+		ContentLines:     r.ContentLines,
+		ContentType:      r.ContentType,
+		RedirectLocation: r.RedirectLocation,
+		RedirectScheme:   r.RedirectScheme,
+		Url:              r.Url,
+		Duration:         r.Duration,
+		ResultFile:       r.ResultFile,
+		Host:             r.Host,
+		Timestamp:        config.FormatTime(r.Timestamp, time.RFC3339Nano),
+		ScraperData:      r.ScraperData,
+		Tags:             r.Tags,
+	}
+}
+
+//Result returns the ffuf result of the ejson result
+func (r EJSONResult) Result() ffuf.Result {
+	return ffuf.Result{
+		Input:            r.Input,
+		Position:         r.Position,
+		StatusCode:       r.StatusCode,
+		ContentLength:    r.ContentLength,
+		ContentWords:     r.ContentWords,
+		ContentLines:     r.ContentLines,
+		ContentType:      r.ContentType,
+		RedirectLocation: r.RedirectLocation,
+		RedirectScheme:   r.RedirectScheme,
+		Url:              r.Url,
+		Duration:         r.Duration,
+		ResultFile:       r.ResultFile,
+		Host:             r.Host,
+		Timestamp:        parseTimestamp(r.Timestamp),
+		ScraperData:      r.ScraperData,
+		Tags:             r.Tags,
+	}
+}
+
+//EJSONSchema returns the JSON Schema of the ejson output file, generated from EJSONDocument
+func EJSONSchema() ([]byte, error) {
+	schema := jsonSchema(reflect.TypeOf(EJSONDocument{}))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "ffuf ejson output"
+	schema["properties"].(map[string]interface{})["schema_version"] = map[string]interface{}{"const": EJSON_SCHEMA_VERSION}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+//jsonSchema returns the JSON Schema of the JSON encoding of the type. The maps, slices and pointers may be null.
+func jsonSchema(t reflect.Type) map[string]interface{} {
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case durationType:
+		return map[string]interface{}{"type": "integer", "description": "nanoseconds"}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(jsonSchema(t.Elem()))
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		required := make([]string, 0)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if f.PkgPath != "" || tag == "-" {
+				continue
+			}
+			name := strings.Split(tag, ",")[0]
+			if name == "" {
+				name = f.Name
+			}
+			properties[name] = jsonSchema(f.Type)
+			if !strings.Contains(tag, ",omitempty") {
+				required = append(required, name)
+			}
+		}
+		return map[string]interface{}{"type": "object", "properties": properties, "required": required}
+	case reflect.Map:
+		return nullable(map[string]interface{}{"type": "object", "additionalProperties": jsonSchema(t.Elem())})
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return nullable(map[string]interface{}{"type": "array", "items": jsonSchema(t.Elem())})
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+	// Any JSON value
+	return map[string]interface{}{}
+}
+
+//nullable allows null in place of the value of the schema
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	if t, ok := schema["type"].(string); ok {
+		schema["type"] = []string{t, "null"}
+	}
+	return schema
+}
@@ -0,0 +1,574 @@
+package output
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// A minimal writer for the SQLite 3 database file format. The rows are appended to the table b-trees of the file as
+// they are inserted and the indexes are built when the file is closed, which lets ffuf stream SQLite output without
+// cgo or third party dependencies.
+// See https://www.sqlite.org/fileformat2.html
+
+const (
+	SQLITE_PAGE_SIZE        = 4096
+	SQLITE_VERSION          = 3045000
+	sqliteTableLeaf         = 0x0D
+	sqliteTableInterior     = 0x05
+	sqliteIndexLeaf         = 0x0A
+	sqliteIndexInterior     = 0x02
+	sqliteTableMaxLocal     = SQLITE_PAGE_SIZE - 35
+	sqliteIndexMaxLocal     = (SQLITE_PAGE_SIZE-12)*64/255 - 23
+	sqliteMinLocal          = (SQLITE_PAGE_SIZE-12)*32/255 - 23
+	sqliteOverflowPageSpace = SQLITE_PAGE_SIZE - 4
+	// Interior table cells are at most 15 bytes including the cell pointer
+	sqliteInteriorChildren = (SQLITE_PAGE_SIZE - 12) / 15
+)
+
+//sqliteTable is a table of the database
+type sqliteTable struct {
+	name    string
+	sql     string
+	indexes []sqliteIndex
+}
+
+//sqliteIndex is an index of a table, the columns are indexes of the table row values
+type sqliteIndex struct {
+	name    string
+	sql     string
+	columns []int
+}
+
+type sqliteCell struct {
+	data []byte
+	key  int64
+}
+
+type sqliteChild struct {
+	page uint32
+	key  int64
+}
+
+//sqliteBuilder allocates the pages of the database, and keeps the pages written since the last flush in memory
+type sqliteBuilder struct {
+	count uint32
+	dirty map[uint32][]byte
+}
+
+//sqliteWriter writes a SQLite database file, appending the inserted rows to the file on every flush. The indexes are
+//built when the writer is closed, until then the database holds the tables only.
+type sqliteWriter struct {
+	file    *os.File
+	fsync   bool
+	builder sqliteBuilder
+	tables  []*sqliteTableState
+	// Root pages of the indexes in the order of the tables, once built
+	indexRoots []uint32
+	changes    uint32
+}
+
+//sqliteTableState is a table being written, with the keys of its indexes so far
+type sqliteTableState struct {
+	sqliteTable
+	tree  *sqliteTableTree
+	rowid int64
+	keys  [][][]interface{}
+}
+
+//newSQLiteWriter creates the database file with the tables
+func newSQLiteWriter(filename string, tables []sqliteTable, fsync bool) (*sqliteWriter, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	w := &sqliteWriter{file: f, fsync: fsync, builder: sqliteBuilder{dirty: make(map[uint32][]byte)}}
+	// The first page is reserved for the schema table
+	w.builder.newPage()
+	for _, t := range tables {
+		w.tables = append(w.tables, &sqliteTableState{
+			sqliteTable: t,
+			tree:        newSQLiteTableTree(&w.builder),
+			keys:        make([][][]interface{}, len(t.indexes)),
+		})
+	}
+	return w, nil
+}
+
+//Insert adds a row to the table, values are nil, int64 or string. The row is written to the file on the next flush.
+func (w *sqliteWriter) Insert(table int, row []interface{}) int64 {
+	t := w.tables[table]
+	t.rowid++
+	t.tree.append(&w.builder, w.builder.tableLeafCell(t.rowid, sqliteRecord(row)))
+	for i, idx := range t.indexes {
+		key := make([]interface{}, 0, len(idx.columns)+1)
+		for _, c := range idx.columns {
+			key = append(key, row[c])
+		}
+		t.keys[i] = append(t.keys[i], append(key, t.rowid))
+	}
+	return t.rowid
+}
+
+//Flush writes the pages changed since the last flush to the file, the first page with the header and the schema
+//referencing them last
+func (w *sqliteWriter) Flush() error {
+	if w.file == nil {
+		return fmt.Errorf("sqlite database is closed")
+	}
+	for _, t := range w.tables {
+		if t.tree.changed {
+			t.tree.write(&w.builder)
+		}
+	}
+	if err := w.writeSchema(); err != nil {
+		return err
+	}
+	pages := make([]uint32, 0, len(w.builder.dirty))
+	for n := range w.builder.dirty {
+		pages = append(pages, n)
+	}
+	sort.Slice(pages, func(i, j int) bool { return pages[i] > 1 && (pages[j] == 1 || pages[i] < pages[j]) })
+	for _, n := range pages {
+		if _, err := w.file.WriteAt(w.builder.dirty[n], int64(n-1)*SQLITE_PAGE_SIZE); err != nil {
+			return err
+		}
+	}
+	w.builder.dirty = make(map[uint32][]byte)
+	if w.fsync {
+		return w.file.Sync()
+	}
+	return nil
+}
+
+//Close builds the indexes of the tables and closes the file
+func (w *sqliteWriter) Close() error {
+	if w.file == nil {
+		return nil
+	}
+	w.indexRoots = make([]uint32, 0)
+	for _, t := range w.tables {
+		for _, keys := range t.keys {
+			sort.Slice(keys, func(i, j int) bool { return sqliteCompare(keys[i], keys[j]) < 0 })
+			w.indexRoots = append(w.indexRoots, w.builder.indexTree(keys))
+		}
+	}
+	err := w.Flush()
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+	w.file = nil
+	return err
+}
+
+//writeSchema writes the schema table and the database header to the first page
+func (w *sqliteWriter) writeSchema() error {
+	schema := make([][]interface{}, 0)
+	for _, t := range w.tables {
+		schema = append(schema, []interface{}{"table", t.name, t.name, int64(t.tree.root()), t.sql})
+	}
+	if w.indexRoots != nil {
+		i := 0
+		for _, t := range w.tables {
+			for _, idx := range t.indexes {
+				schema = append(schema, []interface{}{"index", idx.name, t.name, int64(w.indexRoots[i]), idx.sql})
+				i++
+			}
+		}
+	}
+	cells := make([]sqliteCell, 0, len(schema))
+	for i, row := range schema {
+		cells = append(cells, w.builder.tableLeafCell(int64(i+1), sqliteRecord(row)))
+	}
+	if !pageFits(cells, 100+8) {
+		return fmt.Errorf("sqlite schema does not fit in the first page")
+	}
+	page := w.builder.page(1)
+	w.builder.writePage(page, 100, sqliteTableLeaf, cells, 0)
+	w.changes++
+	cookie := uint32(1)
+	if w.indexRoots != nil {
+		cookie = 2
+	}
+	w.builder.writeHeader(page, w.changes, cookie)
+	return nil
+}
+
+//writeHeader writes the 100 byte database header to the first page. The readers notice the changes to the file
+//through the change counter, and the changes to the schema through the schema cookie.
+func (b *sqliteBuilder) writeHeader(h []byte, changes, cookie uint32) {
+	copy(h, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(h[16:], SQLITE_PAGE_SIZE)
+	h[18] = 1 // legacy journal mode write and read versions
+	h[19] = 1
+	h[21] = 64 // payload fractions, fixed by the file format
+	h[22] = 32
+	h[23] = 32
+	binary.BigEndian.PutUint32(h[24:], changes)
+	binary.BigEndian.PutUint32(h[28:], b.count)
+	binary.BigEndian.PutUint32(h[40:], cookie)
+	binary.BigEndian.PutUint32(h[44:], 4) // schema format
+	binary.BigEndian.PutUint32(h[56:], 1) // UTF-8 text encoding
+	// The database size of the header is valid only while the version is the change counter
+	binary.BigEndian.PutUint32(h[92:], changes)
+	binary.BigEndian.PutUint32(h[96:], SQLITE_VERSION)
+}
+
+//newPage allocates a new page, returning its page number
+func (b *sqliteBuilder) newPage() uint32 {
+	b.count++
+	b.dirty[b.count] = make([]byte, SQLITE_PAGE_SIZE)
+	return b.count
+}
+
+//page returns an empty buffer for the content of the page, written on the next flush
+func (b *sqliteBuilder) page(n uint32) []byte {
+	page := make([]byte, SQLITE_PAGE_SIZE)
+	b.dirty[n] = page
+	return page
+}
+
+//writePage writes a b-tree page with the cells, the header starting at offset
+func (b *sqliteBuilder) writePage(page []byte, offset int, flag byte, cells []sqliteCell, right uint32) {
+	page[offset] = flag
+	binary.BigEndian.PutUint16(page[offset+3:], uint16(len(cells)))
+	headerSize := 8
+	if flag == sqliteTableInterior || flag == sqliteIndexInterior {
+		headerSize = 12
+		binary.BigEndian.PutUint32(page[offset+8:], right)
+	}
+	content := SQLITE_PAGE_SIZE
+	for i, c := range cells {
+		content -= len(c.data)
+		copy(page[content:], c.data)
+		binary.BigEndian.PutUint16(page[offset+headerSize+2*i:], uint16(content))
+	}
+	binary.BigEndian.PutUint16(page[offset+5:], uint16(content))
+}
+
+//pageFits checks if the cells fit in a page with the given header size
+func pageFits(cells []sqliteCell, headerSize int) bool {
+	size := headerSize
+	for _, c := range cells {
+		size += len(c.data) + 2
+	}
+	return size <= SQLITE_PAGE_SIZE
+}
+
+//sqliteTableTree is a table b-tree the rows are appended to in rowid order. Only the pages on the right edge of the
+//tree change when a row is appended, the pages left of them are full and final.
+type sqliteTableTree struct {
+	leaf    uint32
+	cells   []sqliteCell
+	changed bool
+	// The interior pages on the right edge, from the parent of the leaf to the root
+	levels []*sqliteInteriorPage
+}
+
+//sqliteInteriorPage is an interior page on the right edge of a table b-tree. The right-most child is referenced from
+//the page header instead of a cell.
+type sqliteInteriorPage struct {
+	page     uint32
+	children []sqliteChild
+}
+
+func newSQLiteTableTree(b *sqliteBuilder) *sqliteTableTree {
+	t := &sqliteTableTree{leaf: b.newPage()}
+	t.write(b)
+	return t
+}
+
+//root returns the root page number of the tree
+func (t *sqliteTableTree) root() uint32 {
+	if len(t.levels) == 0 {
+		return t.leaf
+	}
+	return t.levels[len(t.levels)-1].page
+}
+
+//append adds the cell of a row with a greater rowid than the earlier ones
+func (t *sqliteTableTree) append(b *sqliteBuilder, cell sqliteCell) {
+	cells := append(t.cells, cell)
+	if len(t.cells) > 0 && !pageFits(cells, 8) {
+		// The leaf is full, and written for the last time before continuing on a new one
+		b.writePage(b.page(t.leaf), 0, sqliteTableLeaf, t.cells, 0)
+		full := sqliteChild{page: t.leaf, key: t.cells[len(t.cells)-1].key}
+		t.leaf = b.newPage()
+		t.addSibling(b, 0, full, t.leaf)
+		cells = []sqliteCell{cell}
+	}
+	t.cells = cells
+	t.changed = true
+}
+
+//addSibling adds the next page after the full page at the level of the tree, 0 being the leaves, to their parent.
+//The tree grows a level when the root is full.
+func (t *sqliteTableTree) addSibling(b *sqliteBuilder, level int, full sqliteChild, next uint32) {
+	if level == len(t.levels) {
+		t.levels = append(t.levels, &sqliteInteriorPage{page: b.newPage(), children: []sqliteChild{full}})
+	}
+	parent := t.levels[level]
+	parent.children[len(parent.children)-1] = full
+	if len(parent.children) < sqliteInteriorChildren {
+		parent.children = append(parent.children, sqliteChild{page: next})
+		return
+	}
+	// The parent is full as well, and written for the last time
+	b.writeInterior(parent)
+	sibling := &sqliteInteriorPage{page: b.newPage(), children: []sqliteChild{{page: next}}}
+	t.addSibling(b, level+1, sqliteChild{page: parent.page, key: full.key}, sibling.page)
+	t.levels[level] = sibling
+}
+
+//write rewrites the pages on the right edge of the tree
+func (t *sqliteTableTree) write(b *sqliteBuilder) {
+	t.changed = false
+	b.writePage(b.page(t.leaf), 0, sqliteTableLeaf, t.cells, 0)
+	for _, p := range t.levels {
+		b.writeInterior(p)
+	}
+}
+
+//writeInterior writes an interior page of a table b-tree
+func (b *sqliteBuilder) writeInterior(p *sqliteInteriorPage) {
+	cells := make([]sqliteCell, 0, len(p.children)-1)
+	for _, c := range p.children[:len(p.children)-1] {
+		data := make([]byte, 4)
+		binary.BigEndian.PutUint32(data, c.page)
+		cells = append(cells, sqliteCell{data: append(data, sqliteVarint(c.key)...), key: c.key})
+	}
+	b.writePage(b.page(p.page), 0, sqliteTableInterior, cells, p.children[len(p.children)-1].page)
+}
+
+//tableLeafCell returns a table b-tree leaf cell for the record
+func (b *sqliteBuilder) tableLeafCell(rowid int64, record []byte) sqliteCell {
+	data := sqliteVarint(int64(len(record)))
+	data = append(data, sqliteVarint(rowid)...)
+	data = append(data, b.localPayload(record, sqliteTableMaxLocal)...)
+	return sqliteCell{data: data, key: rowid}
+}
+
+//indexCell returns an index b-tree cell for the key, with the left child page for interior pages
+func (b *sqliteBuilder) indexCell(key []interface{}, left uint32) sqliteCell {
+	record := sqliteRecord(key)
+	data := make([]byte, 0)
+	if left > 0 {
+		data = make([]byte, 4)
+		binary.BigEndian.PutUint32(data, left)
+	}
+	data = append(data, sqliteVarint(int64(len(record)))...)
+	data = append(data, b.localPayload(record, sqliteIndexMaxLocal)...)
+	return sqliteCell{data: data}
+}
+
+//localPayload returns the part of the payload stored in the cell, writing the rest to overflow pages
+func (b *sqliteBuilder) localPayload(payload []byte, maxLocal int) []byte {
+	if len(payload) <= maxLocal {
+		return payload
+	}
+	local := sqliteLocalSize(len(payload), maxLocal)
+	first := uint32(0)
+	var prev []byte
+	for rest := payload[local:]; len(rest) > 0; {
+		page := b.newPage()
+		if prev == nil {
+			first = page
+		} else {
+			binary.BigEndian.PutUint32(prev, page)
+		}
+		buf := b.page(page)
+		n := copy(buf[4:], rest)
+		rest = rest[n:]
+		prev = buf[:4]
+	}
+	data := append([]byte{}, payload[:local]...)
+	next := make([]byte, 4)
+	binary.BigEndian.PutUint32(next, first)
+	return append(data, next...)
+}
+
+//sqliteLocalSize returns the number of bytes stored in the cell for a payload that overflows
+func sqliteLocalSize(size, maxLocal int) int {
+	local := sqliteMinLocal + (size-sqliteMinLocal)%sqliteOverflowPageSpace
+	if local > maxLocal {
+		return sqliteMinLocal
+	}
+	return local
+}
+
+//indexTree writes the index b-tree pages for the sorted keys, returning the root page number
+func (b *sqliteBuilder) indexTree(keys [][]interface{}) uint32 {
+	if len(keys) == 0 {
+		page := b.newPage()
+		b.writePage(b.page(page), 0, sqliteIndexLeaf, nil, 0)
+		return page
+	}
+	children, separators := b.indexLevel(keys, nil)
+	for len(children) > 1 {
+		children, separators = b.indexLevel(separators, children)
+	}
+	return children[0]
+}
+
+//indexLevel writes a level of index b-tree pages. Unlike in table b-trees, the key separating two pages is not
+//stored in either of them but moves up to the next level. For interior levels, children holds the left child page
+//of every key followed by the right-most child.
+func (b *sqliteBuilder) indexLevel(keys [][]interface{}, children []uint32) ([]uint32, [][]interface{}) {
+	flag, headerSize, childSize := byte(sqliteIndexLeaf), 8, 0
+	if children != nil {
+		flag, headerSize, childSize = sqliteIndexInterior, 12, 4
+	}
+	sizes := make([]int, len(keys))
+	for i, k := range keys {
+		n := len(sqliteRecord(k))
+		local := n
+		if n > sqliteIndexMaxLocal {
+			local = sqliteLocalSize(n, sqliteIndexMaxLocal) + 4
+		}
+		sizes[i] = childSize + len(sqliteVarint(int64(n))) + local + 2
+	}
+	pages := make([]uint32, 0)
+	separators := make([][]interface{}, 0)
+	for start := 0; start < len(keys); {
+		end := start + 1
+		size := headerSize + sizes[start]
+		for end < len(keys) && size+sizes[end] <= SQLITE_PAGE_SIZE {
+			size += sizes[end]
+			end++
+		}
+		if end == len(keys)-1 && end-start > 1 {
+			// Leave a key for the last page, so that it does not end up empty
+			end--
+		}
+		cells := make([]sqliteCell, 0, end-start)
+		right := uint32(0)
+		for i := start; i < end; i++ {
+			left := uint32(0)
+			if children != nil {
+				left = children[i]
+			}
+			cells = append(cells, b.indexCell(keys[i], left))
+		}
+		if children != nil {
+			right = children[end]
+		}
+		page := b.newPage()
+		b.writePage(b.page(page), 0, flag, cells, right)
+		pages = append(pages, page)
+		if end < len(keys) {
+			separators = append(separators, keys[end])
+		}
+		start = end + 1
+	}
+	return pages, separators
+}
+
+//sqliteRecord encodes the values in the record format
+func sqliteRecord(values []interface{}) []byte {
+	types := make([]byte, 0)
+	body := make([]byte, 0)
+	for _, v := range values {
+		switch val := v.(type) {
+		case nil:
+			types = append(types, sqliteVarint(0)...)
+		case int64:
+			t, data := sqliteInteger(val)
+			types = append(types, sqliteVarint(t)...)
+			body = append(body, data...)
+		case string:
+			types = append(types, sqliteVarint(int64(len(val))*2+13)...)
+			body = append(body, val...)
+		}
+	}
+	// The header size includes the size varint itself
+	headerSize := int64(len(types) + 1)
+	if len(sqliteVarint(headerSize)) > 1 {
+		headerSize = int64(len(types) + len(sqliteVarint(headerSize+1)))
+	}
+	record := sqliteVarint(headerSize)
+	record = append(record, types...)
+	return append(record, body...)
+}
+
+//sqliteInteger returns the serial type and the big-endian encoding of an integer
+func sqliteInteger(v int64) (int64, []byte) {
+	if v == 0 {
+		return 8, nil
+	}
+	if v == 1 {
+		return 9, nil
+	}
+	for i, size := range []int{1, 2, 3, 4, 6, 8} {
+		if size == 8 || (v >= -(1<<(8*uint(size)-1)) && v < 1<<(8*uint(size)-1)) {
+			data := make([]byte, size)
+			for j := 0; j < size; j++ {
+				data[size-1-j] = byte(v >> (8 * uint(j)))
+			}
+			return int64(i + 1), data
+		}
+	}
+	return 0, nil
+}
+
+//sqliteVarint encodes a 64-bit variable-length integer
+func sqliteVarint(v int64) []byte {
+	u := uint64(v)
+	if u > 0x00FFFFFFFFFFFFFF {
+		// Nine byte form, the last byte holds full 8 bits
+		out := make([]byte, 9)
+		out[8] = byte(u)
+		u >>= 8
+		for i := 7; i >= 0; i-- {
+			out[i] = byte(u&0x7F) | 0x80
+			u >>= 7
+		}
+		return out
+	}
+	out := []byte{byte(u & 0x7F)}
+	u >>= 7
+	for u > 0 {
+		out = append([]byte{byte(u&0x7F) | 0x80}, out...)
+		u >>= 7
+	}
+	return out
+}
+
+//sqliteCompare compares two index keys in the SQLite sort order: NULL first, then integers and text using the
+//binary collation
+func sqliteCompare(a, b []interface{}) int {
+	for i := range a {
+		if c := sqliteCompareValue(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func sqliteCompareValue(a, b interface{}) int {
+	rank := func(v interface{}) int {
+		switch v.(type) {
+		case nil:
+			return 0
+		case int64:
+			return 1
+		}
+		return 2
+	}
+	if rank(a) != rank(b) {
+		return rank(a) - rank(b)
+	}
+	switch av := a.(type) {
+	case int64:
+		bv := b.(int64)
+		if av < bv {
+			return -1
+		} else if av > bv {
+			return 1
+		}
+	case string:
+		return bytes.Compare([]byte(av), []byte(b.(string)))
+	}
+	return 0
+}
@@ -0,0 +1,455 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//sqliteTestDatabase is the content of a database file read back by sqliteTestReader
+type sqliteTestDatabase struct {
+	pages   uint32
+	tables  map[string][][]interface{}
+	rowids  map[string][]int64
+	indexes map[string][][]interface{}
+}
+
+//sqliteTestReader reads a database file following the file format, and fails the test on any page referenced twice
+//or left unreferenced, b-tree keys out of order or malformed records
+type sqliteTestReader struct {
+	t    *testing.T
+	data []byte
+	used map[uint32]bool
+}
+
+func readSQLiteTestDatabase(t *testing.T, filename string) sqliteTestDatabase {
+	t.Helper()
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Could not read the database: %s", err)
+	}
+	if len(data) == 0 || len(data)%SQLITE_PAGE_SIZE != 0 {
+		t.Fatalf("Database size %d is not a multiple of the page size", len(data))
+	}
+	if !bytes.HasPrefix(data, []byte("SQLite format 3\x00")) {
+		t.Fatalf("Database header is missing")
+	}
+	if size := binary.BigEndian.Uint16(data[16:]); size != SQLITE_PAGE_SIZE {
+		t.Errorf("Was expecting page size %d but got %d", SQLITE_PAGE_SIZE, size)
+	}
+	db := sqliteTestDatabase{
+		pages:   binary.BigEndian.Uint32(data[28:]),
+		tables:  make(map[string][][]interface{}),
+		rowids:  make(map[string][]int64),
+		indexes: make(map[string][][]interface{}),
+	}
+	if int(db.pages) != len(data)/SQLITE_PAGE_SIZE {
+		t.Errorf("Header has %d pages but the file %d", db.pages, len(data)/SQLITE_PAGE_SIZE)
+	}
+	if binary.BigEndian.Uint32(data[24:]) != binary.BigEndian.Uint32(data[92:]) {
+		t.Errorf("Database size of the header is not valid for the change counter")
+	}
+	r := &sqliteTestReader{t: t, data: data, used: make(map[uint32]bool)}
+	var schemaIds []int64
+	var schema [][]interface{}
+	r.table(r.page(1), 100, &schemaIds, &schema)
+	for _, row := range schema {
+		if len(row) != 5 {
+			t.Fatalf("Malformed schema row: %v", row)
+		}
+		name := row[1].(string)
+		root := uint32(row[3].(int64))
+		switch row[0] {
+		case "table":
+			var rowids []int64
+			var rows [][]interface{}
+			r.table(r.page(root), 0, &rowids, &rows)
+			for i := 1; i < len(rowids); i++ {
+				if rowids[i] <= rowids[i-1] {
+					t.Errorf("Table %s: rowid %d after %d", name, rowids[i], rowids[i-1])
+				}
+			}
+			db.tables[name] = rows
+			db.rowids[name] = rowids
+		case "index":
+			var keys [][]interface{}
+			r.index(r.page(root), &keys)
+			for i := 1; i < len(keys); i++ {
+				if sqliteCompare(keys[i-1], keys[i]) >= 0 {
+					t.Errorf("Index %s: key %v after %v", name, keys[i], keys[i-1])
+				}
+			}
+			db.indexes[name] = keys
+		}
+	}
+	for n := uint32(1); n <= db.pages; n++ {
+		if !r.used[n] {
+			t.Errorf("Page %d is not referenced", n)
+		}
+	}
+	return db
+}
+
+func (r *sqliteTestReader) page(n uint32) []byte {
+	if n < 1 || int(n)*SQLITE_PAGE_SIZE > len(r.data) {
+		r.t.Fatalf("Page %d is out of the file", n)
+	}
+	if r.used[n] {
+		r.t.Fatalf("Page %d is referenced twice", n)
+	}
+	r.used[n] = true
+	return r.data[int(n-1)*SQLITE_PAGE_SIZE : int(n)*SQLITE_PAGE_SIZE]
+}
+
+//cell returns the content of the page starting at the cell pointer i
+func (r *sqliteTestReader) cell(page []byte, offset, headerSize, i int) []byte {
+	return page[binary.BigEndian.Uint16(page[offset+headerSize+2*i:]):]
+}
+
+//table reads the rows of a table b-tree page and its children
+func (r *sqliteTestReader) table(page []byte, offset int, rowids *[]int64, rows *[][]interface{}) {
+	count := int(binary.BigEndian.Uint16(page[offset+3:]))
+	switch page[offset] {
+	case sqliteTableLeaf:
+		for i := 0; i < count; i++ {
+			cell := r.cell(page, offset, 8, i)
+			size, n := readTestVarint(cell)
+			rowid, m := readTestVarint(cell[n:])
+			*rowids = append(*rowids, rowid)
+			*rows = append(*rows, r.record(r.payload(cell[n+m:], size, SQLITE_PAGE_SIZE-35)))
+		}
+	case sqliteTableInterior:
+		children := make([]uint32, 0, count+1)
+		keys := make([]int64, 0, count)
+		for i := 0; i < count; i++ {
+			cell := r.cell(page, offset, 12, i)
+			key, _ := readTestVarint(cell[4:])
+			children = append(children, binary.BigEndian.Uint32(cell))
+			keys = append(keys, key)
+		}
+		children = append(children, binary.BigEndian.Uint32(page[offset+8:]))
+		for i, child := range children {
+			start := len(*rowids)
+			r.table(r.page(child), 0, rowids, rows)
+			if len(*rowids) == start {
+				r.t.Errorf("Child page %d of a table b-tree is empty", child)
+				continue
+			}
+			if i < len(keys) && (*rowids)[len(*rowids)-1] > keys[i] {
+				r.t.Errorf("Child page %d has rowids greater than its key %d", child, keys[i])
+			}
+			if i > 0 && (*rowids)[start] <= keys[i-1] {
+				r.t.Errorf("Child page %d has rowids not greater than the key %d", child, keys[i-1])
+			}
+		}
+	default:
+		r.t.Fatalf("Unexpected table b-tree page type %d", page[offset])
+	}
+}
+
+//index reads the keys of an index b-tree page and its children in order
+func (r *sqliteTestReader) index(page []byte, keys *[][]interface{}) {
+	count := int(binary.BigEndian.Uint16(page[3:]))
+	maxLocal := (SQLITE_PAGE_SIZE-12)*64/255 - 23
+	switch page[0] {
+	case sqliteIndexLeaf:
+		for i := 0; i < count; i++ {
+			cell := r.cell(page, 0, 8, i)
+			size, n := readTestVarint(cell)
+			*keys = append(*keys, r.record(r.payload(cell[n:], size, maxLocal)))
+		}
+	case sqliteIndexInterior:
+		for i := 0; i < count; i++ {
+			cell := r.cell(page, 0, 12, i)
+			r.index(r.page(binary.BigEndian.Uint32(cell)), keys)
+			size, n := readTestVarint(cell[4:])
+			*keys = append(*keys, r.record(r.payload(cell[4+n:], size, maxLocal)))
+		}
+		r.index(r.page(binary.BigEndian.Uint32(page[8:])), keys)
+	default:
+		r.t.Fatalf("Unexpected index b-tree page type %d", page[0])
+	}
+}
+
+//payload returns the payload of a cell, reading the part that does not fit in the cell from the overflow pages
+func (r *sqliteTestReader) payload(cell []byte, size int64, maxLocal int) []byte {
+	minLocal := (SQLITE_PAGE_SIZE-12)*32/255 - 23
+	local := int(size)
+	if local > maxLocal {
+		local = minLocal + (int(size)-minLocal)%(SQLITE_PAGE_SIZE-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+	out := append([]byte{}, cell[:local]...)
+	if local == int(size) {
+		return out
+	}
+	next := binary.BigEndian.Uint32(cell[local:])
+	for len(out) < int(size) {
+		page := r.page(next)
+		next = binary.BigEndian.Uint32(page)
+		n := int(size) - len(out)
+		if n > SQLITE_PAGE_SIZE-4 {
+			n = SQLITE_PAGE_SIZE - 4
+		}
+		out = append(out, page[4:4+n]...)
+	}
+	if next != 0 {
+		r.t.Errorf("Overflow chain continues past the payload to page %d", next)
+	}
+	return out
+}
+
+//record decodes the values of a record
+func (r *sqliteTestReader) record(data []byte) []interface{} {
+	headerSize, pos := readTestVarint(data)
+	types := make([]int64, 0)
+	for pos < int(headerSize) {
+		t, n := readTestVarint(data[pos:])
+		types = append(types, t)
+		pos += n
+	}
+	body := data[headerSize:]
+	values := make([]interface{}, 0, len(types))
+	for _, t := range types {
+		switch {
+		case t == 0:
+			values = append(values, nil)
+		case t >= 1 && t <= 6:
+			size := []int{1, 2, 3, 4, 6, 8}[t-1]
+			v := int64(int8(body[0]))
+			for _, b := range body[1:size] {
+				v = v<<8 | int64(b)
+			}
+			values = append(values, v)
+			body = body[size:]
+		case t == 8 || t == 9:
+			values = append(values, t-8)
+		case t >= 13 && t%2 == 1:
+			size := int(t-13) / 2
+			values = append(values, string(body[:size]))
+			body = body[size:]
+		default:
+			r.t.Fatalf("Unexpected serial type %d", t)
+		}
+	}
+	if len(body) != 0 {
+		r.t.Errorf("Record has %d bytes after the values", len(body))
+	}
+	return values
+}
+
+func readTestVarint(b []byte) (int64, int) {
+	var v uint64
+	for i := 0; i < 8; i++ {
+		v = v<<7 | uint64(b[i]&0x7F)
+		if b[i] < 0x80 {
+			return int64(v), i + 1
+		}
+	}
+	return int64(v<<8 | uint64(b[8])), 9
+}
+
+func sqliteTestConfig() *ffuf.Config {
+	ctx, cancel := context.WithCancel(context.Background())
+	conf := ffuf.NewConfig(ctx, cancel)
+	conf.ScanID = "test-scan"
+	conf.CommandLine = "ffuf -of sqlite"
+	conf.InputProviders = []ffuf.InputProviderConfig{{Name: "wordlist", Keyword: "FUZZ"}}
+	return &conf
+}
+
+func sqliteTestResults(count int) []ffuf.Result {
+	results := make([]ffuf.Result, 0, count)
+	for i := 0; i < count; i++ {
+		input := fmt.Sprintf("word%d", i)
+		if i%1000 == 999 {
+			// Spills to overflow pages in the table and in the indexes
+			input = strings.Repeat("x", 10000+i)
+		}
+		r := ffuf.Result{
+			Input:         map[string][]byte{"FUZZ": []byte(input)},
+			Position:      i + 1,
+			StatusCode:    int64(200 + i%3),
+			ContentLength: int64(i % 500),
+			ContentWords:  int64(i % 7),
+			ContentLines:  int64(i % 5),
+			ContentType:   "text/html",
+			Url:           "http://localhost/" + input,
+			Duration:      time.Duration(i) * time.Millisecond,
+			Timestamp:     time.Unix(int64(1600000000+i), 0),
+		}
+		if i%10 == 0 {
+			r.ScraperData = map[string][]string{"title": {"Title " + input}, "email": {"a@localhost", "b@localhost"}}
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+//checkSQLiteResults checks the rows of the tables and the keys of the indexes, if created, against the results
+func checkSQLiteResults(t *testing.T, db sqliteTestDatabase, results []ffuf.Result, indexes bool) {
+	t.Helper()
+	if len(db.tables["scan"]) != 1 || db.tables["scan"][0][0] != "test-scan" {
+		t.Errorf("Was expecting a single scan row, got %v", db.tables["scan"])
+	}
+	rows := db.tables["results"]
+	if len(rows) != len(results) {
+		t.Fatalf("Was expecting %d result rows but got %d", len(results), len(rows))
+	}
+	scraped := 0
+	for i, r := range results {
+		if db.rowids["results"][i] != int64(i+1) || rows[i][1] != r.Url || rows[i][4] != r.StatusCode {
+			t.Errorf("Result row %d does not match the result: %v", i+1, rows[i][:5])
+		}
+		for _, v := range r.ScraperData {
+			scraped += len(v)
+		}
+	}
+	inputs := db.tables["inputs"]
+	if len(inputs) != len(results) {
+		t.Errorf("Was expecting %d input rows but got %d", len(results), len(inputs))
+	}
+	for i, row := range inputs {
+		if row[0] != int64(i+1) || row[2] != string(results[i].Input["FUZZ"]) {
+			t.Errorf("Input row %d does not match the result: %v", i+1, row)
+			break
+		}
+	}
+	if len(db.tables["scraper"]) != scraped {
+		t.Errorf("Was expecting %d scraper rows but got %d", scraped, len(db.tables["scraper"]))
+	}
+	if !indexes {
+		if len(db.indexes) != 0 {
+			t.Errorf("Was expecting no indexes before the writer is closed, got %d", len(db.indexes))
+		}
+		return
+	}
+	for _, table := range sqliteResultTables() {
+		for _, idx := range table.indexes {
+			keys, ok := db.indexes[idx.name]
+			if !ok {
+				t.Errorf("Index %s is missing", idx.name)
+				continue
+			}
+			if len(keys) != len(db.tables[table.name]) {
+				t.Errorf("Index %s has %d keys for %d rows", idx.name, len(keys), len(db.tables[table.name]))
+				continue
+			}
+			for _, key := range keys {
+				rowid := key[len(key)-1].(int64)
+				row := db.tables[table.name][rowid-1]
+				for i, c := range idx.columns {
+					if key[i] != row[c] {
+						t.Errorf("Index %s key %v does not match the row %d", idx.name, key, rowid)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSQLiteEmpty(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "empty.db")
+	if err := writeSQLite(filename, sqliteTestConfig(), nil); err != nil {
+		t.Fatalf("Could not write the database: %s", err)
+	}
+	db := readSQLiteTestDatabase(t, filename)
+	// The schema page, and a root page for every table and index
+	if db.pages != 12 {
+		t.Errorf("Was expecting 12 pages but got %d", db.pages)
+	}
+	checkSQLiteResults(t, db, nil, true)
+}
+
+func TestSQLiteSingleResult(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "single.db")
+	results := sqliteTestResults(1)
+	if err := writeSQLite(filename, sqliteTestConfig(), results); err != nil {
+		t.Fatalf("Could not write the database: %s", err)
+	}
+	db := readSQLiteTestDatabase(t, filename)
+	if db.pages != 12 {
+		t.Errorf("Was expecting 12 pages but got %d", db.pages)
+	}
+	checkSQLiteResults(t, db, results, true)
+	row := db.tables["results"][0]
+	if row[0] != nil || row[3] != int64(1) || row[12] != "2020-09-13T12:26:40Z" {
+		t.Errorf("Unexpected result row: %v", row)
+	}
+}
+
+func TestSQLiteMultiPage(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "multi.db")
+	results := sqliteTestResults(50000)
+	if err := writeSQLite(filename, sqliteTestConfig(), results); err != nil {
+		t.Fatalf("Could not write the database: %s", err)
+	}
+	db := readSQLiteTestDatabase(t, filename)
+	if db.pages < 1000 {
+		t.Errorf("Was expecting the results to span over 1000 pages, got %d", db.pages)
+	}
+	checkSQLiteResults(t, db, results, true)
+}
+
+func TestSQLiteStreamed(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "streamed.db")
+	w, err := newSQLiteResultWriter(filename, false, sqliteTestConfig())
+	if err != nil {
+		t.Fatalf("Could not create the database: %s", err)
+	}
+	checkSQLiteResults(t, readSQLiteTestDatabase(t, filename), nil, false)
+	results := sqliteTestResults(20000)
+	for i, r := range results {
+		if err := w.Write(r); err != nil {
+			t.Fatalf("Could not write result %d: %s", i, err)
+		}
+		// The database is complete after every result
+		if n := i + 1; n == 1 || n == 150 || n == 5000 || n == len(results) {
+			checkSQLiteResults(t, readSQLiteTestDatabase(t, filename), results[:n], false)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Could not close the database: %s", err)
+	}
+	checkSQLiteResults(t, readSQLiteTestDatabase(t, filename), results, true)
+	if err := w.Write(results[0]); err == nil {
+		t.Errorf("Was expecting an error writing to a closed database")
+	}
+}
+
+func TestSQLiteConcurrentResults(t *testing.T) {
+	conf := sqliteTestConfig()
+	conf.OutputFile = filepath.Join(t.TempDir(), "concurrent.db")
+	conf.OutputFormat = "sqlite"
+	conf.Quiet = true
+	s := NewStdoutput(conf)
+	urls := sendTestResults(t, s, 200)
+	s.SetSummary(ffuf.Summary{})
+	if err := s.Finalize(); err != nil {
+		t.Fatalf("Could not finalize the output: %s", err)
+	}
+	db := readSQLiteTestDatabase(t, conf.OutputFile)
+	rows := db.tables["results"]
+	if len(rows) != len(urls) {
+		t.Fatalf("Was expecting %d result rows but got %d", len(urls), len(rows))
+	}
+	for _, row := range rows {
+		if !urls[row[1].(string)] {
+			t.Errorf("Unexpected or duplicate result row: %v", row[:5])
+		}
+		delete(urls, row[1].(string))
+	}
+	if len(db.tables["inputs"]) != len(rows) {
+		t.Errorf("Was expecting %d input rows but got %d", len(rows), len(db.tables["inputs"]))
+	}
+}
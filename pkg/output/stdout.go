@@ -1,13 +1,13 @@
 package output
 
 import (
-	"crypto/md5"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ffuf/ffuf/pkg/ffuf"
@@ -23,12 +23,31 @@ const (
           \/_/    \/_/   \/___/    \/_/       
 `
 	BANNER_SEP = "________________________________________________"
+	//RESULT_FILE_SEPARATOR separates the request from the response in the result files of -od
+	RESULT_FILE_SEPARATOR = "---- ↑ Request ---- Response ↓ ----"
 )
 
 type Stdoutput struct {
 	config         *ffuf.Config
 	Results        []ffuf.Result
 	CurrentResults []ffuf.Result
+	savedResults   int
+	ndjson         *ndjsonWriter
+	sqlite         *sqliteResultWriter
+	resultFiles    int
+	resultMutex    sync.Mutex
+	streamMutex    sync.Mutex
+	streamErr      error
+	snapshotTime   time.Time
+	errorClasses   map[string]int
+	groupMutex     sync.Mutex
+	groupDir       string
+	groupCounts    map[string]int
+	summary        *ffuf.Summary
+	summarySaved   bool
+	progressMutex  sync.Mutex
+	progressTime   time.Time
+	progressDone   int
 }
 
 func NewStdoutput(conf *ffuf.Config) *Stdoutput {
@@ -42,14 +61,35 @@ func NewStdoutput(conf *ffuf.Config) *Stdoutput {
 func (s *Stdoutput) Banner() {
 	version := strings.ReplaceAll(ffuf.Version(), "<3", fmt.Sprintf("%s<3%s", ANSI_RED, ANSI_CLEAR))
 	fmt.Fprintf(os.Stderr, "%s\n       v%s\n%s\n\n", BANNER_HEADER, version, BANNER_SEP)
+	printOption([]byte("Scan ID"), []byte(s.config.ScanID))
 	printOption([]byte("Method"), []byte(s.config.Method))
 	printOption([]byte("URL"), []byte(s.config.Url))
+	if len(s.config.ApiOperations) > 0 {
+		printOption([]byte("API operations"), []byte(strconv.Itoa(len(s.config.ApiOperations))))
+	}
 
 	// Print wordlists
 	for _, provider := range s.config.InputProviders {
-		if provider.Name == "wordlist" {
+		if provider.Keyword == ffuf.ORIGIN_KEYWORD {
+			printOption([]byte("Origin IPs"), []byte(provider.Value))
+		} else if provider.Name == "wordlist" {
 			printOption([]byte("Wordlist"), []byte(provider.Keyword+": "+provider.Value))
+		} else if provider.Name == "range" {
+			printOption([]byte("Range"), []byte(provider.Keyword+": "+strings.TrimPrefix(provider.Value, ffuf.RANGE_PREFIX)))
+		} else if provider.Name == "list" {
+			values, _, _ := ffuf.ParseInputList(provider.Value)
+			printOption([]byte("List"), []byte(provider.Keyword+": "+strings.Join(values, ",")))
+		}
+	}
+	if s.config.ShardCount > 0 {
+		shard := fmt.Sprintf("%d/%d", s.config.Shard, s.config.ShardCount)
+		if s.config.ShardHash {
+			shard += " (by input hash)"
 		}
+		printOption([]byte("Shard"), []byte(shard))
+	}
+	if s.config.Recursion && len(s.config.RecursionWordlist) > 0 {
+		printOption([]byte("Recursion list"), []byte("FUZZ: "+s.config.RecursionWordlist))
 	}
 
 	// Print headers
@@ -58,11 +98,26 @@ func (s *Stdoutput) Banner() {
 			printOption([]byte("Header"), []byte(fmt.Sprintf("%s: %s", k, v)))
 		}
 	}
+	if len(s.config.AuthScheme) > 0 {
+		user := s.config.AuthUser
+		if len(s.config.AuthDomain) > 0 {
+			user = s.config.AuthDomain + `\` + user
+		}
+		printOption([]byte("Authentication"), []byte(fmt.Sprintf("%s (%s)", s.config.AuthScheme, user)))
+	}
 	// Print POST data
 	if len(s.config.Data) > 0 {
 		printOption([]byte("Data"), []byte(s.config.Data))
 	}
 
+	if s.config.SafeMode {
+		printOption([]byte("Safe mode"), []byte("true"))
+	}
+
+	if s.config.Denylist != nil {
+		printOption([]byte("Denylist"), []byte(fmt.Sprintf("%d patterns", s.config.Denylist.Len())))
+	}
+
 	// Print extensions
 	if len(s.config.Extensions) > 0 {
 		exts := ""
@@ -87,21 +142,60 @@ func (s *Stdoutput) Banner() {
 		printOption([]byte("File format"), []byte(s.config.OutputFormat))
 	}
 
+	// HTTP2?
+	if s.config.Http2 {
+		http2 := "true"
+		if s.config.Http2PriorKnowledge {
+			http2 = "true (prior knowledge)"
+		}
+		printOption([]byte("HTTP2"), []byte(http2))
+	}
+
 	// Follow redirects?
 	follow := fmt.Sprintf("%t", s.config.FollowRedirects)
 	printOption([]byte("Follow redirects"), []byte(follow))
 
+	// Crawling
+	if s.config.Crawl {
+		crawl := fmt.Sprintf("depth %d, max %d pages", s.config.CrawlDepth, s.config.CrawlPages)
+		printOption([]byte("Crawl"), []byte(crawl))
+	}
+
 	// Autocalibration
 	autocalib := fmt.Sprintf("%t", s.config.AutoCalibration)
+	if s.config.AutoCalibration {
+		autocalib = s.config.AutoCalibrationStrategy
+		if s.config.AutoCalibrationPerHost {
+			autocalib += ", per host"
+		}
+	}
 	printOption([]byte("Calibration"), []byte(autocalib))
 
 	// Proxies
 	if len(s.config.ProxyURL) > 0 {
 		printOption([]byte("Proxy"), []byte(s.config.ProxyURL))
 	}
+	if len(s.config.ProxyList) > 0 {
+		proxies := len(s.config.ProxyList)
+		if len(s.config.ProxyURL) > 0 {
+			proxies++
+		}
+		printOption([]byte("Proxies"), []byte(fmt.Sprintf("%d rotated (%s)", proxies, s.config.ProxyRotate)))
+	}
 	if len(s.config.ReplayProxyURL) > 0 {
 		printOption([]byte("ReplayProxy"), []byte(s.config.ReplayProxyURL))
 	}
+	for _, f := range s.config.ReplayMatchers {
+		printOption([]byte("Replay Matcher"), []byte(f.ReprVerbose()))
+	}
+	if len(s.config.ReplayDir) > 0 {
+		printOption([]byte("Replay Dir"), []byte(s.config.ReplayDir))
+	}
+
+	// DNS resolvers
+	if len(s.config.Resolvers) > 0 {
+		printOption([]byte("Resolvers"), []byte(strings.Join(s.config.Resolvers, ", ")))
+	}
 
 	// Timeout
 	timeout := fmt.Sprintf("%d", s.config.Timeout)
@@ -110,6 +204,12 @@ func (s *Stdoutput) Banner() {
 	// Threads
 	threads := fmt.Sprintf("%d", s.config.Threads)
 	printOption([]byte("Threads"), []byte(threads))
+	if s.config.IPThreads > 0 {
+		printOption([]byte("Threads per IP"), []byte(strconv.Itoa(s.config.IPThreads)))
+	}
+	if s.config.IPRate > 0 {
+		printOption([]byte("Rate per IP"), []byte(fmt.Sprintf("%d req/sec", s.config.IPRate)))
+	}
 
 	// Delay?
 	if s.config.Delay.HasDelay {
@@ -133,6 +233,24 @@ func (s *Stdoutput) Banner() {
 	fmt.Fprintf(os.Stderr, "%s\n\n", BANNER_SEP)
 }
 
+//SetSummary prints the summary of the finished run, and has it written to the output file when it is finalized
+func (s *Stdoutput) SetSummary(summary ffuf.Summary) {
+	s.resultMutex.Lock()
+	s.summary = &summary
+	s.summarySaved = false
+	s.resultMutex.Unlock()
+	if !s.config.Quiet {
+		s.Raw(SummaryReport(summary))
+	}
+}
+
+//currentSummary returns the summary of the run, or nil while the run has not finished
+func (s *Stdoutput) currentSummary() *ffuf.Summary {
+	s.resultMutex.Lock()
+	defer s.resultMutex.Unlock()
+	return s.summary
+}
+
 // Reset resets the result slice
 func (s *Stdoutput) Reset() {
 	s.CurrentResults = nil
@@ -155,6 +273,17 @@ func (s *Stdoutput) SetCurrentResults(results []ffuf.Result) {
 }
 
 func (s *Stdoutput) Progress(status ffuf.Progress) {
+	// The error counts are written to the ejson output file
+	s.resultMutex.Lock()
+	s.errorClasses = status.ErrorClasses
+	s.resultMutex.Unlock()
+	if s.config.ProgressJSONWriter != nil {
+		s.writeProgressJSON(status)
+		if s.config.ProgressJSON == "stderr" {
+			// The progress stream replaces the progress bar
+			return
+		}
+	}
 	if s.config.Quiet {
 		// No progress for quiet mode
 		return
@@ -175,7 +304,36 @@ func (s *Stdoutput) Progress(status ffuf.Progress) {
 	dur -= mins * time.Minute
 	secs := dur / time.Second
 
-	fmt.Fprintf(os.Stderr, "%s:: Progress: [%d/%d] :: Job [%d/%d] :: %d req/sec :: Duration: [%d:%02d:%02d] :: Errors: %d ::", TERMINAL_CLEAR_LINE, status.ReqCount, status.ReqTotal, status.QueuePos, status.QueueTotal, reqRate, hours, mins, secs, status.ErrorCount)
+	total := strconv.Itoa(status.ReqTotal)
+	if status.ReqTotal < 0 {
+		// Streamed input of unknown length
+		total = "?"
+	}
+	fmt.Fprintf(os.Stderr, "%s:: Progress: [%d/%s] :: Job [%d/%d] :: %d req/sec :: Duration: [%d:%02d:%02d] :: Errors: %d%s ::", TERMINAL_CLEAR_LINE, status.ReqCount, total, status.QueuePos, status.QueueTotal, reqRate, hours, mins, secs, status.ErrorCount, errorClassesRepr(status.ErrorClasses))
+}
+
+//currentErrorClasses returns the error counts by class of the latest progress update
+func (s *Stdoutput) currentErrorClasses() map[string]int {
+	s.resultMutex.Lock()
+	defer s.resultMutex.Unlock()
+	return s.errorClasses
+}
+
+//errorClassesRepr returns the error counts by class for the progress line, eg. " (dns: 3, timeout: 2)"
+func errorClassesRepr(classes map[string]int) string {
+	if len(classes) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(classes))
+	for _, c := range ffuf.ErrorClasses {
+		if classes[c] > 0 {
+			names = append(names, fmt.Sprintf("%s: %d", c, classes[c]))
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(names, ", ") + ")"
 }
 
 func (s *Stdoutput) Info(infostring string) {
@@ -218,125 +376,253 @@ func (s *Stdoutput) Raw(output string) {
 	fmt.Fprintf(os.Stderr, "%s%s", TERMINAL_CLEAR_LINE, output)
 }
 
-func (s *Stdoutput) writeToAll(filename string, config *ffuf.Config, res []ffuf.Result) error {
+func (s *Stdoutput) writeToAll(filename string, config *ffuf.Config, res []ffuf.Result, durable bool) error {
 	var err error
-	var BaseFilename string = s.config.OutputFile
 
 	// Go through each type of write, adding
 	// the suffix to each output file.
-
-	s.config.OutputFile = BaseFilename + ".json"
-	err = writeJSON(filename, s.config, res)
-	if err != nil {
-		s.Error(err.Error())
-	}
-
-	s.config.OutputFile = BaseFilename + ".ejson"
-	err = writeEJSON(filename, s.config, res)
-	if err != nil {
-		s.Error(err.Error())
-	}
-
-	s.config.OutputFile = BaseFilename + ".html"
-	err = writeHTML(filename, s.config, res)
-	if err != nil {
-		s.Error(err.Error())
-	}
-
-	s.config.OutputFile = BaseFilename + ".md"
-	err = writeMarkdown(filename, s.config, res)
-	if err != nil {
-		s.Error(err.Error())
-	}
-
-	s.config.OutputFile = BaseFilename + ".csv"
-	err = writeCSV(filename, s.config, res, false)
-	if err != nil {
-		s.Error(err.Error())
-	}
-
-	s.config.OutputFile = BaseFilename + ".ecsv"
-	err = writeCSV(filename, s.config, res, true)
-	if err != nil {
-		s.Error(err.Error())
+	for _, format := range []string{"json", "ejson", "html", "md", "csv", "ecsv", "sqlite", "ndjson"} {
+		if ferr := s.writeFile(filename+"."+format, format, res, durable); ferr != nil && err == nil {
+			err = ferr
+		}
 	}
-
-	return nil
-
+	return err
 }
 
 // SaveFile saves the current results to a file of a given type
 func (s *Stdoutput) SaveFile(filename, format string) error {
 	var err error
-	if s.config.OutputSkipEmptyFile && len(s.Results) == 0 {
+	results := s.allResults()
+	if s.config.OutputSkipEmptyFile && len(results) == 0 {
 		s.Info("No results and -or defined, output file not written.")
 		return err
 	}
+	return s.writeFile(filename, format, results, true)
+}
+
+// writeFile writes the results to a file of a given type. The file is rewritten atomically, synced to the disk when
+// durable, apart from the streamed ndjson and sqlite output.
+func (s *Stdoutput) writeFile(filename, format string, res []ffuf.Result, durable bool) error {
+	var write func(string) error
 	switch format {
 	case "all":
-		err = s.writeToAll(filename, s.config, append(s.Results, s.CurrentResults...))
+		return s.writeToAll(filename, s.config, res, durable)
 	case "json":
-		err = writeJSON(filename, s.config, append(s.Results, s.CurrentResults...))
+		write = func(f string) error { return writeJSON(f, s.config, res, s.currentSummary()) }
 	case "ejson":
-		err = writeEJSON(filename, s.config, append(s.Results, s.CurrentResults...))
+		write = func(f string) error { return writeEJSON(f, s.config, res, s.currentErrorClasses(), s.currentSummary()) }
 	case "html":
-		err = writeHTML(filename, s.config, append(s.Results, s.CurrentResults...))
+		write = func(f string) error { return writeHTML(f, s.config, res, s.currentSummary()) }
 	case "md":
-		err = writeMarkdown(filename, s.config, append(s.Results, s.CurrentResults...))
+		write = func(f string) error { return writeMarkdown(f, s.config, res, s.currentSummary()) }
 	case "csv":
-		err = writeCSV(filename, s.config, append(s.Results, s.CurrentResults...), false)
+		write = func(f string) error { return writeCSV(f, s.config, res, false) }
 	case "ecsv":
-		err = writeCSV(filename, s.config, append(s.Results, s.CurrentResults...), true)
+		write = func(f string) error { return writeCSV(f, s.config, res, true) }
+	case "sqlite":
+		if s.streaming(filename) {
+			// Results are streamed to the output file as they come
+			return nil
+		}
+		write = func(f string) error { return writeSQLite(f, s.config, res) }
+	case "ndjson":
+		if s.streaming(filename) {
+			// Results are streamed to the output file as they come, only write the ones saved elsewhere
+			return nil
+		}
+		write = func(f string) error { return writeNDJSON(f, s.config, res) }
+	default:
+		return nil
 	}
-	return err
+	return writeAtomic(filename, durable, write)
+}
+
+//AllResults returns the results of the finished queue jobs and the current one
+func (s *Stdoutput) AllResults() []ffuf.Result {
+	return s.allResults()
+}
+
+// allResults returns the results of the finished and the currently running jobs
+func (s *Stdoutput) allResults() []ffuf.Result {
+	s.resultMutex.Lock()
+	defer s.resultMutex.Unlock()
+	results := make([]ffuf.Result, 0, len(s.Results)+len(s.CurrentResults))
+	results = append(results, s.Results...)
+	return append(results, s.CurrentResults...)
 }
 
-// Finalize gets run after all the ffuf jobs are completed
+// Finalize writes the results to the output file. It gets run during the ffuf jobs to keep the file up to date,
+// and after all of them are completed. The results failing to be written are kept for the next attempt. The file is
+// synced to the disk at most every OUTPUT_SNAPSHOT_INTERVAL, or on every write with -fsync. The file is rewritten
+// once more after the summary of the run is set, even without new results.
 func (s *Stdoutput) Finalize() error {
-	var err error
-	if s.config.OutputFile != "" && len(s.CurrentResults) > 0 {
-		err = s.SaveFile(s.config.OutputFile, s.config.OutputFormat)
+	s.streamMutex.Lock()
+	streamErr := s.streamErr
+	s.streamMutex.Unlock()
+	if streamErr != nil {
+		return fmt.Errorf("Could not write the output file %s: %s", s.config.OutputFile, streamErr)
+	}
+	results := s.allResults()
+	summary := s.currentSummary()
+	pendingSummary := summary != nil && !s.summarySaved && !(s.config.OutputSkipEmptyFile && len(results) == 0)
+	if s.config.OutputFile != "" && (len(results) > s.savedResults || pendingSummary) {
+		durable := s.config.OutputFsync || pendingSummary || time.Since(s.snapshotTime) >= OUTPUT_SNAPSHOT_INTERVAL
+		written := results
+		if len(s.config.AppendedResults) > 0 {
+			written = AppendResults(s.config.AppendedResults, results)
+		}
+		err := s.writeFile(s.config.OutputFile, s.config.OutputFormat, written, durable)
 		if err != nil {
-			s.Error(err.Error())
+			return fmt.Errorf("Could not write the output file %s: %s", s.config.OutputFile, err)
+		}
+		s.savedResults = len(results)
+		s.summarySaved = summary != nil
+		if durable {
+			s.snapshotTime = time.Now()
 		}
+	}
+	if summary != nil {
+		// The run has finished, create the indexes of the streamed database
+		s.streamMutex.Lock()
+		defer s.streamMutex.Unlock()
+		if s.sqlite != nil {
+			if err := s.sqlite.Close(); err != nil {
+				return fmt.Errorf("Could not write the output file %s: %s", s.sqlite.filename, err)
+			}
+		}
+	}
+	return nil
+}
 
+// SetOutputFile switches the output file (-o) to another path, and writes all of the results so far to it
+func (s *Stdoutput) SetOutputFile(filename string) error {
+	if err := s.reopenStreams(filename); err != nil {
+		return err
+	}
+	return s.Finalize()
+}
+
+//reopenStreams closes the streamed output files, and writes all of the results so far to the ones of the new output
+//file. The results coming in meanwhile wait for the new files, so that none of them gets written twice or lost.
+func (s *Stdoutput) reopenStreams(filename string) error {
+	s.streamMutex.Lock()
+	defer s.streamMutex.Unlock()
+	if s.ndjson != nil {
+		s.ndjson.file.Close()
+		s.ndjson = nil
+	}
+	if s.sqlite != nil {
+		s.sqlite.Close()
+		s.sqlite = nil
+	}
+	s.streamErr = nil
+	s.config.OutputFile = filename
+	s.savedResults = 0
+	// The streamed results are not rewritten by Finalize
+	if streamed, ok := s.streamedFile("ndjson"); ok {
+		w, err := newNDJSONWriter(streamed, s.config.OutputFsync, s.config)
+		if err != nil {
+			return err
+		}
+		s.ndjson = w
+		for _, r := range s.allResults() {
+			if err := w.Write(r); err != nil {
+				s.streamErr = err
+				return err
+			}
+		}
+	}
+	if streamed, ok := s.streamedFile("sqlite"); ok {
+		w, err := newSQLiteResultWriter(streamed, s.config.OutputFsync, s.config)
+		if err != nil {
+			return err
+		}
+		s.sqlite = w
+		for _, r := range s.allResults() {
+			w.insert(r)
+		}
+		if err := w.db.Flush(); err != nil {
+			s.streamErr = err
+			return err
+		}
 	}
-	s.Results = nil
-	s.CurrentResults = nil
-	//fmt.Fprintf(os.Stderr, "\n")
 	return nil
 }
 
 func (s *Stdoutput) Result(resp ffuf.Response) {
 	// Do we want to write request and response to a file
-	if len(s.config.OutputDirectory) > 1 {
+	if len(s.config.OutputDirectory) > 0 {
 		resp.ResultFile = s.writeResultToFile(resp)
 	}
 
-	inputs := make(map[string][]byte, len(resp.Request.Input))
-	for k, v := range resp.Request.Input {
-		inputs[k] = v
-	}
-	sResult := ffuf.Result{
-		Input:            inputs,
-		Position:         resp.Request.Position,
-		StatusCode:       resp.StatusCode,
-		ContentLength:    resp.ContentLength,
-		ContentWords:     resp.ContentWords,
-		ContentLines:     resp.ContentLines,
-		ContentType:      resp.ContentType,
-		RedirectLocation: resp.GetRedirectLocation(false),
-		Url:              resp.Request.Url,
-		Duration:         resp.Time,
-		ResultFile:       resp.ResultFile,
-		Host:             resp.Request.Host,
-	}
+	sResult := ffuf.NewResult(resp)
+	// The results are streamed in the order they are added, by concurrent goroutines of the job
+	s.streamMutex.Lock()
+	s.resultMutex.Lock()
 	s.CurrentResults = append(s.CurrentResults, sResult)
+	s.resultMutex.Unlock()
+	s.streamResult(sResult)
+	s.streamMutex.Unlock()
 	// Output the result
 	s.PrintResult(sResult)
 
 }
 
+//streamedFile returns the output file of a streamed output format, ndjson or sqlite, if the results are written to it
+func (s *Stdoutput) streamedFile(format string) (string, bool) {
+	if s.config.OutputFile == "" {
+		return "", false
+	}
+	switch s.config.OutputFormat {
+	case format:
+		return s.config.OutputFile, true
+	case "all":
+		return s.config.OutputFile + "." + format, true
+	}
+	return "", false
+}
+
+//streaming tells if the results are streamed to the output file as they come
+func (s *Stdoutput) streaming(filename string) bool {
+	s.streamMutex.Lock()
+	defer s.streamMutex.Unlock()
+	return (s.ndjson != nil && s.ndjson.filename == filename) || (s.sqlite != nil && s.sqlite.filename == filename)
+}
+
+//streamResult appends the result to the output file right away when using the ndjson or sqlite output format. The
+//errors are reported by Finalize, the results missing from the file are written once switched to another one. The
+//streamMutex must be held.
+func (s *Stdoutput) streamResult(res ffuf.Result) {
+	if filename, ok := s.streamedFile("ndjson"); ok {
+		if s.ndjson == nil {
+			w, err := newNDJSONWriter(filename, s.config.OutputFsync, s.config)
+			if err != nil {
+				s.streamErr = err
+				return
+			}
+			s.ndjson = w
+		}
+		if err := s.ndjson.Write(res); err != nil {
+			s.streamErr = err
+		}
+	}
+	if filename, ok := s.streamedFile("sqlite"); ok {
+		if s.sqlite == nil {
+			w, err := newSQLiteResultWriter(filename, s.config.OutputFsync, s.config)
+			if err != nil {
+				s.streamErr = err
+				return
+			}
+			s.sqlite = w
+		}
+		if err := s.sqlite.Write(res); err != nil {
+			s.streamErr = err
+		}
+	}
+}
+
+//writeResultToFile writes the request and response of a result to the output directory (-od), and returns the name
+//of the file. The files are numbered in the order of the results, after the ones already in the directory.
 func (s *Stdoutput) writeResultToFile(resp ffuf.Response) string {
 	var fileContent, fileName, filePath string
 	// Create directory if needed
@@ -349,24 +635,42 @@ func (s *Stdoutput) writeResultToFile(resp ffuf.Response) string {
 			}
 		}
 	}
-	fileContent = fmt.Sprintf("%s\n---- ↑ Request ---- Response ↓ ----\n\n%s", resp.Request.Raw, resp.Raw)
+	fileContent = fmt.Sprintf("%s\n%s\n\n%s", resp.Request.Raw, RESULT_FILE_SEPARATOR, resp.Raw)
 
-	// Create file name
-	fileName = fmt.Sprintf("%x", md5.Sum([]byte(fileContent)))
-
-	filePath = path.Join(s.config.OutputDirectory, fileName)
-	err := ioutil.WriteFile(filePath, []byte(fileContent), 0640)
-	if err != nil {
-		s.Error(err.Error())
+	s.resultMutex.Lock()
+	defer s.resultMutex.Unlock()
+	for {
+		s.resultFiles++
+		if used, _ := filepath.Glob(filepath.Join(s.config.OutputDirectory, fmt.Sprintf("%06d-*", s.resultFiles))); len(used) > 0 {
+			// Left by an earlier run
+			continue
+		}
+		fileName = fmt.Sprintf("%06d-%d.txt", s.resultFiles, resp.StatusCode)
+		filePath = path.Join(s.config.OutputDirectory, fileName)
+		f, err := os.OpenFile(filePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0640)
+		if err != nil {
+			s.Error(err.Error())
+			return ""
+		}
+		_, err = f.WriteString(fileContent)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			s.Error(err.Error())
+		}
+		return fileName
 	}
-	return fileName
 }
 
 func (s *Stdoutput) PrintResult(res ffuf.Result) {
 	if s.config.Quiet {
 		s.resultQuiet(res)
 	} else {
-		if len(res.Input) > 1 || s.config.Verbose || len(s.config.OutputDirectory) > 0 {
+		if s.config.GroupDirectories {
+			s.printGroupHeader(res)
+		}
+		if len(res.Input) > 1 || s.config.Verbose || len(s.config.OutputDirectory) > 0 || len(res.ScraperData) > 0 || len(res.Tags) > 0 || res.RedirectScheme != "" {
 			// Print a multi-line result (when using multiple input keywords and wordlists)
 			s.resultMultiline(res)
 		} else {
@@ -400,13 +704,68 @@ func (s *Stdoutput) prepareInputsOneLine(res ffuf.Result) string {
 }
 
 func (s *Stdoutput) resultQuiet(res ffuf.Result) {
+	if len(s.config.Columns) > 0 {
+		values := make([]string, 0, len(s.config.Columns))
+		for _, c := range s.config.Columns {
+			values = append(values, s.columnValue(res, c))
+		}
+		fmt.Println(strings.Join(values, "\t"))
+		return
+	}
 	fmt.Println(s.prepareInputsOneLine(res))
 }
 
+//columnValue returns the value of a result column of -columns
+func (s *Stdoutput) columnValue(res ffuf.Result, column string) string {
+	switch column {
+	case "input":
+		return s.prepareInputsOneLine(res)
+	case "url":
+		return res.Url
+	case "status":
+		return strconv.FormatInt(res.StatusCode, 10)
+	case "size":
+		return strconv.FormatInt(res.ContentLength, 10)
+	case "words":
+		return strconv.FormatInt(res.ContentWords, 10)
+	case "lines":
+		return strconv.FormatInt(res.ContentLines, 10)
+	case "duration":
+		return fmt.Sprintf("%dms", res.Duration.Milliseconds())
+	case "content-type":
+		return res.ContentType
+	case "redirect":
+		return res.RedirectLocation
+	}
+	return ""
+}
+
+//resultStats returns the bracketed columns of a result line, the default ones or the ones of -columns apart from
+//the input
+func (s *Stdoutput) resultStats(res ffuf.Result) string {
+	if len(s.config.Columns) == 0 {
+		return fmt.Sprintf("[Status: %d, Size: %d, Words: %d, Lines: %d, Duration: %dms]", res.StatusCode, res.ContentLength, res.ContentWords, res.ContentLines, res.Duration.Milliseconds())
+	}
+	labels := map[string]string{"url": "URL", "status": "Status", "size": "Size", "words": "Words", "lines": "Lines", "duration": "Duration", "content-type": "Content-Type", "redirect": "Redirect"}
+	stats := make([]string, 0, len(s.config.Columns))
+	for _, c := range s.config.Columns {
+		if c == "input" {
+			continue
+		}
+		stats = append(stats, fmt.Sprintf("%s: %s", labels[c], s.columnValue(res, c)))
+	}
+	return "[" + strings.Join(stats, ", ") + "]"
+}
+
+//showInput checks if the input is printed on the result line
+func (s *Stdoutput) showInput() bool {
+	return len(s.config.Columns) == 0 || inSlice("input", s.config.Columns)
+}
+
 func (s *Stdoutput) resultMultiline(res ffuf.Result) {
 	var res_hdr, res_str string
 	res_str = "%s%s    * %s: %s\n"
-	res_hdr = fmt.Sprintf("%s%s[Status: %d, Size: %d, Words: %d, Lines: %d, Duration: %dms]%s", TERMINAL_CLEAR_LINE, s.colorize(res.StatusCode), res.StatusCode, res.ContentLength, res.ContentWords, res.ContentLines, res.Duration.Milliseconds(), ANSI_CLEAR)
+	res_hdr = fmt.Sprintf("%s%s%s%s", TERMINAL_CLEAR_LINE, s.colorize(res.StatusCode), s.resultStats(res), ANSI_CLEAR)
 	reslines := ""
 	if s.config.Verbose {
 		reslines = fmt.Sprintf("%s%s| URL | %s\n", reslines, TERMINAL_CLEAR_LINE, res.Url)
@@ -415,6 +774,10 @@ func (s *Stdoutput) resultMultiline(res ffuf.Result) {
 			reslines = fmt.Sprintf("%s%s| --> | %s\n", reslines, TERMINAL_CLEAR_LINE, redirectLocation)
 		}
 	}
+	if res.RedirectScheme != "" {
+		// Redirects to other schemes are findings of their own, shown also without -v
+		reslines = fmt.Sprintf("%s%s| SCH | %s redirect: %s\n", reslines, TERMINAL_CLEAR_LINE, res.RedirectScheme, res.RedirectLocation)
+	}
 	if res.ResultFile != "" {
 		reslines = fmt.Sprintf("%s%s| RES | %s\n", reslines, TERMINAL_CLEAR_LINE, res.ResultFile)
 	}
@@ -427,11 +790,23 @@ func (s *Stdoutput) resultMultiline(res ffuf.Result) {
 			reslines = fmt.Sprintf(res_str, reslines, TERMINAL_CLEAR_LINE, k, v)
 		}
 	}
+	for _, name := range sortedKeys(res.ScraperData) {
+		for _, v := range res.ScraperData[name] {
+			reslines = fmt.Sprintf("%s%s| SCR | %s: %s\n", reslines, TERMINAL_CLEAR_LINE, name, v)
+		}
+	}
+	if len(res.Tags) > 0 {
+		reslines = fmt.Sprintf("%s%s| TAG | %s\n", reslines, TERMINAL_CLEAR_LINE, strings.Join(res.Tags, ", "))
+	}
 	fmt.Printf("%s\n%s\n", res_hdr, reslines)
 }
 
 func (s *Stdoutput) resultNormal(res ffuf.Result) {
-	resnormal := fmt.Sprintf("%s%s%-23s [Status: %d, Size: %d, Words: %d, Lines: %d, Duration: %dms]%s", TERMINAL_CLEAR_LINE, s.colorize(res.StatusCode), s.prepareInputsOneLine(res), res.StatusCode, res.ContentLength, res.ContentWords, res.ContentLines, res.Duration.Milliseconds(), ANSI_CLEAR)
+	if !s.showInput() {
+		fmt.Printf("%s%s%s%s\n", TERMINAL_CLEAR_LINE, s.colorize(res.StatusCode), s.resultStats(res), ANSI_CLEAR)
+		return
+	}
+	resnormal := fmt.Sprintf("%s%s%-23s %s%s", TERMINAL_CLEAR_LINE, s.colorize(res.StatusCode), s.prepareInputsOneLine(res), s.resultStats(res), ANSI_CLEAR)
 	fmt.Println(resnormal)
 }
 
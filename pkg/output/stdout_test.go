@@ -0,0 +1,41 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//sendTestResults hands count results to the output from concurrent goroutines, the way the threads of a job do, and
+//returns the URLs of the results
+func sendTestResults(t *testing.T, s *Stdoutput, count int) map[string]bool {
+	t.Helper()
+	// The results are printed to the terminal as well
+	devnull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("Could not open %s: %s", os.DevNull, err)
+	}
+	stdout := os.Stdout
+	os.Stdout = devnull
+	defer func() {
+		os.Stdout = stdout
+		devnull.Close()
+	}()
+	urls := make(map[string]bool, count)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		url := fmt.Sprintf("http://localhost/word%d", i)
+		urls[url] = true
+		req := ffuf.Request{Url: url, Position: i + 1, Input: map[string][]byte{"FUZZ": []byte(fmt.Sprintf("word%d", i))}}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Result(ffuf.Response{StatusCode: 200, Request: &req})
+		}()
+	}
+	wg.Wait()
+	return urls
+}
@@ -0,0 +1,72 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//StatusCount is the number of responses of a status code
+type StatusCount struct {
+	Status    int64
+	Responses int
+}
+
+//summaryOutput is the summary of the run for the html and markdown templates
+type summaryOutput struct {
+	ffuf.Summary
+	Statuses      []StatusCount
+	ErrorsByClass string
+}
+
+//newSummaryOutput returns the summary for the templates, or nil while the run has not finished
+func newSummaryOutput(summary *ffuf.Summary) *summaryOutput {
+	if summary == nil {
+		return nil
+	}
+	return &summaryOutput{Summary: *summary, Statuses: summaryStatuses(*summary), ErrorsByClass: summaryErrors(*summary)}
+}
+
+//summaryStatuses returns the status code histogram of the summary in the order of the status codes
+func summaryStatuses(summary ffuf.Summary) []StatusCount {
+	statuses := make([]StatusCount, 0, len(summary.StatusCodes))
+	for status, n := range summary.StatusCodes {
+		statuses = append(statuses, StatusCount{Status: status, Responses: n})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Status < statuses[j].Status })
+	return statuses
+}
+
+//summaryErrors returns the error counts of the summary by class, eg. "dns: 3, timeout: 2"
+func summaryErrors(summary ffuf.Summary) string {
+	return strings.Trim(errorClassesRepr(summary.ErrorClasses), " ()")
+}
+
+//SummaryReport returns the summary of the run as a printable table
+func SummaryReport(summary ffuf.Summary) string {
+	var b strings.Builder
+	b.WriteString("Summary:\n")
+	fmt.Fprintf(&b, " :: Requests       : %d in %s (%.1f req/sec)\n", summary.Requests, time.Duration(summary.Duration*float64(time.Second)).Round(time.Millisecond), summary.RequestsPerSecond)
+	fmt.Fprintf(&b, " :: Jobs           : %d\n", summary.Jobs)
+	fmt.Fprintf(&b, " :: Matches        : %d\n", summary.Matches)
+	fmt.Fprintf(&b, " :: Errors         : %d", summary.Errors)
+	if e := summaryErrors(summary); e != "" {
+		fmt.Fprintf(&b, " (%s)", e)
+	}
+	b.WriteString("\n")
+	statuses := make([]string, 0, len(summary.StatusCodes))
+	for _, s := range summaryStatuses(summary) {
+		statuses = append(statuses, fmt.Sprintf("%d: %d", s.Status, s.Responses))
+	}
+	fmt.Fprintf(&b, " :: Status codes   : %s\n", strings.Join(statuses, ", "))
+	lengths := make([]string, 0, len(summary.TopLengths))
+	for _, l := range summary.TopLengths {
+		lengths = append(lengths, fmt.Sprintf("%d: %d", l.Length, l.Responses))
+	}
+	fmt.Fprintf(&b, " :: Top lengths    : %s\n", strings.Join(lengths, ", "))
+	fmt.Fprintf(&b, " :: Stop reason    : %s\n", summary.StopReason)
+	return b.String()
+}
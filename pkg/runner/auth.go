@@ -0,0 +1,332 @@
+package runner
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//authTransport authenticates the requests with the NTLM, Negotiate or Digest HTTP authentication schemes (-auth).
+//NTLM and Negotiate authenticate a connection instead of a single request, so the requests are sent through
+//transports holding a single connection each, and the handshake is made whenever the connection is challenged.
+type authTransport struct {
+	scheme   string
+	user     string
+	password string
+	domain   string
+	base     *http.Transport
+	free     chan *http.Transport
+	// Origin IP hunting (-origin-ips) connects to a different origin per request
+	origins  bool
+	mutex    sync.Mutex
+	digest   *digestChallenge
+}
+
+func newAuthTransport(conf *ffuf.Config, base *http.Transport) *authTransport {
+	return &authTransport{
+		scheme:   conf.AuthScheme,
+		user:     conf.AuthUser,
+		password: conf.AuthPassword,
+		domain:   conf.AuthDomain,
+		base:     base,
+		free:     make(chan *http.Transport, conf.Threads),
+		origins:  len(conf.OriginIPs) > 0,
+	}
+}
+
+func (a *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if a.scheme == ffuf.AUTH_DIGEST {
+		return a.roundTripDigest(req)
+	}
+	return a.roundTripNTLM(req)
+}
+
+//headerScheme returns the name of the scheme in the authentication headers
+func (a *authTransport) headerScheme() string {
+	if a.scheme == ffuf.AUTH_NEGOTIATE {
+		return "Negotiate"
+	}
+	return "NTLM"
+}
+
+func (a *authTransport) roundTripNTLM(req *http.Request) (*http.Response, error) {
+	t := a.connTransport()
+	resp, err := t.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || authChallenge(resp, a.headerScheme()) == nil {
+		return a.releaseOnClose(t, resp, err)
+	}
+	// The connection is not authenticated yet
+	negotiate, err := retryRequest(req)
+	if err != nil {
+		return a.releaseOnClose(t, resp, nil)
+	}
+	drainBody(resp)
+	negotiate.Header.Set("Authorization", a.headerScheme()+" "+base64.StdEncoding.EncodeToString(ntlmNegotiateMessage()))
+	resp, err = t.RoundTrip(negotiate)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return a.releaseOnClose(t, resp, err)
+	}
+	token, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(authChallenge(resp, a.headerScheme()))))
+	if err != nil || len(token) == 0 {
+		return a.releaseOnClose(t, resp, nil)
+	}
+	msg, err := ntlmAuthenticateMessage(token, a.user, a.password, a.domain)
+	if err != nil {
+		return a.releaseOnClose(t, resp, nil)
+	}
+	authenticate, _ := retryRequest(req)
+	drainBody(resp)
+	authenticate.Header.Set("Authorization", a.headerScheme()+" "+base64.StdEncoding.EncodeToString(msg))
+	resp, err = t.RoundTrip(authenticate)
+	return a.releaseOnClose(t, resp, err)
+}
+
+//connTransport returns an idle single connection transport, or a new one if all of them are in use
+func (a *authTransport) connTransport() *http.Transport {
+	select {
+	case t := <-a.free:
+		return t
+	default:
+	}
+	t := a.base.Clone()
+	// The connection is kept for the handshake, but never reused for another origin
+	t.DisableKeepAlives = a.origins
+	t.MaxConnsPerHost = 1
+	t.MaxIdleConnsPerHost = 1
+	return t
+}
+
+//releaseOnClose returns the transport to the idle ones once the response body has been read, as its connection is
+//in use until then
+func (a *authTransport) releaseOnClose(t *http.Transport, resp *http.Response, err error) (*http.Response, error) {
+	release := func() {
+		select {
+		case a.free <- t:
+		default:
+			t.CloseIdleConnections()
+		}
+	}
+	if err != nil {
+		release()
+		return resp, err
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+func (a *authTransport) roundTripDigest(req *http.Request) (*http.Response, error) {
+	a.mutex.Lock()
+	challenge := a.digest
+	a.mutex.Unlock()
+	if challenge != nil {
+		// Answer the latest challenge right away, saving a round trip for every request
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", challenge.authorization(req, a.user, a.password))
+	}
+	resp, err := a.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	params := authChallenge(resp, "Digest")
+	if params == nil {
+		return resp, nil
+	}
+	challenge, err = parseDigestChallenge(string(params))
+	if err != nil {
+		return resp, nil
+	}
+	retry, err := retryRequest(req)
+	if err != nil {
+		return resp, nil
+	}
+	drainBody(resp)
+	a.mutex.Lock()
+	a.digest = challenge
+	a.mutex.Unlock()
+	retry.Header.Set("Authorization", challenge.authorization(retry, a.user, a.password))
+	return a.base.RoundTrip(retry)
+}
+
+//digestChallenge is a Digest challenge of the server (RFC 7616), answered by the following requests with an
+//increasing nonce count
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+	mutex     sync.Mutex
+	count     int
+}
+
+func parseDigestChallenge(params string) (*digestChallenge, error) {
+	c := &digestChallenge{algorithm: "MD5"}
+	for k, v := range authParams(params) {
+		switch k {
+		case "realm":
+			c.realm = v
+		case "nonce":
+			c.nonce = v
+		case "opaque":
+			c.opaque = v
+		case "algorithm":
+			c.algorithm = strings.ToUpper(v)
+		case "qop":
+			for _, q := range strings.Split(v, ",") {
+				if strings.TrimSpace(q) == "auth" {
+					c.qop = "auth"
+				}
+			}
+		}
+	}
+	if c.nonce == "" {
+		return nil, fmt.Errorf("digest challenge without a nonce")
+	}
+	switch c.algorithm {
+	case "MD5", "MD5-SESS", "SHA-256", "SHA-256-SESS":
+	default:
+		return nil, fmt.Errorf("unsupported digest algorithm %s", c.algorithm)
+	}
+	return c, nil
+}
+
+//authorization returns the Authorization header value answering the challenge for the request
+func (c *digestChallenge) authorization(req *http.Request, user, password string) string {
+	c.mutex.Lock()
+	c.count++
+	nc := fmt.Sprintf("%08x", c.count)
+	c.mutex.Unlock()
+	cnonceBytes := make([]byte, 16)
+	_, _ = rand.Read(cnonceBytes)
+	cnonce := hex.EncodeToString(cnonceBytes)
+
+	var h func() hash.Hash = md5.New
+	if strings.HasPrefix(c.algorithm, "SHA-256") {
+		h = sha256.New
+	}
+	digest := func(s string) string {
+		d := h()
+		d.Write([]byte(s))
+		return hex.EncodeToString(d.Sum(nil))
+	}
+	ha1 := digest(user + ":" + c.realm + ":" + password)
+	if strings.HasSuffix(c.algorithm, "-SESS") {
+		ha1 = digest(ha1 + ":" + c.nonce + ":" + cnonce)
+	}
+	uri := req.URL.RequestURI()
+	ha2 := digest(req.Method + ":" + uri)
+	var response string
+	if c.qop == "" {
+		response = digest(ha1 + ":" + c.nonce + ":" + ha2)
+	} else {
+		response = digest(ha1 + ":" + c.nonce + ":" + nc + ":" + cnonce + ":" + c.qop + ":" + ha2)
+	}
+
+	auth := fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", uri="%s", algorithm=%s, response="%s"`, user, c.realm, c.nonce, uri, c.algorithm, response)
+	if c.opaque != "" {
+		auth += fmt.Sprintf(`, opaque="%s"`, c.opaque)
+	}
+	if c.qop != "" {
+		auth += fmt.Sprintf(`, qop=%s, nc=%s, cnonce="%s"`, c.qop, nc, cnonce)
+	}
+	return auth
+}
+
+//authChallenge returns the parameters of the WWW-Authenticate challenge of the scheme, or nil if the scheme is not
+//offered. The parameters of a scheme without any are empty.
+func authChallenge(resp *http.Response, scheme string) []byte {
+	for _, v := range resp.Header.Values("Www-Authenticate") {
+		v = strings.TrimSpace(v)
+		if strings.EqualFold(v, scheme) {
+			return []byte{}
+		}
+		if len(v) > len(scheme) && strings.EqualFold(v[:len(scheme)], scheme) && v[len(scheme)] == ' ' {
+			return []byte(strings.TrimSpace(v[len(scheme):]))
+		}
+	}
+	return nil
+}
+
+//authParams parses the comma separated name=value parameters of a challenge, the values possibly quoted
+func authParams(s string) map[string]string {
+	params := make(map[string]string)
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " ,")
+		eq := strings.Index(s, "=")
+		if eq < 0 {
+			break
+		}
+		name := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimLeft(s[eq+1:], " ")
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				b.WriteByte(s[i])
+			}
+			value = b.String()
+			if i < len(s) {
+				i++
+			}
+			s = s[i:]
+		} else {
+			end := strings.Index(s, ",")
+			if end < 0 {
+				end = len(s)
+			}
+			value = strings.TrimSpace(s[:end])
+			s = s[end:]
+		}
+		params[name] = value
+	}
+	return params
+}
+
+//retryRequest returns a copy of the request to be sent again, with a fresh body
+func retryRequest(req *http.Request) (*http.Request, error) {
+	retry := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, fmt.Errorf("request body cannot be sent again")
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	return retry, nil
+}
+
+//drainBody reads the rest of a response body, so that its connection can be reused
+func drainBody(resp *http.Response) {
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, MAX_DOWNLOAD_SIZE))
+	resp.Body.Close()
+}
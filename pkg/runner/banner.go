@@ -0,0 +1,190 @@
+package runner
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+const (
+	// Status codes of the banner grabs, to keep the status matchers and filters meaningful
+	BANNER_STATUS_BANNER  = 200
+	BANNER_STATUS_SILENT  = 204
+	BANNER_STATUS_REFUSED = 404
+)
+
+//BANNER_READ_GRACE is how long the banner is read for after its first bytes, for the banners sent in several parts
+const BANNER_READ_GRACE = 200 * time.Millisecond
+
+//bannerPorts are the default ports of the banner grab schemes, tcp:// URLs need the port
+var bannerPorts = map[string]string{
+	"ftp": "21",
+	"ssh": "22",
+	"smb": "445",
+}
+
+//BannerRunner connects to the tcp://, ftp://, ssh:// and smb:// target URLs, eg. tcp://host:FUZZ, sends the probe of
+//the protocol or the request data if defined, and returns the banner the service answers with. The time of the
+//response is the connect latency.
+type BannerRunner struct {
+	config *ffuf.Config
+}
+
+func NewBannerRunner(conf *ffuf.Config) ffuf.RunnerProvider {
+	return &BannerRunner{config: conf}
+}
+
+func (r *BannerRunner) Prepare(input map[string][]byte) (ffuf.Request, error) {
+	req := prepareRequest(r.config, input)
+	req.Method = "BANNER"
+	if i := strings.Index(req.Url, "://"); i > 0 {
+		req.Method = strings.ToUpper(req.Url[:i])
+	}
+	return req, nil
+}
+
+func (r *BannerRunner) Execute(req *ffuf.Request) (ffuf.Response, error) {
+	var resp ffuf.Response
+	resp.Request = req
+	resp.Headers = make(map[string][]string)
+	timeout := time.Duration(r.config.Timeout) * time.Second
+
+	u, err := url.Parse(req.Url)
+	if err != nil {
+		return resp, err
+	}
+	scheme := strings.ToLower(u.Scheme)
+	addr := u.Host
+	if u.Port() == "" {
+		port, ok := bannerPorts[scheme]
+		if !ok {
+			return resp, fmt.Errorf("no port to connect to in %s", req.Url)
+		}
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+	req.Host = addr
+	resp.Headers["Protocol"] = []string{scheme}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	start := time.Now()
+	conn, err := dialTarget(r.config.Context, dialer, r.config, "tcp", addr)
+	if err != nil {
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			resp.Time = time.Since(start)
+			resp.StatusCode = BANNER_STATUS_REFUSED
+			return resp, nil
+		}
+		return resp, err
+	}
+	resp.Time = time.Since(start)
+	if r.config.Pcap != nil {
+		conn = r.config.Pcap.Wrap(conn)
+	}
+	defer conn.Close()
+
+	probe := req.Data
+	if len(probe) == 0 && scheme == "smb" {
+		probe = smbNegotiateRequest()
+	}
+	if len(probe) > 0 {
+		_ = conn.SetWriteDeadline(time.Now().Add(timeout))
+		if _, err := conn.Write(probe); err != nil {
+			return resp, err
+		}
+	}
+	banner := readBanner(conn, timeout, r.config.MaxBodySize)
+	if scheme == "smb" && len(req.Data) == 0 {
+		if dialect := smbDialect(banner); dialect != "" {
+			resp.Headers["Smb-Dialect"] = []string{dialect}
+			banner = []byte("SMB " + dialect)
+		}
+	}
+
+	resp.StatusCode = BANNER_STATUS_BANNER
+	if len(banner) == 0 {
+		resp.StatusCode = BANNER_STATUS_SILENT
+	}
+	resp.Data = banner
+	resp.ContentLength = int64(len(resp.Data))
+	resp.ContentWords = int64(len(strings.Split(string(resp.Data), " ")))
+	resp.ContentLines = int64(len(strings.Split(string(resp.Data), "\n")))
+	if len(r.config.OutputDirectory) > 0 {
+		req.Raw = fmt.Sprintf("%s %s\r\n\r\n%s", req.Method, addr, probe)
+		resp.Raw = string(resp.Data)
+	}
+	return resp, nil
+}
+
+//readBanner reads what the service sends within the timeout, and for a short while after its first bytes
+func readBanner(conn net.Conn, timeout time.Duration, limit int64) []byte {
+	banner := make([]byte, 0)
+	buf := make([]byte, 4096)
+	_ = conn.SetReadDeadline(time.Now().Add(timeout))
+	for int64(len(banner)) < limit {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			if len(banner) == 0 {
+				_ = conn.SetReadDeadline(time.Now().Add(BANNER_READ_GRACE))
+			}
+			banner = append(banner, buf[:n]...)
+		}
+		if err != nil {
+			break
+		}
+	}
+	if int64(len(banner)) > limit {
+		banner = banner[:limit]
+	}
+	return banner
+}
+
+//smbNegotiateRequest returns an SMB2 NEGOTIATE request for the SMB 2.0.2 to 3.0.2 dialects, in a NetBIOS session
+//message
+func smbNegotiateRequest() []byte {
+	dialects := []uint16{0x0202, 0x0210, 0x0300, 0x0302}
+	msg := make([]byte, 64+36+2*len(dialects))
+	// SMB2 header, the NEGOTIATE command is 0
+	copy(msg, "\xfeSMB")
+	binary.LittleEndian.PutUint16(msg[4:], 64)
+	binary.LittleEndian.PutUint16(msg[14:], 1)
+	// NEGOTIATE request
+	binary.LittleEndian.PutUint16(msg[64:], 36)
+	binary.LittleEndian.PutUint16(msg[66:], uint16(len(dialects)))
+	binary.LittleEndian.PutUint16(msg[68:], 1)
+	for i, d := range dialects {
+		binary.LittleEndian.PutUint16(msg[100+2*i:], d)
+	}
+	session := make([]byte, 4, 4+len(msg))
+	binary.BigEndian.PutUint32(session, uint32(len(msg)))
+	return append(session, msg...)
+}
+
+//smbDialect returns the dialect of the SMB2 NEGOTIATE response, or "1" for an SMB1 response
+func smbDialect(resp []byte) string {
+	if len(resp) < 8 {
+		return ""
+	}
+	msg := resp[4:]
+	if strings.HasPrefix(string(msg), "\xffSMB") {
+		return "1"
+	}
+	if !strings.HasPrefix(string(msg), "\xfeSMB") || len(msg) < 64+6 {
+		return ""
+	}
+	dialect := binary.LittleEndian.Uint16(msg[68:])
+	if dialect == 0x02ff {
+		return "2.???"
+	}
+	name := fmt.Sprintf("%d.%d", dialect>>8, (dialect>>4)&0xf)
+	if dialect&0xf != 0 {
+		name += fmt.Sprintf(".%d", dialect&0xf)
+	}
+	return name
+}
@@ -0,0 +1,84 @@
+package runner
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+//smbResponse returns an SMB2 NEGOTIATE response of the dialect in a NetBIOS session message
+func smbResponse(dialect uint16) []byte {
+	msg := make([]byte, 64+65)
+	copy(msg, "\xfeSMB")
+	binary.LittleEndian.PutUint16(msg[4:], 64)
+	binary.LittleEndian.PutUint16(msg[64:], 65)
+	binary.LittleEndian.PutUint16(msg[68:], dialect)
+	session := make([]byte, 4, 4+len(msg))
+	binary.BigEndian.PutUint32(session, uint32(len(msg)))
+	return append(session, msg...)
+}
+
+func TestSMBDialect(t *testing.T) {
+	tests := []struct {
+		name     string
+		resp     []byte
+		expected string
+	}{
+		{"SMB 2.0.2", smbResponse(0x0202), "2.0.2"},
+		{"SMB 2.1", smbResponse(0x0210), "2.1"},
+		{"SMB 3.0", smbResponse(0x0300), "3.0"},
+		{"SMB 3.0.2", smbResponse(0x0302), "3.0.2"},
+		{"SMB 3.1.1", smbResponse(0x0311), "3.1.1"},
+		{"SMB2 wildcard", smbResponse(0x02ff), "2.???"},
+		{"SMB1", []byte("\x00\x00\x00\x23\xffSMBr\x00\x00\x00\x00"), "1"},
+		{"truncated SMB2 response", smbResponse(0x0302)[:4+64+4], ""},
+		{"too short", []byte("\x00\x00\x00"), ""},
+		{"not SMB", []byte("SSH-2.0-OpenSSH_9.6\r\n"), ""},
+		{"empty", []byte{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := smbDialect(tt.resp); got != tt.expected {
+				t.Errorf("Expected dialect %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSMBNegotiateRequest(t *testing.T) {
+	req := smbNegotiateRequest()
+	if len(req) < 4 {
+		t.Fatalf("Request of %d bytes is too short for the NetBIOS session header", len(req))
+	}
+	msg := req[4:]
+	if length := binary.BigEndian.Uint32(req); int(length) != len(msg) {
+		t.Errorf("Expected the session message length %d, got %d", len(msg), length)
+	}
+	tests := []struct {
+		name     string
+		offset   int
+		expected uint16
+	}{
+		{"header structure size", 4, 64},
+		{"credit charge", 6, 0},
+		{"command", 12, 0},
+		{"credits requested", 14, 1},
+		{"negotiate structure size", 64, 36},
+		{"dialect count", 66, 4},
+		{"security mode", 68, 1},
+		{"dialect 2.0.2", 100, 0x0202},
+		{"dialect 2.1", 102, 0x0210},
+		{"dialect 3.0", 104, 0x0300},
+		{"dialect 3.0.2", 106, 0x0302},
+	}
+	if string(msg[:4]) != "\xfeSMB" {
+		t.Errorf("Expected the SMB2 protocol id, got %q", msg[:4])
+	}
+	if len(msg) != 108 {
+		t.Fatalf("Expected a message of 108 bytes, got %d", len(msg))
+	}
+	for _, tt := range tests {
+		if got := binary.LittleEndian.Uint16(msg[tt.offset:]); got != tt.expected {
+			t.Errorf("%s: expected %#04x, got %#04x", tt.name, tt.expected, got)
+		}
+	}
+}
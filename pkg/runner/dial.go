@@ -0,0 +1,33 @@
+package runner
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//dialTarget opens the connection to addr, or to the Unix domain socket of -unix in its place. The fixed addresses
+//of -resolve replace the hostname of addr, keeping its port.
+func dialTarget(ctx context.Context, dialer *net.Dialer, conf *ffuf.Config, network, addr string) (net.Conn, error) {
+	if len(conf.UnixSocket) > 0 {
+		return dialer.DialContext(ctx, "unix", conf.UnixSocket)
+	}
+	return dialer.DialContext(ctx, network, resolveAddr(conf, addr))
+}
+
+//resolveAddr replaces the hostname of the host:port address with its -resolve address, if defined
+func resolveAddr(conf *ffuf.Config, addr string) string {
+	if len(conf.Resolve) == 0 {
+		return addr
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	if ip, ok := conf.Resolve[strings.ToLower(host)]; ok {
+		return net.JoinHostPort(ip, port)
+	}
+	return addr
+}
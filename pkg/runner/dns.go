@@ -0,0 +1,126 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+const (
+	// Status codes the DNS response codes are mapped to, to keep the status matchers and filters meaningful
+	DNS_STATUS_NOERROR  = 200
+	DNS_STATUS_NODATA   = 204
+	DNS_STATUS_NXDOMAIN = 404
+)
+
+type DNSRunner struct {
+	config   *ffuf.Config
+	resolver *net.Resolver
+	next     uint32
+}
+
+func NewDNSRunner(conf *ffuf.Config) ffuf.RunnerProvider {
+	var dnsrunner DNSRunner
+	dnsrunner.config = conf
+	dnsrunner.resolver = net.DefaultResolver
+	if len(conf.Resolvers) > 0 {
+		dialer := &net.Dialer{Timeout: time.Duration(conf.Timeout) * time.Second}
+		dnsrunner.resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				// Round robin through the configured resolvers
+				n := atomic.AddUint32(&dnsrunner.next, 1)
+				return dialer.DialContext(ctx, network, conf.Resolvers[int(n)%len(conf.Resolvers)])
+			},
+		}
+	}
+	return &dnsrunner
+}
+
+func (r *DNSRunner) Prepare(input map[string][]byte) (ffuf.Request, error) {
+	req := ffuf.NewRequest(r.config)
+	req.Method = "DNS"
+	req.Url = keywordReplacer(input).Replace(req.Url)
+	req.Input = input
+	return req, nil
+}
+
+func (r *DNSRunner) Execute(req *ffuf.Request) (ffuf.Response, error) {
+	var resp ffuf.Response
+	resp.Request = req
+	resp.Headers = make(map[string][]string)
+
+	u, err := url.Parse(req.Url)
+	if err != nil {
+		return resp, err
+	}
+	req.Host = u.Hostname()
+	if len(req.Host) == 0 {
+		return resp, fmt.Errorf("no hostname to resolve in %s", req.Url)
+	}
+
+	ctx, cancel := context.WithTimeout(r.config.Context, time.Duration(r.config.Timeout)*time.Second)
+	defer cancel()
+	start := time.Now()
+
+	records := make([]string, 0)
+	cname, err := r.resolver.LookupCNAME(ctx, req.Host)
+	if err != nil {
+		if isNotFound(err) {
+			return r.nxdomain(resp, start), nil
+		}
+		return resp, err
+	}
+	if cname != "" && strings.TrimSuffix(cname, ".") != strings.TrimSuffix(req.Host, ".") {
+		resp.Headers["CNAME"] = []string{cname}
+		records = append(records, "CNAME "+cname)
+	}
+	addrs, err := r.resolver.LookupIPAddr(ctx, req.Host)
+	if err != nil && !isNotFound(err) {
+		return resp, err
+	}
+	for _, addr := range addrs {
+		rtype := "AAAA"
+		if addr.IP.To4() != nil {
+			rtype = "A"
+		}
+		resp.Headers[rtype] = append(resp.Headers[rtype], addr.IP.String())
+		records = append(records, rtype+" "+addr.IP.String())
+	}
+	resp.Time = time.Since(start)
+
+	resp.Headers["Rcode"] = []string{"NOERROR"}
+	if len(records) == 0 {
+		resp.StatusCode = DNS_STATUS_NODATA
+	} else {
+		resp.StatusCode = DNS_STATUS_NOERROR
+	}
+	resp.Data = []byte(strings.Join(records, "\n"))
+	resp.ContentLength = int64(len(resp.Data))
+	resp.ContentWords = int64(len(strings.Split(string(resp.Data), " ")))
+	resp.ContentLines = int64(len(strings.Split(string(resp.Data), "\n")))
+	return resp, nil
+}
+
+//nxdomain populates the response for a name that does not exist
+func (r *DNSRunner) nxdomain(resp ffuf.Response, start time.Time) ffuf.Response {
+	resp.Time = time.Since(start)
+	resp.StatusCode = DNS_STATUS_NXDOMAIN
+	resp.Headers["Rcode"] = []string{"NXDOMAIN"}
+	return resp
+}
+
+func isNotFound(err error) bool {
+	var dnserr *net.DNSError
+	if errors.As(err, &dnserr) {
+		return dnserr.IsNotFound
+	}
+	return false
+}
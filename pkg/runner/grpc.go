@@ -0,0 +1,317 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+const (
+	// gRPC status codes of the HTTP responses without a grpc-status, mapped like the gRPC clients do
+	GRPC_STATUS_UNKNOWN           = 2
+	GRPC_STATUS_PERMISSION_DENIED = 7
+	GRPC_STATUS_UNIMPLEMENTED     = 12
+	GRPC_STATUS_INTERNAL          = 13
+	GRPC_STATUS_UNAVAILABLE       = 14
+	GRPC_STATUS_UNAUTHENTICATED   = 16
+)
+
+//grpcReflectionMethods are the methods of the server reflection service, the current one first
+var grpcReflectionMethods = []string{
+	"grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+	"grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+}
+
+//GRPCRunner calls the gRPC methods of grpc:// (plaintext HTTP/2) and grpcs:// (TLS) target URLs, eg.
+//grpc://host:50051/package.Service/FUZZ. The request message is built from the request data, and the gRPC status
+//code is reported as the status of the response.
+type GRPCRunner struct {
+	config *ffuf.Config
+	client *http.Client
+}
+
+//grpcCall is the outcome of a gRPC call
+type grpcCall struct {
+	httpresp *http.Response
+	messages [][]byte
+	status   int64
+	message  string
+}
+
+func NewGRPCRunner(conf *ffuf.Config) ffuf.RunnerProvider {
+	timeout := time.Duration(conf.Timeout) * time.Second
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: timeout}
+	// The TLS options are validated when parsing the configuration
+	tlsConf, err := ffuf.NewTLSConfig(conf)
+	if err != nil {
+		log.Printf("%s", err)
+	}
+	// HTTP/2 only, without upgrade for the plaintext targets
+	var protocols http.Protocols
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+	transport := &http.Transport{
+		Protocols:           &protocols,
+		MaxIdleConnsPerHost: conf.MaxIdleConns,
+		DisableKeepAlives:   !conf.KeepAlive,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialTarget(ctx, dialer, conf, network, addr)
+			if err == nil && conf.Pcap != nil {
+				conn = conf.Pcap.Wrap(conn)
+			}
+			return conn, err
+		},
+		TLSHandshakeTimeout: timeout,
+		TLSClientConfig:     tlsConf,
+	}
+	return &GRPCRunner{config: conf, client: &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse },
+		Timeout:       timeout,
+		Transport:     transport,
+	}}
+}
+
+func (r *GRPCRunner) Prepare(input map[string][]byte) (ffuf.Request, error) {
+	req := prepareRequest(r.config, input)
+	req.Method = "POST"
+	return req, nil
+}
+
+//Execute calls the gRPC method of the request URL with the message of the request data. The response messages are
+//rendered as text, one field per line, and the grpc-status and grpc-message trailers are added to the headers.
+func (r *GRPCRunner) Execute(req *ffuf.Request) (ffuf.Response, error) {
+	var resp ffuf.Response
+	resp.Request = req
+	message, err := grpcRequestMessage(req.Data)
+	if err != nil {
+		return resp, err
+	}
+	start := time.Now()
+	call, err := r.invoke(req.Url, req.Headers, message)
+	if err != nil {
+		return resp, err
+	}
+	resp.Time = time.Since(start)
+	req.Host = call.httpresp.Request.URL.Host
+	resp.Proto = call.httpresp.Proto
+	resp.StatusCode = call.status
+	resp.ContentType = call.httpresp.Header.Get("Content-Type")
+	resp.Headers = make(map[string][]string, len(call.httpresp.Header)+len(call.httpresp.Trailer))
+	for _, h := range []http.Header{call.httpresp.Header, call.httpresp.Trailer} {
+		for k, v := range h {
+			resp.Headers[k] = v
+		}
+	}
+	resp.Headers["Grpc-Status"] = []string{strconv.FormatInt(call.status, 10)}
+	if call.message != "" {
+		resp.Headers["Grpc-Message"] = []string{call.message}
+	}
+	texts := make([]string, 0, len(call.messages))
+	for _, m := range call.messages {
+		texts = append(texts, protoText(m, ""))
+	}
+	resp.Data = []byte(strings.Join(texts, "\n---\n"))
+	resp.ContentLength = int64(len(resp.Data))
+	resp.ContentWords = int64(len(strings.Split(string(resp.Data), " ")))
+	resp.ContentLines = int64(len(strings.Split(string(resp.Data), "\n")))
+	if len(r.config.OutputDirectory) > 0 {
+		req.Raw = fmt.Sprintf("POST %s\r\n\r\n%s", req.Url, protoText(message, ""))
+		resp.Raw = fmt.Sprintf("grpc-status: %d\r\ngrpc-message: %s\r\n\r\n%s", call.status, call.message, resp.Data)
+	}
+	return resp, nil
+}
+
+//invoke sends a single message to the gRPC method of the target URL, and reads the response messages and status
+func (r *GRPCRunner) invoke(target string, headers map[string]string, message []byte) (grpcCall, error) {
+	var call grpcCall
+	u, err := grpcHTTPURL(target)
+	if err != nil {
+		return call, err
+	}
+	frame := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	frame = append(frame, message...)
+	httpreq, err := http.NewRequestWithContext(r.config.Context, "POST", u, bytes.NewReader(frame))
+	if err != nil {
+		return call, err
+	}
+	for k, v := range headers {
+		// The headers are sent as the request metadata
+		httpreq.Header.Set(k, v)
+	}
+	httpreq.Header.Set("Content-Type", "application/grpc")
+	httpreq.Header.Set("Te", "trailers")
+	httpresp, err := r.client.Do(httpreq)
+	if err != nil {
+		return call, err
+	}
+	defer httpresp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(httpresp.Body, r.config.MaxBodySize))
+	if err != nil {
+		return call, err
+	}
+	call.httpresp = httpresp
+	call.messages = grpcMessages(body)
+	call.status, call.message = grpcStatus(httpresp)
+	return call, nil
+}
+
+//grpcHTTPURL returns the HTTP/2 URL of a grpc:// or grpcs:// target URL
+func grpcHTTPURL(target string) (string, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", err
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "grpc":
+		u.Scheme = "http"
+	case "grpcs":
+		u.Scheme = "https"
+	default:
+		return "", fmt.Errorf("not a grpc:// or grpcs:// URL: %s", target)
+	}
+	return u.String(), nil
+}
+
+//grpcRequestMessage encodes the request data, FIELD=VALUE pairs separated by &, as a protobuf message of string
+//fields, eg. 1=FUZZ&2=admin
+func grpcRequestMessage(data []byte) ([]byte, error) {
+	message := make([]byte, 0, len(data))
+	if len(data) == 0 {
+		return message, nil
+	}
+	for _, pair := range strings.Split(string(data), "&") {
+		parts := strings.SplitN(pair, "=", 2)
+		number, err := strconv.Atoi(parts[0])
+		if err != nil || len(parts) != 2 || number < 1 || number > 536870911 {
+			return message, fmt.Errorf("invalid gRPC message field %s, expected FIELD=VALUE with the field number", pair)
+		}
+		message = protoAppendBytes(message, number, []byte(parts[1]))
+	}
+	return message, nil
+}
+
+//grpcMessages splits the length prefixed messages of a gRPC response body
+func grpcMessages(body []byte) [][]byte {
+	messages := make([][]byte, 0)
+	for len(body) >= 5 {
+		length := binary.BigEndian.Uint32(body[1:5])
+		if uint64(len(body)-5) < uint64(length) {
+			break
+		}
+		messages = append(messages, body[5:5+length])
+		body = body[5+length:]
+	}
+	return messages
+}
+
+//grpcStatus returns the status code and message of the call, from the trailers or the headers of a trailers-only
+//response, or mapped from the HTTP status if the server did not send a gRPC status
+func grpcStatus(httpresp *http.Response) (int64, string) {
+	value := httpresp.Trailer.Get("Grpc-Status")
+	message := httpresp.Trailer.Get("Grpc-Message")
+	if value == "" {
+		value = httpresp.Header.Get("Grpc-Status")
+		message = httpresp.Header.Get("Grpc-Message")
+	}
+	if unescaped, err := url.PathUnescape(message); err == nil {
+		message = unescaped
+	}
+	if status, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return status, message
+	}
+	switch httpresp.StatusCode {
+	case http.StatusBadRequest:
+		return GRPC_STATUS_INTERNAL, message
+	case http.StatusUnauthorized:
+		return GRPC_STATUS_UNAUTHENTICATED, message
+	case http.StatusForbidden:
+		return GRPC_STATUS_PERMISSION_DENIED, message
+	case http.StatusNotFound:
+		return GRPC_STATUS_UNIMPLEMENTED, message
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return GRPC_STATUS_UNAVAILABLE, message
+	}
+	return GRPC_STATUS_UNKNOWN, message
+}
+
+//GRPCServices lists the services of the gRPC target through the server reflection, leaving out the reflection
+//service itself
+func GRPCServices(conf *ffuf.Config) ([]string, error) {
+	u, err := url.Parse(conf.Url)
+	if err != nil {
+		return nil, err
+	}
+	r := NewGRPCRunner(conf).(*GRPCRunner)
+	// ServerReflectionRequest with list_services set
+	request := protoAppendBytes(nil, 7, []byte{})
+	for _, method := range grpcReflectionMethods {
+		call, err := r.invoke(u.Scheme+"://"+u.Host+"/"+method, conf.Headers, request)
+		if err != nil {
+			return nil, err
+		}
+		if call.status == GRPC_STATUS_UNIMPLEMENTED {
+			continue
+		}
+		if call.status != 0 || len(call.messages) == 0 {
+			return nil, fmt.Errorf("server reflection failed with status %d: %s", call.status, call.message)
+		}
+		return grpcReflectionServices(call.messages[0])
+	}
+	return nil, fmt.Errorf("the server does not support reflection")
+}
+
+//grpcReflectionServices returns the service names of a ServerReflectionResponse to a list_services request
+func grpcReflectionServices(response []byte) ([]string, error) {
+	fields, err := protoFields(response)
+	if err != nil {
+		return nil, err
+	}
+	services := make([]string, 0)
+	for _, f := range fields {
+		switch f.Number {
+		case 6:
+			// ListServiceResponse of ServiceResponse messages
+			list, err := protoFields(f.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			for _, s := range list {
+				service, err := protoFields(s.Bytes)
+				if err != nil {
+					return nil, err
+				}
+				for _, name := range service {
+					if name.Number == 1 && !strings.HasPrefix(string(name.Bytes), "grpc.reflection.") {
+						services = append(services, string(name.Bytes))
+					}
+				}
+			}
+		case 7:
+			// ErrorResponse
+			if errfields, err := protoFields(f.Bytes); err == nil {
+				for _, ef := range errfields {
+					if ef.Number == 2 {
+						return nil, fmt.Errorf("server reflection failed: %s", ef.Bytes)
+					}
+				}
+			}
+			return nil, fmt.Errorf("server reflection failed")
+		}
+	}
+	sort.Strings(services)
+	return services, nil
+}
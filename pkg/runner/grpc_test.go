@@ -0,0 +1,125 @@
+package runner
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestGRPCRequestMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected []byte
+		err      bool
+	}{
+		{"empty", "", []byte{}, false},
+		{"single field", "1=abc", []byte("\x0a\x03abc"), false},
+		{"two fields", "1=a&2=bc", []byte("\x0a\x01a\x12\x02bc"), false},
+		{"empty value", "3=", []byte("\x1a\x00"), false},
+		{"value with =", "1=a=b", []byte("\x0a\x03a=b"), false},
+		{"two byte tag", "16=x", []byte("\x82\x01\x01x"), false},
+		{"largest field number", "536870911=x", []byte("\xfa\xff\xff\xff\x0f\x01x"), false},
+		{"field number zero", "0=x", nil, true},
+		{"field number too large", "536870912=x", nil, true},
+		{"field name", "name=x", nil, true},
+		{"no value", "1", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := grpcRequestMessage([]byte(tt.data))
+			if tt.err {
+				if err == nil {
+					t.Errorf("Expected an error, got message %x", message)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if string(message) != string(tt.expected) {
+				t.Errorf("Expected message %x, got %x", tt.expected, message)
+			}
+		})
+	}
+}
+
+func TestGRPCMessages(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{"empty body", "", []string{}},
+		{"single message", "\x00\x00\x00\x00\x03abc", []string{"abc"}},
+		{"empty message", "\x00\x00\x00\x00\x00", []string{""}},
+		{"two messages", "\x00\x00\x00\x00\x01a\x00\x00\x00\x00\x02bc", []string{"a", "bc"}},
+		{"compressed flag", "\x01\x00\x00\x00\x01a", []string{"a"}},
+		{"truncated message", "\x00\x00\x00\x00\x01a\x00\x00\x00\x00\x05bc", []string{"a"}},
+		{"truncated prefix", "\x00\x00\x00\x00\x01a\x00\x00", []string{"a"}},
+		{"length beyond the body", "\x00\xff\xff\xff\xffa", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := grpcMessages([]byte(tt.body))
+			got := make([]string, 0, len(messages))
+			for _, m := range messages {
+				got = append(got, string(m))
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected messages %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGRPCStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		header   http.Header
+		trailer  http.Header
+		expected int64
+		message  string
+	}{
+		{"trailers", 200, http.Header{}, http.Header{"Grpc-Status": {"5"}, "Grpc-Message": {"not%20found"}}, 5, "not found"},
+		{"trailers only response", 200, http.Header{"Grpc-Status": {"0"}}, http.Header{}, 0, ""},
+		{"trailers before the headers", 200, http.Header{"Grpc-Status": {"3"}}, http.Header{"Grpc-Status": {"0"}}, 0, ""},
+		{"invalid percent encoding kept", 200, http.Header{"Grpc-Status": {"13"}, "Grpc-Message": {"50%"}}, http.Header{}, 13, "50%"},
+		{"HTTP 400", 400, http.Header{}, http.Header{}, GRPC_STATUS_INTERNAL, ""},
+		{"HTTP 401", 401, http.Header{}, http.Header{}, GRPC_STATUS_UNAUTHENTICATED, ""},
+		{"HTTP 403", 403, http.Header{}, http.Header{}, GRPC_STATUS_PERMISSION_DENIED, ""},
+		{"HTTP 404", 404, http.Header{}, http.Header{}, GRPC_STATUS_UNIMPLEMENTED, ""},
+		{"HTTP 429", 429, http.Header{}, http.Header{}, GRPC_STATUS_UNAVAILABLE, ""},
+		{"HTTP 503", 503, http.Header{}, http.Header{}, GRPC_STATUS_UNAVAILABLE, ""},
+		{"HTTP 200 without a status", 200, http.Header{}, http.Header{}, GRPC_STATUS_UNKNOWN, ""},
+		{"HTTP 500", 500, http.Header{}, http.Header{}, GRPC_STATUS_UNKNOWN, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, message := grpcStatus(&http.Response{StatusCode: tt.status, Header: tt.header, Trailer: tt.trailer})
+			if status != tt.expected || message != tt.message {
+				t.Errorf("Expected status %d %q, got %d %q", tt.expected, tt.message, status, message)
+			}
+		})
+	}
+}
+
+func TestGRPCHTTPURL(t *testing.T) {
+	tests := []struct {
+		target   string
+		expected string
+		err      bool
+	}{
+		{"grpc://localhost:50051/pkg.Service/Method", "http://localhost:50051/pkg.Service/Method", false},
+		{"GRPCS://example.com/pkg.Service/Method", "https://example.com/pkg.Service/Method", false},
+		{"http://example.com/pkg.Service/Method", "", true},
+		{"grpc://[::1", "", true},
+	}
+	for _, tt := range tests {
+		u, err := grpcHTTPURL(tt.target)
+		if (err != nil) != tt.err || u != tt.expected {
+			t.Errorf("%s: expected %q (error %t), got %q (%v)", tt.target, tt.expected, tt.err, u, err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//ipLimiter limits the concurrent requests (-ip-threads) and the request rate (-ip-rate) to every destination IP, so
+//that fuzzing the hostname or the Host header with a high thread count does not overwhelm the individual backends
+//serving the fuzzed hosts. The hostnames are resolved once per run.
+type ipLimiter struct {
+	threads int
+	rate    int64
+	fixed   map[string]string
+	mutex   sync.Mutex
+	ips     map[string]string
+	slots   map[string]chan struct{}
+	rates   map[string]*ffuf.GlobalLimiter
+}
+
+//newIPLimiter returns the limiter of the configuration, or nil when there are no per IP limits
+func newIPLimiter(conf *ffuf.Config) *ipLimiter {
+	if conf.IPThreads <= 0 && conf.IPRate <= 0 {
+		return nil
+	}
+	return &ipLimiter{
+		threads: conf.IPThreads,
+		rate:    conf.IPRate,
+		fixed:   conf.Resolve,
+		ips:     make(map[string]string),
+		slots:   make(map[string]chan struct{}),
+		rates:   make(map[string]*ffuf.GlobalLimiter),
+	}
+}
+
+//acquire waits until a request may be sent to the IP the host resolves to, and returns the function releasing the
+//request slot once the response has been read. Hosts failing to resolve are limited by their name.
+func (l *ipLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	ip := l.resolve(ctx, host)
+	l.mutex.Lock()
+	slot, ok := l.slots[ip]
+	if !ok && l.threads > 0 {
+		slot = make(chan struct{}, l.threads)
+		l.slots[ip] = slot
+	}
+	limiter, ok := l.rates[ip]
+	if !ok && l.rate > 0 {
+		limiter = ffuf.NewGlobalLimiter(l.rate)
+		l.rates[ip] = limiter
+	}
+	l.mutex.Unlock()
+
+	release := func() {}
+	if slot != nil {
+		select {
+		case slot <- struct{}{}:
+			release = func() { <-slot }
+		case <-ctx.Done():
+			return release, ctx.Err()
+		}
+	}
+	if limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			release()
+			return func() {}, err
+		}
+	}
+	return release, nil
+}
+
+//resolve returns the IP address of the host, fixed with -resolve or looked up once and cached
+func (l *ipLimiter) resolve(ctx context.Context, host string) string {
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	if ip, ok := l.fixed[strings.ToLower(host)]; ok {
+		return ip
+	}
+	l.mutex.Lock()
+	ip, ok := l.ips[host]
+	l.mutex.Unlock()
+	if ok {
+		return ip
+	}
+	ip = host
+	if addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host); err == nil && len(addrs) > 0 {
+		ip = addrs[0].IP.String()
+	}
+	l.mutex.Lock()
+	l.ips[host] = ip
+	l.mutex.Unlock()
+	return ip
+}
@@ -0,0 +1,35 @@
+package runner
+
+import (
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//MiddlewareRunner runs the request and response middleware of the configuration (Config.RequestMiddleware and
+//Config.ResponseMiddleware) around the requests of another runner. Applications embedding ffuf register their
+//middleware in the configuration, and may do so after the runner has been created.
+type MiddlewareRunner struct {
+	ffuf.RunnerProvider
+	config *ffuf.Config
+}
+
+func NewMiddlewareRunner(runner ffuf.RunnerProvider, conf *ffuf.Config) ffuf.RunnerProvider {
+	return &MiddlewareRunner{RunnerProvider: runner, config: conf}
+}
+
+//Execute runs the request middleware in the order of registration, sends the request and runs the response
+//middleware on the response
+func (r *MiddlewareRunner) Execute(req *ffuf.Request) (ffuf.Response, error) {
+	for _, mw := range r.config.RequestMiddleware {
+		if err := mw(req); err != nil {
+			return ffuf.Response{}, err
+		}
+	}
+	resp, err := r.RunnerProvider.Execute(req)
+	if err != nil {
+		return resp, err
+	}
+	for _, mw := range r.config.ResponseMiddleware {
+		mw(&resp)
+	}
+	return resp, nil
+}
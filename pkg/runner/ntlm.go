@@ -0,0 +1,130 @@
+package runner
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+var ntlmSignature = []byte("NTLMSSP\x00")
+
+const (
+	ntlmNegotiateUnicode          = 0x00000001
+	ntlmRequestTarget             = 0x00000004
+	ntlmNegotiateNTLM             = 0x00000200
+	ntlmNegotiateAlwaysSign       = 0x00008000
+	ntlmNegotiateExtendedSecurity = 0x00080000
+	ntlmNegotiateTargetInfo       = 0x00800000
+	ntlmNegotiate128              = 0x20000000
+	ntlmNegotiate56               = 0x80000000
+
+	ntlmFlags = ntlmNegotiateUnicode | ntlmRequestTarget | ntlmNegotiateNTLM | ntlmNegotiateAlwaysSign |
+		ntlmNegotiateExtendedSecurity | ntlmNegotiateTargetInfo | ntlmNegotiate128 | ntlmNegotiate56
+
+	//ntlmAvTimestamp is the id of the server timestamp in the target information of the challenge
+	ntlmAvTimestamp = 7
+)
+
+//ntlmNegotiateMessage returns the NEGOTIATE_MESSAGE starting the NTLM handshake
+func ntlmNegotiateMessage() []byte {
+	msg := make([]byte, 32)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 1)
+	binary.LittleEndian.PutUint32(msg[12:], ntlmFlags)
+	// Domain and workstation are left empty, with their offsets pointing to the end of the message
+	binary.LittleEndian.PutUint32(msg[20:], 32)
+	binary.LittleEndian.PutUint32(msg[28:], 32)
+	return msg
+}
+
+//ntlmAuthenticateMessage returns the AUTHENTICATE_MESSAGE answering the CHALLENGE_MESSAGE of the server with an
+//NTLMv2 response
+func ntlmAuthenticateMessage(challenge []byte, user, password, domain string) ([]byte, error) {
+	if len(challenge) < 32 || !bytes.Equal(challenge[:8], ntlmSignature) || binary.LittleEndian.Uint32(challenge[8:]) != 2 {
+		return nil, fmt.Errorf("invalid NTLM challenge")
+	}
+	serverChallenge := challenge[24:32]
+	var targetInfo []byte
+	if len(challenge) >= 48 {
+		l := int(binary.LittleEndian.Uint16(challenge[40:]))
+		offset := int(binary.LittleEndian.Uint32(challenge[44:]))
+		if offset+l > len(challenge) {
+			return nil, fmt.Errorf("invalid NTLM challenge target information")
+		}
+		targetInfo = challenge[offset : offset+l]
+	}
+
+	timestamp := ntlmTimestamp(targetInfo)
+	clientChallenge := make([]byte, 8)
+	if _, err := rand.Read(clientChallenge); err != nil {
+		return nil, err
+	}
+	nt := md4Sum(utf16le(password))
+	mac := hmac.New(md5.New, nt[:])
+	mac.Write(utf16le(strings.ToUpper(user) + domain))
+	ntowf := mac.Sum(nil)
+
+	blob := []byte{1, 1, 0, 0, 0, 0, 0, 0}
+	blob = append(blob, timestamp...)
+	blob = append(blob, clientChallenge...)
+	blob = append(blob, 0, 0, 0, 0)
+	blob = append(blob, targetInfo...)
+	blob = append(blob, 0, 0, 0, 0)
+	mac = hmac.New(md5.New, ntowf)
+	mac.Write(serverChallenge)
+	mac.Write(blob)
+	ntResponse := append(mac.Sum(nil), blob...)
+	// The LMv2 response is left zeroed, like when the server provides the timestamp
+	lmResponse := make([]byte, 24)
+
+	fields := [][]byte{lmResponse, ntResponse, utf16le(domain), utf16le(user), {}, {}}
+	msg := make([]byte, 64)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 3)
+	offset := len(msg)
+	for i, f := range fields {
+		binary.LittleEndian.PutUint16(msg[12+i*8:], uint16(len(f)))
+		binary.LittleEndian.PutUint16(msg[14+i*8:], uint16(len(f)))
+		binary.LittleEndian.PutUint32(msg[16+i*8:], uint32(offset))
+		offset += len(f)
+	}
+	binary.LittleEndian.PutUint32(msg[60:], ntlmFlags)
+	for _, f := range fields {
+		msg = append(msg, f...)
+	}
+	return msg, nil
+}
+
+//ntlmTimestamp returns the server timestamp from the target information, or the current time in the same format
+func ntlmTimestamp(targetInfo []byte) []byte {
+	for i := 0; i+4 <= len(targetInfo); {
+		id := binary.LittleEndian.Uint16(targetInfo[i:])
+		l := int(binary.LittleEndian.Uint16(targetInfo[i+2:]))
+		if id == ntlmAvTimestamp && l == 8 && i+12 <= len(targetInfo) {
+			return targetInfo[i+4 : i+12]
+		}
+		if id == 0 {
+			break
+		}
+		i += 4 + l
+	}
+	ts := make([]byte, 8)
+	// Windows FILETIME, 100 nanosecond intervals since 1601-01-01
+	binary.LittleEndian.PutUint64(ts, uint64(time.Now().UnixNano()/100+116444736000000000))
+	return ts
+}
+
+func utf16le(s string) []byte {
+	codes := utf16.Encode([]rune(s))
+	b := make([]byte, len(codes)*2)
+	for i, c := range codes {
+		binary.LittleEndian.PutUint16(b[i*2:], c)
+	}
+	return b
+}
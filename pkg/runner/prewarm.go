@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//CONN_REUSE_DRAIN_LIMIT is the most of an unread response body that is drained for its connection to be reused
+//(-conn-reuse). The connections of larger bodies are closed.
+const CONN_REUSE_DRAIN_LIMIT = 256 * 1024
+
+//closeBody closes a response body, draining the unread part of it first with -conn-reuse so that the connection goes
+//back to the pool
+func (r *SimpleRunner) closeBody(body io.ReadCloser) {
+	if r.config.ConnReuse {
+		_, _ = io.Copy(ioutil.Discard, io.LimitReader(body, CONN_REUSE_DRAIN_LIMIT))
+	}
+	body.Close()
+}
+
+//Prewarm opens n connections to the target host before the scan starts (-prewarm), by sending concurrent HEAD requests
+//to its root, so that the first requests of the scan reuse them. It returns the number of connections opened.
+func Prewarm(runner ffuf.RunnerProvider, conf *ffuf.Config, n int) (int, error) {
+	if mr, ok := runner.(*MiddlewareRunner); ok {
+		runner = mr.RunnerProvider
+	}
+	sr, ok := runner.(*SimpleRunner)
+	if !ok {
+		return 0, fmt.Errorf("only HTTP targets can be pre-warmed")
+	}
+	u, err := url.Parse(conf.Url)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range conf.InputProviders {
+		if strings.Contains(u.Host, p.Keyword) {
+			return 0, fmt.Errorf("the host of the target is fuzzed with %s", p.Keyword)
+		}
+	}
+	target := u.Scheme + "://" + u.Host + "/"
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	opened := 0
+	var lastErr error
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := http.NewRequestWithContext(conf.Context, "HEAD", target, nil)
+			if err == nil {
+				if host, ok := conf.Headers["Host"]; ok {
+					req.Host = host
+				}
+				req.Header.Set("User-Agent", fmt.Sprintf("%s v%s", "Fuzz Faster U Fool", ffuf.Version()))
+				var resp *http.Response
+				resp, err = sr.client.Do(req)
+				if err == nil {
+					sr.closeBody(resp.Body)
+				}
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				lastErr = err
+				return
+			}
+			opened++
+		}()
+	}
+	wg.Wait()
+	if opened == 0 && lastErr != nil {
+		return 0, lastErr
+	}
+	return opened, nil
+}
@@ -0,0 +1,141 @@
+package runner
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	PROTO_WIRE_VARINT  = 0
+	PROTO_WIRE_FIXED64 = 1
+	PROTO_WIRE_BYTES   = 2
+	PROTO_WIRE_FIXED32 = 5
+)
+
+//protoField is a field of an encoded protobuf message. The value is in Varint for the numeric wire types, and in
+//Bytes for the length delimited ones.
+type protoField struct {
+	Number   int
+	WireType int
+	Varint   uint64
+	Bytes    []byte
+}
+
+//protoAppendBytes appends a length delimited field, a string, bytes or embedded message, to the encoded message
+func protoAppendBytes(b []byte, number int, value []byte) []byte {
+	b = protoAppendVarint(b, uint64(number)<<3|PROTO_WIRE_BYTES)
+	b = protoAppendVarint(b, uint64(len(value)))
+	return append(b, value...)
+}
+
+func protoAppendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func protoVarint(b []byte) (uint64, int, error) {
+	var v uint64
+	for i := 0; i < len(b) && i < 10; i++ {
+		v |= uint64(b[i]&0x7f) << (7 * uint(i))
+		if b[i] < 0x80 {
+			return v, i + 1, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("invalid varint")
+}
+
+//protoFields decodes the fields of a protobuf message, in the order they were encoded
+func protoFields(b []byte) ([]protoField, error) {
+	fields := make([]protoField, 0)
+	for len(b) > 0 {
+		tag, n, err := protoVarint(b)
+		if err != nil {
+			return fields, err
+		}
+		b = b[n:]
+		f := protoField{Number: int(tag >> 3), WireType: int(tag & 0x7)}
+		if f.Number == 0 {
+			return fields, fmt.Errorf("invalid field number 0")
+		}
+		switch f.WireType {
+		case PROTO_WIRE_VARINT:
+			f.Varint, n, err = protoVarint(b)
+			if err != nil {
+				return fields, err
+			}
+			b = b[n:]
+		case PROTO_WIRE_FIXED64:
+			if len(b) < 8 {
+				return fields, fmt.Errorf("truncated fixed64 field %d", f.Number)
+			}
+			f.Varint = binary.LittleEndian.Uint64(b)
+			b = b[8:]
+		case PROTO_WIRE_FIXED32:
+			if len(b) < 4 {
+				return fields, fmt.Errorf("truncated fixed32 field %d", f.Number)
+			}
+			f.Varint = uint64(binary.LittleEndian.Uint32(b))
+			b = b[4:]
+		case PROTO_WIRE_BYTES:
+			length, n, err := protoVarint(b)
+			if err != nil {
+				return fields, err
+			}
+			b = b[n:]
+			if uint64(len(b)) < length {
+				return fields, fmt.Errorf("truncated field %d", f.Number)
+			}
+			f.Bytes = b[:length]
+			b = b[length:]
+		default:
+			return fields, fmt.Errorf("unsupported wire type %d of field %d", f.WireType, f.Number)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+//protoText renders an encoded protobuf message without its schema, one field per line as NUMBER: VALUE. The length
+//delimited fields are shown as strings when they are printable, as embedded messages when they decode as one, and
+//as hex otherwise.
+func protoText(b []byte, indent string) string {
+	fields, err := protoFields(b)
+	if err != nil {
+		return indent + fmt.Sprintf("%x", b)
+	}
+	lines := make([]string, 0, len(fields))
+	for _, f := range fields {
+		prefix := indent + strconv.Itoa(f.Number)
+		if f.WireType != PROTO_WIRE_BYTES {
+			lines = append(lines, prefix+": "+strconv.FormatUint(f.Varint, 10))
+			continue
+		}
+		if printable(f.Bytes) {
+			lines = append(lines, prefix+": "+strconv.Quote(string(f.Bytes)))
+		} else if _, err := protoFields(f.Bytes); err == nil {
+			lines = append(lines, prefix+" {", protoText(f.Bytes, indent+"  "), indent+"}")
+		} else {
+			lines = append(lines, prefix+": "+fmt.Sprintf("%x", f.Bytes))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func printable(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
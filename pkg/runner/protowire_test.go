@@ -0,0 +1,98 @@
+package runner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProtoVarint(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   uint64
+		encoded string
+	}{
+		{"zero", 0, "\x00"},
+		{"one byte", 127, "\x7f"},
+		{"two bytes", 128, "\x80\x01"},
+		{"three bytes", 300000, "\xe0\xa7\x12"},
+		{"max uint64", 1<<64 - 1, "\xff\xff\xff\xff\xff\xff\xff\xff\xff\x01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if encoded := protoAppendVarint(nil, tt.value); string(encoded) != tt.encoded {
+				t.Errorf("Expected the encoding %x, got %x", tt.encoded, encoded)
+			}
+			v, n, err := protoVarint([]byte(tt.encoded + "trailing"))
+			if err != nil || v != tt.value || n != len(tt.encoded) {
+				t.Errorf("Expected %d of %d bytes, got %d of %d bytes (%v)", tt.value, len(tt.encoded), v, n, err)
+			}
+		})
+	}
+	for _, invalid := range []string{"", "\x80", "\xff\xff\xff\xff\xff\xff\xff\xff\xff\xff\x01"} {
+		if _, _, err := protoVarint([]byte(invalid)); err == nil {
+			t.Errorf("Expected an error for the varint %x", invalid)
+		}
+	}
+}
+
+func TestProtoFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected []protoField
+		err      bool
+	}{
+		{"empty", "", []protoField{}, false},
+		{"varint", "\x08\x96\x01", []protoField{{Number: 1, WireType: PROTO_WIRE_VARINT, Varint: 150}}, false},
+		{"string", "\x12\x02hi", []protoField{{Number: 2, WireType: PROTO_WIRE_BYTES, Bytes: []byte("hi")}}, false},
+		{"fixed64", "\x19\x01\x00\x00\x00\x00\x00\x00\x00", []protoField{{Number: 3, WireType: PROTO_WIRE_FIXED64, Varint: 1}}, false},
+		{"fixed32", "\x25\x02\x00\x00\x00", []protoField{{Number: 4, WireType: PROTO_WIRE_FIXED32, Varint: 2}}, false},
+		{"fields in order", "\x10\x01\x08\x02", []protoField{
+			{Number: 2, WireType: PROTO_WIRE_VARINT, Varint: 1},
+			{Number: 1, WireType: PROTO_WIRE_VARINT, Varint: 2},
+		}, false},
+		{"field number zero", "\x00\x01", nil, true},
+		{"truncated string", "\x0a\x05abc", nil, true},
+		{"truncated fixed64", "\x09\x01\x00", nil, true},
+		{"truncated fixed32", "\x0d\x01", nil, true},
+		{"truncated varint", "\x08\x80", nil, true},
+		{"group wire type", "\x0b\x0c", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := protoFields([]byte(tt.message))
+			if tt.err {
+				if err == nil {
+					t.Errorf("Expected an error, got fields %v", fields)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(fields, tt.expected) {
+				t.Errorf("Expected fields %v, got %v", tt.expected, fields)
+			}
+		})
+	}
+}
+
+func TestProtoText(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  []byte
+		expected string
+	}{
+		{"string and varint", []byte("\x0a\x05admin\x10\x2a"), "1: \"admin\"\n2: 42"},
+		{"embedded message", protoAppendBytes(nil, 3, protoAppendBytes(nil, 1, []byte("x"))), "3 {\n  1: \"x\"\n}"},
+		{"binary bytes", []byte("\x0a\x02\xff\x00"), "1: ff00"},
+		{"not a message", []byte("\x0a\x05ab"), "0a056162"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if text := protoText(tt.message, ""); text != tt.expected {
+				t.Errorf("Expected text %q, got %q", tt.expected, text)
+			}
+		})
+	}
+}
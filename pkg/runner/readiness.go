@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//ErrNotReady is returned by WaitForReady when the readiness check did not pass before its timeout
+var ErrNotReady = fmt.Errorf("readiness check timed out")
+
+//WaitForReady polls the readiness endpoint of -wait-for until it answers with a 2xx status, and a body matching
+//-wait-for-regex if set, or -wait-for-timeout passes. The requests go through the HTTP client of the runner, to use
+//the same proxy and TLS settings as the scan.
+func WaitForReady(runner ffuf.RunnerProvider, conf *ffuf.Config, out ffuf.OutputProvider) error {
+	client := http.DefaultClient
+	if mr, ok := runner.(*MiddlewareRunner); ok {
+		runner = mr.RunnerProvider
+	}
+	if sr, ok := runner.(*SimpleRunner); ok {
+		client = sr.client
+	}
+	var re *regexp.Regexp
+	if len(conf.WaitForRegex) > 0 {
+		re = regexp.MustCompile(conf.WaitForRegex)
+	}
+	interval := time.Duration(conf.WaitForInterval) * time.Second
+	deadline := time.Now().Add(time.Duration(conf.WaitForTimeout) * time.Second)
+	for attempt := 1; ; attempt++ {
+		status, err := checkReady(client, conf, re)
+		if err == nil {
+			out.Info(fmt.Sprintf("Readiness check passed after %d attempts", attempt))
+			return nil
+		}
+		if attempt == 1 {
+			out.Info(fmt.Sprintf("Waiting for %s to be ready (%s)", conf.WaitFor, err))
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return fmt.Errorf("%w after %d seconds: %s", ErrNotReady, conf.WaitForTimeout, err)
+		}
+		log.Printf("Readiness check attempt %d failed with status %d: %s", attempt, status, err)
+		select {
+		case <-conf.Context.Done():
+			return conf.Context.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+//checkReady sends a single readiness check request, returning an error describing why the endpoint is not ready
+func checkReady(client *http.Client, conf *ffuf.Config, re *regexp.Regexp) (int, error) {
+	req, err := http.NewRequestWithContext(conf.Context, "GET", conf.WaitFor, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", fmt.Sprintf("%s v%s", "Fuzz Faster U Fool", ffuf.Version()))
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if re == nil {
+		return resp.StatusCode, nil
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, conf.MaxBodySize))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if !re.Match(body) {
+		return resp.StatusCode, fmt.Errorf("body does not match %s", conf.WaitForRegex)
+	}
+	return resp.StatusCode, nil
+}
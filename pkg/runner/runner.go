@@ -1,10 +1,44 @@
 package runner
 
 import (
+	"strings"
+
 	"github.com/ffuf/ffuf/pkg/ffuf"
 )
 
 func NewRunnerByName(name string, conf *ffuf.Config, replay bool) ffuf.RunnerProvider {
-	// We have only one Runner at the moment
-	return NewSimpleRunner(conf, replay)
+	if name == "dns" {
+		return NewMiddlewareRunner(NewDNSRunner(conf), conf)
+	}
+	if name == "websocket" {
+		return NewMiddlewareRunner(NewWebSocketRunner(conf), conf)
+	}
+	if name == "grpc" {
+		return NewMiddlewareRunner(NewGRPCRunner(conf), conf)
+	}
+	if name == "banner" {
+		return NewMiddlewareRunner(NewBannerRunner(conf), conf)
+	}
+	// Default to http
+	return NewMiddlewareRunner(NewSimpleRunner(conf, replay), conf)
+}
+
+//RunnerNameFromURL returns the name of the runner handling the scheme of the target URL
+func RunnerNameFromURL(target string) string {
+	target = strings.ToLower(target)
+	if strings.HasPrefix(target, "dns://") {
+		return "dns"
+	}
+	if strings.HasPrefix(target, "ws://") || strings.HasPrefix(target, "wss://") {
+		return "websocket"
+	}
+	if strings.HasPrefix(target, "grpc://") || strings.HasPrefix(target, "grpcs://") {
+		return "grpc"
+	}
+	for _, scheme := range []string{"tcp://", "ftp://", "ssh://", "smb://"} {
+		if strings.HasPrefix(target, scheme) {
+			return "banner"
+		}
+	}
+	return "http"
 }
@@ -2,15 +2,16 @@ package runner
 
 import (
 	"bytes"
-	"crypto/tls"
+	"context"
 	"fmt"
-	"io/ioutil"
+	"log"
 	"net"
 	"net/http"
 	"net/http/httptrace"
 	"net/http/httputil"
 	"net/textproto"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -21,9 +22,13 @@ import (
 //Download results < 5MB
 const MAX_DOWNLOAD_SIZE = 5242880
 
+type originContextKey struct{}
+
 type SimpleRunner struct {
-	config *ffuf.Config
-	client *http.Client
+	config    *ffuf.Config
+	client    *http.Client
+	dumpRaw   bool
+	ipLimiter *ipLimiter
 }
 
 func NewSimpleRunner(conf *ffuf.Config, replay bool) ffuf.RunnerProvider {
@@ -42,57 +47,184 @@ func NewSimpleRunner(conf *ffuf.Config, replay bool) ffuf.RunnerProvider {
 			proxyURL = http.ProxyURL(pu)
 		}
 	}
+	// The health of the proxy is monitored through the connections made to it, the replay proxy is not monitored
+	var pool *ffuf.ProxyPool
+	if !replay && conf.ProxyPool != nil {
+		pool = conf.ProxyPool
+		proxyURL = pool.Proxy
+	}
+
+	// The TLS options are validated when parsing the configuration
+	tlsConf, err := ffuf.NewTLSConfig(conf)
+	if err != nil {
+		log.Printf("%s", err)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   time.Duration(time.Duration(conf.Timeout) * time.Second),
+		KeepAlive: time.Duration(time.Duration(conf.Timeout) * time.Second), //added keep alive
+	}
 
 	simplerunner.config = conf
+	if !replay {
+		simplerunner.ipLimiter = newIPLimiter(conf)
+	}
+	// The raw requests and responses are stored for the output directory, or recorded for the replays
+	simplerunner.dumpRaw = len(conf.OutputDirectory) > 0 || (replay && len(conf.ReplayDir) > 0)
 	simplerunner.client = &http.Client{
 		CheckRedirect: func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse },
 		Timeout:       time.Duration(time.Duration(conf.Timeout) * time.Second),
 		Transport: &http.Transport{
+			ForceAttemptHTTP2:   conf.Http2,
 			Proxy:               proxyURL,
 			MaxIdleConns:        1000,
-			MaxIdleConnsPerHost: 500,
+			MaxIdleConnsPerHost: conf.MaxIdleConns,
 			MaxConnsPerHost:     500,
-			DisableKeepAlives:   true,
-			DialContext: (&net.Dialer{
-				Timeout:   time.Duration(time.Duration(conf.Timeout) * time.Second),
-				KeepAlive: time.Duration(time.Duration(conf.Timeout) * time.Second), //added keep alive
-			}).DialContext,
-			TLSHandshakeTimeout: time.Duration(time.Duration(conf.Timeout) * time.Second),
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-				Renegotiation:      tls.RenegotiateOnceAsClient,
-				ServerName:         conf.SNI,
+			// The connections are pooled by host, a connection to one origin IP must not be reused for another
+			DisableKeepAlives: !conf.KeepAlive || len(conf.OriginIPs) > 0,
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				conn, err := dialTarget(ctx, dialer, conf, network, originAddr(ctx, addr))
+				if pool != nil && ctx.Err() == nil {
+					pool.DialResult(addr, err)
+				}
+				if err == nil && !replay && conf.Pcap != nil {
+					conn = conf.Pcap.Wrap(conn)
+				}
+				return conn, err
 			},
+			TLSHandshakeTimeout: time.Duration(time.Duration(conf.Timeout) * time.Second),
+			TLSClientConfig:     tlsConf,
 		}}
 
+	if conf.Http2PriorKnowledge {
+		// Speak HTTP/2 without upgrade for plaintext targets (h2c), and require h2 via ALPN for TLS ones
+		var protocols http.Protocols
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+		simplerunner.client.Transport.(*http.Transport).Protocols = &protocols
+	}
+
 	if conf.FollowRedirects {
-		simplerunner.client.CheckRedirect = nil
+		simplerunner.client.CheckRedirect = followRedirect
+	}
+	if conf.AuthScheme != "" {
+		simplerunner.client.Transport = newAuthTransport(conf, simplerunner.client.Transport.(*http.Transport))
 	}
 	return &simplerunner
 }
 
+//followRedirect follows redirects like the default policy of the http client, but stops on redirects to schemes
+//other than http and https, so that they are recorded instead of failing the request
+func followRedirect(req *http.Request, via []*http.Request) error {
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return http.ErrUseLastResponse
+	}
+	if len(via) >= 10 {
+		return fmt.Errorf("stopped after 10 redirects")
+	}
+	return nil
+}
+
 func (r *SimpleRunner) Prepare(input map[string][]byte) (ffuf.Request, error) {
-	req := ffuf.NewRequest(r.config)
-
-	req.Headers = r.config.Headers
-	req.Url = r.config.Url
-	req.Method = r.config.Method
-	req.Data = []byte(r.config.Data)
-
-	for keyword, inputitem := range input {
-		req.Method = strings.ReplaceAll(req.Method, keyword, string(inputitem))
-		headers := make(map[string]string, len(req.Headers))
-		for h, v := range req.Headers {
-			var CanonicalHeader string = textproto.CanonicalMIMEHeaderKey(strings.ReplaceAll(h, keyword, string(inputitem)))
-			headers[CanonicalHeader] = strings.ReplaceAll(v, keyword, string(inputitem))
+	return prepareRequest(r.config, input), nil
+}
+
+//prepareRequest creates a new request from the configured template, with keywords replaced by their input values in
+//the method, the header names and values (including the cookies), the URL and the POST data
+func prepareRequest(conf *ffuf.Config, input map[string][]byte) ffuf.Request {
+	req := ffuf.NewRequest(conf)
+	replacer := keywordReplacer(input)
+
+	req.Method = replacer.Replace(conf.Method)
+	req.Headers = make(map[string]string, len(conf.Headers))
+	for h, v := range conf.Headers {
+		// The header names are canonicalized only once all of their keywords are replaced, not to change the
+		// keywords themselves
+		name := textproto.CanonicalMIMEHeaderKey(replacer.Replace(h))
+		if len(name) == 0 {
+			continue
 		}
-		req.Headers = headers
-		req.Url = strings.ReplaceAll(req.Url, keyword, string(inputitem))
-		req.Data = []byte(strings.ReplaceAll(string(req.Data), keyword, string(inputitem)))
+		req.Headers[name] = replacer.Replace(v)
 	}
-
+	req.Data = []byte(replacer.Replace(conf.Data))
+	req.Url = prepareURL(conf.Url, replacer)
 	req.Input = input
-	return req, nil
+	if conf.InputMode == "sniper" {
+		// The injection position placeholders are not inputs of their own
+		req.Input = make(map[string][]byte, 1)
+		for k, v := range input {
+			if !ffuf.IsSniperKeyword(k) {
+				req.Input[k] = v
+			}
+		}
+	}
+	return req
+}
+
+//keywordReplacer returns the replacer of the keywords with their input values. All of the keywords are replaced in a
+//single pass, so that the input values containing keywords are not replaced again, and the longer keywords are
+//preferred over the ones they start with, eg. FUZZ2 over FUZZ.
+func keywordReplacer(input map[string][]byte) *strings.Replacer {
+	keywords := make([]string, 0, len(input))
+	for keyword := range input {
+		keywords = append(keywords, keyword)
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if len(keywords[i]) != len(keywords[j]) {
+			return len(keywords[i]) > len(keywords[j])
+		}
+		return keywords[i] < keywords[j]
+	})
+	oldnew := make([]string, 0, 2*len(keywords))
+	for _, keyword := range keywords {
+		oldnew = append(oldnew, keyword, string(input[keyword]))
+	}
+	return strings.NewReplacer(oldnew...)
+}
+
+//prepareURL replaces the keywords of the URL template with their input values. Values in the userinfo and fragment
+//parts of the URL are escaped, so that they cannot be confused with the other parts when the URL is parsed.
+func prepareURL(template string, replacer *strings.Replacer) string {
+	replace := replacer.Replace
+	prefix := ""
+	rest := template
+	if i := strings.Index(rest, "://"); i >= 0 {
+		prefix = rest[:i+3]
+		rest = rest[i+3:]
+	}
+	fragment := ""
+	hasFragment := false
+	if i := strings.Index(rest, "#"); i >= 0 {
+		fragment = rest[i+1:]
+		rest = rest[:i]
+		hasFragment = true
+	}
+	userinfo := ""
+	authorityEnd := strings.IndexAny(rest, "/?")
+	if authorityEnd < 0 {
+		authorityEnd = len(rest)
+	}
+	if i := strings.LastIndex(rest[:authorityEnd], "@"); i >= 0 && prefix != "" {
+		userinfo = rest[:i]
+		rest = rest[i+1:]
+	}
+	if userinfo == "" && !hasFragment {
+		return replace(template)
+	}
+	u := prefix
+	if userinfo != "" {
+		if i := strings.Index(userinfo, ":"); i >= 0 {
+			u += url.UserPassword(replace(userinfo[:i]), replace(userinfo[i+1:])).String() + "@"
+		} else {
+			u += url.User(replace(userinfo)).String() + "@"
+		}
+	}
+	u += replace(rest)
+	if hasFragment {
+		// The fragment is not sent to the server, but is kept in the request URL for the results
+		u += (&url.URL{Fragment: replace(fragment)}).String()
+	}
+	return u
 }
 
 func (r *SimpleRunner) Execute(req *ffuf.Request) (ffuf.Response, error) {
@@ -130,50 +262,81 @@ func (r *SimpleRunner) Execute(req *ffuf.Request) (ffuf.Response, error) {
 	}
 
 	req.Host = httpreq.Host
-	httpreq = httpreq.WithContext(httptrace.WithClientTrace(r.config.Context, trace))
+	ctx := r.config.Context
+	if origin, ok := req.Input[ffuf.ORIGIN_KEYWORD]; ok {
+		// Connect to the candidate origin while keeping the Host header and SNI of the target
+		ctx = context.WithValue(ctx, originContextKey{}, string(origin))
+	}
+	httpreq = httpreq.WithContext(httptrace.WithClientTrace(ctx, trace))
 	for k, v := range req.Headers {
 		httpreq.Header.Set(k, v)
 	}
 
-	if len(r.config.OutputDirectory) > 0 {
+	if r.dumpRaw {
 		rawreq, _ = httputil.DumpRequestOut(httpreq, true)
 	}
 
+	if r.ipLimiter != nil {
+		release, err := r.ipLimiter.acquire(r.config.Context, httpreq.URL.Hostname())
+		if err != nil {
+			return ffuf.Response{}, err
+		}
+		defer release()
+	}
+
 	httpresp, err := r.client.Do(httpreq)
 	if err != nil {
 		return ffuf.Response{}, err
 	}
 
 	resp := ffuf.NewResponse(httpresp, req)
-	defer httpresp.Body.Close()
+	defer r.closeBody(httpresp.Body)
 
 	// Check if we should download the resource or not
 	size, err := strconv.Atoi(httpresp.Header.Get("Content-Length"))
 	if err == nil {
 		resp.ContentLength = int64(size)
-		if (r.config.IgnoreBody) || (size > MAX_DOWNLOAD_SIZE) {
+		if r.config.IgnoreBody {
 			resp.Cancelled = true
 			return resp, nil
 		}
 	}
 
-	if len(r.config.OutputDirectory) > 1 {
-		rawresp, _ := httputil.DumpResponse(httpresp, true)
-		resp.Request.Raw = string(rawreq)
-		resp.Raw = string(rawresp)
+	// The body is held within the memory budget, and truncated to the maximum body size. With a known size, only the
+	// start of the body needed by the matchers and filters is read (-fast-size).
+	var rerr error
+	if err == nil && r.config.BodyLimit > 0 {
+		rerr = r.config.BodyStore.ReadBodyPrefix(&resp, httpresp.Body, r.config.BodyLimit)
+	} else {
+		rerr = r.config.BodyStore.ReadBody(&resp, httpresp.Body)
 	}
-
-	if respbody, err := ioutil.ReadAll(httpresp.Body); err == nil {
-		resp.ContentLength = int64(len(string(respbody)))
-		resp.Data = respbody
-		respbody = nil // making it nil
+	if rerr != nil {
+		log.Printf("Could not read the response body of %s: %s", req.Url, rerr)
 	}
 
-	wordsSize := len(strings.Split(string(resp.Data), " "))
-	linesSize := len(strings.Split(string(resp.Data), "\n"))
-	resp.ContentWords = int64(wordsSize)
-	resp.ContentLines = int64(linesSize)
+	if r.dumpRaw {
+		rawresp, _ := httputil.DumpResponse(httpresp, false)
+		resp.Request.Raw = string(rawreq)
+		resp.Raw = string(rawresp) + string(resp.Body())
+	}
 	resp.Time = firstByteTime
 
 	return resp, nil
 }
+
+//originAddr returns the address of the candidate origin server stored in the context, if any, in place of addr
+func originAddr(ctx context.Context, addr string) string {
+	origin, ok := ctx.Value(originContextKey{}).(string)
+	if !ok || len(origin) == 0 {
+		return addr
+	}
+	if _, _, err := net.SplitHostPort(origin); err == nil {
+		// The port was defined for the origin
+		return origin
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return net.JoinHostPort(origin, port)
+}
@@ -0,0 +1,235 @@
+package runner
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+const (
+	WS_OPCODE_TEXT  = 0x1
+	WS_OPCODE_CLOSE = 0x8
+	WS_OPCODE_PING  = 0x9
+	WS_OPCODE_PONG  = 0xA
+)
+
+type WebSocketRunner struct {
+	config *ffuf.Config
+}
+
+func NewWebSocketRunner(conf *ffuf.Config) ffuf.RunnerProvider {
+	return &WebSocketRunner{config: conf}
+}
+
+func (r *WebSocketRunner) Prepare(input map[string][]byte) (ffuf.Request, error) {
+	return prepareRequest(r.config, input), nil
+}
+
+//Execute performs the WebSocket handshake, sends the request data as the first message if defined, and returns
+//the handshake status along with the first frame received from the server
+func (r *WebSocketRunner) Execute(req *ffuf.Request) (ffuf.Response, error) {
+	var resp ffuf.Response
+	resp.Request = req
+	timeout := time.Duration(r.config.Timeout) * time.Second
+
+	u, err := url.Parse(req.Url)
+	if err != nil {
+		return resp, err
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		if u.Scheme == "wss" {
+			addr = net.JoinHostPort(u.Hostname(), "443")
+		} else {
+			addr = net.JoinHostPort(u.Hostname(), "80")
+		}
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := dialTarget(r.config.Context, dialer, r.config, "tcp", addr)
+	if err != nil {
+		return resp, err
+	}
+	if r.config.Pcap != nil {
+		conn = r.config.Pcap.Wrap(conn)
+	}
+	if u.Scheme == "wss" {
+		sni := r.config.SNI
+		if sni == "" {
+			sni = u.Hostname()
+		}
+		tlsConf, err := ffuf.NewTLSConfig(r.config)
+		if err != nil {
+			conn.Close()
+			return resp, err
+		}
+		tlsConf.ServerName = sni
+		conn = tls.Client(conn, tlsConf)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	handshake, err := r.handshake(req, u)
+	if err != nil {
+		return resp, err
+	}
+	start := time.Now()
+	if _, err := conn.Write([]byte(handshake)); err != nil {
+		return resp, err
+	}
+	reader := bufio.NewReader(conn)
+	httpresp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		return resp, err
+	}
+	resp.Time = time.Since(start)
+	resp.StatusCode = int64(httpresp.StatusCode)
+	resp.Headers = httpresp.Header
+	resp.ContentType = httpresp.Header.Get("Content-Type")
+	req.Host = u.Host
+	req.Raw = handshake
+
+	if httpresp.StatusCode != http.StatusSwitchingProtocols {
+		// The upgrade was refused, use the HTTP response body instead
+		body, _ := ioutil.ReadAll(io.LimitReader(httpresp.Body, r.config.MaxBodySize))
+		httpresp.Body.Close()
+		resp.Data = body
+	} else {
+		if len(req.Data) > 0 {
+			if _, err := conn.Write(wsFrame(WS_OPCODE_TEXT, req.Data)); err != nil {
+				return resp, err
+			}
+		}
+		// A server not sending anything before the timeout is not an error, the response is just empty
+		payload, _ := wsReadMessage(reader, conn)
+		resp.Data = payload
+		if len(resp.Data) > 0 {
+			resp.Time = time.Since(start)
+		}
+	}
+	resp.ContentLength = int64(len(resp.Data))
+	resp.ContentWords = int64(len(strings.Split(string(resp.Data), " ")))
+	resp.ContentLines = int64(len(strings.Split(string(resp.Data), "\n")))
+	if len(r.config.OutputDirectory) > 0 {
+		resp.Raw = fmt.Sprintf("HTTP/1.1 %s\r\n\r\n%s", httpresp.Status, resp.Data)
+	}
+	return resp, nil
+}
+
+//handshake returns the raw HTTP upgrade request
+func (r *WebSocketRunner) handshake(req *ffuf.Request, u *url.URL) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	host := u.Host
+	if h, ok := req.Headers["Host"]; ok {
+		host = h
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "GET %s HTTP/1.1\r\n", u.RequestURI())
+	fmt.Fprintf(&b, "Host: %s\r\n", host)
+	fmt.Fprintf(&b, "Upgrade: websocket\r\nConnection: Upgrade\r\n")
+	fmt.Fprintf(&b, "Sec-WebSocket-Key: %s\r\nSec-WebSocket-Version: 13\r\n", base64.StdEncoding.EncodeToString(nonce))
+	if _, ok := req.Headers["User-Agent"]; !ok {
+		fmt.Fprintf(&b, "User-Agent: %s v%s\r\n", "Fuzz Faster U Fool", ffuf.Version())
+	}
+	for k, v := range req.Headers {
+		if k == "Host" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\r\n", k, v)
+	}
+	b.WriteString("\r\n")
+	return b.String(), nil
+}
+
+//wsFrame returns a single final, masked client frame
+func wsFrame(opcode byte, payload []byte) []byte {
+	frame := []byte{0x80 | opcode}
+	switch {
+	case len(payload) < 126:
+		frame = append(frame, 0x80|byte(len(payload)))
+	case len(payload) <= 0xFFFF:
+		frame = append(frame, 0x80|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(len(payload)))
+	default:
+		frame = append(frame, 0x80|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(len(payload)))
+	}
+	mask := make([]byte, 4)
+	_, _ = rand.Read(mask)
+	frame = append(frame, mask...)
+	for i, c := range payload {
+		frame = append(frame, c^mask[i%4])
+	}
+	return frame
+}
+
+//wsReadMessage returns the payload of the first data frame received, answering pings on the way
+func wsReadMessage(reader *bufio.Reader, conn net.Conn) ([]byte, error) {
+	for {
+		header := make([]byte, 2)
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return nil, err
+		}
+		opcode := header[0] & 0x0F
+		masked := header[1]&0x80 != 0
+		length := uint64(header[1] & 0x7F)
+		switch length {
+		case 126:
+			ext := make([]byte, 2)
+			if _, err := io.ReadFull(reader, ext); err != nil {
+				return nil, err
+			}
+			length = uint64(binary.BigEndian.Uint16(ext))
+		case 127:
+			ext := make([]byte, 8)
+			if _, err := io.ReadFull(reader, ext); err != nil {
+				return nil, err
+			}
+			length = binary.BigEndian.Uint64(ext)
+		}
+		mask := make([]byte, 4)
+		if masked {
+			if _, err := io.ReadFull(reader, mask); err != nil {
+				return nil, err
+			}
+		}
+		if length > MAX_DOWNLOAD_SIZE {
+			return nil, fmt.Errorf("websocket frame too large: %d bytes", length)
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return nil, err
+		}
+		if masked {
+			for i := range payload {
+				payload[i] ^= mask[i%4]
+			}
+		}
+		switch opcode {
+		case WS_OPCODE_PING:
+			if _, err := conn.Write(wsFrame(WS_OPCODE_PONG, payload)); err != nil {
+				return nil, err
+			}
+		case WS_OPCODE_PONG:
+			continue
+		case WS_OPCODE_CLOSE:
+			return nil, nil
+		default:
+			return payload, nil
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+)
+
+//SCRAPER_MAX_VALUES is the maximum number of values a single rule extracts from a response
+const SCRAPER_MAX_VALUES = 20
+
+//ScraperRule is a single extraction rule of a scraper group
+type ScraperRule struct {
+	Name   string `json:"name"`
+	Rule   string `json:"rule"`
+	Target string `json:"target"`
+	regexp *regexp.Regexp
+}
+
+//ScraperGroup is a named set of extraction rules, loaded from a rules file
+type ScraperGroup struct {
+	Name   string         `json:"groupname"`
+	Active bool           `json:"active"`
+	Rules  []*ScraperRule `json:"rules"`
+}
+
+//Scraper extracts data from the matched responses using the rules of the active scraper groups
+type Scraper struct {
+	groups []*ScraperGroup
+}
+
+//builtinGroup holds the rules shipped with ffuf, active when "builtin" or "all" is given with -scrapers
+func builtinGroup() *ScraperGroup {
+	return &ScraperGroup{
+		Name:   "builtin",
+		Active: false,
+		Rules: []*ScraperRule{
+			{Name: "title", Rule: `(?is)<title[^>]*>\s*(.*?)\s*</title>`, Target: "body"},
+			{Name: "email", Rule: `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`, Target: "body"},
+			{Name: "s3bucket", Rule: `(?i)(?:([a-z0-9][a-z0-9.-]{1,61}[a-z0-9])\.s3[.-](?:[a-z0-9-]+\.)?amazonaws\.com|s3://([a-z0-9][a-z0-9.-]{1,61}[a-z0-9])|s3[.-](?:[a-z0-9-]+\.)?amazonaws\.com/([a-z0-9][a-z0-9.-]{1,61}[a-z0-9]))`, Target: "body"},
+			{Name: "server", Rule: `(?im)^(?:Server|X-Powered-By|X-AspNet-Version|X-Generator): *(.+?)\r?$`, Target: "headers"},
+		},
+	}
+}
+
+//DefaultDir returns the default scraper rules directory
+func DefaultDir() string {
+	userhome, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(userhome, ".ffuf", "scraper")
+}
+
+//NewScraper loads the scraper groups from the rules directory and the additional rules file, and returns a
+//scraper running the active ones. A nil scraper is returned if no rules are active.
+func NewScraper(conf *ffuf.Config) (*Scraper, error) {
+	builtin := builtinGroup()
+	if err := builtin.compile(); err != nil {
+		return nil, err
+	}
+	groups := []*ScraperGroup{builtin}
+	dir := conf.ScraperDir
+	if dir == "" {
+		dir = DefaultDir()
+	}
+	if dir != "" {
+		files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return nil, fmt.Errorf("Could not read the scraper directory: %s", err)
+		}
+		if len(files) == 0 && conf.ScraperDir != "" {
+			if _, err := os.Stat(dir); err != nil {
+				return nil, fmt.Errorf("Could not read the scraper directory: %s", err)
+			}
+		}
+		sort.Strings(files)
+		for _, f := range files {
+			g, err := readGroup(f)
+			if err != nil {
+				return nil, err
+			}
+			groups = append(groups, g)
+		}
+	}
+	if conf.ScraperFile != "" {
+		g, err := readGroup(conf.ScraperFile)
+		if err != nil {
+			return nil, err
+		}
+		// Rules given explicitly on the command line are always used
+		g.Active = true
+		groups = append(groups, g)
+	}
+
+	s := &Scraper{groups: make([]*ScraperGroup, 0)}
+	selected := make(map[string]bool)
+	for _, name := range strings.Split(conf.Scrapers, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			selected[strings.ToLower(name)] = true
+		}
+	}
+	for _, g := range groups {
+		if selected["all"] || selected[strings.ToLower(g.Name)] || (len(selected) == 0 && g.Active) {
+			delete(selected, strings.ToLower(g.Name))
+			s.groups = append(s.groups, g)
+		}
+	}
+	delete(selected, "all")
+	for name := range selected {
+		return nil, fmt.Errorf("Unknown scraper group %s", name)
+	}
+	if len(s.groups) == 0 {
+		return nil, nil
+	}
+	return s, nil
+}
+
+//readGroup reads a scraper group from a JSON rules file and compiles its rules
+func readGroup(filename string) (*ScraperGroup, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read the scraper file: %s", err)
+	}
+	var g ScraperGroup
+	if err := json.Unmarshal(content, &g); err != nil {
+		return nil, fmt.Errorf("Could not parse the scraper file %s: %s", filename, err)
+	}
+	if g.Name == "" {
+		g.Name = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	}
+	if err := g.compile(); err != nil {
+		return nil, fmt.Errorf("Invalid scraper file %s: %s", filename, err)
+	}
+	return &g, nil
+}
+
+//compile validates the rules of the group and compiles their regular expressions
+func (g *ScraperGroup) compile() error {
+	for _, r := range g.Rules {
+		if r.Name == "" {
+			return fmt.Errorf("rule without a name")
+		}
+		switch r.Target {
+		case "":
+			r.Target = "body"
+		case "body", "headers", "all":
+		default:
+			return fmt.Errorf("unknown target %s of rule %s, use body, headers or all", r.Target, r.Name)
+		}
+		re, err := regexp.Compile(r.Rule)
+		if err != nil {
+			return fmt.Errorf("rule %s: %s", r.Name, err)
+		}
+		r.regexp = re
+	}
+	return nil
+}
+
+//Groups returns the names of the active scraper groups
+func (s *Scraper) Groups() []string {
+	names := make([]string, 0, len(s.groups))
+	for _, g := range s.groups {
+		names = append(names, g.Name)
+	}
+	return names
+}
+
+//Execute runs the active rules against a response, and returns the extracted values keyed by rule name
+func (s *Scraper) Execute(resp *ffuf.Response) map[string][]string {
+	data := make(map[string][]string)
+	headers := ""
+	body := string(resp.Body())
+	for _, g := range s.groups {
+		for _, r := range g.Rules {
+			var target string
+			switch r.Target {
+			case "body":
+				target = body
+			case "headers", "all":
+				if headers == "" {
+					headers = headerText(resp.Headers)
+				}
+				target = headers
+				if r.Target == "all" {
+					target += "\n" + body
+				}
+			}
+			for _, v := range extract(r.regexp, target) {
+				if !inSlice(v, data[r.Name]) && len(data[r.Name]) < SCRAPER_MAX_VALUES {
+					data[r.Name] = append(data[r.Name], v)
+				}
+			}
+		}
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return data
+}
+
+//extract returns the values matching the regular expression. The first non-empty capture group is used as the
+//value when the expression has any, the whole match otherwise.
+func extract(re *regexp.Regexp, target string) []string {
+	values := make([]string, 0)
+	for _, m := range re.FindAllStringSubmatch(target, SCRAPER_MAX_VALUES*4) {
+		v := m[0]
+		for _, group := range m[1:] {
+			if group != "" {
+				v = group
+				break
+			}
+		}
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+//headerText returns the response headers in their wire format, sorted by name
+func headerText(headers map[string][]string) string {
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, k := range names {
+		for _, v := range headers[k] {
+			b.WriteString(k + ": " + v + "\n")
+		}
+	}
+	return b.String()
+}
+
+func inSlice(key string, slice []string) bool {
+	for _, v := range slice {
+		if v == key {
+			return true
+		}
+	}
+	return false
+}
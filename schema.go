@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ffuf/ffuf/pkg/output"
+)
+
+//runSchema runs the schema subcommand, printing the JSON Schema of the ejson output file, and returns the exit code
+func runSchema(args []string) int {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s schema\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Prints the JSON Schema of the ejson output file (-of ejson), version %d.\n", output.EJSON_SCHEMA_VERSION)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 1
+	}
+	schema, err := output.EJSONSchema()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not encode the schema: %s\n", err)
+		return 1
+	}
+	fmt.Printf("%s\n", schema)
+	return 0
+}
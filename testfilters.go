@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ffuf/ffuf/pkg/ffuf"
+	"github.com/ffuf/ffuf/pkg/filter"
+	"github.com/ffuf/ffuf/pkg/output"
+)
+
+//runTestFilters runs the test-filters subcommand, evaluating the matchers and filters of the options against a saved
+//response, and returns the exit code
+func runTestFilters(args []string) int {
+	fs := flag.NewFlagSet("test-filters", flag.ExitOnError)
+	reqUrl := fs.String("url", "http://localhost/", "URL the response was received from, for the matchers and filters using it")
+	duration := fs.Int("time", 0, "Milliseconds to the first byte of the response, for the response time matchers and filters")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s test-filters [-url URL] [-time MS] RESPONSEFILE [-- FFUF OPTIONS]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Evaluates the matchers and filters of the options against a saved raw HTTP response, and reports which\nof them match it and whether it would be a result, to debug the filters without sending any requests.\nThe result files of -od are read from the response part. The calibration filters are not hit.\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() < 1 || *duration < 0 {
+		fs.Usage()
+		return 1
+	}
+	data, err := ioutil.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not read the response: %s\n", err)
+		return 1
+	}
+	if i := bytes.Index(data, []byte(output.RESULT_FILE_SEPARATOR)); i >= 0 {
+		// A result file of -od, with the request before the response
+		data = bytes.TrimLeft(data[i+len(output.RESULT_FILE_SEPARATOR):], "\r\n")
+	}
+
+	// The ffuf options are parsed like the ones of a regular run
+	ffufArgs := fs.Args()[1:]
+	if len(ffufArgs) > 0 && ffufArgs[0] == "--" {
+		ffufArgs = ffufArgs[1:]
+	}
+	os.Args = append([]string{os.Args[0]}, ffufArgs...)
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	opts := ParseFlags(ffuf.NewConfigOptions())
+	opts.HTTP.URL = *reqUrl
+	if !strings.Contains(opts.HTTP.URL, "FUZZ") {
+		opts.HTTP.URL += "FUZZ"
+	}
+	opts.Input.Wordlists = []string{"range:1-1"}
+	opts.Input.Inputcommands = nil
+	log.SetOutput(ioutil.Discard)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conf, err := ffuf.ConfigFromOptions(opts, ctx, cancel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		return 1
+	}
+	if err := filter.SetupFilters(opts, conf); err != nil {
+		fmt.Fprintf(os.Stderr, "Encountered error(s): %s\n", err)
+		return 1
+	}
+
+	req := ffuf.NewRequest(conf)
+	req.Url = *reqUrl
+	httpresp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not parse the response: %s\n", err)
+		return 1
+	}
+	defer httpresp.Body.Close()
+	resp := ffuf.NewResponse(httpresp, &req)
+	if err := conf.BodyStore.ReadBody(&resp, httpresp.Body); err != nil {
+		fmt.Fprintf(os.Stderr, "Could not read the response body: %s\n", err)
+		return 1
+	}
+	resp.Time = time.Duration(*duration) * time.Millisecond
+	fmt.Printf("Response: [Status: %d, Size: %d, Words: %d, Lines: %d, Duration: %dms, Content-Type: %s]\n\n", resp.StatusCode, resp.ContentLength, resp.ContentWords, resp.ContentLines, *duration, resp.ContentType)
+
+	matched := evaluateFilters("Matchers", conf.Matchers, &resp, "match", "no match")
+	filtered := evaluateFilters("Filters", conf.Filters, &resp, "filtered", "passed")
+	switch {
+	case !matched:
+		fmt.Println("Verdict: not a result, none of the matchers match the response")
+	case filtered:
+		fmt.Println("Verdict: not a result, the response is matched but filtered")
+	default:
+		fmt.Println("Verdict: result, the response is matched and passes all of the filters")
+	}
+	return 0
+}
+
+//evaluateFilters prints the verdict of each of the matchers or filters on the response, in the order of their names,
+//and returns true if any of them evaluates to true
+func evaluateFilters(title string, filters map[string]ffuf.FilterProvider, resp *ffuf.Response, trueLabel, falseLabel string) bool {
+	names := make([]string, 0, len(filters))
+	for name := range filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Printf("%s:\n", title)
+	if len(names) == 0 {
+		fmt.Printf(" :: (none)\n")
+	}
+	hit := false
+	for _, name := range names {
+		f := filters[name]
+		ok, err := f.Filter(resp)
+		label := falseLabel
+		switch {
+		case err != nil:
+			label = fmt.Sprintf("error: %s", err)
+		case ok:
+			label = trueLabel
+			hit = true
+		}
+		fmt.Printf(" :: %-10s %-60s %s\n", name, f.ReprVerbose(), label)
+	}
+	fmt.Println()
+	return hit
+}